@@ -17,23 +17,38 @@ import (
 	otelchimetric "github.com/riandyrn/otelchi/metric"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"google.golang.org/grpc"
 
 	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
 	"github.com/manuelarte/go-web-layout/internal/config"
 	"github.com/manuelarte/go-web-layout/internal/config/info"
 	loggingCfg "github.com/manuelarte/go-web-layout/internal/config/logging"
 	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+	"github.com/manuelarte/go-web-layout/internal/events"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
 	grpc2 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc"
 	usersv1 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
 	"github.com/manuelarte/go-web-layout/internal/infrastructure/api/rest"
 	"github.com/manuelarte/go-web-layout/internal/infrastructure/db"
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/mail"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/outbox"
 	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+	"github.com/manuelarte/go-web-layout/internal/webhooks"
 )
 
 func main() {
@@ -55,7 +70,12 @@ func run() error {
 
 	tracer := otel.Tracer(info.AppName)
 
-	otelShutdown, mp, lp, err := setupOTelSDK(ctx, cfg)
+	redactor, err := redaction.New(cfg.RedactionMode, cfg.RedactionKeys, cfg.RedactionHashSalt)
+	if err != nil {
+		return fmt.Errorf("failed to create redactor: %w", err)
+	}
+
+	otelShutdown, mp, lp, err := setupOTelSDK(ctx, cfg, redactor)
 	if err != nil {
 		return fmt.Errorf("error setting open telemetry: %w", err)
 	}
@@ -64,8 +84,11 @@ func run() error {
 		err = errors.Join(err, otelShutdown(context.Background()))
 	}()
 
-	// Create a bridged slog logger
-	logger := otelslog.NewLogger(info.AppName, otelslog.WithLoggerProvider(lp))
+	// Create a bridged slog logger, redacting the sensitive attributes
+	logger := slog.New(loggingCfg.NewRedactingHandler(
+		otelslog.NewHandler(info.AppName, otelslog.WithLoggerProvider(lp)),
+		redactor,
+	))
 
 	dbConn, err := config.Migrate(goweblayout.ResourcesFolder)
 	if err != nil {
@@ -78,29 +101,78 @@ func run() error {
 		}
 	}(dbConn)
 
-	userRepo := db.NewRepository(dbConn)
+	userRepo, auditRepo, err := newRepositories(dbConn, mp)
+	if err != nil {
+		return err
+	}
 
-	// define base config for metric middlewares
-	baseCfg := otelchimetric.NewBaseConfig(info.AppName, otelchimetric.WithMeterProvider(mp))
+	usersMetrics, err := registerMetrics(dbConn, mp, userRepo)
+	if err != nil {
+		return err
+	}
+
+	wideEventSampler := loggingCfg.WideEventSampler{
+		SuccessRate:   cfg.WideEventSampleRate,
+		SlowThreshold: cfg.WideEventSlowThreshold,
+	}
+
+	limiter, err := newRateLimiter(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create rate limiter: %w", err)
+	}
+
+	idempotencyStore, err := db.NewIdempotencyStore(dbConn, mp)
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency store: %w", err)
+	}
+
+	go deleteExpiredIdempotencyKeys(ctx, idempotencyStore, logger)
+	go purgeDeletedUsers(ctx, userRepo, cfg.DeletedUsersRetention, logger)
+
+	bus := events.NewBus(cfg.EventsHistorySize)
+
+	webhookRepo, dispatcher, err := startDelivery(ctx, cfg, dbConn, mp, logger, bus)
+	if err != nil {
+		return err
+	}
+
+	accountsHandler, err := startAccounts(ctx, cfg, userRepo, usersMetrics, logger)
+	if err != nil {
+		return err
+	}
+
+	apiKeyRepo, apiKeysService, tokenVerifier, err := newAuthentication(ctx, cfg, dbConn, mp)
+	if err != nil {
+		return err
+	}
+
+	createUserService := services.NewCreateUser(userRepo, usersMetrics)
+	importUsersService := services.NewImportUsers(userRepo, usersMetrics, cfg.ImportBatchSize)
 
 	r := chi.NewRouter()
 
 	//nolint:mnd // guess
 	headerTimeout := 4 * time.Second
-	r.Use(
-		loggingCfg.Middleware(logger),
-		addHostValue(),
-		middleware.Logger,
-		otelchi.Middleware(info.AppName, otelchi.WithChiRoutes(r)),
-		otelchimetric.NewServerRequestDuration(baseCfg),
-		otelchimetric.NewServerActiveRequests(baseCfg),
-		otelchimetric.NewServerResponseBodySize(baseCfg),
-		middleware.Recoverer,
-		middleware.RequestID,
-		middleware.ClientIPFromRemoteAddr,
-		middleware.Timeout(headerTimeout),
+	r.Use(httpMiddlewares(r, cfg, logger, mp, wideEventSampler, headerTimeout)...)
+
+	api := rest.API{
+		AccountsHandler: accountsHandler,
+		UsersHandler: rest.NewUsersHandler(
+			cfg,
+			userRepo,
+			auditRepo,
+			createUserService,
+			services.NewUpdateUser(userRepo),
+			services.NewDeleteUser(userRepo, usersMetrics),
+			importUsersService,
+			bus,
+		),
+		WebhooksHandler: rest.NewWebhooksHandler(cfg, webhookRepo, dispatcher),
+		APIKeysHandler:  rest.NewAPIKeysHandler(cfg, apiKeyRepo, apiKeysService),
+	}
+	rest.CreateRestAPI(
+		r, cfg, api, apiKeysService, tokenVerifier, limiter, idempotencyStore, goweblayout.SwaggerUI, goweblayout.OpenAPI,
 	)
-	rest.CreateRestAPI(r, cfg, userRepo, goweblayout.SwaggerUI, goweblayout.OpenAPI)
 
 	srvErr := make(chan error, 1)
 
@@ -119,6 +191,8 @@ func run() error {
 		srvErr <- srv.ListenAndServe()
 	}()
 
+	adminSrv := startAdminServer(ctx, cfg, logger, srvErr)
+
 	listenConfig := net.ListenConfig{}
 
 	lis, err := listenConfig.Listen(ctx, "tcp", cfg.GRPCServeAddress)
@@ -130,29 +204,33 @@ func run() error {
 		interceptorlogging.WithLogOnEvents(),
 	}
 
-	createUserService := services.NewCreateUser(userRepo)
-
-	injectWideEventFn := func(ctx context.Context, req any) (context.Context, bool) {
-		switch req.(type) {
-		case *usersv1.CreateUserRequest:
-			return loggingCfg.AddCreateUserLogEvent(ctx), true
-		default:
-			return ctx, false
-		}
-	}
-
 	s := grpc.NewServer(
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainUnaryInterceptor(
 			interceptorlogging.UnaryServerInterceptor(loggingCfg.InterceptorLogger(logger), loggingOpts...),
 			loggingCfg.AddToContext(logger),
-			loggingCfg.AddCreateUserWideEvent(injectWideEventFn),
+			loggingCfg.WideEventUnaryServerInterceptor(wideEventSampler),
+			grpc2.AuthUnaryServerInterceptor(apiKeysService, tokenVerifier, cfg.AuthRequired),
+			grpc2.TenantUnaryServerInterceptor(),
+			grpc2.RateLimitUnaryServerInterceptor(limiter),
+			grpc2.IdempotencyUnaryServerInterceptor(idempotencyStore, cfg.IdempotencyKeyTTL),
 		),
 		grpc.ChainStreamInterceptor(
 			interceptorlogging.StreamServerInterceptor(loggingCfg.InterceptorLogger(logger), loggingOpts...),
+			loggingCfg.AddToStreamContext(logger),
+			loggingCfg.WideEventStreamServerInterceptor(wideEventSampler),
+			grpc2.AuthStreamServerInterceptor(apiKeysService, tokenVerifier, cfg.AuthRequired),
+			grpc2.TenantStreamServerInterceptor(),
+			grpc2.RateLimitStreamServerInterceptor(limiter),
 		),
 	)
-	usersv1.RegisterUsersServiceServer(s, grpc2.NewServer(createUserService))
+	usersv1.RegisterUsersServiceServer(s, grpc2.NewServer(
+		userRepo,
+		auditRepo,
+		createUserService,
+		importUsersService,
+		bus,
+	))
 	logger.InfoContext(ctx, "Starting gRPC server", slog.Any("addr", lis.Addr()))
 
 	go func() {
@@ -175,9 +253,434 @@ func run() error {
 		return fmt.Errorf("error shutting down http server: %w", errHTTP)
 	}
 
+	if adminSrv != nil {
+		errAdmin := adminSrv.Shutdown(context.Background())
+		if errAdmin != nil {
+			return fmt.Errorf("error shutting down admin server: %w", errAdmin)
+		}
+	}
+
 	return nil
 }
 
+// httpMiddlewares returns the middlewares of all the requests to the HTTP server, in the order they are applied.
+func httpMiddlewares(
+	r chi.Routes,
+	cfg config.AppEnv,
+	logger *slog.Logger,
+	mp metric.MeterProvider,
+	wideEventSampler loggingCfg.WideEventSampler,
+	timeout time.Duration,
+) []func(http.Handler) http.Handler {
+	// define base config for metric middlewares
+	baseCfg := otelchimetric.NewBaseConfig(info.AppName, otelchimetric.WithMeterProvider(mp))
+
+	return []func(http.Handler) http.Handler{
+		loggingCfg.Middleware(logger),
+		addHostValue(),
+		middleware.Logger,
+		otelchi.Middleware(info.AppName, otelchi.WithChiRoutes(r)),
+		otelchimetric.NewServerRequestDuration(baseCfg),
+		otelchimetric.NewServerActiveRequests(baseCfg),
+		otelchimetric.NewServerResponseBodySize(baseCfg),
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.ClientIPFromRemoteAddr,
+		loggingCfg.WideEventMiddleware(wideEventSampler),
+		rest.SecurityHeaders(cfg),
+		rest.CORS(cfg),
+		rest.LimitBody(cfg),
+		skip(middleware.Timeout(timeout), rest.LongRunning),
+	}
+}
+
+// skip applies the middleware to all the requests except the ones matching skipped.
+func skip(mw func(http.Handler) http.Handler, skipped func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withMiddleware := mw(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skipped(r) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			withMiddleware.ServeHTTP(w, r)
+		})
+	}
+}
+
+// newRepositories creates the repositories of the users and of their audit log.
+func newRepositories(dbConn *sql.DB, mp metric.MeterProvider) (db.Repository, db.AuditRepository, error) {
+	userRepo, err := db.NewRepository(dbConn, mp)
+	if err != nil {
+		return db.Repository{}, db.AuditRepository{}, fmt.Errorf("failed to create users repository: %w", err)
+	}
+
+	auditRepo, err := db.NewAuditRepository(dbConn, mp)
+	if err != nil {
+		return db.Repository{}, db.AuditRepository{}, fmt.Errorf("failed to create audit repository: %w", err)
+	}
+
+	return userRepo, auditRepo, nil
+}
+
+// newAuthentication returns the repository and the service of the API keys,
+// and the verifier of the bearer tokens, the requests are authenticated with.
+// The bootstrap admin key is created if it is configured and doesn't exist.
+func newAuthentication(
+	ctx context.Context,
+	cfg config.AppEnv,
+	dbConn *sql.DB,
+	mp metric.MeterProvider,
+) (db.APIKeyRepository, services.APIKeys, oidc.Verifier, error) {
+	apiKeyRepo, err := db.NewAPIKeyRepository(dbConn, mp)
+	if err != nil {
+		return db.APIKeyRepository{}, services.APIKeys{}, oidc.Verifier{},
+			fmt.Errorf("failed to create api key repository: %w", err)
+	}
+
+	apiKeysService := services.NewAPIKeys(apiKeyRepo)
+	if cfg.AuthBootstrapAdminKey != "" {
+		err = apiKeysService.Bootstrap(ctx, apikeys.Secret(cfg.AuthBootstrapAdminKey))
+		if err != nil {
+			return db.APIKeyRepository{}, services.APIKeys{}, oidc.Verifier{},
+				fmt.Errorf("failed to bootstrap admin api key: %w", err)
+		}
+	}
+
+	tokenVerifier, err := newTokenVerifier(cfg)
+	if err != nil {
+		return db.APIKeyRepository{}, services.APIKeys{}, oidc.Verifier{}, err
+	}
+
+	return apiKeyRepo, apiKeysService, tokenVerifier, nil
+}
+
+// newTokenVerifier returns the verifier of the bearer tokens issued by the OpenID Connect issuer,
+// or the zero verifier, that rejects them, if there is no issuer.
+func newTokenVerifier(cfg config.AppEnv) (oidc.Verifier, error) {
+	if cfg.OIDCIssuer == "" {
+		return oidc.Verifier{}, nil
+	}
+
+	roleMapping := make(map[string]auth.Role, len(cfg.OIDCRoleMapping))
+	for value, role := range cfg.OIDCRoleMapping {
+		roleMapping[value] = auth.Role(role)
+	}
+
+	//nolint:mnd // guess
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	verifier, err := oidc.NewVerifier(client, oidc.Config{
+		Issuer:      cfg.OIDCIssuer,
+		Audience:    cfg.OIDCAudience,
+		JWKSURL:     cfg.OIDCJWKSURL,
+		ClockSkew:   cfg.OIDCClockSkew,
+		CacheTTL:    cfg.OIDCJWKSCacheTTL,
+		RolesClaim:  cfg.OIDCRolesClaim,
+		RoleMapping: roleMapping,
+		TenantClaim: cfg.OIDCTenantClaim,
+	})
+	if err != nil {
+		return oidc.Verifier{}, fmt.Errorf("failed to create oidc verifier: %w", err)
+	}
+
+	return verifier, nil
+}
+
+// startAccounts starts in the background the deletion of the expired account tokens and failed logins,
+// returning the handler of the logins, email verifications and password resets.
+// The emails are sent to the SMTP server if configured, otherwise they are written to the directory.
+func startAccounts(
+	ctx context.Context,
+	cfg config.AppEnv,
+	userRepo db.Repository,
+	usersMetrics services.Metrics,
+	logger *slog.Logger,
+) (rest.AccountsHandler, error) {
+	var mailer mail.Mailer = mail.NewFileMailer(cfg.MailDirectory, cfg.MailFrom)
+
+	if cfg.MailSMTPAddress != "" {
+		smtpMailer, err := mail.NewSMTPMailer(cfg.MailSMTPAddress, cfg.MailFrom, cfg.MailSMTPUsername, cfg.MailSMTPPassword)
+		if err != nil {
+			return rest.AccountsHandler{}, fmt.Errorf("failed to create smtp mailer: %w", err)
+		}
+
+		mailer = smtpMailer
+	}
+
+	templates, err := mail.ParseTemplates(goweblayout.ResourcesFolder, "resources/mail/*.tmpl")
+	if err != nil {
+		return rest.AccountsHandler{}, fmt.Errorf("failed to parse mail templates: %w", err)
+	}
+
+	accountRepo := db.NewAccountRepository(userRepo)
+	loginRepo := db.NewLoginRepository(userRepo)
+	accountMailer := services.NewAccountMailer(mailer, templates, cfg.MailLinkBaseURL)
+
+	go deleteExpiredTokens(ctx, accountRepo, logger)
+	go deleteExpiredLoginAttempts(ctx, loginRepo, logger)
+
+	// The IPs are only locked out, not delayed, so the users sharing one are not delayed by the failures of others.
+	usernamePolicy := login.Policy{
+		MaxFailures:     cfg.LoginUsernameMaxFailures,
+		LockoutDuration: cfg.LoginLockoutDuration,
+		BaseDelay:       cfg.LoginBaseDelay,
+		MaxDelay:        cfg.LoginMaxDelay,
+		Window:          cfg.LoginFailureWindow,
+	}
+	ipPolicy := login.Policy{
+		MaxFailures:     cfg.LoginIPMaxFailures,
+		LockoutDuration: cfg.LoginLockoutDuration,
+		Window:          cfg.LoginFailureWindow,
+	}
+
+	return rest.NewAccountsHandler(
+		loginRepo,
+		services.NewLogin(loginRepo, usernamePolicy, ipPolicy, usersMetrics),
+		services.NewEmailVerification(userRepo, accountRepo, accountMailer, cfg.EmailVerificationTokenTTL),
+		services.NewPasswordReset(accountRepo, accountMailer, cfg.PasswordResetTokenTTL),
+	), nil
+}
+
+// registerMetrics registers the metrics of the database pool, of the runtime and of the users,
+// returning the metrics the users services record.
+func registerMetrics(dbConn *sql.DB, mp metric.MeterProvider, userRepo db.Repository) (services.Metrics, error) {
+	err := db.RegisterPoolMetrics(dbConn, mp)
+	if err != nil {
+		return services.Metrics{}, fmt.Errorf("failed to register database pool metrics: %w", err)
+	}
+
+	err = runtime.Start(runtime.WithMeterProvider(mp))
+	if err != nil {
+		return services.Metrics{}, fmt.Errorf("failed to start runtime metrics: %w", err)
+	}
+
+	usersMetrics, err := services.NewMetrics(mp)
+	if err != nil {
+		return services.Metrics{}, fmt.Errorf("failed to create users metrics: %w", err)
+	}
+
+	err = services.RegisterTotalUsers(mp, userRepo)
+	if err != nil {
+		return services.Metrics{}, fmt.Errorf("failed to register total users metric: %w", err)
+	}
+
+	return usersMetrics, nil
+}
+
+// newRateLimiter creates the rate limiter of the REST and gRPC APIs, keeping the token buckets in memory.
+func newRateLimiter(cfg config.AppEnv) (ratelimit.Limiter, error) {
+	defaultLimit, err := ratelimit.ParseLimit(cfg.RateLimitDefault)
+	if err != nil {
+		return ratelimit.Limiter{}, fmt.Errorf("error parsing default rate limit: %w", err)
+	}
+
+	rules, err := ratelimit.ParseRules(cfg.RateLimitRules)
+	if err != nil {
+		return ratelimit.Limiter{}, fmt.Errorf("error parsing rate limit rules: %w", err)
+	}
+
+	return ratelimit.NewLimiter(ratelimit.NewMemoryStore(), defaultLimit, rules), nil
+}
+
+// startDelivery starts in the background the delivery of the user events: the relay of the outbox events,
+// and the dispatcher of the webhook deliveries, returned with the webhook repository to be managed by the API.
+func startDelivery(
+	ctx context.Context,
+	cfg config.AppEnv,
+	dbConn *sql.DB,
+	mp metric.MeterProvider,
+	logger *slog.Logger,
+	bus *events.Bus,
+) (db.WebhookRepository, webhooks.Dispatcher, error) {
+	webhookRepo, err := db.NewWebhookRepository(dbConn, mp)
+	if err != nil {
+		return db.WebhookRepository{}, webhooks.Dispatcher{}, fmt.Errorf("failed to create webhook repository: %w", err)
+	}
+
+	dispatcher := webhooks.NewDispatcher(
+		webhookRepo,
+		webhooks.NewClient(cfg.WebhookTimeout, cfg.WebhookAllowedNetworks),
+		logger,
+		cfg.WebhookPollInterval,
+	)
+
+	relay, err := newRelay(cfg, dbConn, mp, logger, bus, dispatcher)
+	if err != nil {
+		return db.WebhookRepository{}, webhooks.Dispatcher{}, err
+	}
+
+	go dispatcher.Run(ctx)
+	go relay.Run(ctx)
+
+	return webhookRepo, dispatcher, nil
+}
+
+// newRelay creates the relay of the outbox events to the bus of the change feed, to the dispatcher of the webhook
+// subscriptions, and to the configured publishers.
+func newRelay(
+	cfg config.AppEnv,
+	dbConn *sql.DB,
+	mp metric.MeterProvider,
+	logger *slog.Logger,
+	bus *events.Bus,
+	dispatcher webhooks.Dispatcher,
+) (outbox.Relay, error) {
+	publishers := outbox.Publishers{"bus": bus, "webhooks": dispatcher}
+
+	for _, name := range cfg.OutboxPublishers {
+		switch name {
+		case "log":
+			publishers[name] = outbox.NewLogPublisher(logger)
+		case "webhook":
+			if cfg.OutboxWebhookURL == "" {
+				return outbox.Relay{}, errors.New("the webhook outbox publisher requires OUTBOX_WEBHOOK_URL")
+			}
+
+			//nolint:mnd // guess
+			client := &http.Client{Timeout: 10 * time.Second}
+			publishers[name] = outbox.NewWebhookPublisher(client, cfg.OutboxWebhookURL)
+		default:
+			return outbox.Relay{}, fmt.Errorf("unknown outbox publisher %q", name)
+		}
+	}
+
+	store, err := db.NewOutboxStore(dbConn, mp)
+	if err != nil {
+		return outbox.Relay{}, fmt.Errorf("failed to create outbox store: %w", err)
+	}
+
+	relay, err := outbox.NewRelay(store, publishers, mp, logger, cfg.OutboxPollInterval)
+	if err != nil {
+		return outbox.Relay{}, fmt.Errorf("failed to create outbox relay: %w", err)
+	}
+
+	return relay, nil
+}
+
+// deleteExpiredIdempotencyKeys deletes periodically the expired idempotency keys, until the context is done.
+func deleteExpiredIdempotencyKeys(ctx context.Context, store idempotency.Store, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := store.DeleteExpired(ctx, now)
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to delete expired idempotency keys", slog.Any("error", err))
+
+				continue
+			}
+
+			logger.DebugContext(ctx, "Deleted expired idempotency keys", slog.Int64("deleted", deleted))
+		}
+	}
+}
+
+// purgeDeletedUsers purges periodically the users deleted longer than the retention ago, until the context is done.
+func purgeDeletedUsers(ctx context.Context, trash users.Trash, retention time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			purged, err := trash.PurgeDeleted(ctx, now.Add(-retention))
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to purge deleted users", slog.Any("error", err))
+
+				continue
+			}
+
+			logger.DebugContext(ctx, "Purged deleted users", slog.Int64("purged", purged))
+		}
+	}
+}
+
+// deleteExpiredTokens deletes periodically the expired account tokens, until the context is done.
+func deleteExpiredTokens(ctx context.Context, repository accounts.Repository, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := repository.DeleteExpiredTokens(ctx, now)
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to delete expired account tokens", slog.Any("error", err))
+
+				continue
+			}
+
+			logger.DebugContext(ctx, "Deleted expired account tokens", slog.Int64("deleted", deleted))
+		}
+	}
+}
+
+// deleteExpiredLoginAttempts deletes periodically the failed logins forgotten and unlocked, until the context is done.
+func deleteExpiredLoginAttempts(ctx context.Context, repository login.Repository, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := repository.DeleteExpired(ctx, now)
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to delete expired login attempts", slog.Any("error", err))
+
+				continue
+			}
+
+			logger.DebugContext(ctx, "Deleted expired login attempts", slog.Int64("deleted", deleted))
+		}
+	}
+}
+
+// startAdminServer starts, if its address is configured, the admin server with the actuators, metrics and pprof.
+// Any error serving is sent to srvErr.
+func startAdminServer(ctx context.Context, cfg config.AppEnv, logger *slog.Logger, srvErr chan<- error) *http.Server {
+	if cfg.AdminServeAddress == "" {
+		return nil
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		loggingCfg.Middleware(logger),
+		middleware.Recoverer,
+		middleware.RequestID,
+	)
+	rest.CreateAdminAPI(r)
+
+	//nolint:mnd // guess
+	headerTimeout := 4 * time.Second
+	srv := &http.Server{
+		Addr:              cfg.AdminServeAddress,
+		Handler:           r,
+		ReadHeaderTimeout: headerTimeout, // Prevent G112 (CWE-400)
+	}
+
+	logger.InfoContext(ctx, "Starting admin server", slog.String("addr", srv.Addr))
+
+	go func() {
+		srvErr <- srv.ListenAndServe()
+	}()
+
+	return srv
+}
+
 func newPropagator() propagation.TextMapPropagator {
 	return propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{},
@@ -188,6 +691,7 @@ func newPropagator() propagation.TextMapPropagator {
 func setupOTelSDK(
 	ctx context.Context,
 	cfg config.AppEnv,
+	redactor redaction.Redactor,
 ) (func(context.Context) error, *sdkmetric.MeterProvider, *log.LoggerProvider, error) {
 	shutdownFuncs := make([]func(context.Context) error, 3)
 
@@ -219,7 +723,20 @@ func setupOTelSDK(
 	prop := newPropagator()
 	otel.SetTextMapPropagator(prop)
 
-	tp, err := observability.InitTracerProvider(ctx, cfg.OtelExporterEndpoint, cfg.Hostname)
+	sampler, err := observability.NewSampler(cfg.OtelTracesSampler, cfg.OtelTracesSamplerArg)
+	if err != nil {
+		handleErr(err)
+
+		return shutdown, nil, nil, fmt.Errorf("error creating trace sampler: %w", err)
+	}
+
+	tp, err := observability.InitTracerProvider(
+		ctx,
+		cfg.OtelExporterConfig(cfg.OtelTracesExporter),
+		sampler,
+		cfg.Hostname,
+		redactor,
+	)
 	if err != nil {
 		handleErr(err)
 
@@ -229,7 +746,7 @@ func setupOTelSDK(
 	shutdownFuncs[0] = tp.Shutdown
 	otel.SetTracerProvider(tp)
 
-	mp, err := observability.InitMeterProvider(ctx, cfg.OtelExporterEndpoint, cfg.Hostname)
+	mp, err := observability.InitMeterProvider(ctx, cfg.OtelExporterConfig(cfg.OtelMetricsExporter), cfg.Hostname)
 	if err != nil {
 		handleErr(err)
 
@@ -239,7 +756,11 @@ func setupOTelSDK(
 	shutdownFuncs[1] = mp.Shutdown
 	otel.SetMeterProvider(mp)
 
-	loggerProvider, err := observability.InitLoggingProvider(ctx, cfg.OtelExporterEndpoint, cfg.Hostname)
+	loggerProvider, err := observability.InitLoggingProvider(
+		ctx,
+		cfg.OtelExporterConfig(cfg.OtelLogsExporter),
+		cfg.Hostname,
+	)
 	if err != nil {
 		handleErr(err)
 
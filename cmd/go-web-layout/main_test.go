@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/api/rest"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+)
+
+func TestNewAuthentication_BootstrapAdminKey(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dbConn, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = dbConn.Close() })
+
+	secret := "gwl_1a2b3c4d_" + strings.Repeat("a", 26)
+	cfg := config.AppEnv{AuthRequired: true, AuthBootstrapAdminKey: secret}
+
+	var (
+		apiKeyRepo     db.APIKeyRepository
+		apiKeysService services.APIKeys
+		tokenVerifier  oidc.Verifier
+	)
+
+	// The key is created at the first startup, and left as it is at the next one.
+	for range 2 {
+		apiKeyRepo, apiKeysService, tokenVerifier, err = newAuthentication(t.Context(), cfg, dbConn, noop.NewMeterProvider())
+		require.NoError(t, err)
+	}
+
+	idempotencyStore, err := db.NewIdempotencyStore(dbConn, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	rest.CreateRestAPI(
+		r, cfg, rest.API{APIKeysHandler: rest.NewAPIKeysHandler(cfg, apiKeyRepo, apiKeysService)},
+		apiKeysService, tokenVerifier, ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil),
+		idempotencyStore, goweblayout.SwaggerUI, goweblayout.OpenAPI,
+	)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/api-keys", http.NoBody)
+	req.Header.Set("Authorization", apikeys.Scheme+secret)
+
+	w := httptest.NewRecorder()
+
+	// Act
+	r.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"bootstrap"`)
+	assert.Contains(t, w.Body.String(), `"prefix":"gwl_1a2b3c4d"`)
+}
+
+func TestNewAuthentication_WeakBootstrapAdminKey(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	dbConn, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = dbConn.Close() })
+
+	cfg := config.AppEnv{AuthBootstrapAdminKey: "gwl_1a2b3c4d_short"}
+
+	// Act
+	//nolint:dogsled // only the error is asserted
+	_, _, _, err = newAuthentication(t.Context(), cfg, dbConn, noop.NewMeterProvider())
+
+	// Assert
+	require.ErrorIs(t, err, apikeys.ErrWeakSecret)
+}
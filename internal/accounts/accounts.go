@@ -0,0 +1,80 @@
+package accounts
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// Purposes of the tokens, a token can only be used for the purpose it was created for.
+const (
+	PurposeEmailVerification Purpose = "email_verification"
+	PurposePasswordReset     Purpose = "password_reset"
+)
+
+var (
+	// ErrInvalidToken is returned for the tokens that don't exist, were already used, or expired.
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrNoEmail is returned when verifying the email of a user without email.
+	ErrNoEmail = errors.New("user has no email")
+	// ErrEmailAlreadyVerified is returned when verifying the email of a user that was already verified.
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+var _ slog.LogValuer = Token("")
+
+//go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package accounts -destination ./mock.gen.$GOFILE
+type (
+	// Purpose is what a token can be used for.
+	Purpose string
+
+	// Token is the secret sent to the email of a user, to prove they own it.
+	// Only its hash is stored, and it can only be used once before it expires.
+	Token string
+
+	// Repository interface with the methods of the account tokens.
+	Repository interface {
+		// CreateToken stores the token of the purpose for the user, expiring at the time,
+		// invalidating the previous tokens of the user for the purpose.
+		// The email verification tokens are bound to the email the user has when they are created.
+		// Can return either NotFoundError, or any other database error.
+		CreateToken(context.Context, users.UserID, Purpose, Token, time.Time) error
+		// VerifyEmail uses the email verification token, marking the email of its user verified.
+		// Can return either ErrInvalidToken, also if the email of the user changed, or any other database error.
+		VerifyEmail(context.Context, Token) (users.User, error)
+		// ResetPassword uses the password reset token, setting the password of its user,
+		// and invalidates the other password reset tokens of the user.
+		// Can return either ErrInvalidToken, a validation error, or any other database error.
+		ResetPassword(context.Context, Token, users.Password) (users.User, error)
+		// GetByEmail gets the user with the email, compared in its normalized form, the soft-deleted users are not found.
+		// Can return either NotFoundError, with no id, or any other database error.
+		GetByEmail(context.Context, users.Email) (users.User, error)
+		// DeleteExpiredTokens deletes the tokens expired at the time, returning how many.
+		DeleteExpiredTokens(context.Context, time.Time) (int64, error)
+	}
+)
+
+// NewToken returns a new random token, with 128 bits of randomness and URL safe.
+func NewToken() Token {
+	return Token(rand.Text())
+}
+
+// Hash returns the hex SHA-256 of the token, the form it is stored in.
+// The tokens are random, so they don't need a slow password hash.
+func (t Token) Hash() string {
+	sum := sha256.Sum256([]byte(t))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// LogValue masks the token, a token is never logged.
+func (t Token) LogValue() slog.Value {
+	return slog.StringValue(redaction.Masked)
+}
@@ -0,0 +1,237 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: accounts.go
+//
+// Generated by this command:
+//
+//	mockgen -typed -package accounts -source accounts.go -package accounts -destination ./mock.gen.accounts.go
+//
+
+// Package accounts is a generated GoMock package.
+package accounts
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	users "github.com/manuelarte/go-web-layout/internal/users"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateToken mocks base method.
+func (m *MockRepository) CreateToken(arg0 context.Context, arg1 users.UserID, arg2 Purpose, arg3 Token, arg4 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateToken", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateToken indicates an expected call of CreateToken.
+func (mr *MockRepositoryMockRecorder) CreateToken(arg0, arg1, arg2, arg3, arg4 any) *MockRepositoryCreateTokenCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockRepository)(nil).CreateToken), arg0, arg1, arg2, arg3, arg4)
+	return &MockRepositoryCreateTokenCall{Call: call}
+}
+
+// MockRepositoryCreateTokenCall wrap *gomock.Call
+type MockRepositoryCreateTokenCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCreateTokenCall) Return(arg0 error) *MockRepositoryCreateTokenCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCreateTokenCall) Do(f func(context.Context, users.UserID, Purpose, Token, time.Time) error) *MockRepositoryCreateTokenCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCreateTokenCall) DoAndReturn(f func(context.Context, users.UserID, Purpose, Token, time.Time) error) *MockRepositoryCreateTokenCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteExpiredTokens mocks base method.
+func (m *MockRepository) DeleteExpiredTokens(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTokens", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredTokens indicates an expected call of DeleteExpiredTokens.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredTokens(arg0, arg1 any) *MockRepositoryDeleteExpiredTokensCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTokens", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredTokens), arg0, arg1)
+	return &MockRepositoryDeleteExpiredTokensCall{Call: call}
+}
+
+// MockRepositoryDeleteExpiredTokensCall wrap *gomock.Call
+type MockRepositoryDeleteExpiredTokensCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryDeleteExpiredTokensCall) Return(arg0 int64, arg1 error) *MockRepositoryDeleteExpiredTokensCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryDeleteExpiredTokensCall) Do(f func(context.Context, time.Time) (int64, error)) *MockRepositoryDeleteExpiredTokensCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryDeleteExpiredTokensCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockRepositoryDeleteExpiredTokensCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetByEmail mocks base method.
+func (m *MockRepository) GetByEmail(arg0 context.Context, arg1 users.Email) (users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", arg0, arg1)
+	ret0, _ := ret[0].(users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockRepositoryMockRecorder) GetByEmail(arg0, arg1 any) *MockRepositoryGetByEmailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockRepository)(nil).GetByEmail), arg0, arg1)
+	return &MockRepositoryGetByEmailCall{Call: call}
+}
+
+// MockRepositoryGetByEmailCall wrap *gomock.Call
+type MockRepositoryGetByEmailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetByEmailCall) Return(arg0 users.User, arg1 error) *MockRepositoryGetByEmailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetByEmailCall) Do(f func(context.Context, users.Email) (users.User, error)) *MockRepositoryGetByEmailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetByEmailCall) DoAndReturn(f func(context.Context, users.Email) (users.User, error)) *MockRepositoryGetByEmailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ResetPassword mocks base method.
+func (m *MockRepository) ResetPassword(arg0 context.Context, arg1 Token, arg2 users.Password) (users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockRepositoryMockRecorder) ResetPassword(arg0, arg1, arg2 any) *MockRepositoryResetPasswordCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockRepository)(nil).ResetPassword), arg0, arg1, arg2)
+	return &MockRepositoryResetPasswordCall{Call: call}
+}
+
+// MockRepositoryResetPasswordCall wrap *gomock.Call
+type MockRepositoryResetPasswordCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryResetPasswordCall) Return(arg0 users.User, arg1 error) *MockRepositoryResetPasswordCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryResetPasswordCall) Do(f func(context.Context, Token, users.Password) (users.User, error)) *MockRepositoryResetPasswordCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryResetPasswordCall) DoAndReturn(f func(context.Context, Token, users.Password) (users.User, error)) *MockRepositoryResetPasswordCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// VerifyEmail mocks base method.
+func (m *MockRepository) VerifyEmail(arg0 context.Context, arg1 Token) (users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", arg0, arg1)
+	ret0, _ := ret[0].(users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockRepositoryMockRecorder) VerifyEmail(arg0, arg1 any) *MockRepositoryVerifyEmailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockRepository)(nil).VerifyEmail), arg0, arg1)
+	return &MockRepositoryVerifyEmailCall{Call: call}
+}
+
+// MockRepositoryVerifyEmailCall wrap *gomock.Call
+type MockRepositoryVerifyEmailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryVerifyEmailCall) Return(arg0 users.User, arg1 error) *MockRepositoryVerifyEmailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryVerifyEmailCall) Do(f func(context.Context, Token) (users.User, error)) *MockRepositoryVerifyEmailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryVerifyEmailCall) DoAndReturn(f func(context.Context, Token) (users.User, error)) *MockRepositoryVerifyEmailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
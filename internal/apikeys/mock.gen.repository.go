@@ -0,0 +1,237 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -typed -package apikeys -source repository.go -package apikeys -destination ./mock.gen.repository.go
+//
+
+// Package apikeys is a generated GoMock package.
+package apikeys
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	pagination "github.com/manuelarte/go-web-layout/internal/pagination"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(arg0 context.Context, arg1 APIKey) (APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(arg0, arg1 any) *MockRepositoryCreateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1)
+	return &MockRepositoryCreateCall{Call: call}
+}
+
+// MockRepositoryCreateCall wrap *gomock.Call
+type MockRepositoryCreateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCreateCall) Return(arg0 APIKey, arg1 error) *MockRepositoryCreateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCreateCall) Do(f func(context.Context, APIKey) (APIKey, error)) *MockRepositoryCreateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCreateCall) DoAndReturn(f func(context.Context, APIKey) (APIKey, error)) *MockRepositoryCreateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetAll mocks base method.
+func (m *MockRepository) GetAll(arg0 context.Context, arg1 pagination.PageRequest) (pagination.Page[APIKey], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0, arg1)
+	ret0, _ := ret[0].(pagination.Page[APIKey])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRepositoryMockRecorder) GetAll(arg0, arg1 any) *MockRepositoryGetAllCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), arg0, arg1)
+	return &MockRepositoryGetAllCall{Call: call}
+}
+
+// MockRepositoryGetAllCall wrap *gomock.Call
+type MockRepositoryGetAllCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetAllCall) Return(arg0 pagination.Page[APIKey], arg1 error) *MockRepositoryGetAllCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetAllCall) Do(f func(context.Context, pagination.PageRequest) (pagination.Page[APIKey], error)) *MockRepositoryGetAllCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetAllCall) DoAndReturn(f func(context.Context, pagination.PageRequest) (pagination.Page[APIKey], error)) *MockRepositoryGetAllCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetByPrefix mocks base method.
+func (m *MockRepository) GetByPrefix(arg0 context.Context, arg1 string) (APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPrefix indicates an expected call of GetByPrefix.
+func (mr *MockRepositoryMockRecorder) GetByPrefix(arg0, arg1 any) *MockRepositoryGetByPrefixCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPrefix", reflect.TypeOf((*MockRepository)(nil).GetByPrefix), arg0, arg1)
+	return &MockRepositoryGetByPrefixCall{Call: call}
+}
+
+// MockRepositoryGetByPrefixCall wrap *gomock.Call
+type MockRepositoryGetByPrefixCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetByPrefixCall) Return(arg0 APIKey, arg1 error) *MockRepositoryGetByPrefixCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetByPrefixCall) Do(f func(context.Context, string) (APIKey, error)) *MockRepositoryGetByPrefixCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetByPrefixCall) DoAndReturn(f func(context.Context, string) (APIKey, error)) *MockRepositoryGetByPrefixCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MarkUsed mocks base method.
+func (m *MockRepository) MarkUsed(arg0 context.Context, arg1 KeyID, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockRepositoryMockRecorder) MarkUsed(arg0, arg1, arg2 any) *MockRepositoryMarkUsedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockRepository)(nil).MarkUsed), arg0, arg1, arg2)
+	return &MockRepositoryMarkUsedCall{Call: call}
+}
+
+// MockRepositoryMarkUsedCall wrap *gomock.Call
+type MockRepositoryMarkUsedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryMarkUsedCall) Return(arg0 error) *MockRepositoryMarkUsedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryMarkUsedCall) Do(f func(context.Context, KeyID, time.Time) error) *MockRepositoryMarkUsedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryMarkUsedCall) DoAndReturn(f func(context.Context, KeyID, time.Time) error) *MockRepositoryMarkUsedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Revoke mocks base method.
+func (m *MockRepository) Revoke(arg0 context.Context, arg1 KeyID, arg2 time.Time) (APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1, arg2)
+	ret0, _ := ret[0].(APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockRepositoryMockRecorder) Revoke(arg0, arg1, arg2 any) *MockRepositoryRevokeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRepository)(nil).Revoke), arg0, arg1, arg2)
+	return &MockRepositoryRevokeCall{Call: call}
+}
+
+// MockRepositoryRevokeCall wrap *gomock.Call
+type MockRepositoryRevokeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryRevokeCall) Return(arg0 APIKey, arg1 error) *MockRepositoryRevokeCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryRevokeCall) Do(f func(context.Context, KeyID, time.Time) (APIKey, error)) *MockRepositoryRevokeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryRevokeCall) DoAndReturn(f func(context.Context, KeyID, time.Time) (APIKey, error)) *MockRepositoryRevokeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,197 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+)
+
+const (
+	// Scheme is the authorization scheme of the API keys, e.g. "Authorization: ApiKey <key>".
+	Scheme = "ApiKey "
+	// prefixTag starts the prefixes of the keys, so they can be recognized, e.g. by secret scanners.
+	prefixTag = "gwl_"
+	// prefixRandomLength is the number of random characters of the prefixes.
+	prefixRandomLength = 8
+	// prefixLength is the length of the prefixes, e.g. "gwl_1a2b3c4d".
+	prefixLength = len(prefixTag) + prefixRandomLength
+	// secretRandomMinLength is the minimum number of random characters after the prefix of the secrets
+	// given by the operators, as many as the generated ones.
+	secretRandomMinLength = 26
+	// nameMaxLength is the maximum length of the names of the keys.
+	nameMaxLength = 64
+)
+
+var (
+	// ErrInvalidKey is returned for the keys that are malformed, don't exist or were revoked.
+	ErrInvalidKey  = errors.New("invalid or revoked API key")
+	ErrNoName      = errors.New("the name is required")
+	ErrNameTooLong = fmt.Errorf("the name can't be longer than %d characters", nameMaxLength)
+	ErrNoRoles     = errors.New("at least one role is required")
+	ErrPrefixTaken = errors.New("another key has the prefix of the secret")
+	ErrWeakSecret  = fmt.Errorf(
+		"the secret must be %s<%d characters>_<at least %d characters>",
+		prefixTag, prefixRandomLength, secretRandomMinLength,
+	)
+	_ error = new(NotFoundError)
+)
+
+var _ slog.LogValuer = Secret("")
+
+type (
+	// APIKey grants its roles to the clients authenticated with its secret, until it is revoked.
+	// Only the hash of the secret is stored, and its prefix identifies it.
+	APIKey struct {
+		ID   KeyID
+		Name string
+		// Prefix is the start of the secret, that identifies the key without disclosing it, e.g. "gwl_1a2b3c4d".
+		Prefix string
+		// Hash is the hex SHA-256 of the secret.
+		Hash      string
+		Roles     []auth.Role
+		CreatedAt time.Time
+		// LastUsedAt is when the key last authenticated a request, zero if it never did.
+		LastUsedAt time.Time
+		// RevokedAt is when the key was revoked, zero if it wasn't.
+		RevokedAt time.Time
+	}
+
+	// Secret is the key the clients are authenticated with, e.g. "gwl_1a2b3c4d_<random>".
+	// It is only shown when the key is created.
+	Secret string
+
+	KeyID uuid.UUID
+
+	NotFoundError struct {
+		ID KeyID
+	}
+)
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("api key with id %s not found", e.ID.String())
+}
+
+// IsValidationError returns whether the error is due to invalid key fields, so it can be shown to the client.
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrNoName) ||
+		errors.Is(err, ErrNameTooLong) ||
+		errors.Is(err, ErrNoRoles) ||
+		errors.Is(err, auth.ErrUnknownRole)
+}
+
+// New creates the key with the name and the roles, returning it with its secret.
+func New(name string, roles []auth.Role) (APIKey, Secret, error) {
+	secret := Secret(prefixTag + strings.ToLower(rand.Text()[:prefixRandomLength]) + "_" + rand.Text())
+
+	key, err := FromSecret(name, roles, secret)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+// FromSecret creates the key with the name and the roles authenticated with the secret, instead of a random one,
+// e.g. the bootstrap key of the operators. It returns ErrWeakSecret if the secret is malformed or too short.
+func FromSecret(name string, roles []auth.Role, secret Secret) (APIKey, error) {
+	err := errors.Join(ValidateName(name), ValidateRoles(roles))
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	prefix, ok := secret.Prefix()
+	if !ok || len(secret) < prefixLength+1+secretRandomMinLength {
+		return APIKey{}, ErrWeakSecret
+	}
+
+	return APIKey{
+		ID:     KeyID(uuid.New()),
+		Name:   name,
+		Prefix: prefix,
+		Hash:   secret.Hash(),
+		Roles:  slices.Compact(slices.Sorted(slices.Values(roles))),
+	}, nil
+}
+
+// ValidateName returns ErrNoName or ErrNameTooLong if the name is empty or too long.
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrNoName
+	}
+
+	if len([]rune(name)) > nameMaxLength {
+		return ErrNameTooLong
+	}
+
+	return nil
+}
+
+// ValidateRoles returns ErrNoRoles if there are no roles, or auth.ErrUnknownRole for each one that doesn't exist.
+func ValidateRoles(roles []auth.Role) error {
+	if len(roles) == 0 {
+		return ErrNoRoles
+	}
+
+	errs := make([]error, 0, len(roles))
+	for _, role := range roles {
+		errs = append(errs, role.IsValid())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Matches returns whether the secret is the one of the key, comparing their hashes in constant time.
+func (k APIKey) Matches(s Secret) bool {
+	return subtle.ConstantTimeCompare([]byte(k.Hash), []byte(s.Hash())) == 1
+}
+
+// IsRevoked returns whether the key was revoked.
+func (k APIKey) IsRevoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// Principal returns the principal of the requests authenticated with the key, identified by its prefix.
+func (k APIKey) Principal() auth.Principal {
+	return auth.Principal{
+		ID:    "apikey:" + k.Prefix,
+		Roles: k.Roles,
+	}
+}
+
+// Prefix returns the prefix of the secret, false if the secret is malformed.
+func (s Secret) Prefix() (string, bool) {
+	if len(s) <= prefixLength+1 || !strings.HasPrefix(string(s), prefixTag) || s[prefixLength] != '_' {
+		return "", false
+	}
+
+	return string(s[:prefixLength]), true
+}
+
+// Hash returns the hex SHA-256 of the secret, the form it is stored in.
+// The secrets are random, so they don't need a slow password hash.
+func (s Secret) Hash() string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// LogValue masks the secret, a secret is never logged.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redaction.Masked)
+}
+
+func (id KeyID) String() string {
+	return uuid.UUID(id).String()
+}
@@ -0,0 +1,156 @@
+package apikeys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		name    string
+		roles   []auth.Role
+		wantErr []error
+	}{
+		"valid key": {
+			name:  "ci",
+			roles: []auth.Role{auth.RoleWriter, auth.RoleReader, auth.RoleWriter},
+		},
+		"no name": {
+			name:    " ",
+			roles:   []auth.Role{auth.RoleReader},
+			wantErr: []error{ErrNoName},
+		},
+		"name too long": {
+			name:    strings.Repeat("a", nameMaxLength+1),
+			roles:   []auth.Role{auth.RoleReader},
+			wantErr: []error{ErrNameTooLong},
+		},
+		"no roles and unknown role": {
+			name:    "",
+			roles:   []auth.Role{"root"},
+			wantErr: []error{ErrNoName, auth.ErrUnknownRole},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			key, secret, err := New(test.name, test.roles)
+
+			// Assert
+			if len(test.wantErr) > 0 {
+				for _, wantErr := range test.wantErr {
+					require.ErrorIs(t, err, wantErr)
+				}
+
+				assert.True(t, IsValidationError(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []auth.Role{auth.RoleReader, auth.RoleWriter}, key.Roles)
+
+			prefix, ok := secret.Prefix()
+			require.True(t, ok)
+			assert.Equal(t, key.Prefix, prefix)
+			assert.Len(t, prefix, prefixLength)
+			assert.NotContains(t, key.Hash, string(secret))
+			assert.True(t, key.Matches(secret))
+			assert.False(t, key.Matches(secret+"x"))
+		})
+	}
+}
+
+func TestFromSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		secret  Secret
+		roles   []auth.Role
+		wantErr error
+	}{
+		"valid secret": {
+			secret: "gwl_1a2b3c4d_" + Secret(strings.Repeat("a", secretRandomMinLength)),
+			roles:  []auth.Role{auth.RoleAdmin},
+		},
+		"short secret": {
+			secret:  "gwl_1a2b3c4d_" + Secret(strings.Repeat("a", secretRandomMinLength-1)),
+			roles:   []auth.Role{auth.RoleAdmin},
+			wantErr: ErrWeakSecret,
+		},
+		"malformed secret": {
+			secret:  Secret(strings.Repeat("a", prefixLength+1+secretRandomMinLength)),
+			roles:   []auth.Role{auth.RoleAdmin},
+			wantErr: ErrWeakSecret,
+		},
+		"no roles": {
+			secret:  "gwl_1a2b3c4d_" + Secret(strings.Repeat("a", secretRandomMinLength)),
+			wantErr: ErrNoRoles,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			key, err := FromSecret("bootstrap", test.roles, test.secret)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "gwl_1a2b3c4d", key.Prefix)
+			assert.True(t, key.Matches(test.secret))
+		})
+	}
+}
+
+func TestSecret_Prefix(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		secret Secret
+		want   string
+		wantOk bool
+	}{
+		"valid secret": {
+			secret: "gwl_1a2b3c4d_secret",
+			want:   "gwl_1a2b3c4d",
+			wantOk: true,
+		},
+		"without secret": {
+			secret: "gwl_1a2b3c4d_",
+		},
+		"without tag": {
+			secret: "abc_1a2b3c4d_secret",
+		},
+		"short prefix": {
+			secret: "gwl_1a2b_secret",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual, ok := test.secret.Prefix()
+
+			// Assert
+			assert.Equal(t, test.wantOk, ok)
+			assert.Equal(t, test.want, actual)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package apikeys
+
+import (
+	"context"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+)
+
+//go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package apikeys -destination ./mock.gen.$GOFILE
+type (
+	// Repository interface with the API keys repository methods.
+	Repository interface {
+		// Create creates the key.
+		Create(context.Context, APIKey) (APIKey, error)
+		// GetAll gets all the keys paginated, newest first, the revoked ones included.
+		GetAll(context.Context, pagination.PageRequest) (pagination.Page[APIKey], error)
+		// GetByPrefix gets the key with the prefix.
+		// Can return either ErrInvalidKey if there is no key with the prefix, or any other database error.
+		GetByPrefix(context.Context, string) (APIKey, error)
+		// Revoke revokes the key at the time, the keys already revoked keep the time they were revoked at.
+		// Can return either NotFoundError, or any other database error.
+		Revoke(context.Context, KeyID, time.Time) (APIKey, error)
+		// MarkUsed records that the key authenticated a request at the time.
+		MarkUsed(context.Context, KeyID, time.Time) error
+	}
+)
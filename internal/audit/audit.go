@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// Actions on the users recorded in the audit log.
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionRestore is the restore of a soft-deleted user.
+	ActionRestore Action = "restore"
+	// ActionPurge is the permanent deletion of a soft-deleted user.
+	ActionPurge Action = "purge"
+	// ActionLock is the lockout of the username of a user, after too many failed logins.
+	ActionLock Action = "lock"
+	// ActionUnlock is the unlock of the username of a user by an admin.
+	ActionUnlock Action = "unlock"
+)
+
+// AnonymousActor is the actor of the changes made without a principal.
+const AnonymousActor = "anonymous"
+
+// FieldPassword is the field of the password, whose changes are recorded without their values.
+const FieldPassword = "password"
+
+//go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package audit -destination ./mock.gen.$GOFILE
+type (
+	// Action is the kind of change of a user.
+	Action string
+
+	// Entry is the record of a change of a user: who made it, when, and what changed.
+	// The entries are recorded in the same transaction as the changes, and never modified.
+	Entry struct {
+		ID int64
+		// TenantID is the tenant of the user.
+		TenantID tenant.ID
+		// Actor is the principal that made the change, AnonymousActor if there was none.
+		Actor   string
+		Action  Action
+		UserID  users.UserID
+		Changes []Change
+		// RequestID is the id of the request that made the change.
+		RequestID string
+		// TraceID is the trace of the request that made the change, empty if it was not traced.
+		TraceID    string
+		OccurredAt time.Time
+	}
+
+	// Change is the change of a field of the user.
+	// The secret fields, like the password, are recorded without their values.
+	Change struct {
+		Field string `json:"field"`
+		// Before is the value before the change, nil if it was created or is secret.
+		Before *string `json:"before,omitempty"`
+		// After is the value after the change, nil if it was deleted or is secret.
+		After *string `json:"after,omitempty"`
+	}
+
+	// Repository interface with the audit log repository methods.
+	// The entries are created by the users repository with the changes, so there is no method to create them.
+	Repository interface {
+		// GetByUserID gets the entries of the user paginated, newest first.
+		GetByUserID(context.Context, users.UserID, pagination.PageRequest) (pagination.Page[Entry], error)
+	}
+)
+
+// NewEntry returns the entry of the action on the user, made in the request of the context.
+// Before is the user before the change, nil if it was created,
+// and after the user after the change, nil if it was deleted.
+func NewEntry(ctx context.Context, action Action, before, after *users.User, now time.Time) Entry {
+	entry := Entry{
+		TenantID:   tenant.FromContext(ctx),
+		Actor:      AnonymousActor,
+		Action:     action,
+		Changes:    Diff(before, after),
+		RequestID:  middleware.GetReqID(ctx),
+		OccurredAt: now,
+	}
+
+	if before != nil {
+		entry.UserID = before.ID()
+	} else if after != nil {
+		entry.UserID = after.ID()
+	}
+
+	if principal, ok := logging.PrincipalFromContext(ctx); ok {
+		entry.Actor = principal
+	}
+
+	// The gRPC calls have no chi request id, but the one of their wide event.
+	if entry.RequestID == "" {
+		entry.RequestID, _ = logging.RequestIDFromContext(ctx)
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		entry.TraceID = spanContext.TraceID().String()
+	}
+
+	return entry
+}
+
+// Diff returns the changes of the non-secret fields of the user, from before to after.
+// Before is nil if the user was created, and after nil if it was deleted.
+// The password is not part of the users, its change is returned by DiffPassword.
+func Diff(before, after *users.User) []Change {
+	beforeFields, afterFields := fields(before), fields(after)
+	changes := make([]Change, 0, len(fieldNames))
+
+	for i, name := range fieldNames {
+		var b, a *string
+		if beforeFields != nil {
+			b = new(beforeFields[i])
+		}
+
+		if afterFields != nil {
+			a = new(afterFields[i])
+		}
+
+		if b != nil && a != nil && *b == *a {
+			continue
+		}
+
+		changes = append(changes, Change{Field: name, Before: b, After: a})
+	}
+
+	return changes
+}
+
+// DiffPassword returns the change of the password, without its values, if its hash changed from before to after.
+// The before hash is empty if the user was created.
+func DiffPassword(beforeHash, afterHash string) []Change {
+	if beforeHash == afterHash {
+		return nil
+	}
+
+	return []Change{{Field: FieldPassword}}
+}
+
+// fieldNames are the names of the non-secret fields of the users, in the order returned by fields.
+//
+//nolint:gochecknoglobals // read-only list
+var fieldNames = []string{
+	"id", "username", "email", "emailVerifiedAt", "displayName", "locale", "avatarUrl",
+	"createdAt", "updatedAt", "version",
+}
+
+// fields returns the values of the non-secret fields of the user, nil if there is no user.
+func fields(user *users.User) []string {
+	if user == nil {
+		return nil
+	}
+
+	profile := user.Profile()
+
+	var emailVerifiedAt string
+	if verifiedAt, ok := user.EmailVerifiedAt(); ok {
+		emailVerifiedAt = verifiedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return []string{
+		user.ID().String(),
+		string(user.Username()),
+		string(profile.Email),
+		emailVerifiedAt,
+		string(profile.DisplayName),
+		string(profile.Locale),
+		string(profile.AvatarURL),
+		user.CreatedAt().UTC().Format(time.RFC3339Nano),
+		user.UpdatedAt().UTC().Format(time.RFC3339Nano),
+		strconv.FormatInt(user.Version(), 10),
+	}
+}
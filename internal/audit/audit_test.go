@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	id := users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699"))
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	john := users.NewUser(id, createdAt, createdAt, "john", users.Profile{}, 1)
+	jane := users.NewUser(id, createdAt, updatedAt, "jane", users.Profile{DisplayName: "Jane"}, 2)
+
+	tests := map[string]struct {
+		before   *users.User
+		after    *users.User
+		expected []Change
+	}{
+		"created": {
+			after: &john,
+			expected: []Change{
+				{Field: "id", After: new(id.String())},
+				{Field: "username", After: new("john")},
+				{Field: "email", After: new("")},
+				{Field: "emailVerifiedAt", After: new("")},
+				{Field: "displayName", After: new("")},
+				{Field: "locale", After: new("")},
+				{Field: "avatarUrl", After: new("")},
+				{Field: "createdAt", After: new("2025-01-01T00:00:00Z")},
+				{Field: "updatedAt", After: new("2025-01-01T00:00:00Z")},
+				{Field: "version", After: new("1")},
+			},
+		},
+		"updated only has the changed fields": {
+			before: &john,
+			after:  &jane,
+			expected: []Change{
+				{Field: "username", Before: new("john"), After: new("jane")},
+				{Field: "displayName", Before: new(""), After: new("Jane")},
+				{Field: "updatedAt", Before: new("2025-01-01T00:00:00Z"), After: new("2025-01-02T00:00:00Z")},
+				{Field: "version", Before: new("1"), After: new("2")},
+			},
+		},
+		"deleted": {
+			before: &jane,
+			expected: []Change{
+				{Field: "id", Before: new(id.String())},
+				{Field: "username", Before: new("jane")},
+				{Field: "email", Before: new("")},
+				{Field: "emailVerifiedAt", Before: new("")},
+				{Field: "displayName", Before: new("Jane")},
+				{Field: "locale", Before: new("")},
+				{Field: "avatarUrl", Before: new("")},
+				{Field: "createdAt", Before: new("2025-01-01T00:00:00Z")},
+				{Field: "updatedAt", Before: new("2025-01-02T00:00:00Z")},
+				{Field: "version", Before: new("2")},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual := Diff(test.before, test.after)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestDiffPassword(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		beforeHash string
+		afterHash  string
+		expected   []Change
+	}{
+		"created": {
+			afterHash: "$2a$14$created",
+			expected:  []Change{{Field: FieldPassword}},
+		},
+		"changed": {
+			beforeHash: "$2a$14$before",
+			afterHash:  "$2a$14$after",
+			expected:   []Change{{Field: FieldPassword}},
+		},
+		"not changed": {
+			beforeHash: "$2a$14$same",
+			afterHash:  "$2a$14$same",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual := DiffPassword(test.beforeHash, test.afterHash)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
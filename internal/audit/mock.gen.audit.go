@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: audit.go
+//
+// Generated by this command:
+//
+//	mockgen -typed -package audit -source audit.go -package audit -destination ./mock.gen.audit.go
+//
+
+// Package audit is a generated GoMock package.
+package audit
+
+import (
+	context "context"
+	reflect "reflect"
+
+	pagination "github.com/manuelarte/go-web-layout/internal/pagination"
+	users "github.com/manuelarte/go-web-layout/internal/users"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByUserID mocks base method.
+func (m *MockRepository) GetByUserID(arg0 context.Context, arg1 users.UserID, arg2 pagination.PageRequest) (pagination.Page[Entry], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(pagination.Page[Entry])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockRepositoryMockRecorder) GetByUserID(arg0, arg1, arg2 any) *MockRepositoryGetByUserIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockRepository)(nil).GetByUserID), arg0, arg1, arg2)
+	return &MockRepositoryGetByUserIDCall{Call: call}
+}
+
+// MockRepositoryGetByUserIDCall wrap *gomock.Call
+type MockRepositoryGetByUserIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetByUserIDCall) Return(arg0 pagination.Page[Entry], arg1 error) *MockRepositoryGetByUserIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetByUserIDCall) Do(f func(context.Context, users.UserID, pagination.PageRequest) (pagination.Page[Entry], error)) *MockRepositoryGetByUserIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetByUserIDCall) DoAndReturn(f func(context.Context, users.UserID, pagination.PageRequest) (pagination.Page[Entry], error)) *MockRepositoryGetByUserIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
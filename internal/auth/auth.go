@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+// Roles of the principals, each role is granted the permissions of the ones before it.
+const (
+	// RoleReader can read the users.
+	RoleReader Role = "reader"
+	// RoleWriter can also create, update, delete and import the users.
+	RoleWriter Role = "writer"
+	// RoleAdmin can also manage the API keys, the webhooks and the lockouts, and purge the users.
+	RoleAdmin Role = "admin"
+)
+
+var (
+	// ErrUnauthenticated is returned for the anonymous requests to the operations requiring a principal.
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden is returned for the requests of principals without the role the operation requires.
+	ErrForbidden = errors.New("the principal does not have the required role")
+	// ErrUnknownRole is returned for the roles that don't exist.
+	ErrUnknownRole = errors.New("unknown role")
+)
+
+type (
+	// Role is a set of permissions granted to a principal.
+	Role string
+
+	// Principal is the identity that performs a request, with its roles.
+	Principal struct {
+		// ID identifies the principal in the logs and in the audit log, e.g. "apikey:gwl_1a2b3c4d".
+		ID    string
+		Roles []Role
+		// Tenant is the only tenant the principal can act on, empty if it can act on any of them, e.g. the API keys.
+		Tenant tenant.ID
+	}
+
+	principalKey struct{}
+)
+
+// Roles returns the roles, from the least to the most privileged.
+func Roles() []Role {
+	return []Role{RoleReader, RoleWriter, RoleAdmin}
+}
+
+// IsValid returns ErrUnknownRole if the role doesn't exist.
+func (r Role) IsValid() error {
+	if !slices.Contains(Roles(), r) {
+		return fmt.Errorf("%w: %s", ErrUnknownRole, r)
+	}
+
+	return nil
+}
+
+// Grants returns whether the role is granted the permissions of the other role.
+func (r Role) Grants(other Role) bool {
+	rank := slices.Index(Roles(), r)
+
+	return rank >= 0 && rank >= slices.Index(Roles(), other)
+}
+
+// HasAnyRole returns whether the principal is granted any of the roles, true if there are none.
+func (p Principal) HasAnyRole(roles ...Role) bool {
+	if len(roles) == 0 {
+		return true
+	}
+
+	for _, role := range p.Roles {
+		if slices.ContainsFunc(roles, role.Grants) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authorize returns ErrForbidden if the principal is not granted any of the roles.
+func (p Principal) Authorize(roles ...Role) error {
+	if !p.HasAnyRole(roles...) {
+		return fmt.Errorf("%w: requires one of %v", ErrForbidden, roles)
+	}
+
+	return nil
+}
+
+// WithPrincipal returns a copy of the context with the principal of the request,
+// also set as the principal of its wide event, so it is logged and recorded as the actor in the audit log.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	logging.SetPrincipal(ctx, p.ID)
+
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of the request, if it was authenticated.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+
+	return p, ok
+}
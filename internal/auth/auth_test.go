@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrincipal_Authorize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		roles    []Role
+		required []Role
+		wantErr  error
+	}{
+		"no required roles": {},
+		"same role": {
+			roles:    []Role{RoleWriter},
+			required: []Role{RoleWriter},
+		},
+		"more privileged role": {
+			roles:    []Role{RoleAdmin},
+			required: []Role{RoleReader},
+		},
+		"any of the required roles": {
+			roles:    []Role{RoleReader},
+			required: []Role{RoleAdmin, RoleReader},
+		},
+		"less privileged role": {
+			roles:    []Role{RoleReader},
+			required: []Role{RoleWriter},
+			wantErr:  ErrForbidden,
+		},
+		"unknown role": {
+			roles:    []Role{"root"},
+			required: []Role{RoleReader},
+			wantErr:  ErrForbidden,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			principal := Principal{ID: "apikey:gwl_1a2b3c4d", Roles: test.roles}
+
+			// Act
+			err := principal.Authorize(test.required...)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithPrincipal(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	principal := Principal{ID: "apikey:gwl_1a2b3c4d", Roles: []Role{RoleReader}}
+
+	// Act
+	_, okBefore := FromContext(t.Context())
+	actual, ok := FromContext(WithPrincipal(t.Context(), principal))
+
+	// Assert
+	assert.False(t, okBefore)
+	require.True(t, ok)
+	assert.Equal(t, principal, actual)
+}
@@ -1,27 +1,177 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/netip"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/caarlos0/env/v11"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
 )
 
 // AppEnv contains the application environment variables.
 type AppEnv struct {
 	// keep-sorted start
+	// AdminServeAddress is the address to run the admin HTTP server (actuators, metrics and pprof), disabled if empty.
+	AdminServeAddress string `env:"ADMIN_SERVE_ADDRESS"`
+	// AuthBootstrapAdminKey is the secret of an admin API key created at startup if it doesn't exist,
+	// e.g. "gwl_1a2b3c4d_<at least 26 random characters>", so the first keys can be created without OpenID Connect.
+	AuthBootstrapAdminKey string `env:"AUTH_BOOTSTRAP_ADMIN_KEY"`
+	// AuthRequired rejects the anonymous requests to the operations requiring a role,
+	// otherwise only the requests authenticated with an API key are authorized with its roles.
+	AuthRequired bool `env:"AUTH_REQUIRED" envDefault:"false"`
+	// CORSAllowCredentials allows the cross-origin requests with credentials, e.g. cookies,
+	// it can't be used with any origin (*).
+	CORSAllowCredentials bool `env:"CORS_ALLOW_CREDENTIALS" envDefault:"false"`
+	// CORSAllowedHeaders are the request headers the cross-origin requests can send.
+	//nolint:lll // default value
+	CORSAllowedHeaders []string `env:"CORS_ALLOWED_HEADERS" envDefault:"Accept,Authorization,Content-Type,Idempotency-Key,If-Match,If-None-Match,X-Tenant-Id"`
+	// CORSAllowedMethods are the methods the cross-origin requests can use.
+	CORSAllowedMethods []string `env:"CORS_ALLOWED_METHODS" envDefault:"GET,HEAD,POST,PUT,PATCH,DELETE"`
+	// CORSAllowedOrigins are the origins allowed to call the API from a browser, e.g. https://example.com,
+	// or * for any of them. The cross-origin requests are not allowed if empty.
+	CORSAllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS"`
+	// CORSExposedHeaders are the response headers the cross-origin requests can read.
+	//nolint:lll // default value
+	CORSExposedHeaders []string `env:"CORS_EXPOSED_HEADERS" envDefault:"ETag,Location,Retry-After,Idempotent-Replayed,Ratelimit-Limit,Ratelimit-Remaining,Ratelimit-Reset,Ratelimit-Policy"`
+	// CORSMaxAge is how long the browsers cache the responses to the preflight requests.
+	CORSMaxAge time.Duration `env:"CORS_MAX_AGE" envDefault:"10m"`
+	// ContentSecurityPolicy is the Content-Security-Policy of the responses, not set if empty.
+	// The Swagger UI overrides it with a policy allowing its own scripts, styles and images.
+	ContentSecurityPolicy string `env:"CONTENT_SECURITY_POLICY" envDefault:"default-src 'none'; frame-ancestors 'none'"`
+	// DeletedUsersRetention is how long the deleted users are kept, to be restored, before they are purged.
+	DeletedUsersRetention time.Duration `env:"DELETED_USERS_RETENTION" envDefault:"720h"`
+	// EmailVerificationTokenTTL is how long the tokens sent to verify the emails can be used.
+	EmailVerificationTokenTTL time.Duration `env:"EMAIL_VERIFICATION_TOKEN_TTL" envDefault:"24h"`
 	// Env is the application environment.
 	Env string `env:"ENV" envDefault:"local"`
+	// EventsHistorySize is the number of user events kept, to resume the subscriptions to them.
+	EventsHistorySize int `env:"EVENTS_HISTORY_SIZE" envDefault:"1000"`
 	// GRPCServeAddress is the address to run the gRPC server.
 	GRPCServeAddress string `env:"GRPC_SERVE_ADDRESS" envDefault:":3002"`
+	// HSTSMaxAge is how long the browsers only use HTTPS to connect to the server (Strict-Transport-Security),
+	// not set if zero. The browsers ignore it in the responses over HTTP.
+	HSTSMaxAge time.Duration `env:"HSTS_MAX_AGE" envDefault:"8760h"`
+	// HTTPMaxBodySize is the maximum size, in bytes, of the request bodies, the larger ones are rejected with
+	// 413 Content Too Large. Zero is unlimited.
+	HTTPMaxBodySize int64 `env:"HTTP_MAX_BODY_SIZE" envDefault:"1048576"`
+	// HTTPMaxImportBodySize is the maximum size, in bytes, of the request bodies of the bulk import of users,
+	// instead of HTTPMaxBodySize. Zero is unlimited.
+	HTTPMaxImportBodySize int64 `env:"HTTP_MAX_IMPORT_BODY_SIZE" envDefault:"33554432"`
 	// HTTPServeAddress is the address to run the HTTP server.
 	HTTPServeAddress string `env:"HTTP_SERVE_ADDRESS" envDefault:":3001"`
 	// Hostname is the hostname of the server.
 	Hostname string `env:"HOSTNAME"`
+	// IdempotencyKeyTTL is how long the responses of the requests with an idempotency key are replayed.
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	// ImportBatchSize is the number of users created in each transaction of a bulk import.
+	ImportBatchSize int `env:"IMPORT_BATCH_SIZE" envDefault:"100"`
+	// LoginBaseDelay is how long the next login of a username is delayed after a failed one,
+	// doubled after each of the following failures up to LoginMaxDelay.
+	LoginBaseDelay time.Duration `env:"LOGIN_BASE_DELAY" envDefault:"1s"`
+	// LoginFailureWindow is how long the failed logins are remembered, they are forgotten if there is none in it.
+	LoginFailureWindow time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	// LoginIPMaxFailures is the number of consecutive failed logins from an IP locking it out, zero never locks it.
+	LoginIPMaxFailures int `env:"LOGIN_IP_MAX_FAILURES" envDefault:"50"`
+	// LoginLockoutDuration is how long the usernames and the IPs are locked out.
+	LoginLockoutDuration time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+	// LoginMaxDelay caps the delay of the next login of a username after a failed one.
+	LoginMaxDelay time.Duration `env:"LOGIN_MAX_DELAY" envDefault:"30s"`
+	// LoginUsernameMaxFailures is the number of consecutive failed logins of a username locking it out,
+	// zero never locks it.
+	LoginUsernameMaxFailures int `env:"LOGIN_USERNAME_MAX_FAILURES" envDefault:"5"`
+	// MailDirectory is the directory the emails are written to, instead of sent, if MailSMTPAddress is not set.
+	MailDirectory string `env:"MAIL_DIRECTORY" envDefault:"mails"`
+	// MailFrom is the sender of the emails.
+	MailFrom string `env:"MAIL_FROM" envDefault:"Go Web Layout <no-reply@localhost>"`
+	// MailLinkBaseURL is the URL of the frontend the links of the emails point to, e.g. to reset the password.
+	MailLinkBaseURL string `env:"MAIL_LINK_BASE_URL" envDefault:"http://localhost:3000"`
+	// MailSMTPAddress is the address (host:port) of the SMTP server the emails are sent to.
+	MailSMTPAddress string `env:"MAIL_SMTP_ADDRESS"`
+	// MailSMTPPassword is the password of MailSMTPUsername.
+	MailSMTPPassword string `env:"MAIL_SMTP_PASSWORD"`
+	// MailSMTPUsername is the user authenticated in the SMTP server, if any.
+	MailSMTPUsername string `env:"MAIL_SMTP_USERNAME"`
+	// OIDCAudience is the audience the bearer tokens must be issued for, required if OIDCIssuer is set.
+	OIDCAudience string `env:"OIDC_AUDIENCE"`
+	// OIDCClockSkew is the leeway of the expiration, not before and issued at times of the bearer tokens.
+	OIDCClockSkew time.Duration `env:"OIDC_CLOCK_SKEW" envDefault:"1m"`
+	// OIDCIssuer is the issuer of the bearer tokens accepted (Authorization: Bearer <token>), disabled if empty.
+	OIDCIssuer string `env:"OIDC_ISSUER"`
+	// OIDCJWKSCacheTTL is how long the public keys of the issuer are kept, they are fetched again before
+	// if a token is signed with an unknown key.
+	OIDCJWKSCacheTTL time.Duration `env:"OIDC_JWKS_CACHE_TTL" envDefault:"1h"`
+	// OIDCJWKSURL is the URL of the public keys of the issuer, discovered from its OpenID configuration if empty.
+	OIDCJWKSURL string `env:"OIDC_JWKS_URL"`
+	// OIDCRoleMapping maps the values of the roles claim to roles, e.g. gwl-admins=admin,gwl-readers=reader.
+	OIDCRoleMapping map[string]string `env:"OIDC_ROLE_MAPPING" envKeyValSeparator:"="`
+	// OIDCRolesClaim is the claim of the bearer tokens with the roles, nested claims are separated by dots,
+	// e.g. realm_access.roles.
+	OIDCRolesClaim string `env:"OIDC_ROLES_CLAIM" envDefault:"roles"`
+	// OIDCTenantClaim is the claim of the bearer tokens with the tenant of the principal, nested claims are separated
+	// by dots. The tokens without it are rejected if it is set, otherwise the principals can act on any tenant.
+	OIDCTenantClaim string `env:"OIDC_TENANT_CLAIM"`
+	// OtelExporterCertificate is the path to the trusted certificates of the OTLP endpoint.
+	OtelExporterCertificate string `env:"OTEL_EXPORTER_OTLP_CERTIFICATE"`
 	// OtelExporterEndpoint address for the OpenTelemetry exporter.
 	OtelExporterEndpoint string `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	// OtelExporterHeaders are the headers sent to the OTLP endpoint, as key1=value1,key2=value2.
+	OtelExporterHeaders map[string]string `env:"OTEL_EXPORTER_OTLP_HEADERS" envKeyValSeparator:"="`
+	// OtelExporterInsecure disables TLS in the connection to the OTLP endpoint.
+	OtelExporterInsecure bool `env:"OTEL_EXPORTER_OTLP_INSECURE" envDefault:"true"`
+	// OtelExporterProtocol is the OTLP protocol, grpc or http/protobuf.
+	OtelExporterProtocol observability.Protocol `env:"OTEL_EXPORTER_OTLP_PROTOCOL" envDefault:"grpc"`
+	// OtelLogsExporter is the logs exporter (otlp, console or none), it depends on OtelExporterEndpoint if not set.
+	OtelLogsExporter observability.Exporter `env:"OTEL_LOGS_EXPORTER"`
+	// OtelMetricsExporter is the metrics exporter (otlp, console or none), it depends on OtelExporterEndpoint if not set.
+	OtelMetricsExporter observability.Exporter `env:"OTEL_METRICS_EXPORTER"`
+	// OtelTracesExporter is the traces exporter (otlp, console or none), it depends on OtelExporterEndpoint if not set.
+	OtelTracesExporter observability.Exporter `env:"OTEL_TRACES_EXPORTER"`
+	// OtelTracesSampler is the traces sampler, e.g. parentbased_traceidratio.
+	OtelTracesSampler string `env:"OTEL_TRACES_SAMPLER" envDefault:"parentbased_always_on"`
+	// OtelTracesSamplerArg is the argument of the traces sampler, e.g. the ratio 0.25 for parentbased_traceidratio.
+	OtelTracesSamplerArg string `env:"OTEL_TRACES_SAMPLER_ARG"`
+	// OutboxPollInterval is how often the outbox is checked for events pending to be delivered.
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"500ms"`
+	// OutboxPublishers are the publishers the user events are delivered to, besides the change feed: log and webhook.
+	OutboxPublishers []string `env:"OUTBOX_PUBLISHERS" envDefault:"log"`
+	// OutboxWebhookURL is the URL the user events are posted to by the webhook publisher.
+	OutboxWebhookURL string `env:"OUTBOX_WEBHOOK_URL"`
+	// PasswordResetTokenTTL is how long the tokens sent to reset the passwords can be used.
+	PasswordResetTokenTTL time.Duration `env:"PASSWORD_RESET_TOKEN_TTL" envDefault:"1h"`
+	// RateLimitDefault is the rate limit of each client to the routes without rule, as <requests>/<period>.
+	// Empty is unlimited.
+	RateLimitDefault string `env:"RATE_LIMIT_DEFAULT" envDefault:"100/1s"`
+	// RateLimitRules are the rate limits of each client per route, e.g. GET /api/v1/users=10/1s,
+	// or per RPC, e.g. /users.v1.UsersService/CreateUser=5/1m.
+	RateLimitRules map[string]string `env:"RATE_LIMIT_RULES" envKeyValSeparator:"="`
+	// RedactionHashSalt is the secret key used to hash the sensitive values, required when RedactionMode is hash.
+	RedactionHashSalt string `env:"REDACTION_HASH_SALT"`
+	// RedactionKeys are the log and span attribute keys whose values are sensitive.
+	//nolint:lll // default value
+	RedactionKeys []string `env:"REDACTION_KEYS" envDefault:"username,password,email,grpc.request.content,grpc.response.content"`
+	// RedactionMode is how sensitive values are redacted (none, mask or hash), it depends on Env if not set.
+	RedactionMode redaction.Mode `env:"REDACTION_MODE"`
 	// ServerID is the server id.
 	ServerID string `env:"SERVER_ID" envDefault:"local"`
+	// WebhookAllowedNetworks are the networks the webhook deliveries can reach even if they are not public,
+	// e.g. 127.0.0.0/8 to subscribe local services. The rest of the loopback, link-local and private addresses
+	// are rejected.
+	WebhookAllowedNetworks []netip.Prefix `env:"WEBHOOK_ALLOWED_NETWORKS"`
+	// WebhookPollInterval is how often the webhook deliveries due to be sent are checked.
+	WebhookPollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"1s"`
+	// WebhookTimeout is how long an attempt of a webhook delivery waits for the response.
+	WebhookTimeout time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"3s"`
+	// WideEventSampleRate is the ratio of successful requests logged as wide events.
+	// Failed and slow requests are always logged.
+	WideEventSampleRate float64 `env:"WIDE_EVENT_SAMPLE_RATE" envDefault:"0.1"`
+	// WideEventSlowThreshold is the duration after which a request is considered slow.
+	WideEventSlowThreshold time.Duration `env:"WIDE_EVENT_SLOW_THRESHOLD" envDefault:"500ms"`
 	// keep-sorted end
 }
 
@@ -40,5 +190,25 @@ func GetAppEnv() (AppEnv, error) {
 		cfg.Hostname = hostname
 	}
 
+	if cfg.CORSAllowCredentials && slices.Contains(cfg.CORSAllowedOrigins, "*") {
+		return AppEnv{}, errors.New("the cross-origin requests with credentials can't be allowed from any origin")
+	}
+
+	if cfg.RedactionMode == "" {
+		cfg.RedactionMode = redaction.ModeForEnv(cfg.Env)
+	}
+
 	return cfg, nil
 }
+
+// OtelExporterConfig returns the OpenTelemetry configuration of the given signal exporter.
+func (a AppEnv) OtelExporterConfig(exporter observability.Exporter) observability.ExporterConfig {
+	return observability.ExporterConfig{
+		Exporter:    exporter,
+		Endpoint:    a.OtelExporterEndpoint,
+		Protocol:    a.OtelExporterProtocol,
+		Insecure:    a.OtelExporterInsecure,
+		Certificate: a.OtelExporterCertificate,
+		Headers:     a.OtelExporterHeaders,
+	}
+}
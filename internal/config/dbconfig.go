@@ -9,6 +9,9 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	sqlite "github.com/mattn/go-sqlite3"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
 )
 
 func Migrate(resourcesFolder embed.FS, name ...string) (*sql.DB, error) {
@@ -19,10 +22,8 @@ func Migrate(resourcesFolder embed.FS, name ...string) (*sql.DB, error) {
 
 	dsn := fmt.Sprintf("file:%s?cache=shared&mode=memory", dbName)
 
-	db, err := sql.Open("sqlite3", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
+	// The rows of the queries are tracked, so their spans end once they are read.
+	db := sql.OpenDB(observability.NewRowsConnector(&sqlite.SQLiteDriver{}, dsn))
 
 	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
 	if err != nil {
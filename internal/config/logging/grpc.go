@@ -4,10 +4,20 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/google/uuid"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
 )
 
+// RequestIDMetadataKey is the gRPC metadata key used to propagate the request id.
+const RequestIDMetadataKey = "x-request-id"
+
 // InterceptorLogger adapts slog logger to interceptor logger.
 // This code is simple enough to be copied and not imported.
 func InterceptorLogger(l *slog.Logger) logging.Logger {
@@ -27,3 +37,81 @@ func AddToContext(logger *slog.Logger) grpc.UnaryServerInterceptor {
 		return handler(withLogger(ctx, logger), req)
 	}
 }
+
+// AddToStreamContext returns a gRPC stream server interceptor that injects the logger into the context.
+func AddToStreamContext(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = withLogger(ss.Context(), logger)
+
+		return handler(srv, wrapped)
+	}
+}
+
+// WideEventUnaryServerInterceptor returns a gRPC unary server interceptor that creates a wide event per call,
+// and emits it once the call is finished.
+func WideEventUnaryServerInterceptor(sampler WideEventSampler) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, event := newWideEvent(ctx, observability.ChannelGRPC, grpcRequestID(ctx))
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		event.finish(info.FullMethod, code.String(), grpcCodeLevel(code))
+		event.emit(ctx, FromContext(ctx), sampler)
+
+		return resp, err
+	}
+}
+
+// WideEventStreamServerInterceptor returns a gRPC stream server interceptor that creates a wide event per stream,
+// and emits it once the stream is finished.
+func WideEventStreamServerInterceptor(sampler WideEventSampler) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, event := newWideEvent(ss.Context(), observability.ChannelGRPC, grpcRequestID(ss.Context()))
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+
+		err := handler(srv, wrapped)
+
+		code := status.Code(err)
+		event.finish(info.FullMethod, code.String(), grpcCodeLevel(code))
+		event.emit(ctx, FromContext(ctx), sampler)
+
+		return err
+	}
+}
+
+// grpcRequestID returns the request id sent by the client, or generates a new one.
+// The request id is sent back to the client in the response headers.
+func grpcRequestID(ctx context.Context) string {
+	requestID := ""
+	if values := metadata.ValueFromIncomingContext(ctx, RequestIDMetadataKey); len(values) > 0 {
+		requestID = values[0]
+	}
+
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, requestID))
+
+	return requestID
+}
+
+func grpcCodeLevel(code codes.Code) slog.Level {
+	//nolint:exhaustive // the rest of the codes are client errors
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
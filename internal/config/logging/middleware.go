@@ -1,8 +1,15 @@
 package logging
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
 )
 
 // Middleware returns a middleware that injects the given logger into the request context.
@@ -14,3 +21,57 @@ func Middleware(logger *slog.Logger) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// WideEventMiddleware returns a middleware that creates a wide event per request,
+// and emits it once the request is finished.
+// It needs to be placed after the [middleware.RequestID] middleware and the tracing middleware.
+func WideEventMiddleware(sampler WideEventSampler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, event := newWideEvent(r.Context(), observability.ChannelREST, middleware.GetReqID(r.Context()))
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				rec := recover()
+				if rec != nil {
+					status = http.StatusInternalServerError
+
+					AddError(ctx, "panic", fmt.Errorf("%v", rec))
+				}
+
+				event.finish(r.Method+" "+routePattern(r), strconv.Itoa(status), httpStatusLevel(status))
+				event.emit(ctx, FromContext(ctx), sampler)
+
+				if rec != nil {
+					panic(rec)
+				}
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		})
+	}
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+
+	return r.URL.Path
+}
+
+func httpStatusLevel(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+)
+
+var _ slog.Handler = new(redactingHandler)
+
+// redactingHandler is a slog.Handler that redacts the sensitive attributes before passing them to the next handler.
+type redactingHandler struct {
+	next     slog.Handler
+	redactor redaction.Redactor
+}
+
+// NewRedactingHandler wraps the handler so the sensitive attributes are redacted.
+// An attribute is sensitive if its value implements [redaction.Sensitive], or if its key is configured as sensitive.
+func NewRedactingHandler(next slog.Handler, redactor redaction.Redactor) slog.Handler {
+	return redactingHandler{
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+func (h redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+
+		return true
+	})
+
+	//nolint:wrapcheck // decorator
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return redactingHandler{
+		next:     h.next.WithAttrs(h.redactAttrs(attrs)),
+		redactor: h.redactor,
+	}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{
+		next:     h.next.WithGroup(name),
+		redactor: h.redactor,
+	}
+}
+
+func (h redactingHandler) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactAttr(attr)
+	}
+
+	return redacted
+}
+
+func (h redactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	if sensitive, ok := sensitiveValue(attr.Value); ok {
+		return slog.String(attr.Key, h.redactor.Redact(sensitive.SensitiveValue()))
+	}
+
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(h.redactAttrs(value.Group())...)}
+	}
+
+	if h.redactor.Enabled() && h.redactor.IsSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, h.redactor.Redact(value.String()))
+	}
+
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+func sensitiveValue(value slog.Value) (redaction.Sensitive, bool) {
+	if value.Kind() == slog.KindLogValuer {
+		sensitive, ok := value.LogValuer().(redaction.Sensitive)
+
+		return sensitive, ok
+	}
+
+	if value.Kind() == slog.KindAny {
+		sensitive, ok := value.Any().(redaction.Sensitive)
+
+		return sensitive, ok
+	}
+
+	return nil, false
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+)
+
+type sensitiveString string
+
+func (s sensitiveString) LogValue() slog.Value {
+	return slog.StringValue("[REDACTED]")
+}
+
+func (s sensitiveString) SensitiveValue() string {
+	return string(s)
+}
+
+func TestRedactingHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mode     redaction.Mode
+		expected map[string]any
+	}{
+		"none keeps the values": {
+			mode: redaction.ModeNone,
+			expected: map[string]any{
+				"username": "manuelarte",
+				"email":    "manuel@example.com",
+				"group":    map[string]any{"email": "manuel@example.com", "id": "1234"},
+				"id":       "1234",
+			},
+		},
+		"mask replaces the values": {
+			mode: redaction.ModeMask,
+			expected: map[string]any{
+				"username": redaction.Masked,
+				"email":    redaction.Masked,
+				"group":    map[string]any{"email": redaction.Masked, "id": "1234"},
+				"id":       "1234",
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			redactor, err := redaction.New(test.mode, []string{"email"}, "")
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+
+			logger := slog.New(NewRedactingHandler(slog.NewJSONHandler(&buf, nil), redactor))
+
+			// Act
+			logger.InfoContext(
+				t.Context(),
+				"msg",
+				slog.Any("username", sensitiveString("manuelarte")),
+				slog.String("email", "manuel@example.com"),
+				slog.Group("group", slog.String("email", "manuel@example.com"), slog.String("id", "1234")),
+				slog.String("id", "1234"),
+			)
+
+			// Assert
+			var actual map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+
+			for key, value := range test.expected {
+				assert.Equal(t, value, actual[key], key)
+			}
+		})
+	}
+}
+
+func TestRedactingHandler_Hash(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	redactor, err := redaction.New(redaction.ModeHash, []string{"email"}, "salt")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	logger := slog.New(NewRedactingHandler(slog.NewJSONHandler(&buf, nil), redactor)).
+		With(slog.String("email", "manuel@example.com"))
+
+	// Act
+	logger.InfoContext(t.Context(), "msg", slog.Any("username", sensitiveString("manuelarte")))
+
+	// Assert
+	var actual map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+
+	email, _ := actual["email"].(string)
+	username, _ := actual["username"].(string)
+
+	assert.True(t, strings.HasPrefix(email, "hash:"), email)
+	assert.True(t, strings.HasPrefix(username, "hash:"), username)
+	assert.Equal(t, redactor.Redact("manuel@example.com"), email)
+}
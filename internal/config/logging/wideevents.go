@@ -3,95 +3,88 @@ package logging
 import (
 	"context"
 	"log/slog"
+	"math/rand/v2"
 	"sync"
+	"time"
 
-	"google.golang.org/grpc"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
 )
 
-type (
-	createUserLogKey struct{}
+const anonymousPrincipal = "anonymous"
 
-	createUserLogEvent struct {
-		mu       sync.RWMutex
-		Username string `json:"username"`
-		UserID   string `json:"userId"`
-		Error    createUserError
+type (
+	wideEventKey struct{}
+
+	// WideEvent accumulates everything that happened during a single request,
+	// so it can be emitted once, at the end of the request, as a single structured log line.
+	WideEvent struct {
+		mu        sync.RWMutex
+		channel   string
+		start     time.Time
+		requestID string
+		traceID   string
+		name      string
+		status    string
+		level     slog.Level
+		principal string
+		attrs     []slog.Attr
+		err       *wideEventError
 	}
 
-	createUserError struct {
+	wideEventError struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 	}
+
+	// WideEventSampler decides which wide events are emitted (tail sampling).
+	// Errors and slow requests are always kept, successful requests are kept with a probability of SuccessRate.
+	WideEventSampler struct {
+		// SuccessRate is the ratio, between 0 and 1, of successful requests that are emitted.
+		SuccessRate float64
+		// SlowThreshold is the duration after which a request is considered slow.
+		SlowThreshold time.Duration
+	}
 )
 
-func AddCreateUserLogEvent(ctx context.Context) context.Context {
-	event := &createUserLogEvent{}
+// newWideEvent creates a new wide event and attaches it, and the channel, to the returned context.
+func newWideEvent(ctx context.Context, channel, requestID string) (context.Context, *WideEvent) {
+	event := &WideEvent{
+		channel:   channel,
+		start:     time.Now(),
+		requestID: requestID,
+		traceID:   traceID(ctx),
+		level:     slog.LevelInfo,
+	}
 
-	return context.WithValue(ctx, createUserLogKey{}, event)
-}
+	ctx = observability.WithChannel(ctx, channel)
 
-func (we *createUserLogEvent) isSuccessful() bool {
-	return !we.isError()
+	return context.WithValue(ctx, wideEventKey{}, event), event
 }
 
-func (we *createUserLogEvent) isError() bool {
-	we.mu.RLock()
-	defer we.mu.RUnlock()
+func wideEventFromContext(ctx context.Context) (*WideEvent, bool) {
+	event, ok := ctx.Value(wideEventKey{}).(*WideEvent)
 
-	return we.Error.Type != ""
+	return event, ok
 }
 
-func (we *createUserLogEvent) mapToArgs() []any {
-	we.mu.RLock()
-	defer we.mu.RUnlock()
-
-	return []any{
-		slog.String("username", we.Username),
-		slog.String("userId", we.UserID),
-		slog.Group("error", slog.String("type", we.Error.Type), slog.String("message", we.Error.Message)),
+// AddAttrs adds the attributes to the wide event of the request, if any.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	event, ok := wideEventFromContext(ctx)
+	if !ok {
+		return
 	}
-}
 
-// AddCreateUserWideEvent returns a gRPC unary server interceptor that injects
-// the create user wide event into the context.
-func AddCreateUserWideEvent(
-	injectWideEventFn func(ctx context.Context, req any) (context.Context, bool),
-) grpc.UnaryServerInterceptor {
-	return func(
-		ctx context.Context,
-		req any,
-		info *grpc.UnaryServerInfo,
-		handler grpc.UnaryHandler,
-	) (any, error) {
-		event := &createUserLogEvent{}
-
-		ctx, ok := injectWideEventFn(ctx, req)
-
-		toReturnAny, toReturnErr := handler(ctx, req)
-		if !ok {
-			return handler(ctx, req)
-		}
-
-		if event.isSuccessful() {
-			FromContext(ctx).InfoContext(
-				ctx,
-				"User created",
-				event.mapToArgs()...,
-			)
-		} else {
-			FromContext(ctx).ErrorContext(
-				ctx,
-				"Error creating user",
-				event.mapToArgs()...,
-			)
-		}
-
-		return toReturnAny, toReturnErr
-	}
+	event.mu.Lock()
+	defer event.mu.Unlock()
+
+	event.attrs = append(event.attrs, attrs...)
 }
 
-func AddUsername(ctx context.Context, username string) {
-	event, ok := ctx.Value(createUserLogKey{}).(*createUserLogEvent)
+// SetPrincipal sets the identity that performed the request.
+func SetPrincipal(ctx context.Context, principal string) {
+	event, ok := wideEventFromContext(ctx)
 	if !ok {
 		return
 	}
@@ -99,23 +92,35 @@ func AddUsername(ctx context.Context, username string) {
 	event.mu.Lock()
 	defer event.mu.Unlock()
 
-	event.Username = username
+	event.principal = principal
 }
 
-func AddUserID(ctx context.Context, userID string) {
-	event, ok := ctx.Value(createUserLogKey{}).(*createUserLogEvent)
+// PrincipalFromContext returns the identity that performed the request, if it was set.
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	event, ok := wideEventFromContext(ctx)
 	if !ok {
-		return
+		return "", false
 	}
 
-	event.mu.Lock()
-	defer event.mu.Unlock()
+	event.mu.RLock()
+	defer event.mu.RUnlock()
 
-	event.UserID = userID
+	return event.principal, event.principal != ""
 }
 
+// RequestIDFromContext returns the id of the request, sent by the client or generated, if there is a wide event.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	event, ok := wideEventFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	return event.requestID, event.requestID != ""
+}
+
+// AddError flags the wide event of the request as failed, with the error type and message.
 func AddError(ctx context.Context, errType string, err error) {
-	event, ok := ctx.Value(createUserLogKey{}).(*createUserLogEvent)
+	event, ok := wideEventFromContext(ctx)
 	if !ok {
 		return
 	}
@@ -123,8 +128,81 @@ func AddError(ctx context.Context, errType string, err error) {
 	event.mu.Lock()
 	defer event.mu.Unlock()
 
-	event.Error = createUserError{
+	event.err = &wideEventError{
 		Type:    errType,
 		Message: err.Error(),
 	}
 }
+
+// finish sets the operation name, the final status and the log level the wide event is emitted with.
+func (we *WideEvent) finish(name, status string, level slog.Level) {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+
+	we.name = name
+	we.status = status
+	we.level = level
+
+	if we.err != nil && we.level < slog.LevelError {
+		we.level = slog.LevelError
+	}
+}
+
+// emit logs the wide event if the sampler keeps it.
+func (we *WideEvent) emit(ctx context.Context, logger *slog.Logger, sampler WideEventSampler) {
+	duration := time.Since(we.start)
+
+	we.mu.RLock()
+	defer we.mu.RUnlock()
+
+	sampleRate, keep := sampler.keep(we.level > slog.LevelInfo, duration)
+	if !keep {
+		return
+	}
+
+	principal := we.principal
+	if principal == "" {
+		principal = anonymousPrincipal
+	}
+
+	attrs := make([]slog.Attr, 0, len(we.attrs)+9)
+	attrs = append(attrs,
+		slog.String("channel", we.channel),
+		slog.String("status", we.status),
+		slog.Duration("duration", duration),
+		slog.String("principal", principal),
+		slog.String("requestId", we.requestID),
+		slog.String("traceId", we.traceID),
+		slog.Float64("sampleRate", sampleRate),
+	)
+	attrs = append(attrs, we.attrs...)
+
+	if we.err != nil {
+		attrs = append(attrs, slog.Group("error", slog.String("type", we.err.Type), slog.String("message", we.err.Message)))
+	}
+
+	logger.LogAttrs(ctx, we.level, we.name, attrs...)
+}
+
+// keep returns whether the event must be emitted, and the sample rate that was applied to it.
+func (s WideEventSampler) keep(failed bool, duration time.Duration) (float64, bool) {
+	if failed || (s.SlowThreshold > 0 && duration >= s.SlowThreshold) {
+		return 1, true
+	}
+
+	if s.SuccessRate >= 1 {
+		return 1, true
+	}
+
+	//nolint:gosec // sampling does not need a cryptographically secure random number
+	return s.SuccessRate, rand.Float64() < s.SuccessRate
+}
+
+func traceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWideEventMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		sampler  WideEventSampler
+		handler  http.HandlerFunc
+		expected map[string]any
+	}{
+		"successful request is emitted with its attributes": {
+			sampler: WideEventSampler{SuccessRate: 1},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				AddAttrs(r.Context(), slog.String("userId", "1234"))
+				SetPrincipal(r.Context(), "manuelarte")
+				w.WriteHeader(http.StatusOK)
+			},
+			expected: map[string]any{
+				"level":      "INFO",
+				"msg":        "GET /users/{id}",
+				"channel":    "rest",
+				"status":     "200",
+				"principal":  "manuelarte",
+				"userId":     "1234",
+				"sampleRate": float64(1),
+			},
+		},
+		"successful request is dropped by the sampler": {
+			sampler: WideEventSampler{SuccessRate: 0},
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			expected: nil,
+		},
+		"client error is always emitted": {
+			sampler: WideEventSampler{SuccessRate: 0},
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expected: map[string]any{
+				"level":      "WARN",
+				"msg":        "GET /users/{id}",
+				"channel":    "rest",
+				"status":     "404",
+				"principal":  "anonymous",
+				"sampleRate": float64(1),
+			},
+		},
+		"error added to the event is always emitted": {
+			sampler: WideEventSampler{SuccessRate: 0},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				AddError(r.Context(), "db", errors.New("connection refused"))
+				w.WriteHeader(http.StatusOK)
+			},
+			expected: map[string]any{
+				"level":      "ERROR",
+				"msg":        "GET /users/{id}",
+				"channel":    "rest",
+				"status":     "200",
+				"principal":  "anonymous",
+				"sampleRate": float64(1),
+				"error": map[string]any{
+					"type":    "db",
+					"message": "connection refused",
+				},
+			},
+		},
+		"slow request is always emitted": {
+			sampler: WideEventSampler{SuccessRate: 0, SlowThreshold: time.Millisecond},
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(2 * time.Millisecond)
+				w.WriteHeader(http.StatusOK)
+			},
+			expected: map[string]any{
+				"level":      "INFO",
+				"msg":        "GET /users/{id}",
+				"channel":    "rest",
+				"status":     "200",
+				"principal":  "anonymous",
+				"sampleRate": float64(1),
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			var buf bytes.Buffer
+
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			r := chi.NewRouter()
+			r.Use(Middleware(logger), middleware.RequestID, WideEventMiddleware(test.sampler))
+			r.Get("/users/{id}", test.handler)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "/users/1234", http.NoBody)
+			require.NoError(t, err)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			if test.expected == nil {
+				assert.Empty(t, buf.String())
+
+				return
+			}
+
+			var actual map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+			assert.NotEmpty(t, actual["requestId"])
+			assert.Contains(t, actual, "duration")
+
+			for key, value := range test.expected {
+				assert.Equal(t, value, actual[key], key)
+			}
+		})
+	}
+}
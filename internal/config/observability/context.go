@@ -9,11 +9,25 @@ import (
 	"github.com/manuelarte/go-web-layout/internal/config/info"
 )
 
+const (
+	// ChannelREST is the channel of the requests received through the REST API.
+	ChannelREST = "rest"
+	// ChannelGRPC is the channel of the requests received through the gRPC API.
+	ChannelGRPC = "grpc"
+	// ChannelUnknown is the channel of the operations not started by a request.
+	ChannelUnknown = "unknown"
+)
+
 //nolint:gochecknoglobals // Context key used for tracing.
 var contextKey = key{}
 
-// Context tracing value to be passed through the stack trace through [context.Context].
-type key struct{}
+type (
+	// Context tracing value to be passed through the stack trace through [context.Context].
+	key struct{}
+
+	// channelKey is the context key of the channel the request was received from.
+	channelKey struct{}
+)
 
 func AddContext(ctx context.Context, tracer trace.Tracer) context.Context {
 	return context.WithValue(ctx, contextKey, tracer)
@@ -34,3 +48,17 @@ func getOrNewTracer(ctx context.Context) trace.Tracer {
 
 	return otel.Tracer(info.AppName)
 }
+
+// WithChannel returns a new context with the channel (e.g. ChannelREST) the request was received from.
+func WithChannel(ctx context.Context, channel string) context.Context {
+	return context.WithValue(ctx, channelKey{}, channel)
+}
+
+// ChannelFromContext returns the channel the request was received from, or ChannelUnknown.
+func ChannelFromContext(ctx context.Context) string {
+	if channel, ok := ctx.Value(channelKey{}).(string); ok {
+		return channel
+	}
+
+	return ChannelUnknown
+}
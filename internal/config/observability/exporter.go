@@ -0,0 +1,310 @@
+package observability
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	stdout "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// ExporterOTLP exports the telemetry to an OTLP endpoint.
+	ExporterOTLP Exporter = "otlp"
+	// ExporterConsole pretty prints the telemetry to the standard output.
+	ExporterConsole Exporter = "console"
+	// ExporterNone does not export the telemetry.
+	ExporterNone Exporter = "none"
+
+	// ProtocolGRPC is OTLP over gRPC.
+	ProtocolGRPC Protocol = "grpc"
+	// ProtocolHTTP is OTLP over HTTP with protobuf payloads.
+	ProtocolHTTP Protocol = "http/protobuf"
+)
+
+var (
+	_ error = new(UnknownExporterError)
+	_ error = new(UnknownProtocolError)
+)
+
+type (
+	// Exporter is where the telemetry of a signal (traces, metrics or logs) is exported to.
+	Exporter string
+
+	// Protocol is the transport used by the OTLP exporters.
+	Protocol string
+
+	// ExporterConfig configures the exporter of a signal.
+	ExporterConfig struct {
+		// Exporter to use, if empty, ExporterOTLP if Endpoint is set, ExporterConsole otherwise.
+		Exporter Exporter
+		// Endpoint of the OTLP receiver, either host:port or a URL.
+		Endpoint string
+		// Protocol of the OTLP exporter, ProtocolGRPC if empty.
+		Protocol Protocol
+		// Insecure disables the TLS of the OTLP exporter.
+		Insecure bool
+		// Certificate is the path to the PEM file of the trusted certificates, the system ones if empty.
+		Certificate string
+		// Headers sent in every OTLP export request, e.g. for authentication.
+		Headers map[string]string
+	}
+
+	UnknownExporterError struct {
+		Exporter Exporter
+	}
+
+	UnknownProtocolError struct {
+		Protocol Protocol
+	}
+)
+
+func (e UnknownExporterError) Error() string {
+	return fmt.Sprintf("unknown exporter %q", e.Exporter)
+}
+
+func (e UnknownProtocolError) Error() string {
+	return fmt.Sprintf("unknown OTLP protocol %q", e.Protocol)
+}
+
+// exporter returns the exporter to use, defaulting it depending on whether the endpoint is set.
+func (c ExporterConfig) exporter() (Exporter, error) {
+	switch c.Exporter {
+	case "":
+		if c.Endpoint == "" {
+			return ExporterConsole, nil
+		}
+
+		return ExporterOTLP, nil
+	case ExporterOTLP, ExporterConsole, ExporterNone:
+		return c.Exporter, nil
+	default:
+		return "", UnknownExporterError{Exporter: c.Exporter}
+	}
+}
+
+// protocol returns the protocol of the OTLP exporter, ProtocolGRPC by default.
+func (c ExporterConfig) protocol() (Protocol, error) {
+	switch c.Protocol {
+	case "", ProtocolGRPC:
+		return ProtocolGRPC, nil
+	case ProtocolHTTP:
+		return ProtocolHTTP, nil
+	default:
+		return "", UnknownProtocolError{Protocol: c.Protocol}
+	}
+}
+
+// isURL returns whether the endpoint contains the scheme, e.g. https://collector:4318.
+func (c ExporterConfig) isURL() bool {
+	return strings.Contains(c.Endpoint, "://")
+}
+
+// tlsConfig returns the TLS configuration of the OTLP exporter, trusting the configured certificate if any.
+func (c ExporterConfig) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.Certificate == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(c.Certificate)
+	if err != nil {
+		return nil, fmt.Errorf("error reading certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("error parsing certificate %q", c.Certificate)
+	}
+
+	cfg.RootCAs = pool
+
+	return cfg, nil
+}
+
+// newSpanExporter creates the span exporter, or nil if the traces are not exported.
+func newSpanExporter(ctx context.Context, cfg ExporterConfig) (sdktrace.SpanExporter, error) {
+	exporter, protocol, tlsConfig, err := resolve(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case exporter == ExporterNone:
+		//nolint:nilnil // the signal is not exported
+		return nil, nil
+	case exporter == ExporterConsole:
+		//nolint:wrapcheck // wrapped by the caller
+		return stdout.New(stdout.WithPrettyPrint())
+	case protocol == ProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+		if cfg.isURL() {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+
+		if tlsConfig == nil {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		//nolint:wrapcheck // wrapped by the caller
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(cfg.Headers)}
+		if cfg.isURL() {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+
+		if tlsConfig == nil {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		//nolint:wrapcheck // wrapped by the caller
+		return otlptracegrpc.New(ctx, opts...)
+	}
+}
+
+// newMetricExporter creates the metric exporter, or nil if the metrics are not exported.
+func newMetricExporter(ctx context.Context, cfg ExporterConfig) (sdkmetric.Exporter, error) {
+	exporter, protocol, tlsConfig, err := resolve(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case exporter == ExporterNone:
+		//nolint:nilnil // the signal is not exported
+		return nil, nil
+	case exporter == ExporterConsole:
+		//nolint:wrapcheck // wrapped by the caller
+		return stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+	case protocol == ProtocolHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(cfg.Headers)}
+		if cfg.isURL() {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(cfg.Endpoint))
+		}
+
+		if tlsConfig == nil {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else {
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		//nolint:wrapcheck // wrapped by the caller
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(cfg.Headers)}
+		if cfg.isURL() {
+			opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(cfg.Endpoint))
+		}
+
+		if tlsConfig == nil {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		//nolint:wrapcheck // wrapped by the caller
+		return otlpmetricgrpc.New(ctx, opts...)
+	}
+}
+
+// newLogExporter creates the log exporter, or nil if the logs are not exported.
+func newLogExporter(ctx context.Context, cfg ExporterConfig) (log.Exporter, error) {
+	exporter, protocol, tlsConfig, err := resolve(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case exporter == ExporterNone:
+		//nolint:nilnil // the signal is not exported
+		return nil, nil
+	case exporter == ExporterConsole:
+		//nolint:wrapcheck // wrapped by the caller
+		return stdoutlog.New(stdoutlog.WithPrettyPrint())
+	case protocol == ProtocolHTTP:
+		opts := []otlploghttp.Option{otlploghttp.WithHeaders(cfg.Headers)}
+		if cfg.isURL() {
+			opts = append(opts, otlploghttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlploghttp.WithEndpoint(cfg.Endpoint))
+		}
+
+		if tlsConfig == nil {
+			opts = append(opts, otlploghttp.WithInsecure())
+		} else {
+			opts = append(opts, otlploghttp.WithTLSClientConfig(tlsConfig))
+		}
+
+		//nolint:wrapcheck // wrapped by the caller
+		return otlploghttp.New(ctx, opts...)
+	default:
+		opts := []otlploggrpc.Option{otlploggrpc.WithHeaders(cfg.Headers)}
+		if cfg.isURL() {
+			opts = append(opts, otlploggrpc.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlploggrpc.WithEndpoint(cfg.Endpoint))
+		}
+
+		if tlsConfig == nil {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+
+		//nolint:wrapcheck // wrapped by the caller
+		return otlploggrpc.New(ctx, opts...)
+	}
+}
+
+// resolve validates the configuration, and returns the exporter, the protocol and,
+// unless it is insecure, the TLS configuration of the OTLP exporter.
+func resolve(cfg ExporterConfig) (Exporter, Protocol, *tls.Config, error) {
+	exporter, err := cfg.exporter()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	protocol, err := cfg.protocol()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if exporter != ExporterOTLP || cfg.Insecure {
+		return exporter, protocol, nil, nil
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return exporter, protocol, tlsConfig, nil
+}
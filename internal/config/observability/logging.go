@@ -4,26 +4,15 @@ import (
 	"context"
 	"fmt"
 
-	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
-	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
 	"go.opentelemetry.io/otel/sdk/log"
 )
 
-func InitLoggingProvider(ctx context.Context, exporterURL, hostname string) (*log.LoggerProvider, error) {
-	var (
-		exporter log.Exporter
-		err      error
-	)
-	if exporterURL == "" {
-		exporter, err = stdoutlog.New(stdoutlog.WithPrettyPrint())
-	} else {
-		exporter, err = otlploggrpc.New(
-			ctx,
-			otlploggrpc.WithEndpoint(exporterURL),
-			otlploggrpc.WithInsecure(),
-		)
-	}
-
+func InitLoggingProvider(
+	ctx context.Context,
+	exporterCfg ExporterConfig,
+	hostname string,
+) (*log.LoggerProvider, error) {
+	exporter, err := newLogExporter(ctx, exporterCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize exporter: %w", err)
 	}
@@ -33,10 +22,14 @@ func InitLoggingProvider(ctx context.Context, exporterURL, hostname string) (*lo
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	loggerProvider := log.NewLoggerProvider(
-		log.WithProcessor(log.NewBatchProcessor(exporter)),
+	opts := []log.LoggerProviderOption{
 		log.WithResource(res),
-	)
+	}
+	if exporter != nil {
+		opts = append(opts, log.WithProcessor(log.NewBatchProcessor(exporter)))
+	}
+
+	loggerProvider := log.NewLoggerProvider(opts...)
 
 	return loggerProvider, nil
 }
@@ -4,29 +4,28 @@ import (
 	"context"
 	"fmt"
 
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
-	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/prometheus"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 )
 
 // InitMeterProvider initializes the meter provider.
-func InitMeterProvider(ctx context.Context, exporterURL, hostname string) (*sdkmetric.MeterProvider, error) {
-	var (
-		exporter sdkmetric.Exporter
-		err      error
-	)
-	if exporterURL == "" {
-		exporter, err = stdoutmetric.New(stdoutmetric.WithPrettyPrint())
-	} else {
-		exporter, err = otlpmetricgrpc.New(
-			ctx,
-			otlpmetricgrpc.WithEndpoint(exporterURL),
-			otlpmetricgrpc.WithInsecure(),
-		)
+// Besides being pushed to the exporter, the metrics are registered in the default Prometheus registry,
+// so they can be scraped from the /metrics endpoint.
+// The measurements recorded within a sampled span keep its trace id as exemplar, linking the metrics to the traces.
+func InitMeterProvider(
+	ctx context.Context,
+	exporterCfg ExporterConfig,
+	hostname string,
+) (*sdkmetric.MeterProvider, error) {
+	exporter, err := newMetricExporter(ctx, exporterCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize exporter: %w", err)
 	}
 
+	prometheusExporter, err := prometheus.New()
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize exporter: %w", err)
+		return nil, fmt.Errorf("failed to initialize prometheus exporter: %w", err)
 	}
 
 	res, err := createResource(ctx, hostname)
@@ -34,8 +33,14 @@ func InitMeterProvider(ctx context.Context, exporterURL, hostname string) (*sdkm
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	return sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+	opts := []sdkmetric.Option{
+		sdkmetric.WithReader(prometheusExporter),
 		sdkmetric.WithResource(res),
-	), nil
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	}
+	if exporter != nil {
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)))
+	}
+
+	return sdkmetric.NewMeterProvider(opts...), nil
 }
@@ -0,0 +1,75 @@
+package observability
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+)
+
+var _ sdktrace.SpanProcessor = new(redactingSpanProcessor)
+
+type (
+	// redactingSpanProcessor redacts the sensitive attributes of the spans before passing them to the next processor.
+	redactingSpanProcessor struct {
+		sdktrace.SpanProcessor
+
+		redactor redaction.Redactor
+	}
+
+	// redactedSpan is a read only span with its attributes redacted.
+	redactedSpan struct {
+		sdktrace.ReadOnlySpan
+
+		attributes []attribute.KeyValue
+		events     []sdktrace.Event
+	}
+)
+
+// NewRedactingSpanProcessor wraps the span processor so the span and span event attributes
+// whose keys are sensitive are redacted before being exported.
+func NewRedactingSpanProcessor(next sdktrace.SpanProcessor, redactor redaction.Redactor) sdktrace.SpanProcessor {
+	if !redactor.Enabled() {
+		return next
+	}
+
+	return redactingSpanProcessor{
+		SpanProcessor: next,
+		redactor:      redactor,
+	}
+}
+
+func (p redactingSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	events := make([]sdktrace.Event, len(s.Events()))
+	for i, event := range s.Events() {
+		event.Attributes = p.redact(event.Attributes)
+		events[i] = event
+	}
+
+	p.SpanProcessor.OnEnd(redactedSpan{
+		ReadOnlySpan: s,
+		attributes:   p.redact(s.Attributes()),
+		events:       events,
+	})
+}
+
+func (p redactingSpanProcessor) redact(attributes []attribute.KeyValue) []attribute.KeyValue {
+	redacted := make([]attribute.KeyValue, len(attributes))
+	for i, kv := range attributes {
+		if p.redactor.IsSensitiveKey(string(kv.Key)) {
+			kv = kv.Key.String(p.redactor.Redact(kv.Value.Emit()))
+		}
+
+		redacted[i] = kv
+	}
+
+	return redacted
+}
+
+func (s redactedSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+func (s redactedSpan) Events() []sdktrace.Event {
+	return s.events
+}
@@ -0,0 +1,64 @@
+package observability
+
+import (
+	"fmt"
+	"strconv"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Samplers defined by OTEL_TRACES_SAMPLER.
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+var _ error = new(InvalidSamplerError)
+
+// InvalidSamplerError is returned when the sampler, or its argument, is not valid.
+type InvalidSamplerError struct {
+	Sampler string
+	Arg     string
+}
+
+func (e InvalidSamplerError) Error() string {
+	return fmt.Sprintf("invalid sampler %q with argument %q", e.Sampler, e.Arg)
+}
+
+// NewSampler creates the sampler following the OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG specification.
+// The sampler is parentbased_always_on if empty, and the ratio of the trace id ratio samplers is 1 if the arg is empty.
+func NewSampler(sampler, arg string) (sdktrace.Sampler, error) {
+	switch sampler {
+	case "", SamplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case SamplerAlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case SamplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+		ratio := 1.0
+
+		if arg != "" {
+			var err error
+
+			ratio, err = strconv.ParseFloat(arg, 64)
+			if err != nil || ratio < 0 || ratio > 1 {
+				return nil, InvalidSamplerError{Sampler: sampler, Arg: arg}
+			}
+		}
+
+		if sampler == SamplerTraceIDRatio {
+			return sdktrace.TraceIDRatioBased(ratio), nil
+		}
+
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, InvalidSamplerError{Sampler: sampler, Arg: arg}
+	}
+}
@@ -0,0 +1,72 @@
+package observability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSampler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		sampler     string
+		arg         string
+		description string
+		wantErr     error
+	}{
+		"default is parent based always on": {
+			description: "ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler," +
+				"remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler," +
+				"localParentNotSampled:AlwaysOffSampler}",
+		},
+		"always off": {
+			sampler:     SamplerAlwaysOff,
+			description: "AlwaysOffSampler",
+		},
+		"trace id ratio": {
+			sampler:     SamplerTraceIDRatio,
+			arg:         "0.25",
+			description: "TraceIDRatioBased{0.25}",
+		},
+		"trace id ratio without arg samples everything": {
+			sampler:     SamplerTraceIDRatio,
+			description: "TraceIDRatioBased{1}",
+		},
+		"parent based trace id ratio": {
+			sampler: SamplerParentBasedTraceIDRatio,
+			arg:     "0.5",
+			description: "ParentBased{root:TraceIDRatioBased{0.5},remoteParentSampled:AlwaysOnSampler," +
+				"remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler," +
+				"localParentNotSampled:AlwaysOffSampler}",
+		},
+		"ratio out of range": {
+			sampler: SamplerParentBasedTraceIDRatio,
+			arg:     "2",
+			wantErr: InvalidSamplerError{Sampler: SamplerParentBasedTraceIDRatio, Arg: "2"},
+		},
+		"unknown sampler": {
+			sampler: "jaeger_remote",
+			wantErr: InvalidSamplerError{Sampler: "jaeger_remote"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			sampler, err := NewSampler(test.sampler, test.arg)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.description, sampler.Description())
+		})
+	}
+}
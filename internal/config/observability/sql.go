@@ -0,0 +1,157 @@
+package observability
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	_ driver.Connector          = new(rowsConnector)
+	_ driver.QueryerContext     = new(rowsConn)
+	_ driver.ExecerContext      = new(rowsConn)
+	_ driver.ConnPrepareContext = new(rowsConn)
+	_ driver.ConnBeginTx        = new(rowsConn)
+	_ driver.Pinger             = new(rowsConn)
+)
+
+type (
+	// rowsTracker reports the end of the rows of a query, once they are closed.
+	rowsTracker struct {
+		closed  func(error)
+		tracked atomic.Bool
+	}
+
+	rowsTrackerKey struct{}
+
+	// rowsConnector opens the connections of the driver that report the end of the rows of the queries.
+	rowsConnector struct {
+		driver driver.Driver
+		dsn    string
+	}
+
+	// rowsConn decorates a connection of the driver, tracking the rows of its queries.
+	rowsConn struct {
+		driver.Conn
+	}
+
+	// rows decorates the rows of a query, reporting the first error reading them once they are closed.
+	rows struct {
+		driver.Rows
+
+		tracker *rowsTracker
+		err     error
+		once    sync.Once
+	}
+)
+
+// NewRowsConnector returns the connector of the driver to the data source name, whose connections report the end
+// of the rows of the queries run with the context of TrackRows, e.g. to end their spans once the rows are read.
+func NewRowsConnector(d driver.Driver, dsn string) driver.Connector {
+	return rowsConnector{driver: d, dsn: dsn}
+}
+
+// TrackRows returns a copy of the context that reports to closed, once the rows of the query run with it are closed,
+// the error reading them, if any.
+// The returned function tells whether the rows are tracked, closed is never called otherwise,
+// e.g. if the query failed or the connection was not opened by NewRowsConnector.
+func TrackRows(ctx context.Context, closed func(error)) (context.Context, func() bool) {
+	tracker := &rowsTracker{closed: closed}
+
+	return context.WithValue(ctx, rowsTrackerKey{}, tracker), tracker.tracked.Load
+}
+
+func (c rowsConnector) Connect(_ context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening connection: %w", err)
+	}
+
+	return rowsConn{Conn: conn}, nil
+}
+
+func (c rowsConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+//nolint:wrapcheck // decorator
+func (c rowsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	r, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker, ok := ctx.Value(rowsTrackerKey{}).(*rowsTracker)
+	if !ok || !tracker.tracked.CompareAndSwap(false, true) {
+		return r, nil
+	}
+
+	return &rows{Rows: r, tracker: tracker}, nil
+}
+
+//nolint:wrapcheck // decorator
+func (c rowsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	return execer.ExecContext(ctx, query, args)
+}
+
+//nolint:wrapcheck // decorator
+func (c rowsConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+
+	return c.Prepare(query)
+}
+
+//nolint:wrapcheck // decorator
+func (c rowsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	//nolint:staticcheck // fallback of the drivers without BeginTx
+	return c.Begin()
+}
+
+//nolint:wrapcheck // decorator
+func (c rowsConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && !errors.Is(err, io.EOF) && r.err == nil {
+		r.err = err
+	}
+
+	return err //nolint:wrapcheck // decorator, io.EOF can't be wrapped
+}
+
+//nolint:wrapcheck // decorator
+func (r *rows) Close() error {
+	err := r.Rows.Close()
+
+	r.once.Do(func() {
+		r.tracker.closed(errors.Join(r.err, err))
+	})
+
+	return err
+}
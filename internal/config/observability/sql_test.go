@@ -0,0 +1,160 @@
+package observability
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errFetch = errors.New("error fetching row")
+
+type (
+	fakeDriver struct {
+		rows int
+		err  error
+	}
+
+	// fakeConnector opens the connections without tracking their rows.
+	fakeConnector struct{}
+
+	fakeConn struct {
+		driver.Conn
+
+		rows int
+		err  error
+	}
+
+	fakeRows struct {
+		remaining int
+		err       error
+	}
+)
+
+func TestTrackRows(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		fetchErr error
+		wantRows int
+		wantErr  error
+	}{
+		"rows read": {
+			wantRows: 2,
+		},
+		"error reading the rows": {
+			fetchErr: errFetch,
+			wantRows: 2,
+			wantErr:  errFetch,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db := sql.OpenDB(NewRowsConnector(fakeDriver{rows: 2, err: test.fetchErr}, ""))
+
+			t.Cleanup(func() { _ = db.Close() })
+
+			var (
+				closedCalls int
+				closedErr   error
+			)
+
+			ctx, tracked := TrackRows(t.Context(), func(err error) {
+				closedCalls++
+				closedErr = err
+			})
+
+			// Act
+			rows, err := db.QueryContext(ctx, "SELECT 1")
+			require.NoError(t, err)
+
+			closedBeforeReading := closedCalls
+
+			read := 0
+			for rows.Next() {
+				read++
+			}
+
+			require.NoError(t, rows.Close())
+
+			// Assert
+			assert.True(t, tracked())
+			assert.Equal(t, test.wantRows, read)
+			assert.Zero(t, closedBeforeReading)
+			assert.Equal(t, 1, closedCalls)
+			require.ErrorIs(t, closedErr, test.wantErr)
+		})
+	}
+}
+
+func TestTrackRows_NotTracked(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db := sql.OpenDB(fakeConnector{})
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	closedCalls := 0
+	ctx, tracked := TrackRows(t.Context(), func(error) { closedCalls++ })
+
+	// Act
+	rows, err := db.QueryContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	// Assert
+	assert.False(t, tracked())
+	assert.Zero(t, closedCalls)
+}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{rows: 1}, nil
+}
+
+func (fakeConnector) Driver() driver.Driver {
+	return fakeDriver{rows: 1}
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{rows: d.rows, err: d.err}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{remaining: c.rows, err: c.err}, nil
+}
+
+func (*fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (*fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.remaining == 0 {
+		if r.err != nil {
+			return r.err
+		}
+
+		return io.EOF
+	}
+
+	r.remaining--
+	dest[0] = int64(r.remaining)
+
+	return nil
+}
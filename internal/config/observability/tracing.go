@@ -4,28 +4,21 @@ import (
 	"context"
 	"fmt"
 
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	stdout "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-)
-
-// InitTracerProvider initializes the tracer provider.
-func InitTracerProvider(ctx context.Context, exporterURL, hostname string) (*sdktrace.TracerProvider, error) {
-	var (
-		exporter sdktrace.SpanExporter
-		err      error
-	)
 
-	if exporterURL == "" {
-		exporter, err = stdout.New(stdout.WithPrettyPrint())
-	} else {
-		exporter, err = otlptracegrpc.New(
-			ctx,
-			otlptracegrpc.WithEndpoint(exporterURL),
-			otlptracegrpc.WithInsecure(),
-		)
-	}
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+)
 
+// InitTracerProvider initializes the tracer provider, sampling the traces with the sampler.
+// The sensitive span attributes are redacted by the redactor before being exported.
+func InitTracerProvider(
+	ctx context.Context,
+	exporterCfg ExporterConfig,
+	sampler sdktrace.Sampler,
+	hostname string,
+	redactor redaction.Redactor,
+) (*sdktrace.TracerProvider, error) {
+	exporter, err := newSpanExporter(ctx, exporterCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize exporter: %w", err)
 	}
@@ -35,9 +28,15 @@ func InitTracerProvider(ctx context.Context, exporterURL, hostname string) (*sdk
 		return nil, fmt.Errorf("unable to initialize resource: %w", err)
 	}
 
-	return sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithBatcher(exporter),
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sampler),
 		sdktrace.WithResource(res),
-	), nil
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithSpanProcessor(
+			NewRedactingSpanProcessor(sdktrace.NewBatchSpanProcessor(exporter), redactor),
+		))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
 }
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the full buckets are removed from the memory store.
+const sweepInterval = time.Minute
+
+var _ Store = new(MemoryStore)
+
+type (
+	// MemoryStore keeps the token buckets in memory, so each instance of the application limits on its own.
+	MemoryStore struct {
+		mu        sync.Mutex
+		buckets   map[string]*bucket
+		now       func() time.Time
+		lastSweep time.Time
+	}
+
+	bucket struct {
+		tokens float64
+		last   time.Time
+		limit  Limit
+	}
+)
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return newMemoryStore(time.Now)
+}
+
+func newMemoryStore(now func() time.Time) *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		now:       now,
+		lastSweep: now(),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Requests), last: now, limit: limit}
+		s.buckets[key] = b
+	}
+
+	b.refill(now)
+
+	result := Result{Limit: limit}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = b.timeFor(1 - b.tokens)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = b.timeFor(float64(limit.Requests) - b.tokens)
+
+	return result, nil
+}
+
+// sweep removes the buckets that are full, as they are the same as a new one.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		b.refill(now)
+
+		if b.tokens >= float64(b.limit.Requests) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// refill adds the tokens generated since the last refill, up to the bucket capacity.
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+elapsed.Seconds()*b.rate())
+	b.last = now
+}
+
+// timeFor returns the time needed to generate the tokens.
+func (b *bucket) timeFor(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / b.rate() * float64(time.Second)))
+}
+
+// rate returns the tokens generated per second.
+func (b *bucket) rate() float64 {
+	return float64(b.limit.Requests) / b.limit.Period.Seconds()
+}
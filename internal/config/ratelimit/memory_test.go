@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	t.Parallel()
+
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+
+	tests := map[string]struct {
+		takes    []time.Duration
+		expected Result
+	}{
+		"first request": {
+			takes: []time.Duration{0},
+			expected: Result{
+				Allowed:   true,
+				Limit:     limit,
+				Remaining: 1,
+				Reset:     time.Second,
+			},
+		},
+		"burst exhausted": {
+			takes: []time.Duration{0, 0, 0},
+			expected: Result{
+				Allowed:    false,
+				Limit:      limit,
+				Remaining:  0,
+				Reset:      2 * time.Second,
+				RetryAfter: time.Second,
+			},
+		},
+		"token refilled": {
+			takes: []time.Duration{0, 0, time.Second},
+			expected: Result{
+				Allowed:   true,
+				Limit:     limit,
+				Remaining: 0,
+				Reset:     2 * time.Second,
+			},
+		},
+		"bucket full after the period": {
+			takes: []time.Duration{0, 0, 10 * time.Second},
+			expected: Result{
+				Allowed:   true,
+				Limit:     limit,
+				Remaining: 1,
+				Reset:     time.Second,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			store := newMemoryStore(func() time.Time { return now })
+
+			var (
+				actual Result
+				err    error
+			)
+
+			// Act
+			for _, elapsed := range test.takes {
+				now = now.Add(elapsed)
+
+				actual, err = store.Take(t.Context(), "ip:127.0.0.1", limit)
+				require.NoError(t, err)
+			}
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	limiter := NewLimiter(NewMemoryStore(), Limit{}, map[string]Limit{
+		"GET /api/v1/users": {Requests: 1, Period: time.Minute},
+	})
+
+	// Act
+	first, errFirst := limiter.Allow(t.Context(), "GET /api/v1/users", KeyForIP("127.0.0.1"))
+	second, errSecond := limiter.Allow(t.Context(), "GET /api/v1/users", KeyForIP("127.0.0.1"))
+	otherClient, errOtherClient := limiter.Allow(t.Context(), "GET /api/v1/users", KeyForIP("10.0.0.1"))
+	unlimited, errUnlimited := limiter.Allow(t.Context(), "GET /api/v1/users/{userId}", KeyForIP("127.0.0.1"))
+
+	// Assert
+	require.NoError(t, errFirst)
+	require.NoError(t, errSecond)
+	require.NoError(t, errOtherClient)
+	require.NoError(t, errUnlimited)
+	assert.True(t, first.Allowed)
+	assert.False(t, second.Allowed)
+	assert.True(t, otherClient.Allowed)
+	assert.True(t, unlimited.Allowed)
+}
+
+func TestParseLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value    string
+		expected Limit
+		wantErr  error
+	}{
+		"unlimited": {
+			value: "",
+		},
+		"per minute": {
+			value:    "100/1m",
+			expected: Limit{Requests: 100, Period: time.Minute},
+		},
+		"missing period": {
+			value:   "100",
+			wantErr: InvalidLimitError{Value: "100"},
+		},
+		"negative requests": {
+			value:   "-1/1s",
+			wantErr: InvalidLimitError{Value: "-1/1s"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual, err := ParseLimit(test.value)
+
+			// Assert
+			require.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package ratelimit provides a token bucket rate limiter, configurable per route,
+// whose buckets are kept in a pluggable Store.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ error = new(InvalidLimitError)
+
+type (
+	// Limit is the quota of a token bucket: Requests can be done in a burst, and they are refilled every Period.
+	// The zero Limit is unlimited.
+	Limit struct {
+		Requests int
+		Period   time.Duration
+	}
+
+	// Result is the outcome of taking a token from a bucket.
+	Result struct {
+		// Allowed is whether the request is allowed.
+		Allowed bool
+		// Limit is the quota of the bucket.
+		Limit Limit
+		// Remaining is the number of requests that can still be done.
+		Remaining int
+		// Reset is the time until the bucket is full again.
+		Reset time.Duration
+		// RetryAfter is the time until the next request is allowed, if this one was not.
+		RetryAfter time.Duration
+	}
+
+	// Store keeps the token buckets, e.g. in memory or in a distributed store shared by all the instances.
+	Store interface {
+		// Take takes a token from the bucket of the key, creating it if it does not exist.
+		Take(ctx context.Context, key string, limit Limit) (Result, error)
+	}
+
+	// Limiter limits the requests done by each client to every route.
+	Limiter struct {
+		store        Store
+		defaultLimit Limit
+		rules        map[string]Limit
+	}
+
+	InvalidLimitError struct {
+		Value string
+	}
+)
+
+func (e InvalidLimitError) Error() string {
+	return fmt.Sprintf("invalid rate limit %q, expected <requests>/<period>, e.g. 100/1m", e.Value)
+}
+
+// ParseLimit parses a limit with the format <requests>/<period>, e.g. 100/1m. The empty string is unlimited.
+func ParseLimit(value string) (Limit, error) {
+	if value == "" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, InvalidLimitError{Value: value}
+	}
+
+	r, err := strconv.Atoi(requests)
+	if err != nil || r <= 0 {
+		return Limit{}, InvalidLimitError{Value: value}
+	}
+
+	p, err := time.ParseDuration(period)
+	if err != nil || p <= 0 {
+		return Limit{}, InvalidLimitError{Value: value}
+	}
+
+	return Limit{Requests: r, Period: p}, nil
+}
+
+// ParseRules parses the limit of each route.
+func ParseRules(rules map[string]string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(rules))
+	for route, value := range rules {
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing rate limit of route %q: %w", route, err)
+		}
+
+		limits[route] = limit
+	}
+
+	return limits, nil
+}
+
+func (l Limit) String() string {
+	if l.IsUnlimited() {
+		return ""
+	}
+
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// IsUnlimited returns whether the limit does not limit the requests.
+func (l Limit) IsUnlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// NewLimiter creates a limiter with the buckets kept in the store.
+// The routes with a rule are limited by it, and the rest by the default limit.
+func NewLimiter(store Store, defaultLimit Limit, rules map[string]Limit) Limiter {
+	return Limiter{
+		store:        store,
+		defaultLimit: defaultLimit,
+		rules:        rules,
+	}
+}
+
+// Allow takes a token from the bucket of the client identified by the key for the route.
+// The requests to unlimited routes are always allowed.
+func (l Limiter) Allow(ctx context.Context, route, key string) (Result, error) {
+	limit, ok := l.rules[route]
+	if !ok {
+		limit = l.defaultLimit
+	}
+
+	if limit.IsUnlimited() {
+		return Result{Allowed: true, Limit: limit}, nil
+	}
+
+	result, err := l.store.Take(ctx, route+" "+key, limit)
+	if err != nil {
+		return Result{}, fmt.Errorf("error taking token: %w", err)
+	}
+
+	return result, nil
+}
+
+// KeyForPrincipal returns the key of a client identified by its authenticated principal, e.g. a user or an API key.
+func KeyForPrincipal(principal string) string {
+	return "principal:" + principal
+}
+
+// KeyForIP returns the key of a client identified by its IP.
+func KeyForIP(ip string) string {
+	return "ip:" + ip
+}
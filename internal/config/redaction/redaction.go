@@ -0,0 +1,123 @@
+// Package redaction provides the policy to hide personal identifiable information (PII)
+// before it leaves the application through logs, traces or wide events.
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ModeNone keeps the sensitive values as they are.
+	ModeNone Mode = "none"
+	// ModeMask replaces the sensitive values with Masked.
+	ModeMask Mode = "mask"
+	// ModeHash replaces the sensitive values with a keyed hash, so they can still be correlated.
+	ModeHash Mode = "hash"
+
+	// Masked is the value used to replace the sensitive values.
+	Masked = "[REDACTED]"
+
+	hashPrefix = "hash:"
+	hashLength = 16
+)
+
+// ErrMissingSalt is returned for ModeHash without salt, as the unsalted hashes of the usernames and the emails
+// are reversed with a dictionary.
+var ErrMissingSalt = errors.New("the hash redaction mode requires a salt")
+
+var _ error = new(UnknownModeError)
+
+type (
+	// Mode is the strategy used to redact the sensitive values.
+	Mode string
+
+	// Sensitive is implemented by the values that contain personal identifiable information.
+	Sensitive interface {
+		// SensitiveValue returns the raw value to be redacted.
+		SensitiveValue() string
+	}
+
+	// Redactor redacts sensitive values, either because of their type (see Sensitive) or their key.
+	Redactor struct {
+		mode Mode
+		keys map[string]struct{}
+		salt []byte
+	}
+
+	UnknownModeError struct {
+		Mode Mode
+	}
+)
+
+func (e UnknownModeError) Error() string {
+	return fmt.Sprintf("unknown redaction mode %q", e.Mode)
+}
+
+// New creates a Redactor that redacts with the given mode the values of the keys, case-insensitively.
+// The salt is used as the key of the hash in ModeHash, where it is required.
+func New(mode Mode, keys []string, salt string) (Redactor, error) {
+	switch mode {
+	case ModeNone, ModeMask:
+	case ModeHash:
+		if salt == "" {
+			return Redactor{}, ErrMissingSalt
+		}
+	default:
+		return Redactor{}, UnknownModeError{Mode: mode}
+	}
+
+	sensitiveKeys := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		sensitiveKeys[strings.ToLower(strings.TrimSpace(key))] = struct{}{}
+	}
+
+	return Redactor{
+		mode: mode,
+		keys: sensitiveKeys,
+		salt: []byte(salt),
+	}, nil
+}
+
+// ModeForEnv returns the default redaction mode for the environment.
+// Only local environments keep the sensitive values.
+func ModeForEnv(env string) Mode {
+	if env == "local" {
+		return ModeNone
+	}
+
+	return ModeHash
+}
+
+// Enabled returns whether the values are redacted at all.
+func (r Redactor) Enabled() bool {
+	return r.mode != ModeNone && r.mode != ""
+}
+
+// IsSensitiveKey returns whether the values of the key need to be redacted.
+func (r Redactor) IsSensitiveKey(key string) bool {
+	_, ok := r.keys[strings.ToLower(key)]
+
+	return ok
+}
+
+// Redact redacts the value following the redactor's mode.
+func (r Redactor) Redact(value string) string {
+	switch r.mode {
+	case ModeMask:
+		return Masked
+	case ModeHash:
+		mac := hmac.New(sha256.New, r.salt)
+		_, _ = mac.Write([]byte(value))
+
+		return hashPrefix + hex.EncodeToString(mac.Sum(nil))[:hashLength]
+	case ModeNone:
+		return value
+	default:
+		return value
+	}
+}
@@ -0,0 +1,52 @@
+package redaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mode    Mode
+		salt    string
+		wantErr error
+	}{
+		"none without salt": {
+			mode: ModeNone,
+		},
+		"mask without salt": {
+			mode: ModeMask,
+		},
+		"hash with salt": {
+			mode: ModeHash,
+			salt: "salt",
+		},
+		"hash without salt": {
+			mode:    ModeHash,
+			wantErr: ErrMissingSalt,
+		},
+		"unknown mode": {
+			mode:    "encrypt",
+			wantErr: UnknownModeError{Mode: "encrypt"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			redactor, err := New(test.mode, []string{"email"}, test.salt)
+
+			// Assert
+			require.ErrorIs(t, err, test.wantErr)
+
+			if test.wantErr == nil {
+				assert.True(t, redactor.IsSensitiveKey("Email"))
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package events delivers the user events to the subscribers in process.
+package events
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/outbox"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// subscriberBuffer is the number of messages buffered for a subscriber, before it is considered too slow.
+const subscriberBuffer = 64
+
+var (
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+	ErrResumeTokenExpired = errors.New("resume token expired, the events after it are no longer kept")
+)
+
+var _ outbox.Publisher = new(Bus)
+
+type (
+	// Message is an event delivered to a subscriber, with the token to resume the subscription after it.
+	Message struct {
+		ResumeToken string
+		Event       users.Event
+	}
+
+	// Bus delivers the published events to its subscribers, each one only receives the events of its tenant.
+	// The last events are kept, so a subscriber can resume its subscription after the last event it received.
+	// The resume tokens are only valid for the bus that issued them, they expire when the application restarts.
+	Bus struct {
+		mu          sync.Mutex
+		epoch       string
+		seq         uint64
+		history     []sequencedEvent
+		historySize int
+		subscribers map[*subscriber]struct{}
+	}
+
+	sequencedEvent struct {
+		seq   uint64
+		event users.Event
+	}
+
+	subscriber struct {
+		tenant   tenant.ID
+		messages chan Message
+	}
+)
+
+// NewBus creates the bus, keeping the last historySize events to resume the subscriptions.
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: max(historySize, 0),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish delivers the event to the subscribers, it never fails.
+// The subscribers that don't keep up are unsubscribed, so they have to resume their subscription.
+func (b *Bus) Publish(_ context.Context, event users.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = b.history[1:]
+		}
+
+		b.history = append(b.history, sequencedEvent{seq: b.seq, event: event})
+	}
+
+	message := Message{ResumeToken: b.token(b.seq), Event: event}
+
+	for s := range b.subscribers {
+		if !s.receives(event) {
+			continue
+		}
+
+		select {
+		case s.messages <- message:
+		default:
+			b.unsubscribe(s)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns the channel with the events of the tenant of the context published from now on or,
+// if the resume token is set, with the events published after the one of the token.
+// The channel is closed when the context is done, or if the subscriber does not keep up with the events.
+// Returns ErrInvalidResumeToken if the token is not valid,
+// or ErrResumeTokenExpired if the events after it are no longer kept.
+func (b *Bus) Subscribe(ctx context.Context, resumeToken string) (<-chan Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	missed, err := b.missed(resumeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &subscriber{tenant: tenant.FromContext(ctx), messages: make(chan Message, subscriberBuffer+len(missed))}
+	for _, e := range missed {
+		if s.receives(e.event) {
+			s.messages <- Message{ResumeToken: b.token(e.seq), Event: e.event}
+		}
+	}
+
+	b.subscribers[s] = struct{}{}
+
+	context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.unsubscribe(s)
+	})
+
+	return s.messages, nil
+}
+
+// missed returns the events published after the one of the resume token.
+func (b *Bus) missed(resumeToken string) ([]sequencedEvent, error) {
+	if resumeToken == "" {
+		return nil, nil
+	}
+
+	epoch, s, ok := strings.Cut(resumeToken, ".")
+
+	seq, err := strconv.ParseUint(s, 10, 64)
+	if !ok || err != nil {
+		return nil, ErrInvalidResumeToken
+	}
+
+	if epoch != b.epoch {
+		return nil, fmt.Errorf("%w: issued before the application restarted", ErrResumeTokenExpired)
+	}
+
+	if seq > b.seq {
+		return nil, ErrInvalidResumeToken
+	}
+
+	if seq == b.seq {
+		return nil, nil
+	}
+
+	if len(b.history) == 0 || b.history[0].seq > seq+1 {
+		return nil, ErrResumeTokenExpired
+	}
+
+	return b.history[seq+1-b.history[0].seq:], nil
+}
+
+// unsubscribe removes the subscriber, closing its channel. It must be called holding the lock.
+func (b *Bus) unsubscribe(s *subscriber) {
+	if _, ok := b.subscribers[s]; !ok {
+		return
+	}
+
+	delete(b.subscribers, s)
+	close(s.messages)
+}
+
+// receives returns whether the event is of the tenant of the subscriber,
+// the events without tenant are of the default one.
+func (s *subscriber) receives(event users.Event) bool {
+	return s.tenant == cmp.Or(event.TenantID, tenant.Default)
+}
+
+func (b *Bus) token(seq uint64) string {
+	return b.epoch + "." + strconv.FormatUint(seq, 10)
+}
@@ -0,0 +1,180 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestBus_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		resumeToken    func(t *testing.T, b *Bus, published []Message) string
+		expectedEvents []int
+		expectedErr    error
+	}{
+		"without resume token": {
+			resumeToken:    func(*testing.T, *Bus, []Message) string { return "" },
+			expectedEvents: []int{3},
+		},
+		"resumed after the first event": {
+			resumeToken: func(_ *testing.T, _ *Bus, published []Message) string {
+				return published[0].ResumeToken
+			},
+			expectedEvents: []int{1, 2, 3},
+		},
+		"resumed after the last event": {
+			resumeToken: func(_ *testing.T, _ *Bus, published []Message) string {
+				return published[2].ResumeToken
+			},
+			expectedEvents: []int{3},
+		},
+		"resume token no longer kept": {
+			resumeToken: func(t *testing.T, b *Bus, _ []Message) string {
+				t.Helper()
+
+				return b.token(0)
+			},
+			expectedErr: ErrResumeTokenExpired,
+		},
+		"resume token from another bus": {
+			resumeToken: func(*testing.T, *Bus, []Message) string { return "other.1" },
+			expectedErr: ErrResumeTokenExpired,
+		},
+		"resume token not issued": {
+			resumeToken: func(t *testing.T, b *Bus, _ []Message) string {
+				t.Helper()
+
+				return b.token(10)
+			},
+			expectedErr: ErrInvalidResumeToken,
+		},
+		"malformed resume token": {
+			resumeToken: func(*testing.T, *Bus, []Message) string { return "token" },
+			expectedErr: ErrInvalidResumeToken,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			b := NewBus(2)
+			ctx, cancel := context.WithCancel(t.Context())
+
+			all, err := b.Subscribe(ctx, "")
+			require.NoError(t, err)
+
+			events := newEvents(4)
+			publish(t, b, events[:3]...)
+
+			published := make([]Message, 0, 3)
+			for range 3 {
+				published = append(published, <-all)
+			}
+
+			// Act
+			messages, err := b.Subscribe(ctx, test.resumeToken(t, b, published))
+
+			// Assert
+			require.ErrorIs(t, err, test.expectedErr)
+
+			if test.expectedErr != nil {
+				cancel()
+
+				return
+			}
+
+			publish(t, b, events[3])
+			cancel()
+
+			var actual []users.Event
+			for message := range messages {
+				actual = append(actual, message.Event)
+			}
+
+			expected := make([]users.Event, 0, len(test.expectedEvents))
+			for _, i := range test.expectedEvents {
+				expected = append(expected, events[i])
+			}
+
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestBus_Publish_SlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	b := NewBus(0)
+
+	messages, err := b.Subscribe(t.Context(), "")
+	require.NoError(t, err)
+
+	// Act
+	publish(t, b, newEvents(subscriberBuffer+1)...)
+
+	// Assert
+	received := 0
+	for range messages {
+		received++
+	}
+
+	assert.Equal(t, subscriberBuffer, received)
+}
+
+func TestBus_Subscribe_Tenant(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	b := NewBus(10)
+
+	events := newEvents(3)
+	events[1].TenantID = "acme"
+
+	publish(t, b, events[0])
+
+	ctx, cancel := context.WithCancel(tenant.WithID(t.Context(), "acme"))
+	t.Cleanup(cancel)
+
+	live, err := b.Subscribe(ctx, "")
+	require.NoError(t, err)
+
+	// Act
+	publish(t, b, events[1:]...)
+
+	resumed, err := b.Subscribe(ctx, b.token(0))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, events[1], (<-live).Event)
+	assert.Equal(t, events[1], (<-resumed).Event)
+	assert.Empty(t, live)
+	assert.Empty(t, resumed)
+}
+
+func newEvents(n int) []users.Event {
+	events := make([]users.Event, 0, n)
+	for range n {
+		events = append(events, users.NewDeletedEvent(users.UserID(uuid.New()), time.Now()))
+	}
+
+	return events
+}
+
+func publish(t *testing.T, b *Bus, events ...users.Event) {
+	t.Helper()
+
+	for _, event := range events {
+		require.NoError(t, b.Publish(t.Context(), event))
+	}
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInProgress          = errors.New("a request with the same idempotency key is in progress")
+	ErrFingerprintMismatch = errors.New("the idempotency key was used with a different request")
+)
+
+//go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package idempotency -destination ./mock.gen.$GOFILE
+type (
+	// Record is the request sent with an idempotency key and, once completed, its response.
+	Record struct {
+		// Scope is the operation the key belongs to, e.g. the REST route or the gRPC method.
+		Scope string
+		// Key is the idempotency key sent by the client.
+		Key string
+		// Fingerprint identifies the request, so the key can't be reused with a different one.
+		Fingerprint string
+		// Completed is whether the response is stored.
+		Completed bool
+		// StatusCode is the status code of the response.
+		StatusCode int
+		// Response is the serialized response.
+		Response []byte
+		// ExpiresAt is when the key can be reused.
+		ExpiresAt time.Time
+	}
+
+	// Store keeps the idempotency records.
+	Store interface {
+		// Reserve creates the record of the request, unless there is already one not expired for the scope and key.
+		// It returns the existing record, and false, if the key was already reserved.
+		Reserve(ctx context.Context, record Record) (Record, bool, error)
+		// Complete stores the response of the reserved request.
+		Complete(ctx context.Context, scope, key string, statusCode int, response []byte) error
+		// Release deletes the record, so the request can be retried, e.g. after a server error.
+		Release(ctx context.Context, scope, key string) error
+		// DeleteExpired deletes the expired records, returning how many.
+		DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	}
+)
+
+// Scope returns the scope of the keys sent to the operation by the principal, empty if anonymous, in the tenant.
+// The keys are not scoped by the client address, so the retries still match if the client changes it.
+func Scope(operation, principal, tenant string) string {
+	if principal == "" {
+		principal = "anonymous"
+	}
+
+	return operation + " principal:" + principal + " tenant:" + tenant
+}
+
+// Fingerprint returns the hash of the request parts, e.g. its route and body.
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = h.Write(part)
+		_, _ = h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Replayable returns nil if the stored response can be replayed to the request with the fingerprint,
+// ErrFingerprintMismatch if the request is different, or ErrInProgress if the response is not stored yet.
+func (r Record) Replayable(fingerprint string) error {
+	if r.Fingerprint != fingerprint {
+		return ErrFingerprintMismatch
+	}
+
+	if !r.Completed {
+		return ErrInProgress
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: idempotency.go
+//
+// Generated by this command:
+//
+//	mockgen -typed -package idempotency -source idempotency.go -package idempotency -destination ./mock.gen.idempotency.go
+//
+
+// Package idempotency is a generated GoMock package.
+package idempotency
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockStore) Complete(ctx context.Context, scope, key string, statusCode int, response []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, scope, key, statusCode, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockStoreMockRecorder) Complete(ctx, scope, key, statusCode, response any) *MockStoreCompleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockStore)(nil).Complete), ctx, scope, key, statusCode, response)
+	return &MockStoreCompleteCall{Call: call}
+}
+
+// MockStoreCompleteCall wrap *gomock.Call
+type MockStoreCompleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreCompleteCall) Return(arg0 error) *MockStoreCompleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreCompleteCall) Do(f func(context.Context, string, string, int, []byte) error) *MockStoreCompleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreCompleteCall) DoAndReturn(f func(context.Context, string, string, int, []byte) error) *MockStoreCompleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteExpired mocks base method.
+func (m *MockStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockStoreMockRecorder) DeleteExpired(ctx, now any) *MockStoreDeleteExpiredCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockStore)(nil).DeleteExpired), ctx, now)
+	return &MockStoreDeleteExpiredCall{Call: call}
+}
+
+// MockStoreDeleteExpiredCall wrap *gomock.Call
+type MockStoreDeleteExpiredCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreDeleteExpiredCall) Return(arg0 int64, arg1 error) *MockStoreDeleteExpiredCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreDeleteExpiredCall) Do(f func(context.Context, time.Time) (int64, error)) *MockStoreDeleteExpiredCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreDeleteExpiredCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockStoreDeleteExpiredCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Release mocks base method.
+func (m *MockStore) Release(ctx context.Context, scope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockStoreMockRecorder) Release(ctx, scope, key any) *MockStoreReleaseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockStore)(nil).Release), ctx, scope, key)
+	return &MockStoreReleaseCall{Call: call}
+}
+
+// MockStoreReleaseCall wrap *gomock.Call
+type MockStoreReleaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreReleaseCall) Return(arg0 error) *MockStoreReleaseCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreReleaseCall) Do(f func(context.Context, string, string) error) *MockStoreReleaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreReleaseCall) DoAndReturn(f func(context.Context, string, string) error) *MockStoreReleaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Reserve mocks base method.
+func (m *MockStore) Reserve(ctx context.Context, record Record) (Record, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, record)
+	ret0, _ := ret[0].(Record)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockStoreMockRecorder) Reserve(ctx, record any) *MockStoreReserveCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockStore)(nil).Reserve), ctx, record)
+	return &MockStoreReserveCall{Call: call}
+}
+
+// MockStoreReserveCall wrap *gomock.Call
+type MockStoreReserveCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreReserveCall) Return(arg0 Record, arg1 bool, arg2 error) *MockStoreReserveCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreReserveCall) Do(f func(context.Context, Record) (Record, bool, error)) *MockStoreReserveCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreReserveCall) DoAndReturn(f func(context.Context, Record) (Record, bool, error)) *MockStoreReserveCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
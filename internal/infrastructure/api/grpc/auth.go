@@ -0,0 +1,162 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	usersv1 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+)
+
+// methodRoles are the roles the RPCs require, any of them is enough.
+// The RPCs that are not listed require the admin role.
+//
+//nolint:gochecknoglobals // read-only map
+var methodRoles = map[string][]auth.Role{
+	usersv1.UsersService_CreateUser_FullMethodName:   {auth.RoleWriter},
+	usersv1.UsersService_DeleteUser_FullMethodName:   {auth.RoleWriter},
+	usersv1.UsersService_GetUserAudit_FullMethodName: {auth.RoleReader},
+	usersv1.UsersService_ImportUsers_FullMethodName:  {auth.RoleWriter},
+	usersv1.UsersService_WatchUsers_FullMethodName:   {auth.RoleReader},
+}
+
+// bearerScheme is the authorization scheme of the OpenID Connect tokens, e.g. "authorization: Bearer <token>".
+const bearerScheme = "Bearer "
+
+// AuthUnaryServerInterceptor authenticates the calls with an API key (authorization: ApiKey <key>),
+// or with a bearer token (authorization: Bearer <token>) verified by the token verifier, as their principal,
+// and authorizes them with the roles of the RPC, failing with Unauthenticated for the invalid keys,
+// and for the anonymous calls if the authentication is required or the RPC requires the admin role,
+// or with PermissionDenied for the principals without any of the roles.
+func AuthUnaryServerInterceptor(
+	apiKeys services.APIKeys,
+	tokens oidc.Verifier,
+	required bool,
+) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, apiKeys, tokens, required, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamServerInterceptor authenticates and authorizes the streams as AuthUnaryServerInterceptor does the calls.
+func AuthStreamServerInterceptor(
+	apiKeys services.APIKeys,
+	tokens oidc.Verifier,
+	required bool,
+) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), apiKeys, tokens, required, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
+
+// authenticate returns the context with the principal of the credentials of the call, if any,
+// or the error status if it is not authenticated or not authorized to call the method.
+func authenticate(
+	ctx context.Context,
+	apiKeys services.APIKeys,
+	tokens oidc.Verifier,
+	required bool,
+	method string,
+) (context.Context, error) {
+	ctx, err := authenticateCall(ctx, apiKeys, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, ok := methodRoles[method]
+	if !ok {
+		roles = []auth.Role{auth.RoleAdmin}
+	}
+
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		if required || slices.Contains(roles, auth.RoleAdmin) {
+			//nolint:wrapcheck // gRPC status error
+			return nil, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+		}
+
+		return ctx, nil
+	}
+
+	if errAuthorize := principal.Authorize(roles...); errAuthorize != nil {
+		//nolint:wrapcheck // gRPC status error
+		return nil, status.Error(codes.PermissionDenied, errAuthorize.Error())
+	}
+
+	return ctx, nil
+}
+
+// authenticateCall returns the context with the principal of the credentials of the authorization metadata, if any,
+// or the error status if they are invalid or can't be checked.
+func authenticateCall(ctx context.Context, apiKeys services.APIKeys, tokens oidc.Verifier) (context.Context, error) {
+	for _, authorization := range metadata.ValueFromIncomingContext(ctx, "authorization") {
+		if secret, ok := strings.CutPrefix(authorization, apikeys.Scheme); ok {
+			key, err := apiKeys.Authenticate(ctx, apikeys.Secret(secret))
+			if err != nil {
+				return nil, authenticationStatus(ctx, "Failed to authenticate api key", err)
+			}
+
+			return auth.WithPrincipal(ctx, key.Principal()), nil
+		}
+
+		if token, ok := strings.CutPrefix(authorization, bearerScheme); ok {
+			principal, err := tokens.Verify(ctx, token)
+			if err != nil {
+				return nil, authenticationStatus(ctx, "Failed to verify bearer token", err)
+			}
+
+			return auth.WithPrincipal(ctx, principal), nil
+		}
+	}
+
+	return ctx, nil
+}
+
+// authenticationStatus returns the Unauthenticated status for the invalid credentials,
+// or the Internal status if they can't be checked.
+func authenticationStatus(ctx context.Context, msg string, err error) error {
+	if errors.Is(err, apikeys.ErrInvalidKey) || errors.Is(err, oidc.ErrInvalidToken) {
+		//nolint:wrapcheck // gRPC status error
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	logging.FromContext(ctx).ErrorContext(ctx, msg, slog.Any("err", err))
+
+	if errors.Is(err, oidc.ErrKeySetUnavailable) {
+		logging.AddError(ctx, "oidc", err)
+
+		//nolint:wrapcheck // gRPC status error
+		return status.Error(codes.Internal, "error verifying bearer token")
+	}
+
+	logging.AddError(ctx, "db", err)
+
+	//nolint:wrapcheck // gRPC status error
+	return status.Error(codes.Internal, "error authenticating api key")
+}
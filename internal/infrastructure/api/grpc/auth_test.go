@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	usersv1 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+)
+
+func TestAuthUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	// adminMethod is not listed in the roles of the RPCs, so it requires the admin role.
+	const adminMethod = "/users.v1.UsersService/PurgeUser"
+
+	tests := map[string]struct {
+		authRequired  bool
+		method        string
+		authorization string
+		expected      codes.Code
+		wantPrincipal bool
+	}{
+		"anonymous allowed if not required": {
+			method:   usersv1.UsersService_GetUserAudit_FullMethodName,
+			expected: codes.OK,
+		},
+		"anonymous rejected if required": {
+			authRequired: true,
+			method:       usersv1.UsersService_GetUserAudit_FullMethodName,
+			expected:     codes.Unauthenticated,
+		},
+		"anonymous rejected from admin method if not required": {
+			method:   adminMethod,
+			expected: codes.Unauthenticated,
+		},
+		"key with the role allowed": {
+			authRequired:  true,
+			method:        usersv1.UsersService_GetUserAudit_FullMethodName,
+			authorization: "reader",
+			expected:      codes.OK,
+			wantPrincipal: true,
+		},
+		"key without the role forbidden": {
+			method:        usersv1.UsersService_CreateUser_FullMethodName,
+			authorization: "reader",
+			expected:      codes.PermissionDenied,
+		},
+		"key without the admin role forbidden": {
+			method:        adminMethod,
+			authorization: "writer",
+			expected:      codes.PermissionDenied,
+		},
+		"key with the admin role allowed": {
+			method:        adminMethod,
+			authorization: "admin",
+			expected:      codes.OK,
+			wantPrincipal: true,
+		},
+		"invalid key rejected": {
+			method:        usersv1.UsersService_GetUserAudit_FullMethodName,
+			authorization: apikeys.Scheme + "gwl_unknown_secret",
+			expected:      codes.Unauthenticated,
+		},
+		"bearer token rejected without issuer": {
+			method:        usersv1.UsersService_GetUserAudit_FullMethodName,
+			authorization: bearerScheme + "token",
+			expected:      codes.Unauthenticated,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			apiKeys, authorizations := newAPIKeys(t, auth.RoleReader, auth.RoleWriter, auth.RoleAdmin)
+			interceptor := AuthUnaryServerInterceptor(apiKeys, oidc.Verifier{}, test.authRequired)
+
+			authorization, ok := authorizations[test.authorization]
+			if !ok {
+				authorization = test.authorization
+			}
+
+			ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs("authorization", authorization))
+
+			var hasPrincipal bool
+
+			// Act
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method},
+				func(ctx context.Context, req any) (any, error) {
+					_, hasPrincipal = auth.FromContext(ctx)
+
+					return req, nil
+				})
+
+			// Assert
+			require.Equal(t, test.expected, status.Code(err))
+			assert.Equal(t, test.wantPrincipal, hasPrincipal)
+		})
+	}
+}
+
+// newAPIKeys returns the service authenticating an API key with each of the roles,
+// with the authorization metadata to authenticate with them by role.
+func newAPIKeys(t *testing.T, roles ...auth.Role) (services.APIKeys, map[string]string) {
+	t.Helper()
+
+	keys := make(map[string]apikeys.APIKey, len(roles))
+	authorizations := make(map[string]string, len(roles))
+
+	for _, role := range roles {
+		key, secret, err := apikeys.New(string(role), []auth.Role{role})
+		require.NoError(t, err)
+
+		keys[key.Prefix] = key
+		authorizations[string(role)] = apikeys.Scheme + string(secret)
+	}
+
+	repository := apikeys.NewMockRepository(gomock.NewController(t))
+	repository.EXPECT().GetByPrefix(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, prefix string) (apikeys.APIKey, error) {
+			key, ok := keys[prefix]
+			if !ok {
+				return apikeys.APIKey{}, apikeys.ErrInvalidKey
+			}
+
+			return key, nil
+		}).
+		AnyTimes()
+	repository.EXPECT().MarkUsed(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return services.NewAPIKeys(repository), authorizations
+}
@@ -0,0 +1,194 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+const (
+	// idempotencyKeyMetadata is the metadata key with the idempotency key of the call.
+	idempotencyKeyMetadata = "idempotency-key"
+	// idempotentReplayedMetadata is set to true in the responses replayed from a previous call.
+	idempotentReplayedMetadata = "idempotent-replayed"
+)
+
+// IdempotencyUnaryServerInterceptor makes the calls sent with an idempotency-key metadata safe to retry:
+// the response, or the error, of the first call is stored for the ttl and replayed to the retries.
+// Reusing the key with a different request fails with FailedPrecondition,
+// and retrying while the first call is in progress fails with Aborted.
+// The server errors are not stored, so the call can be retried.
+func IdempotencyUnaryServerInterceptor(store idempotency.Store, ttl time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		keys := metadata.ValueFromIncomingContext(ctx, idempotencyKeyMetadata)
+		message, ok := req.(proto.Message)
+
+		if len(keys) == 0 || keys[0] == "" || !ok {
+			return handler(ctx, req)
+		}
+
+		request, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error marshalling request: %v", err)
+		}
+
+		fingerprint := idempotency.Fingerprint([]byte(info.FullMethod), request)
+
+		principal, _ := auth.FromContext(ctx)
+
+		record, reserved, err := store.Reserve(ctx, idempotency.Record{
+			// The same key can be used by the principal in each tenant.
+			Scope:       idempotency.Scope(info.FullMethod, principal.ID, tenant.FromContext(ctx).String()),
+			Key:         keys[0],
+			Fingerprint: fingerprint,
+			ExpiresAt:   time.Now().Add(ttl),
+		})
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to reserve idempotency key", slog.Any("err", err))
+			logging.AddError(ctx, "db", err)
+
+			return nil, status.Error(codes.Internal, "error reserving idempotency key")
+		}
+
+		if !reserved {
+			return replay(ctx, record, fingerprint)
+		}
+
+		// The key is released if the handler panics, so the call can be retried instead of being in progress
+		// until it expires.
+		defer func() {
+			if p := recover(); p != nil {
+				release(ctx, store, record)
+				panic(p)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		complete(ctx, store, record, resp, err)
+
+		return resp, err
+	}
+}
+
+// replay returns the stored response, or error, of the record, if the request is the same and it is completed.
+func replay(ctx context.Context, record idempotency.Record, fingerprint string) (any, error) {
+	err := record.Replayable(fingerprint)
+
+	switch {
+	case errors.Is(err, idempotency.ErrFingerprintMismatch):
+		//nolint:wrapcheck // gRPC status error
+		return nil, status.Error(codes.FailedPrecondition, "the idempotency key was already used with a different request")
+	case errors.Is(err, idempotency.ErrInProgress):
+		//nolint:wrapcheck // gRPC status error
+		return nil, status.Error(codes.Aborted, "a call with the same idempotency key is in progress, retry later")
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(idempotentReplayedMetadata, "true"))
+
+	if codes.Code(record.StatusCode) != codes.OK { //nolint:gosec // the stored status code is a gRPC code
+		var st spb.Status
+
+		err = proto.Unmarshal(record.Response, &st)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error unmarshalling stored error: %v", err)
+		}
+
+		//nolint:wrapcheck // gRPC status error
+		return nil, status.FromProto(&st).Err()
+	}
+
+	var response anypb.Any
+
+	err = proto.Unmarshal(record.Response, &response)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error unmarshalling stored response: %v", err)
+	}
+
+	message, err := response.UnmarshalNew()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error unmarshalling stored response: %v", err)
+	}
+
+	return message, nil
+}
+
+// complete stores the response, or the error, of the reserved call, or releases the key if it failed with a
+// server error.
+func complete(ctx context.Context, store idempotency.Store, record idempotency.Record, resp any, handlerErr error) {
+	// The response is already returned, so the key is completed even if the call is cancelled.
+	ctx = context.WithoutCancel(ctx)
+	logger := logging.FromContext(ctx)
+
+	st := status.Convert(handlerErr)
+	if isServerError(st.Code()) {
+		release(ctx, store, record)
+
+		return
+	}
+
+	var message proto.Message = st.Proto()
+
+	if handlerErr == nil {
+		m, ok := resp.(proto.Message)
+		if !ok {
+			return
+		}
+
+		response, err := anypb.New(m)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to marshal response", slog.Any("err", err))
+
+			return
+		}
+
+		message = response
+	}
+
+	response, err := proto.Marshal(message)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to marshal response", slog.Any("err", err))
+
+		return
+	}
+
+	err = store.Complete(ctx, record.Scope, record.Key, int(st.Code()), response)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to complete idempotency key", slog.Any("err", err))
+	}
+}
+
+// release releases the key of the reserved call, so it can be retried.
+func release(ctx context.Context, store idempotency.Store, record idempotency.Record) {
+	ctx = context.WithoutCancel(ctx)
+
+	err := store.Release(ctx, record.Scope, record.Key)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to release idempotency key", slog.Any("err", err))
+	}
+}
+
+// isServerError returns whether the code is a transient or server error, so the call can be retried.
+func isServerError(code codes.Code) bool {
+	//nolint:exhaustive // the rest of the codes are client errors
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DeadlineExceeded, codes.Canceled,
+		codes.ResourceExhausted, codes.Aborted, codes.DataLoss, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
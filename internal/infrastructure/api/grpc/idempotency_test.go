@@ -0,0 +1,194 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+)
+
+func TestIdempotencyUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	method := usersv1.UsersService_CreateUser_FullMethodName
+	scope := method + " principal:anonymous tenant:default"
+	response := &usersv1.CreateUserResponse{User: &usersv1.User{Username: "john"}}
+
+	stored, errAny := anypb.New(response)
+	require.NoError(t, errAny)
+
+	storedResponse, errMarshal := proto.Marshal(stored)
+	require.NoError(t, errMarshal)
+
+	tests := map[string]struct {
+		idempotencyKey   string
+		handlerErr       error
+		expectedMockCall func(ms *idempotency.MockStore)
+		expected         codes.Code
+		wantHandled      bool
+	}{
+		"without idempotency key": {
+			expectedMockCall: func(*idempotency.MockStore) {},
+			expected:         codes.OK,
+			wantHandled:      true,
+		},
+		"first call is completed": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						return record, true, nil
+					})
+				ms.EXPECT().Complete(gomock.Any(), scope, "key", int(codes.OK), storedResponse).Return(nil)
+			},
+			expected:    codes.OK,
+			wantHandled: true,
+		},
+		"first call failing with a client error is completed": {
+			idempotencyKey: "key",
+			handlerErr:     status.Error(codes.InvalidArgument, "invalid username"),
+			expectedMockCall: func(ms *idempotency.MockStore) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						return record, true, nil
+					})
+				ms.EXPECT().Complete(gomock.Any(), scope, "key", int(codes.InvalidArgument), gomock.Any()).Return(nil)
+			},
+			expected:    codes.InvalidArgument,
+			wantHandled: true,
+		},
+		"first call failing with a server error is released": {
+			idempotencyKey: "key",
+			handlerErr:     status.Error(codes.Internal, "db error"),
+			expectedMockCall: func(ms *idempotency.MockStore) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						return record, true, nil
+					})
+				ms.EXPECT().Release(gomock.Any(), scope, "key").Return(nil)
+			},
+			expected:    codes.Internal,
+			wantHandled: true,
+		},
+		"retry is replayed": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						record.Completed = true
+						record.StatusCode = int(codes.OK)
+						record.Response = storedResponse
+
+						return record, false, nil
+					})
+			},
+			expected: codes.OK,
+		},
+		"retry while in progress": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						return record, false, nil
+					})
+			},
+			expected: codes.Aborted,
+		},
+		"key reused with a different request": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						record.Fingerprint = "different"
+						record.Completed = true
+
+						return record, false, nil
+					})
+			},
+			expected: codes.FailedPrecondition,
+		},
+		"store failing": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(idempotency.Record{}, false, errors.New("db error"))
+			},
+			expected: codes.Internal,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			store := idempotency.NewMockStore(gomock.NewController(t))
+			test.expectedMockCall(store)
+
+			interceptor := IdempotencyUnaryServerInterceptor(store, time.Hour)
+
+			ctx := t.Context()
+			if test.idempotencyKey != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(idempotencyKeyMetadata, test.idempotencyKey))
+			}
+
+			var handled bool
+
+			// Act
+			actual, err := interceptor(ctx, &usersv1.CreateUserRequest{Username: "john"},
+				&grpc.UnaryServerInfo{FullMethod: method},
+				func(context.Context, any) (any, error) {
+					handled = true
+
+					if test.handlerErr != nil {
+						return nil, test.handlerErr
+					}
+
+					return response, nil
+				})
+
+			// Assert
+			require.Equal(t, test.expected, status.Code(err))
+			assert.Equal(t, test.wantHandled, handled)
+
+			if test.expected == codes.OK {
+				assert.True(t, proto.Equal(response, actual.(proto.Message)))
+			}
+		})
+	}
+}
+
+func TestIdempotencyUnaryServerInterceptor_HandlerPanics(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	method := "/users.v1.UsersService/CreateUser"
+	store := idempotency.NewMockStore(gomock.NewController(t))
+	store.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+			return record, true, nil
+		})
+	store.EXPECT().Release(gomock.Any(), method+" principal:anonymous tenant:default", "key").Return(nil)
+
+	interceptor := IdempotencyUnaryServerInterceptor(store, time.Hour)
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(idempotencyKeyMetadata, "key"))
+
+	// Act & Assert
+	assert.PanicsWithValue(t, "handler panic", func() {
+		_, _ = interceptor(ctx, &usersv1.CreateUserRequest{Username: "john"}, &grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, any) (any, error) {
+				panic("handler panic")
+			})
+	})
+}
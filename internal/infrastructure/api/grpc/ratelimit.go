@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+)
+
+// RateLimitUnaryServerInterceptor limits the calls of each client to every RPC,
+// e.g. /users.v1.UsersService/CreateUser, failing with ResourceExhausted once the quota is exhausted.
+// It needs to be placed after AuthUnaryServerInterceptor, so the clients are identified by their principal.
+func RateLimitUnaryServerInterceptor(limiter ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		err := allow(ctx, limiter, info.FullMethod, func(md metadata.MD) error {
+			return grpc.SetHeader(ctx, md)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamServerInterceptor limits the streams opened by each client to every RPC,
+// failing with ResourceExhausted once the quota is exhausted.
+func RateLimitStreamServerInterceptor(limiter ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := allow(ss.Context(), limiter, info.FullMethod, ss.SetHeader)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// allow takes a token for the call, sending the rate limit headers, and returns the ResourceExhausted error if denied.
+// The calls are allowed if the limiter fails, so its store is not a single point of failure.
+func allow(ctx context.Context, limiter ratelimit.Limiter, method string, setHeader func(metadata.MD) error) error {
+	result, err := limiter.Allow(ctx, method, clientKey(ctx))
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "Failed to rate limit call", slog.Any("err", err))
+
+		return nil
+	}
+
+	if !result.Limit.IsUnlimited() {
+		_ = setHeader(metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(result.Limit.Requests),
+			"ratelimit-remaining", strconv.Itoa(result.Remaining),
+			"ratelimit-reset", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))),
+		))
+	}
+
+	if result.Allowed {
+		return nil
+	}
+
+	st := status.Newf(codes.ResourceExhausted, "rate limit of %s exceeded", result.Limit)
+
+	withDetails, errDetails := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)})
+	if errDetails == nil {
+		st = withDetails
+	}
+
+	//nolint:wrapcheck // gRPC status error
+	return st.Err()
+}
+
+// clientKey identifies the client by its authenticated principal, or by its IP if it is anonymous.
+// The credentials are only trusted once verified, so a client can't get a new bucket by sending made-up ones.
+func clientKey(ctx context.Context) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return ratelimit.KeyForPrincipal(principal.ID)
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ratelimit.KeyForIP("unknown")
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ratelimit.KeyForIP(p.Addr.String())
+	}
+
+	return ratelimit.KeyForIP(host)
+}
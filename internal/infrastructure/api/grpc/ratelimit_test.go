@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	usersv1 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+)
+
+func TestRateLimitUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		firstIP         string
+		firstPrincipal  string
+		secondIP        string
+		secondPrincipal string
+		expected        codes.Code
+	}{
+		"same ip is limited": {
+			firstIP:  "192.0.2.1",
+			secondIP: "192.0.2.1",
+			expected: codes.ResourceExhausted,
+		},
+		"different ips are not limited": {
+			firstIP:  "192.0.2.1",
+			secondIP: "192.0.2.2",
+			expected: codes.OK,
+		},
+		"same principal is limited from different ips": {
+			firstIP:         "192.0.2.1",
+			firstPrincipal:  "apikey:gwl_1a2b3c4d",
+			secondIP:        "192.0.2.2",
+			secondPrincipal: "apikey:gwl_1a2b3c4d",
+			expected:        codes.ResourceExhausted,
+		},
+		"different principals are not limited from the same ip": {
+			firstIP:         "192.0.2.1",
+			firstPrincipal:  "apikey:gwl_1a2b3c4d",
+			secondIP:        "192.0.2.1",
+			secondPrincipal: "apikey:gwl_5e6f7a8b",
+			expected:        codes.OK,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			method := usersv1.UsersService_CreateUser_FullMethodName
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, map[string]ratelimit.Limit{
+				method: {Requests: 1, Period: time.Minute},
+			})
+			interceptor := RateLimitUnaryServerInterceptor(limiter)
+			info := &grpc.UnaryServerInfo{FullMethod: method}
+			handler := func(_ context.Context, req any) (any, error) {
+				return req, nil
+			}
+
+			// Act
+			_, errFirst := interceptor(callContext(t, test.firstIP, test.firstPrincipal), nil, info, handler)
+			_, errSecond := interceptor(callContext(t, test.secondIP, test.secondPrincipal), nil, info, handler)
+
+			// Assert
+			require.NoError(t, errFirst)
+
+			st := status.Convert(errSecond)
+			require.Equal(t, test.expected, st.Code())
+
+			if test.expected != codes.ResourceExhausted {
+				return
+			}
+
+			require.Len(t, st.Details(), 1)
+
+			retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+			require.True(t, ok)
+			assert.InDelta(t, time.Minute.Seconds(), retryInfo.GetRetryDelay().AsDuration().Seconds(), 1)
+		})
+	}
+}
+
+// callContext returns the context of a call from the IP, authenticated as the principal if not empty.
+func callContext(t *testing.T, ip, principal string) context.Context {
+	t.Helper()
+
+	ctx := peer.NewContext(t.Context(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50051}})
+	if principal == "" {
+		return ctx
+	}
+
+	return auth.WithPrincipal(ctx, auth.Principal{ID: principal, Roles: []auth.Role{auth.RoleWriter}})
+}
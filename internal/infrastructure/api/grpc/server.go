@@ -3,29 +3,66 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"time"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/manuelarte/go-web-layout/internal/audit"
 	wideEventLogging "github.com/manuelarte/go-web-layout/internal/config/logging"
 	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/events"
 	"github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
 	"github.com/manuelarte/go-web-layout/internal/services"
 	"github.com/manuelarte/go-web-layout/internal/users"
 )
 
+// auditActions are the kinds of change of the users of the audit actions.
+// A restore, a lock and an unlock are reported as updates, and a purge as a deletion.
+//
+//nolint:gochecknoglobals // read-only map
+var auditActions = map[audit.Action]usersv1.UserEventType{
+	audit.ActionCreate:  usersv1.UserEventType_USER_EVENT_TYPE_CREATED,
+	audit.ActionUpdate:  usersv1.UserEventType_USER_EVENT_TYPE_UPDATED,
+	audit.ActionDelete:  usersv1.UserEventType_USER_EVENT_TYPE_DELETED,
+	audit.ActionRestore: usersv1.UserEventType_USER_EVENT_TYPE_UPDATED,
+	audit.ActionPurge:   usersv1.UserEventType_USER_EVENT_TYPE_DELETED,
+	audit.ActionLock:    usersv1.UserEventType_USER_EVENT_TYPE_UPDATED,
+	audit.ActionUnlock:  usersv1.UserEventType_USER_EVENT_TYPE_UPDATED,
+}
+
 type Server struct {
 	usersv1.UnimplementedUsersServiceServer
 
-	createUserService services.CreateUser
+	repository         users.Repository
+	auditRepository    audit.Repository
+	createUserService  services.CreateUser
+	importUsersService services.ImportUsers
+	bus                *events.Bus
 }
 
-func NewServer(createUserService services.CreateUser) Server {
+func NewServer(
+	repository users.Repository,
+	auditRepository audit.Repository,
+	createUserService services.CreateUser,
+	importUsersService services.ImportUsers,
+	bus *events.Bus,
+) Server {
 	return Server{
-		createUserService: createUserService,
+		repository:         repository,
+		auditRepository:    auditRepository,
+		createUserService:  createUserService,
+		importUsersService: importUsersService,
+		bus:                bus,
 	}
 }
 
@@ -43,20 +80,42 @@ func (s Server) CreateUser(
 			Value: attribute.StringValue(request.GetUsername()),
 		},
 	)
-	wideEventLogging.AddUsername(ctx, request.GetUsername())
+	wideEventLogging.AddAttrs(ctx, slog.Any("username", users.Username(request.GetUsername())))
 
 	user, err := s.createUserService.CreateUser(
 		ctx,
 		users.Username(request.GetUsername()),
 		users.Password(request.GetPassword()),
+		users.Profile{
+			Email:       users.Email(request.GetEmail()),
+			DisplayName: users.DisplayName(request.GetDisplayName()),
+			Locale:      users.Locale(request.GetLocale()),
+			AvatarURL:   users.AvatarURL(request.GetAvatarUrl()),
+		},
 	)
 	if err != nil {
+		if usernameTakenError, ok := errors.AsType[users.UsernameTakenError](err); ok {
+			//nolint:wrapcheck // gRPC status error
+			return nil, status.Error(codes.AlreadyExists, usernameTakenError.Error())
+		}
+
+		if emailTakenError, ok := errors.AsType[users.EmailTakenError](err); ok {
+			//nolint:wrapcheck // gRPC status error
+			return nil, status.Error(codes.AlreadyExists, emailTakenError.Error())
+		}
+
+		// The locale is not validated by the request rules, so it is only validated when creating the user.
+		if users.IsValidationError(err) {
+			//nolint:wrapcheck // gRPC status error
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
 		wideEventLogging.AddError(ctx, "db", err)
 
 		return nil, fmt.Errorf("error creating user: %w", err)
 	}
 
-	wideEventLogging.AddUserID(ctx, user.ID().String())
+	wideEventLogging.AddAttrs(ctx, slog.String("userId", user.ID().String()))
 
 	return &usersv1.CreateUserResponse{
 		User: new(transformUser(user)),
@@ -68,11 +127,249 @@ func (s Server) DeleteUser(_ context.Context, _ *usersv1.DeleteUserRequest) (*us
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
 }
 
+// GetUserAudit gets a page of the audit log of the user, newest first.
+func (s Server) GetUserAudit(
+	ctx context.Context,
+	request *usersv1.GetUserAuditRequest,
+) (*usersv1.GetUserAuditResponse, error) {
+	ctx, span := observability.StartSpan(ctx, "Server.GetUserAudit")
+	defer span.End()
+
+	wideEventLogging.AddAttrs(ctx, slog.String("userId", request.GetUserId()))
+
+	userID, err := uuid.Parse(request.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id: %s", err)
+	}
+
+	size := request.GetSize()
+	if size == 0 {
+		size = 20
+	}
+
+	pr, err := pagination.NewPageRequest(int(request.GetPage()), int(size))
+	if err != nil {
+		//nolint:wrapcheck // gRPC status error
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	id := users.UserID(userID)
+
+	page, err := s.auditRepository.GetByUserID(ctx, id, pr)
+	if err != nil {
+		wideEventLogging.AddError(ctx, "db", err)
+
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
+	}
+
+	// The deleted users keep their audit log, so the user is only looked up when there are no entries.
+	if page.TotalElements() == 0 {
+		_, err = s.repository.GetByID(ctx, id)
+		if notFoundError, ok := errors.AsType[users.NotFoundError](err); ok {
+			//nolint:wrapcheck // gRPC status error
+			return nil, status.Error(codes.NotFound, notFoundError.Error())
+		}
+
+		if err != nil {
+			wideEventLogging.AddError(ctx, "db", err)
+
+			return nil, fmt.Errorf("error getting user: %w", err)
+		}
+	}
+
+	return transformAuditPage(page), nil
+}
+
+// ImportUsers creates the users streamed by the client, and responds with the result of each of them.
+func (s Server) ImportUsers(
+	stream grpc.ClientStreamingServer[usersv1.ImportUsersRequest, usersv1.ImportUsersResponse],
+) error {
+	ctx, span := observability.StartSpan(stream.Context(), "Server.ImportUsers")
+	defer span.End()
+
+	rows := func(yield func(users.ImportRow, error) bool) {
+		for line := 1; ; line++ {
+			request, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(users.ImportRow{}, err)
+
+				return
+			}
+
+			row := users.ImportRow{
+				Line:     line,
+				Username: users.Username(request.GetUsername()),
+				Password: users.Password(request.GetPassword()),
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+
+	results, err := s.importUsersService.ImportUsers(ctx, rows)
+	if err != nil {
+		wideEventLogging.AddError(ctx, "db", err)
+
+		return fmt.Errorf("error importing users: %w", err)
+	}
+
+	response := transformImportResults(results)
+	wideEventLogging.AddAttrs(
+		ctx,
+		slog.Int("created", int(response.GetCreated())),
+		slog.Int("failed", int(response.GetFailed())),
+	)
+
+	//nolint:wrapcheck // gRPC status error
+	return stream.SendAndClose(response)
+}
+
+// WatchUsers streams the changes of the users, after the resume token if set.
+// The stream is aborted if the client does not keep up with the changes, so it has to resume watching.
+func (s Server) WatchUsers(
+	request *usersv1.WatchUsersRequest,
+	stream grpc.ServerStreamingServer[usersv1.WatchUsersResponse],
+) error {
+	ctx, span := observability.StartSpan(stream.Context(), "Server.WatchUsers")
+	defer span.End()
+
+	messages, err := s.bus.Subscribe(ctx, request.GetResumeToken())
+	if err != nil {
+		if errors.Is(err, events.ErrResumeTokenExpired) {
+			//nolint:wrapcheck // gRPC status error
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		//nolint:wrapcheck // gRPC status error
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for message := range messages {
+		err = stream.Send(transformMessage(message))
+		if err != nil {
+			return fmt.Errorf("error sending user event: %w", err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		//nolint:wrapcheck // gRPC status error
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	//nolint:wrapcheck // gRPC status error
+	return status.Error(codes.Aborted, "the changes were not received fast enough, resume watching from the last one")
+}
+
+func transformMessage(message events.Message) *usersv1.WatchUsersResponse {
+	response := &usersv1.WatchUsersResponse{
+		ResumeToken: message.ResumeToken,
+		UserId:      message.Event.UserID.String(),
+		OccurredAt:  timestamppb.New(message.Event.OccurredAt),
+	}
+
+	switch message.Event.Type {
+	case users.EventCreated:
+		response.Type = usersv1.UserEventType_USER_EVENT_TYPE_CREATED
+		response.User = new(transformUser(message.Event.User))
+	case users.EventUpdated:
+		response.Type = usersv1.UserEventType_USER_EVENT_TYPE_UPDATED
+		response.User = new(transformUser(message.Event.User))
+	case users.EventDeleted:
+		response.Type = usersv1.UserEventType_USER_EVENT_TYPE_DELETED
+	}
+
+	return response
+}
+
+func transformAuditPage(page pagination.Page[audit.Entry]) *usersv1.GetUserAuditResponse {
+	//gosec:disable G115 -- Not expecting to overflow
+	response := &usersv1.GetUserAuditResponse{
+		Entries:       make([]*usersv1.AuditEntry, 0, len(page.Content())),
+		Page:          int32(page.Number()),
+		Size:          int32(page.Size()),
+		TotalElements: page.TotalElements(),
+		TotalPages:    int32(page.TotalPages()),
+	}
+
+	for _, entry := range page.Content() {
+		changes := make([]*usersv1.AuditChange, 0, len(entry.Changes))
+		for _, change := range entry.Changes {
+			changes = append(changes, &usersv1.AuditChange{
+				Field:  change.Field,
+				Before: change.Before,
+				After:  change.After,
+			})
+		}
+
+		response.Entries = append(response.Entries, &usersv1.AuditEntry{
+			Id:         entry.ID,
+			Actor:      entry.Actor,
+			Action:     auditActions[entry.Action],
+			UserId:     entry.UserID.String(),
+			Changes:    changes,
+			RequestId:  entry.RequestID,
+			TraceId:    entry.TraceID,
+			OccurredAt: timestamppb.New(entry.OccurredAt),
+		})
+	}
+
+	return response
+}
+
+func transformImportResults(results []users.ImportResult) *usersv1.ImportUsersResponse {
+	response := &usersv1.ImportUsersResponse{
+		Results: make([]*usersv1.ImportUserResult, 0, len(results)),
+	}
+
+	for _, result := range results {
+		//gosec:disable G115 -- Not expecting to overflow
+		r := &usersv1.ImportUserResult{Index: int32(result.Line)}
+
+		switch {
+		case result.Err == nil:
+			r.User = new(transformUser(result.User))
+			response.Created++
+		case users.IsValidationError(result.Err), users.IsTakenError(result.Err):
+			r.Error = result.Err.Error()
+			response.Failed++
+		default:
+			r.Error = "error creating user"
+			response.Failed++
+		}
+
+		response.Results = append(response.Results, r)
+	}
+
+	return response
+}
+
 func transformUser(user users.User) usersv1.User {
+	profile := user.Profile()
+
 	return usersv1.User{
-		Id:        user.ID().String(),
-		CreatedAt: timestamppb.New(user.CreatedAt()),
-		UpdatedAt: timestamppb.New(user.UpdatedAt()),
-		Username:  string(user.Username()),
+		Id:              user.ID().String(),
+		CreatedAt:       timestamppb.New(user.CreatedAt()),
+		UpdatedAt:       timestamppb.New(user.UpdatedAt()),
+		Username:        string(user.Username()),
+		Email:           string(profile.Email),
+		DisplayName:     string(profile.DisplayName),
+		Locale:          string(profile.Locale),
+		AvatarUrl:       string(profile.AvatarURL),
+		DeletedAt:       optionalTimestamp(user.DeletedAt()),
+		EmailVerifiedAt: optionalTimestamp(user.EmailVerifiedAt()),
 	}
 }
+
+// optionalTimestamp returns the timestamp of the time, nil if it is not set.
+func optionalTimestamp(at time.Time, ok bool) *timestamppb.Timestamp {
+	if !ok {
+		return nil
+	}
+
+	return timestamppb.New(at)
+}
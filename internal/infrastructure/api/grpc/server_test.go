@@ -2,25 +2,34 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"net"
 	"strings"
 	"testing"
 	"time"
 
 	"buf.build/go/protovalidate"
+	"github.com/google/uuid"
 	protovalidatemiddleware "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/protovalidate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/events"
 	"github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
 	"github.com/manuelarte/go-web-layout/internal/services"
 	"github.com/manuelarte/go-web-layout/internal/users"
 )
@@ -74,6 +83,22 @@ func TestServer_CreateUser_ValidationErrors(t *testing.T) {
 			},
 			wantErr: "password: must be at most 64 characters",
 		},
+		"email not valid": {
+			request: &usersv1.CreateUserRequest{
+				Username: "MyUsername",
+				Password: "MyPassword",
+				Email:    "not-an-email",
+			},
+			wantErr: "email: must be a valid email address",
+		},
+		"avatar url not https": {
+			request: &usersv1.CreateUserRequest{
+				Username:  "MyUsername",
+				Password:  "MyPassword",
+				AvatarUrl: "http://example.com/john.png",
+			},
+			wantErr: "avatar_url: does not have prefix `https://`",
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -83,8 +108,8 @@ func TestServer_CreateUser_ValidationErrors(t *testing.T) {
 			ctx := t.Context()
 			ctrl := gomock.NewController(t)
 			usersRepository := users.NewMockRepository(ctrl)
-			createUserService := services.NewCreateUser(usersRepository)
-			listener := setup(t, ctx, NewServer(createUserService))
+			createUserService := services.NewCreateUser(usersRepository, newMetrics(t))
+			listener := setup(t, ctx, NewServer(nil, nil, createUserService, services.ImportUsers{}, events.NewBus(0)))
 
 			resolver.SetDefaultScheme("passthrough")
 
@@ -138,8 +163,8 @@ func TestServer_CreateUser_Successful(t *testing.T) {
 			ctx := t.Context()
 			ctrl := gomock.NewController(t)
 			usersRepository := users.NewMockRepository(ctrl)
-			createUserService := services.NewCreateUser(usersRepository)
-			listener := setup(t, ctx, NewServer(createUserService))
+			createUserService := services.NewCreateUser(usersRepository, newMetrics(t))
+			listener := setup(t, ctx, NewServer(nil, nil, createUserService, services.ImportUsers{}, events.NewBus(0)))
 
 			resolver.SetDefaultScheme("passthrough")
 
@@ -158,11 +183,14 @@ func TestServer_CreateUser_Successful(t *testing.T) {
 				time.Time{},
 				time.Time{},
 				users.Username(test.request.GetUsername()),
+				users.Profile{},
+				1,
 			)
 			usersRepository.EXPECT().Create(
 				gomock.Any(),
 				gomock.Eq(users.Username(test.request.GetUsername())),
 				gomock.Eq(users.Password(test.request.GetPassword())),
+				gomock.Eq(users.Profile{}),
 			).Return(userCreated, nil)
 
 			// Act
@@ -175,6 +203,139 @@ func TestServer_CreateUser_Successful(t *testing.T) {
 	}
 }
 
+func TestServer_ImportUsers(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctx := t.Context()
+	ctrl := gomock.NewController(t)
+	usersRepository := users.NewMockRepository(ctrl)
+	importUsersService := services.NewImportUsers(usersRepository, newMetrics(t), 10)
+	listener := setup(t, ctx, NewServer(nil, nil, services.CreateUser{}, importUsersService, events.NewBus(0)))
+
+	resolver.SetDefaultScheme("passthrough")
+
+	conn, errClient := grpc.NewClient("bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, errClient)
+
+	defer conn.Close()
+
+	client := usersv1.NewUsersServiceClient(conn)
+
+	// Assert mocks
+	userCreated := users.NewUser(users.UserID(uuid.New()), time.Time{}, time.Time{}, "John", users.Profile{}, 1)
+	usersRepository.EXPECT().CreateMany(gomock.Any(), []users.ImportRow{
+		{Line: 1, Username: "John", Password: "12345678"},
+		{Line: 3, Username: "Jane", Password: "12345678"},
+	}).Return([]users.ImportResult{
+		{Line: 1, User: userCreated},
+		{Line: 3, Err: errors.New("UNIQUE constraint failed: users.username")},
+	}, nil)
+
+	// Act
+	stream, err := client.ImportUsers(ctx)
+	require.NoError(t, err)
+
+	for _, request := range []*usersv1.ImportUsersRequest{
+		{Username: "John", Password: "12345678"},
+		{Username: "Jo", Password: "12345678"},
+		{Username: "Jane", Password: "12345678"},
+	} {
+		require.NoError(t, stream.Send(request))
+	}
+
+	resp, err := stream.CloseAndRecv()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.GetCreated())
+	assert.Equal(t, int32(2), resp.GetFailed())
+	require.Len(t, resp.GetResults(), 3)
+	assert.Equal(t, userCreated.ID().String(), resp.GetResults()[0].GetUser().GetId())
+	assert.Equal(t, users.ErrUsernameTooShort.Error(), resp.GetResults()[1].GetError())
+	assert.Equal(t, "error creating user", resp.GetResults()[2].GetError())
+}
+
+func TestServer_WatchUsers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		resumeToken func(first events.Message) string
+		wantCode    codes.Code
+	}{
+		"resuming after the first event receives the next ones": {
+			resumeToken: func(first events.Message) string {
+				return first.ResumeToken
+			},
+			wantCode: codes.OK,
+		},
+		"invalid resume token": {
+			resumeToken: func(events.Message) string {
+				return "invalid"
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"resume token from before a restart": {
+			resumeToken: func(events.Message) string {
+				return "restarted.1"
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctx := t.Context()
+			bus := events.NewBus(10)
+			listener := setup(t, ctx, NewServer(nil, nil, services.CreateUser{}, services.ImportUsers{}, bus))
+
+			// The first event is received from the bus, to resume the watch after it.
+			messages, err := bus.Subscribe(ctx, "")
+			require.NoError(t, err)
+
+			created := users.NewUser(users.UserID(uuid.New()), time.Now(), time.Now(), "John", users.Profile{}, 1)
+			deletedAt := time.Now().UTC()
+
+			require.NoError(t, bus.Publish(ctx, users.NewCreatedEvent(created)))
+			require.NoError(t, bus.Publish(ctx, users.NewDeletedEvent(created.ID(), deletedAt)))
+
+			resolver.SetDefaultScheme("passthrough")
+
+			conn, errClient := grpc.NewClient("bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return listener.Dial()
+			}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, errClient)
+
+			defer conn.Close()
+
+			client := usersv1.NewUsersServiceClient(conn)
+
+			// Act
+			stream, err := client.WatchUsers(ctx, &usersv1.WatchUsersRequest{ResumeToken: test.resumeToken(<-messages)})
+			require.NoError(t, err)
+
+			resp, err := stream.Recv()
+
+			// Assert
+			require.Equal(t, test.wantCode, status.Code(err))
+
+			if test.wantCode != codes.OK {
+				return
+			}
+
+			assert.Equal(t, usersv1.UserEventType_USER_EVENT_TYPE_DELETED, resp.GetType())
+			assert.Equal(t, created.ID().String(), resp.GetUserId())
+			assert.Nil(t, resp.GetUser())
+			assert.True(t, deletedAt.Equal(resp.GetOccurredAt().AsTime()))
+			assert.Equal(t, (<-messages).ResumeToken, resp.GetResumeToken())
+		})
+	}
+}
+
 func assertCreateUsersResponse(t *testing.T, resp, response *usersv1.CreateUserResponse) {
 	t.Helper()
 
@@ -184,6 +345,106 @@ func assertCreateUsersResponse(t *testing.T, resp, response *usersv1.CreateUserR
 	assert.Equal(t, resp.GetUser().GetUsername(), response.GetUser().GetUsername())
 }
 
+func TestServer_GetUserAudit(t *testing.T) {
+	t.Parallel()
+
+	userID := users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699"))
+	occurredAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := audit.Entry{
+		ID:         1,
+		Actor:      audit.AnonymousActor,
+		Action:     audit.ActionUpdate,
+		UserID:     userID,
+		Changes:    []audit.Change{{Field: "username", Before: new("john"), After: new("jane")}},
+		RequestID:  "request-id",
+		OccurredAt: occurredAt,
+	}
+
+	tests := map[string]struct {
+		entries  []audit.Entry
+		getByID  error
+		expected *usersv1.GetUserAuditResponse
+		wantCode codes.Code
+	}{
+		"entries of the user": {
+			entries: []audit.Entry{entry},
+			expected: &usersv1.GetUserAuditResponse{
+				Entries: []*usersv1.AuditEntry{
+					{
+						Id:         1,
+						Actor:      audit.AnonymousActor,
+						Action:     usersv1.UserEventType_USER_EVENT_TYPE_UPDATED,
+						UserId:     userID.String(),
+						Changes:    []*usersv1.AuditChange{{Field: "username", Before: new("john"), After: new("jane")}},
+						RequestId:  "request-id",
+						OccurredAt: timestamppb.New(occurredAt),
+					},
+				},
+				Page:          0,
+				Size:          20,
+				TotalElements: 1,
+				TotalPages:    1,
+			},
+		},
+		"user not found": {
+			entries:  []audit.Entry{},
+			getByID:  users.NotFoundError{ID: userID},
+			wantCode: codes.NotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctx := t.Context()
+			ctrl := gomock.NewController(t)
+			usersRepository := users.NewMockRepository(ctrl)
+			auditRepository := audit.NewMockRepository(ctrl)
+			listener := setup(t, ctx, NewServer(
+				usersRepository,
+				auditRepository,
+				services.CreateUser{},
+				services.ImportUsers{},
+				events.NewBus(0),
+			))
+
+			resolver.SetDefaultScheme("passthrough")
+
+			conn, errClient := grpc.NewClient("bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return listener.Dial()
+			}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, errClient)
+
+			defer conn.Close()
+
+			client := usersv1.NewUsersServiceClient(conn)
+
+			// Assert mocks
+			pr := pagination.MustPageRequest(0, 20)
+			auditRepository.EXPECT().GetByUserID(gomock.Any(), userID, pr).
+				Return(pagination.MustPage(test.entries, pr, int64(len(test.entries))), nil)
+
+			if test.getByID != nil {
+				usersRepository.EXPECT().GetByID(gomock.Any(), userID).Return(users.User{}, test.getByID)
+			}
+
+			// Act
+			resp, err := client.GetUserAudit(ctx, &usersv1.GetUserAuditRequest{UserId: userID.String()})
+
+			// Assert
+			if test.wantCode != codes.OK {
+				assert.Equal(t, test.wantCode, status.Code(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(test.expected, resp))
+		})
+	}
+}
+
 func setup(t *testing.T, ctx context.Context, server Server) *bufconn.Listener {
 	t.Helper()
 
@@ -220,3 +481,12 @@ func setup(t *testing.T, ctx context.Context, server Server) *bufconn.Listener {
 
 	return lis
 }
+
+func newMetrics(t *testing.T) services.Metrics {
+	t.Helper()
+
+	metrics, err := services.NewMetrics(noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	return metrics
+}
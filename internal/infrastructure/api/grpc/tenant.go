@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+// TenantMetadataKey is the gRPC metadata key of the tenant of the calls.
+const TenantMetadataKey = "x-tenant-id"
+
+// TenantUnaryServerInterceptor sets the tenant of the calls: the one of the x-tenant-id metadata, or the one their
+// principal is bound to, or the default tenant, failing with InvalidArgument for the invalid tenants,
+// or with PermissionDenied for the tenants other than the one of the principal.
+// It needs to be placed after AuthUnaryServerInterceptor.
+func TenantUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := resolveTenant(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// TenantStreamServerInterceptor sets the tenant of the streams as TenantUnaryServerInterceptor does of the calls.
+func TenantStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
+
+// resolveTenant returns the context with the tenant of the call, or the error status if it can't act on it.
+func resolveTenant(ctx context.Context) (context.Context, error) {
+	requested := ""
+	if values := metadata.ValueFromIncomingContext(ctx, TenantMetadataKey); len(values) > 0 {
+		requested = values[0]
+	}
+
+	principal, _ := auth.FromContext(ctx)
+
+	id, err := tenant.Resolve(requested, principal.Tenant)
+	if err != nil {
+		if errors.Is(err, tenant.ErrForbidden) {
+			//nolint:wrapcheck // gRPC status error
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+
+		//nolint:wrapcheck // gRPC status error
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return tenant.WithID(ctx, id), nil
+}
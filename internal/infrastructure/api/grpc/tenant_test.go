@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	usersv1 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+func TestTenantUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		principal  *auth.Principal
+		tenant     string
+		expected   codes.Code
+		wantTenant tenant.ID
+	}{
+		"default tenant": {
+			expected:   codes.OK,
+			wantTenant: tenant.Default,
+		},
+		"tenant of the metadata": {
+			tenant:     "globex",
+			expected:   codes.OK,
+			wantTenant: "globex",
+		},
+		"invalid tenant rejected": {
+			tenant:   "Globex Corp",
+			expected: codes.InvalidArgument,
+		},
+		"tenant of the principal": {
+			principal:  &auth.Principal{ID: "248289761001", Roles: []auth.Role{auth.RoleReader}, Tenant: "acme"},
+			expected:   codes.OK,
+			wantTenant: "acme",
+		},
+		"tenant of the principal in the metadata": {
+			principal:  &auth.Principal{ID: "248289761001", Roles: []auth.Role{auth.RoleReader}, Tenant: "acme"},
+			tenant:     "acme",
+			expected:   codes.OK,
+			wantTenant: "acme",
+		},
+		"other tenant than the one of the principal forbidden": {
+			principal: &auth.Principal{ID: "248289761001", Roles: []auth.Role{auth.RoleReader}, Tenant: "acme"},
+			tenant:    "globex",
+			expected:  codes.PermissionDenied,
+		},
+		"any tenant with a principal not bound to one": {
+			principal:  &auth.Principal{ID: "apikey:gwl_1a2b3c4d", Roles: []auth.Role{auth.RoleReader}},
+			tenant:     "globex",
+			expected:   codes.OK,
+			wantTenant: "globex",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			interceptor := TenantUnaryServerInterceptor()
+
+			ctx := t.Context()
+			if test.principal != nil {
+				ctx = auth.WithPrincipal(ctx, *test.principal)
+			}
+
+			if test.tenant != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(TenantMetadataKey, test.tenant))
+			}
+
+			var actualTenant tenant.ID
+
+			// Act
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: usersv1.UsersService_GetUserAudit_FullMethodName},
+				func(ctx context.Context, req any) (any, error) {
+					actualTenant = tenant.FromContext(ctx)
+
+					return req, nil
+				})
+
+			// Assert
+			require.Equal(t, test.expected, status.Code(err))
+			assert.Equal(t, test.wantTenant, actualTenant)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golaxo/gofieldselect"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+type AccountsHandler struct {
+	loginRepository          login.Repository
+	loginService             services.Login
+	emailVerificationService services.EmailVerification
+	passwordResetService     services.PasswordReset
+}
+
+func NewAccountsHandler(
+	loginRepository login.Repository,
+	loginService services.Login,
+	emailVerificationService services.EmailVerification,
+	passwordResetService services.PasswordReset,
+) AccountsHandler {
+	return AccountsHandler{
+		loginRepository:          loginRepository,
+		loginService:             loginService,
+		emailVerificationService: emailVerificationService,
+		passwordResetService:     passwordResetService,
+	}
+}
+
+func (h AccountsHandler) Login(ctx context.Context, request LoginRequestObject) (LoginResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountsHandler.Login")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	logging.AddAttrs(ctx, slog.Any("username", request.Body.Username))
+
+	user, err := h.loginService.Login(ctx, request.Body.Username, request.Body.Password, middleware.GetClientIP(ctx))
+	if err != nil {
+		if throttledError, ok := errors.AsType[login.ThrottledError](err); ok {
+			problem := throttledProblem(ctx, throttledError)
+
+			return Login429ApplicationProblemPlusJSONResponse{
+				Body:    problem,
+				Headers: Login429ResponseHeaders{RetryAfter: new(int(math.Ceil(throttledError.RetryAfter.Seconds())))},
+			}, nil
+		}
+
+		problem := accountProblem(ctx, "Error logging in", err)
+		if problem.Status == http.StatusInternalServerError {
+			return Login500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return Login4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("userId", user.ID().String()))
+
+	return Login200JSONResponse(transformUserDaoToDto(host, gofieldselect.AllIdentifiers{}, user)), nil
+}
+
+func (h AccountsHandler) GetUserLockout(
+	ctx context.Context,
+	request GetUserLockoutRequestObject,
+) (GetUserLockoutResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"AccountsHandler.GetUserLockout",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	attempts, err := h.loginRepository.GetLockout(ctx, users.UserID(request.UserId))
+	if err != nil {
+		problem := accountProblem(ctx, "Error getting user lockout", err)
+		if problem.Status == http.StatusInternalServerError {
+			return GetUserLockout500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return GetUserLockout4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return GetUserLockout200JSONResponse(transformAttemptsToDto(attempts, time.Now())), nil
+}
+
+func (h AccountsHandler) UnlockUser(
+	ctx context.Context,
+	request UnlockUserRequestObject,
+) (UnlockUserResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"AccountsHandler.UnlockUser",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	_, err := h.loginRepository.Unlock(ctx, users.UserID(request.UserId))
+	if err != nil {
+		problem := accountProblem(ctx, "Error unlocking user", err)
+		if problem.Status == http.StatusInternalServerError {
+			return UnlockUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return UnlockUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return UnlockUser204Response{}, nil
+}
+
+func (h AccountsHandler) RequestEmailVerification(
+	ctx context.Context,
+	request RequestEmailVerificationRequestObject,
+) (RequestEmailVerificationResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"AccountsHandler.RequestEmailVerification",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	err := h.emailVerificationService.Request(ctx, users.UserID(request.UserId))
+	if err != nil {
+		problem := accountProblem(ctx, "Error requesting email verification", err)
+		if problem.Status == http.StatusInternalServerError {
+			return RequestEmailVerification500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return RequestEmailVerification4XXApplicationProblemPlusJSONResponse{
+			StatusCode: int(problem.Status),
+			Body:       *problem,
+		}, nil
+	}
+
+	return RequestEmailVerification202Response{}, nil
+}
+
+func (h AccountsHandler) ConfirmEmailVerification(
+	ctx context.Context,
+	request ConfirmEmailVerificationRequestObject,
+) (ConfirmEmailVerificationResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountsHandler.ConfirmEmailVerification")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	user, err := h.emailVerificationService.Confirm(ctx, request.Body.Token)
+	if err != nil {
+		problem := accountProblem(ctx, "Error confirming email verification", err)
+		if problem.Status == http.StatusInternalServerError {
+			return ConfirmEmailVerification500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return ConfirmEmailVerification4XXApplicationProblemPlusJSONResponse{
+			StatusCode: int(problem.Status),
+			Body:       *problem,
+		}, nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("userId", user.ID().String()))
+
+	return ConfirmEmailVerification200JSONResponse(transformUserDaoToDto(host, gofieldselect.AllIdentifiers{}, user)), nil
+}
+
+// RequestPasswordReset accepts the request whether a user has the email or not,
+// so the endpoint can't be used to find out the emails of the users.
+func (h AccountsHandler) RequestPasswordReset(
+	ctx context.Context,
+	request RequestPasswordResetRequestObject,
+) (RequestPasswordResetResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountsHandler.RequestPasswordReset")
+	defer span.End()
+
+	if err := request.Body.Email.IsValid(); err != nil {
+		return nil, ValidationError{errors: map[string][]error{"/email": {err}}}
+	}
+
+	err := h.passwordResetService.Request(ctx, request.Body.Email)
+	if err != nil {
+		problem := accountProblem(ctx, "Error requesting password reset", err)
+
+		return RequestPasswordReset500ApplicationProblemPlusJSONResponse(*problem), nil
+	}
+
+	return RequestPasswordReset202Response{}, nil
+}
+
+func (h AccountsHandler) ConfirmPasswordReset(
+	ctx context.Context,
+	request ConfirmPasswordResetRequestObject,
+) (ConfirmPasswordResetResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountsHandler.ConfirmPasswordReset")
+	defer span.End()
+
+	if err := request.Body.Password.IsValid(); err != nil {
+		return nil, ValidationError{errors: map[string][]error{"/password": {err}}}
+	}
+
+	user, err := h.passwordResetService.Confirm(ctx, request.Body.Token, request.Body.Password)
+	if err != nil {
+		problem := accountProblem(ctx, "Error confirming password reset", err)
+		if problem.Status == http.StatusInternalServerError {
+			return ConfirmPasswordReset500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return ConfirmPasswordReset4XXApplicationProblemPlusJSONResponse{
+			StatusCode: int(problem.Status),
+			Body:       *problem,
+		}, nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("userId", user.ID().String()))
+
+	return ConfirmPasswordReset204Response{}, nil
+}
+
+func accountProblem(ctx context.Context, msg string, err error) *ErrorResponse {
+	requestID := middleware.GetReqID(ctx)
+
+	// The problem is the same whether the username exists or not, so the usernames are not disclosed.
+	if errors.Is(err, users.ErrInvalidCredentials) {
+		return &ErrorResponse{
+			Type:      "InvalidCredentials",
+			Title:     "Invalid credentials",
+			Detail:    users.ErrInvalidCredentials.Error(),
+			Status:    http.StatusUnauthorized,
+			RequestId: requestID,
+		}
+	}
+
+	if errors.Is(err, accounts.ErrInvalidToken) {
+		return &ErrorResponse{
+			Type:      "InvalidToken",
+			Title:     "Invalid token",
+			Detail:    accounts.ErrInvalidToken.Error(),
+			Status:    http.StatusBadRequest,
+			RequestId: requestID,
+		}
+	}
+
+	if errors.Is(err, accounts.ErrNoEmail) {
+		return &ErrorResponse{
+			Type:      "NoEmail",
+			Title:     "User has no email",
+			Detail:    accounts.ErrNoEmail.Error(),
+			Status:    http.StatusConflict,
+			RequestId: requestID,
+		}
+	}
+
+	if errors.Is(err, accounts.ErrEmailAlreadyVerified) {
+		return &ErrorResponse{
+			Type:      "EmailAlreadyVerified",
+			Title:     "Email already verified",
+			Detail:    accounts.ErrEmailAlreadyVerified.Error(),
+			Status:    http.StatusConflict,
+			RequestId: requestID,
+		}
+	}
+
+	if notFoundError, ok := errors.AsType[users.NotFoundError](err); ok {
+		return &ErrorResponse{
+			Type:      "NotFound",
+			Title:     "User not found",
+			Detail:    notFoundError.Error(),
+			Status:    http.StatusNotFound,
+			RequestId: requestID,
+		}
+	}
+
+	logging.FromContext(ctx).ErrorContext(ctx, msg, slog.Any("err", err))
+	logging.AddError(ctx, "accounts", err)
+
+	return &ErrorResponse{
+		Type:      "InternalServerError",
+		Title:     "Internal Server Error",
+		Detail:    msg,
+		Status:    http.StatusInternalServerError,
+		RequestId: requestID,
+	}
+}
+
+// throttledProblem returns the problem of the logins rejected because they were delayed or locked out.
+func throttledProblem(ctx context.Context, err login.ThrottledError) ErrorResponse {
+	problem := ErrorResponse{
+		Type:      "LoginThrottled",
+		Title:     "Too Many Failed Logins",
+		Detail:    err.Error(),
+		Status:    http.StatusTooManyRequests,
+		RequestId: middleware.GetReqID(ctx),
+	}
+
+	if err.Locked {
+		problem.Type = "LoginLocked"
+		problem.Title = "Locked Out"
+	}
+
+	return problem
+}
+
+func transformAttemptsToDto(attempts login.Attempts, now time.Time) Lockout {
+	dto := Lockout{
+		Kind:          KindLockout,
+		Locked:        attempts.IsLocked(now),
+		Failures:      attempts.Failures,
+		LastFailureAt: lo.EmptyableToPtr(attempts.LastFailureAt),
+	}
+
+	if dto.Locked {
+		dto.LockedUntil = new(attempts.LockedUntil)
+	}
+
+	return dto
+}
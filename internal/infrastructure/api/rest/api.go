@@ -7,39 +7,107 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/manuelarte/go-web-layout/internal/config"
 	"github.com/manuelarte/go-web-layout/internal/config/logging"
 	"github.com/manuelarte/go-web-layout/internal/config/observability"
-	"github.com/manuelarte/go-web-layout/internal/users"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
 )
 
 var _ StrictServerInterface = new(API)
 
 type API struct {
+	AccountsHandler
 	ActuatorsHandler
+	APIKeysHandler
 	UsersHandler
+	WebhooksHandler
 }
 
+// CreateRestAPI registers the API and its documentation in the router.
+// The actuators and the metrics are also registered, unless they are served by the admin server.
+// The requests to the API operations are authenticated with the API keys or the bearer tokens verified by
+// the token verifier, rate limited by the limiter, scoped to the tenant of their principal or their
+// X-Tenant-ID header, and the requests creating or importing users with an Idempotency-Key are made safe to retry
+// with the idempotency store.
 func CreateRestAPI(
 	r chi.Router,
 	cfg config.AppEnv,
-	userRepository users.Repository,
+	api API,
+	apiKeys services.APIKeys,
+	tokens oidc.Verifier,
+	limiter ratelimit.Limiter,
+	idempotencyStore idempotency.Store,
 	swaggerFS embed.FS,
 	openAPIBytes []byte,
 ) {
-	api := API{
-		UsersHandler: NewUsersHandler(cfg, userRepository),
+	// The last middleware is the outermost one, so the requests are authenticated before being rate limited
+	// by their principal, rate limited before their tenant is resolved, and reserved in their tenant.
+	middlewares := []MiddlewareFunc{
+		idempotent(idempotencyStore, cfg.IdempotencyKeyTTL),
+		resolveTenant,
+		rateLimit(limiter),
+		authenticate(apiKeys, tokens, cfg.AuthRequired),
 	}
-	ssi := NewStrictHandlerWithOptions(api, nil, StrictHTTPServerOptions{
+	if cfg.AdminServeAddress != "" {
+		middlewares = append(middlewares, hideActuators)
+	}
+
+	HandlerWithOptions(newStrictHandler(api), ChiServerOptions{
+		BaseRouter:       r,
+		Middlewares:      middlewares,
+		ErrorHandlerFunc: errorHandlerFunc,
+	})
+
+	if cfg.AdminServeAddress == "" {
+		r.Handle("/metrics", metricsHandler())
+	}
+
+	// Swagger
+	sfs, _ := fs.Sub(fs.FS(swaggerFS), "static/swagger-ui")
+	r.With(middleware.SetHeader(contentSecurityPolicyHeader, swaggerContentSecurityPolicy)).
+		Handle("/swagger/*", http.StripPrefix("/swagger/", http.FileServer(http.FS(sfs))))
+
+	r.Get("/api/docs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(openAPIBytes)
+	})
+}
+
+// CreateAdminAPI registers in the router the endpoints to operate the application:
+// the actuators, the metrics, and the net/http/pprof profiles (including the goroutine dumps) under /debug.
+func CreateAdminAPI(r chi.Router) {
+	wrapper := ServerInterfaceWrapper{
+		Handler:          newStrictHandler(API{}),
+		ErrorHandlerFunc: errorHandlerFunc,
+	}
+
+	r.Get(ActuatorsHealthEndpoint{}.Path(), wrapper.ActuatorsHealth)
+	r.Get(ActuatorsInfoEndpoint{}.Path(), wrapper.ActuatorsInfo)
+	r.Handle("/metrics", metricsHandler())
+	r.Mount("/debug", middleware.Profiler())
+}
+
+func newStrictHandler(api API) ServerInterface {
+	return NewStrictHandlerWithOptions(api, []StrictMiddlewareFunc{negotiateContent}, StrictHTTPServerOptions{
+		RequestErrorHandlerFunc: requestErrorHandlerFunc,
 		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
 			_, span := observability.StartSpan(r.Context(), "ResponseErrorHandlerFunc")
 			defer span.End()
 
+			if _, ok := errors.AsType[streamError](err); ok {
+				// The status was already sent, so the connection is aborted for the client to see the response is incomplete.
+				panic(http.ErrAbortHandler)
+			}
+
 			if _, ok := errors.AsType[ValidationError](err); ok {
 				w.Header().Set("Content-Type", "application/problem+json")
 				w.WriteHeader(http.StatusBadRequest)
@@ -68,8 +136,8 @@ func CreateRestAPI(
 				return
 			}
 
-			if invalidParamError, ok := errors.AsType[*InvalidParamFormatError](err); ok {
-				resp := invalidParamError.ErrorResponse(middleware.GetReqID(r.Context()))
+			if notAcceptableError, ok := errors.AsType[NotAcceptableError](err); ok {
+				resp := notAcceptableError.ErrorResponse(middleware.GetReqID(r.Context()))
 
 				bytes, errMarshal := json.Marshal(resp)
 				if errMarshal != nil {
@@ -83,19 +151,11 @@ func CreateRestAPI(
 				}
 
 				w.Header().Set("Content-Type", "application/problem+json")
-				w.WriteHeader(http.StatusBadRequest)
+				w.WriteHeader(http.StatusNotAcceptable)
 				_, _ = w.Write(bytes) // #nosec G705
 
 				return
 			}
-		},
-	})
-	HandlerWithOptions(ssi, ChiServerOptions{
-		BaseRouter:  r,
-		Middlewares: nil,
-		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
-			_, span := observability.StartSpan(r.Context(), "ErrorHandlerFunc")
-			defer span.End()
 
 			if invalidParamError, ok := errors.AsType[*InvalidParamFormatError](err); ok {
 				resp := invalidParamError.ErrorResponse(middleware.GetReqID(r.Context()))
@@ -114,18 +174,66 @@ func CreateRestAPI(
 				w.Header().Set("Content-Type", "application/problem+json")
 				w.WriteHeader(http.StatusBadRequest)
 				_, _ = w.Write(bytes) // #nosec G705
+
+				return
 			}
 		},
 	})
+}
 
-	// Prometheus
-	r.Handle("/metrics", promhttp.Handler())
+// requestErrorHandlerFunc responds 413 Content Too Large to the request bodies larger than the limit,
+// and 400 Bad Request to the ones that can't be decoded.
+func requestErrorHandlerFunc(w http.ResponseWriter, r *http.Request, err error) {
+	if maxBytesErr, ok := errors.AsType[*http.MaxBytesError](err); ok {
+		writeContentTooLarge(w, r, maxBytesErr.Limit)
 
-	// Swagger
-	sfs, _ := fs.Sub(fs.FS(swaggerFS), "static/swagger-ui")
-	r.Handle("/swagger/*", http.StripPrefix("/swagger/", http.FileServer(http.FS(sfs))))
+		return
+	}
 
-	r.Get("/api/docs", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write(openAPIBytes)
+	writeProblem(w, r, http.StatusBadRequest, "InvalidBody", "Invalid Body", err.Error())
+}
+
+func errorHandlerFunc(w http.ResponseWriter, r *http.Request, err error) {
+	_, span := observability.StartSpan(r.Context(), "ErrorHandlerFunc")
+	defer span.End()
+
+	if invalidParamError, ok := errors.AsType[*InvalidParamFormatError](err); ok {
+		resp := invalidParamError.ErrorResponse(middleware.GetReqID(r.Context()))
+
+		bytes, errMarshal := json.Marshal(resp)
+		if errMarshal != nil {
+			logging.FromContext(r.Context()).ErrorContext(
+				r.Context(),
+				"Failed to marshal error response",
+				slog.Any("err", errMarshal),
+			)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(bytes) // #nosec G705
+	}
+}
+
+// metricsHandler returns the Prometheus handler, in OpenMetrics format if requested, so the exemplars are exposed.
+func metricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+}
+
+// hideActuators responds not found to the actuators requests, because they are served by the admin server.
+func hideActuators(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/actuators/") {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
 	})
 }
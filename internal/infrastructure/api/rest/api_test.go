@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestCreateRestAPI_Routes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		adminServeAddress string
+		path              string
+		expectedStatus    int
+	}{
+		"actuators served without admin server": {
+			path:           "/actuators/info",
+			expectedStatus: http.StatusOK,
+		},
+		"metrics served without admin server": {
+			path:           "/metrics",
+			expectedStatus: http.StatusOK,
+		},
+		"actuators not served with admin server": {
+			adminServeAddress: ":3003",
+			path:              "/actuators/info",
+			expectedStatus:    http.StatusNotFound,
+		},
+		"metrics not served with admin server": {
+			adminServeAddress: ":3003",
+			path:              "/metrics",
+			expectedStatus:    http.StatusNotFound,
+		},
+		"docs served with admin server": {
+			adminServeAddress: ":3003",
+			path:              "/api/docs",
+			expectedStatus:    http.StatusOK,
+		},
+		"pprof not served": {
+			path:           "/debug/pprof/",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			r := chi.NewRouter()
+			cfg := config.AppEnv{AdminServeAddress: test.adminServeAddress}
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			ctrl := gomock.NewController(t)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, users.NewMockRepository(ctrl)), services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(ctrl), embed.FS{}, []byte("openapi"),
+			)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, test.path, http.NoBody)
+			require.NoError(t, err)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestCreateAdminAPI_Routes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		path           string
+		expectedStatus int
+	}{
+		"actuators info": {
+			path:           "/actuators/info",
+			expectedStatus: http.StatusOK,
+		},
+		"metrics": {
+			path:           "/metrics",
+			expectedStatus: http.StatusOK,
+		},
+		"pprof index": {
+			path:           "/debug/pprof/",
+			expectedStatus: http.StatusOK,
+		},
+		"goroutine dump": {
+			path:           "/debug/pprof/goroutine?debug=2",
+			expectedStatus: http.StatusOK,
+		},
+		"api not served": {
+			path:           "/api/v1/users",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			r := chi.NewRouter()
+			CreateAdminAPI(r)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, test.path, http.NoBody)
+			require.NoError(t, err)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/services"
+)
+
+type APIKeysHandler struct {
+	cfg        config.AppEnv
+	repository apikeys.Repository
+	service    services.APIKeys
+}
+
+func NewAPIKeysHandler(cfg config.AppEnv, repository apikeys.Repository, service services.APIKeys) APIKeysHandler {
+	return APIKeysHandler{
+		cfg:        cfg,
+		repository: repository,
+		service:    service,
+	}
+}
+
+func (h APIKeysHandler) CreateAPIKey(
+	ctx context.Context,
+	request CreateAPIKeyRequestObject,
+) (CreateAPIKeyResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "APIKeysHandler.CreateAPIKey")
+	defer span.End()
+
+	validationErrors := make(map[string][]error)
+	if err := apikeys.ValidateName(request.Body.Name); err != nil {
+		validationErrors["/name"] = append(validationErrors["/name"], err)
+	}
+
+	if err := apikeys.ValidateRoles(request.Body.Roles); err != nil {
+		validationErrors["/roles"] = append(validationErrors["/roles"], err)
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, ValidationError{errors: validationErrors}
+	}
+
+	key, secret, err := h.service.Create(ctx, request.Body.Name, request.Body.Roles)
+	if err != nil {
+		problem := apiKeyProblem(ctx, "Error creating api key", err)
+
+		return CreateAPIKey500ApplicationProblemPlusJSONResponse(*problem), nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("apiKeyId", key.ID.String()), slog.String("apiKeyPrefix", key.Prefix))
+
+	// The key is only shown when it is created, only its hash is stored.
+	dto := transformAPIKeyToDto(key)
+	dto.Key = new(string(secret))
+
+	return CreateAPIKey201JSONResponse(dto), nil
+}
+
+func (h APIKeysHandler) GetAPIKeys(
+	ctx context.Context,
+	request GetAPIKeysRequestObject,
+) (GetAPIKeysResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "APIKeysHandler.GetAPIKeys")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	pr, err := newPageRequest(request.Params.Page, request.Params.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.AddAttrs(ctx, slog.Int("page", pr.Page()), slog.Int("size", pr.Size()))
+
+	page, err := h.repository.GetAll(ctx, pr)
+	if err != nil {
+		problem := apiKeyProblem(ctx, "Error getting api keys", err)
+
+		return GetAPIKeys500ApplicationProblemPlusJSONResponse(*problem), nil
+	}
+
+	urlBuilder := func(page, size int32) string {
+		return fmt.Sprintf("%s%s", host, Paths{}.GetAPIKeysEndpoint.Path(GetAPIKeysEndpointQueryParams{
+			Page: strconv.FormatInt(int64(page), 10),
+			Size: strconv.FormatInt(int64(size), 10),
+		}))
+	}
+
+	return GetAPIKeys200JSONResponse{
+		Kind: KindPage,
+		Content: lo.Map(page.Content(), func(item apikeys.APIKey, _ int) ApiKey {
+			return transformAPIKeyToDto(item)
+		}),
+		Page: transformPage(page, urlBuilder),
+		Metadata: RequestMetadata{
+			Environment: h.cfg.Env,
+			RequestId:   middleware.GetReqID(ctx),
+			ServerId:    h.cfg.ServerID,
+			ApiVersion:  "v1",
+		},
+	}, nil
+}
+
+func (h APIKeysHandler) RevokeAPIKey(
+	ctx context.Context,
+	request RevokeAPIKeyRequestObject,
+) (RevokeAPIKeyResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"APIKeysHandler.RevokeAPIKey",
+		oteltrace.WithAttributes(attribute.String("id", request.ApiKeyId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("apiKeyId", request.ApiKeyId.String()))
+
+	key, err := h.repository.Revoke(ctx, apikeys.KeyID(request.ApiKeyId), time.Now())
+	if err != nil {
+		problem := apiKeyProblem(ctx, "Error revoking api key", err)
+		if problem.Status == http.StatusInternalServerError {
+			return RevokeAPIKey500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return RevokeAPIKey4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("apiKeyPrefix", key.Prefix))
+
+	return RevokeAPIKey204Response{}, nil
+}
+
+// apiKeyProblem returns the problem to respond when an API key could not be read or modified.
+func apiKeyProblem(ctx context.Context, msg string, err error) *ErrorResponse {
+	requestID := middleware.GetReqID(ctx)
+
+	if notFoundError, ok := errors.AsType[apikeys.NotFoundError](err); ok {
+		return &ErrorResponse{
+			Type:      "NotFound",
+			Title:     "API key not found",
+			Detail:    notFoundError.Error(),
+			Status:    http.StatusNotFound,
+			RequestId: requestID,
+		}
+	}
+
+	logging.FromContext(ctx).ErrorContext(ctx, msg, slog.Any("err", err))
+	logging.AddError(ctx, "db", err)
+
+	return &ErrorResponse{
+		Type:      "DatabaseError",
+		Title:     "Internal Server Error",
+		Detail:    msg,
+		Status:    http.StatusInternalServerError,
+		RequestId: requestID,
+	}
+}
+
+func transformAPIKeyToDto(k apikeys.APIKey) ApiKey {
+	return ApiKey{
+		Kind:       KindApiKey,
+		Id:         uuid.UUID(k.ID),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Roles:      k.Roles,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: lo.EmptyableToPtr(k.LastUsedAt),
+		RevokedAt:  lo.EmptyableToPtr(k.RevokedAt),
+	}
+}
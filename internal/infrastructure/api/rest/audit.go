@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"github.com/manuelarte/go-web-layout/internal/audit"
+)
+
+func transformAuditEntryToDto(entry audit.Entry) AuditEntry {
+	dto := AuditEntry{
+		Kind:      KindAuditEntry,
+		Id:        entry.ID,
+		Actor:     entry.Actor,
+		Action:    AuditEntryAction(entry.Action),
+		UserId:    uuid.UUID(entry.UserID),
+		RequestId: entry.RequestID,
+		Changes: lo.Map(entry.Changes, func(item audit.Change, _ int) AuditChange {
+			return AuditChange{Field: item.Field, Before: item.Before, After: item.After}
+		}),
+		OccurredAt: entry.OccurredAt,
+	}
+
+	if entry.TraceID != "" {
+		dto.TraceId = new(entry.TraceID)
+	}
+
+	return dto
+}
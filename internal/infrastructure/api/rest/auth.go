@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/samber/lo"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+)
+
+// bearerScheme is the authorization scheme of the OpenID Connect tokens, e.g. "Authorization: Bearer <token>".
+const bearerScheme = "Bearer "
+
+// authenticate authenticates the requests with an API key (Authorization: ApiKey <key>),
+// or with a bearer token (Authorization: Bearer <token>) verified by the token verifier, as their principal,
+// and authorizes the operations with a security requirement: the principal must be granted any of its roles.
+// The invalid credentials are rejected with 401 Unauthorized, as the anonymous requests if the authentication
+// is required, and the principals without any of the roles with 403 Forbidden.
+// The operations without security requirement, e.g. the login, are public.
+func authenticate(apiKeys services.APIKeys, tokens oidc.Verifier, required bool) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			principal, ok, err := authenticateRequest(r, apiKeys, tokens)
+			if err != nil {
+				if errors.Is(err, apikeys.ErrInvalidKey) || errors.Is(err, oidc.ErrInvalidToken) {
+					writeUnauthorized(w, r, tokens, err)
+
+					return
+				}
+
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to authenticate request", slog.Any("err", err))
+				writeAuthenticationProblem(ctx, w, r, err)
+
+				return
+			}
+
+			if ok {
+				r = r.WithContext(auth.WithPrincipal(ctx, principal))
+			}
+
+			if status, errAuthorize := authorize(r, required); errAuthorize != nil {
+				if status == http.StatusUnauthorized {
+					writeUnauthorized(w, r, tokens, errAuthorize)
+				} else {
+					writeProblem(w, r, status, "Forbidden", "Forbidden", errAuthorize.Error())
+				}
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authenticateRequest returns the principal of the credentials of the Authorization header, false if there are none.
+func authenticateRequest(
+	r *http.Request,
+	apiKeys services.APIKeys,
+	tokens oidc.Verifier,
+) (auth.Principal, bool, error) {
+	authorization := r.Header.Get("Authorization")
+
+	if secret, ok := strings.CutPrefix(authorization, apikeys.Scheme); ok {
+		key, err := apiKeys.Authenticate(r.Context(), apikeys.Secret(secret))
+		if err != nil {
+			return auth.Principal{}, false, fmt.Errorf("error authenticating api key: %w", err)
+		}
+
+		return key.Principal(), true, nil
+	}
+
+	if token, ok := strings.CutPrefix(authorization, bearerScheme); ok {
+		principal, err := tokens.Verify(r.Context(), token)
+		if err != nil {
+			return auth.Principal{}, false, fmt.Errorf("error verifying bearer token: %w", err)
+		}
+
+		return principal, true, nil
+	}
+
+	return auth.Principal{}, false, nil
+}
+
+// authorize returns the status and the error to reject the request with if its principal can't perform the operation.
+// The admin operations always require a principal, even if the authentication is not required.
+func authorize(r *http.Request, required bool) (int32, error) {
+	scopes, secured := r.Context().Value(ApiKeyScopes).([]string)
+	if !secured {
+		return http.StatusOK, nil
+	}
+
+	roles := lo.Map(scopes, func(item string, _ int) auth.Role {
+		return auth.Role(item)
+	})
+
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		if required || slices.Contains(roles, auth.RoleAdmin) {
+			return http.StatusUnauthorized, auth.ErrUnauthenticated
+		}
+
+		return http.StatusOK, nil
+	}
+
+	if err := principal.Authorize(roles...); err != nil {
+		return http.StatusForbidden, fmt.Errorf("error authorizing %s: %w", principal.ID, err)
+	}
+
+	return http.StatusOK, nil
+}
+
+// authorizationProblem returns the problem of the requests without a principal granted any of the roles,
+// nil if it is, for the parameters restricted to some roles, e.g. includeDeleted to the admins.
+func authorizationProblem(ctx context.Context, roles ...auth.Role) *ErrorResponse {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return &ErrorResponse{
+			Type:      "Unauthorized",
+			Title:     "Unauthorized",
+			Detail:    auth.ErrUnauthenticated.Error(),
+			Status:    http.StatusUnauthorized,
+			RequestId: middleware.GetReqID(ctx),
+		}
+	}
+
+	if err := principal.Authorize(roles...); err != nil {
+		return &ErrorResponse{
+			Type:      "Forbidden",
+			Title:     "Forbidden",
+			Detail:    fmt.Errorf("error authorizing %s: %w", principal.ID, err).Error(),
+			Status:    http.StatusForbidden,
+			RequestId: middleware.GetReqID(ctx),
+		}
+	}
+
+	return nil
+}
+
+// writeUnauthorized responds 401 Unauthorized, with the challenges of the accepted authorization schemes.
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, tokens oidc.Verifier, err error) {
+	w.Header().Set("WWW-Authenticate", strings.TrimSpace(apikeys.Scheme))
+
+	if tokens.Enabled() {
+		w.Header().Add("WWW-Authenticate", strings.TrimSpace(bearerScheme))
+	}
+
+	writeProblem(w, r, http.StatusUnauthorized, "Unauthorized", "Unauthorized", err.Error())
+}
+
+// writeAuthenticationProblem responds 500 Internal Server Error if the credentials of the request can't be checked,
+// as the keys can't be read from the database, or the identity provider is unavailable.
+func writeAuthenticationProblem(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, oidc.ErrKeySetUnavailable) {
+		logging.AddError(ctx, "oidc", err)
+		writeProblem(w, r, http.StatusInternalServerError,
+			"IdentityProviderError", "Internal Server Error", "Error verifying bearer token")
+
+		return
+	}
+
+	logging.AddError(ctx, "db", err)
+	writeProblem(w, r, http.StatusInternalServerError,
+		"DatabaseError", "Internal Server Error", "Error authenticating api key")
+}
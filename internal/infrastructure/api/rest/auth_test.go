@@ -0,0 +1,246 @@
+package rest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.NewString()
+
+	tests := map[string]struct {
+		authRequired  bool
+		withoutOIDC   bool
+		method        string
+		path          string
+		authorization string
+		expected      int
+		expectedType  string
+		// rejectedByHandler is whether the request is rejected by the handler, without the challenges.
+		rejectedByHandler bool
+	}{
+		"anonymous allowed if not required": {
+			method:   http.MethodGet,
+			path:     "/api/v1/users/" + userID,
+			expected: http.StatusOK,
+		},
+		"anonymous rejected if required": {
+			authRequired: true,
+			method:       http.MethodGet,
+			path:         "/api/v1/users/" + userID,
+			expected:     http.StatusUnauthorized,
+			expectedType: "Unauthorized",
+		},
+		"anonymous rejected from admin operation if not required": {
+			method:       http.MethodPost,
+			path:         "/api/v1/users/" + userID + "/purge",
+			expected:     http.StatusUnauthorized,
+			expectedType: "Unauthorized",
+		},
+		"anonymous rejected listing deleted users": {
+			method:            http.MethodGet,
+			path:              "/api/v1/users?includeDeleted=true",
+			expected:          http.StatusUnauthorized,
+			expectedType:      "Unauthorized",
+			rejectedByHandler: true,
+		},
+		"key without the admin role forbidden listing deleted users": {
+			method:        http.MethodGet,
+			path:          "/api/v1/users?includeDeleted=true",
+			authorization: "reader",
+			expected:      http.StatusForbidden,
+			expectedType:  "Forbidden",
+		},
+		"public operation allowed if required": {
+			authRequired: true,
+			method:       http.MethodGet,
+			path:         "/actuators/info",
+			expected:     http.StatusOK,
+		},
+		"key with the role allowed": {
+			authRequired:  true,
+			method:        http.MethodGet,
+			path:          "/api/v1/users/" + userID,
+			authorization: "reader",
+			expected:      http.StatusOK,
+		},
+		"key without the role forbidden": {
+			method:        http.MethodPost,
+			path:          "/api/v1/users/" + userID + "/purge",
+			authorization: "reader",
+			expected:      http.StatusForbidden,
+			expectedType:  "Forbidden",
+		},
+		"bearer token with the role allowed": {
+			authRequired:  true,
+			method:        http.MethodGet,
+			path:          "/api/v1/users/" + userID,
+			authorization: "reader token",
+			expected:      http.StatusOK,
+		},
+		"bearer token without the role forbidden": {
+			method:        http.MethodPost,
+			path:          "/api/v1/users/" + userID + "/purge",
+			authorization: "reader token",
+			expected:      http.StatusForbidden,
+			expectedType:  "Forbidden",
+		},
+		"invalid bearer token rejected": {
+			method:        http.MethodGet,
+			path:          "/api/v1/users/" + userID,
+			authorization: bearerScheme + "invalid",
+			expected:      http.StatusUnauthorized,
+			expectedType:  "Unauthorized",
+		},
+		"bearer token rejected without issuer": {
+			withoutOIDC:   true,
+			method:        http.MethodGet,
+			path:          "/api/v1/users/" + userID,
+			authorization: "reader token",
+			expected:      http.StatusUnauthorized,
+			expectedType:  "Unauthorized",
+		},
+		"invalid key rejected": {
+			method:        http.MethodGet,
+			path:          "/api/v1/users/" + userID,
+			authorization: apikeys.Scheme + "gwl_unknown_secret",
+			expected:      http.StatusUnauthorized,
+			expectedType:  "Unauthorized",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			r := chi.NewRouter()
+			cfg := config.AppEnv{AuthRequired: test.authRequired}
+			repository := users.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+				Return(users.NewUser(users.UserID(uuid.New()), time.Now(), time.Now(), "John", users.Profile{}, 1), nil).
+				AnyTimes()
+
+			apiKeys, headers := newAPIKeys(t, "reader")
+			tokens, tokenHeaders := newBearerTokens(t, "reader")
+			maps.Copy(headers, tokenHeaders)
+
+			if test.withoutOIDC {
+				tokens = oidc.Verifier{}
+			}
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), apiKeys, tokens,
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			req := httptest.NewRequestWithContext(t.Context(), test.method, test.path, http.NoBody)
+			if header, ok := headers[test.authorization]; ok {
+				req.Header = header.Clone()
+			} else if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			w := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, test.expected, w.Code)
+
+			if test.expectedType == "" {
+				return
+			}
+
+			var actual ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+			assert.Equal(t, test.expectedType, actual.Type)
+
+			if test.expected == http.StatusUnauthorized && !test.rejectedByHandler {
+				assert.Contains(t, w.Header().Values("WWW-Authenticate"), "ApiKey")
+			}
+		})
+	}
+}
+
+// newBearerTokens returns the verifier of the tokens of an identity provider served by a test server,
+// with the headers to authenticate with a token with each of the roles, by "<role> token".
+// The tokens are of the tenant "acme".
+func newBearerTokens(t *testing.T, roles ...auth.Role) (oidc.Verifier, map[string]http.Header) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	point, err := key.PublicKey.Bytes()
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "EC",
+			"kid": "test",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(point[1:33]),
+			"y":   base64.RawURLEncoding.EncodeToString(point[33:]),
+		}}})
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := oidc.NewVerifier(server.Client(), oidc.Config{
+		Issuer:      "https://idp.example.com",
+		Audience:    "go-web-layout",
+		JWKSURL:     server.URL,
+		CacheTTL:    time.Hour,
+		RolesClaim:  "roles",
+		TenantClaim: "tenant",
+	})
+	require.NoError(t, err)
+
+	headers := make(map[string]http.Header, len(roles))
+
+	for _, role := range roles {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"iss":    "https://idp.example.com",
+			"aud":    "go-web-layout",
+			"sub":    "248289761001",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"roles":  []auth.Role{role},
+			"tenant": "acme",
+		})
+		token.Header["kid"] = "test"
+
+		signed, errSign := token.SignedString(key)
+		require.NoError(t, errSign)
+
+		headers[string(role)+" token"] = http.Header{"Authorization": []string{bearerScheme + signed}}
+	}
+
+	return verifier, headers
+}
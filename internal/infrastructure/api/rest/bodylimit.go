@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+)
+
+// LimitBody limits the size of the request bodies to HTTPMaxBodySize, or to HTTPMaxImportBodySize for the bulk
+// import of users, responding 413 Content Too Large to the larger ones.
+// The bodies declaring a larger Content-Length are rejected before being read, and the ones without it,
+// e.g. chunked, once the limit is read.
+func LimitBody(cfg config.AppEnv) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := cfg.HTTPMaxBodySize
+			if r.URL.Path == importUsersPath {
+				limit = cfg.HTTPMaxImportBodySize
+			}
+
+			if limit <= 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if r.ContentLength > limit {
+				writeContentTooLarge(w, r, limit)
+
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeContentTooLarge responds 413 Content Too Large to the request with a body larger than the limit.
+func writeContentTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	writeProblem(w, r, http.StatusRequestEntityTooLarge, "ContentTooLarge", "Content Too Large",
+		fmt.Sprintf("The request body is larger than %d bytes", limit))
+}
@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestLimitBody(t *testing.T) {
+	t.Parallel()
+
+	largeBody := `{"username":"` + strings.Repeat("a", 100) + `"`
+
+	tests := map[string]struct {
+		maxBodySize  int64
+		path         string
+		body         string
+		chunked      bool
+		expected     int
+		expectedType string
+	}{
+		"body within the limit": {
+			maxBodySize:  64,
+			path:         "/api/v1/users",
+			body:         "{",
+			expected:     http.StatusBadRequest,
+			expectedType: "InvalidBody",
+		},
+		"content length over the limit": {
+			maxBodySize:  64,
+			path:         "/api/v1/users",
+			body:         largeBody,
+			expected:     http.StatusRequestEntityTooLarge,
+			expectedType: "ContentTooLarge",
+		},
+		"chunked body over the limit": {
+			maxBodySize:  64,
+			path:         "/api/v1/users",
+			body:         largeBody,
+			chunked:      true,
+			expected:     http.StatusRequestEntityTooLarge,
+			expectedType: "ContentTooLarge",
+		},
+		"unlimited": {
+			path:         "/api/v1/users",
+			body:         largeBody,
+			expected:     http.StatusBadRequest,
+			expectedType: "InvalidBody",
+		},
+		"import within its own limit": {
+			maxBodySize:  64,
+			path:         importUsersPath,
+			body:         largeBody,
+			expected:     http.StatusBadRequest,
+			expectedType: "InvalidBody",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{
+				HTTPMaxBodySize:       test.maxBodySize,
+				HTTPMaxImportBodySize: 1024,
+			}
+			r := chi.NewRouter()
+			r.Use(LimitBody(cfg))
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, users.NewMockRepository(gomock.NewController(t))),
+				services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			var body io.Reader = strings.NewReader(test.body)
+			if test.chunked {
+				body = io.MultiReader(body)
+			}
+
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, test.path, body)
+			req.Header.Set("Content-Type", "application/json")
+
+			if test.chunked {
+				req.ContentLength = -1
+			}
+
+			w := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, test.expected, w.Code)
+
+			var actual ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+			assert.Equal(t, test.expectedType, actual.Type)
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/golaxo/gofieldselect"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+const (
+	// importFileField is the form field with the file of the users to import.
+	importFileField = "file"
+	// importUsersPath is the path of the bulk import of users.
+	importUsersPath = "/api/v1/users/import"
+	// maxNDJSONLineSize is the maximum size of a line of an NDJSON file to import.
+	maxNDJSONLineSize = 64 * 1024
+)
+
+var (
+	_ error = new(importReadError)
+	_ error = new(streamError)
+)
+
+type (
+	// importReadError is returned when the file to import can't be read, so the import is stopped.
+	importReadError struct {
+		err error
+	}
+
+	// streamError is returned when a streamed response fails after its status was sent.
+	streamError struct {
+		err error
+	}
+
+	// csvImportColumns are the indexes of the columns of the CSV file to import, -1 for the optional ones missing.
+	csvImportColumns struct {
+		username    int
+		password    int
+		email       int
+		displayName int
+		locale      int
+		avatarURL   int
+	}
+)
+
+func (e importReadError) Error() string {
+	return "error reading the file: " + e.err.Error()
+}
+
+func (e importReadError) Unwrap() error {
+	return e.err
+}
+
+func (e streamError) Error() string {
+	return "error streaming the response: " + e.err.Error()
+}
+
+func (e streamError) Unwrap() error {
+	return e.err
+}
+
+// LongRunning returns whether the request is to an operation that is expected to take longer than the
+// request timeout, as the bulk import, the streaming export of users and the feed of user events.
+func LongRunning(r *http.Request) bool {
+	switch r.URL.Path {
+	case importUsersPath,
+		Paths{}.ExportUsersEndpoint.Path(ExportUsersEndpointQueryParams{}),
+		Paths{}.GetUserEventsEndpoint.Path(GetUserEventsEndpointQueryParams{}):
+		return true
+	default:
+		return false
+	}
+}
+
+// importBodyProblem returns the problem of the body of the import that can't be read:
+// 413 Content Too Large if it is larger than the limit, or 400 Bad Request otherwise.
+func importBodyProblem(requestID string, err error) ImportUsers4XXApplicationProblemPlusJSONResponse {
+	if maxBytesErr, ok := errors.AsType[*http.MaxBytesError](err); ok {
+		return ImportUsers4XXApplicationProblemPlusJSONResponse{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Body: ErrorResponse{
+				Type:      "ContentTooLarge",
+				Title:     "Content Too Large",
+				Detail:    fmt.Sprintf("The request body is larger than %d bytes", maxBytesErr.Limit),
+				Status:    http.StatusRequestEntityTooLarge,
+				RequestId: requestID,
+			},
+		}
+	}
+
+	return ImportUsers4XXApplicationProblemPlusJSONResponse{
+		StatusCode: http.StatusBadRequest,
+		Body: ErrorResponse{
+			Type:      "InvalidBody",
+			Title:     "Invalid Body",
+			Detail:    err.Error(),
+			Status:    http.StatusBadRequest,
+			RequestId: requestID,
+		},
+	}
+}
+
+// importFile returns the part of the multipart body with the file to import.
+func importFile(body *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := body.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("the %q field with the file is required", importFileField)
+			}
+
+			return nil, fmt.Errorf("error reading multipart body: %w", err)
+		}
+
+		if part.FormName() == importFileField {
+			return part, nil
+		}
+
+		_ = part.Close()
+	}
+}
+
+// importRows returns the rows of the file to import, read as CSV or NDJSON depending on its Content-Type,
+// or on its extension if the Content-Type is not one of them.
+func importRows(part *multipart.Part) (iter.Seq2[users.ImportRow, error], bool) {
+	mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	if mediaType != mediaTypeCSV && mediaType != mediaTypeNDJSON {
+		switch strings.ToLower(path.Ext(part.FileName())) {
+		case ".csv":
+			mediaType = mediaTypeCSV
+		case ".ndjson", ".jsonl":
+			mediaType = mediaTypeNDJSON
+		}
+	}
+
+	switch mediaType {
+	case mediaTypeCSV:
+		return csvRows(part), true
+	case mediaTypeNDJSON:
+		return ndjsonRows(part), true
+	default:
+		return nil, false
+	}
+}
+
+// csvRows reads the rows of a CSV file, with a header row with the username and password columns,
+// and optionally the email, displayName, locale and avatarUrl ones.
+// The line of each row is its line in the file.
+func csvRows(r io.Reader) iter.Seq2[users.ImportRow, error] {
+	return func(yield func(users.ImportRow, error) bool) {
+		reader := csv.NewReader(r)
+
+		columns, err := readCSVHeader(reader)
+		if err != nil {
+			yield(users.ImportRow{}, importReadError{err: err})
+
+			return
+		}
+
+		for {
+			record, errRead := reader.Read()
+			if errors.Is(errRead, io.EOF) {
+				return
+			}
+
+			if parseErr, ok := errors.AsType[*csv.ParseError](errRead); ok {
+				row := users.ImportRow{Line: parseErr.StartLine, Err: fmt.Errorf("%w: %w", users.ErrInvalidRow, parseErr.Err)}
+				if !yield(row, nil) {
+					return
+				}
+
+				continue
+			}
+
+			if errRead != nil {
+				yield(users.ImportRow{}, importReadError{err: errRead})
+
+				return
+			}
+
+			line, _ := reader.FieldPos(0)
+
+			row := users.ImportRow{
+				Line:     line,
+				Username: users.Username(record[columns.username]),
+				Password: users.Password(record[columns.password]),
+				Profile: users.Profile{
+					Email:       users.Email(csvField(record, columns.email)),
+					DisplayName: users.DisplayName(csvField(record, columns.displayName)),
+					Locale:      users.Locale(csvField(record, columns.locale)),
+					AvatarURL:   users.AvatarURL(csvField(record, columns.avatarURL)),
+				},
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readCSVHeader reads the header row, returning the indexes of the columns.
+func readCSVHeader(reader *csv.Reader) (csvImportColumns, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return csvImportColumns{}, fmt.Errorf("error reading the header: %w", err)
+	}
+
+	for i, column := range header {
+		// The byte order mark written by some spreadsheets is not part of the column name.
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+	}
+
+	columns := csvImportColumns{
+		username:    slices.Index(header, "username"),
+		password:    slices.Index(header, "password"),
+		email:       slices.Index(header, "email"),
+		displayName: slices.Index(header, "displayname"),
+		locale:      slices.Index(header, "locale"),
+		avatarURL:   slices.Index(header, "avatarurl"),
+	}
+	if columns.username < 0 || columns.password < 0 {
+		return csvImportColumns{}, errors.New("the header must have the username and password columns")
+	}
+
+	return columns, nil
+}
+
+// csvField returns the value of the column of the record, empty if the column is missing.
+func csvField(record []string, column int) string {
+	if column < 0 {
+		return ""
+	}
+
+	return record[column]
+}
+
+// ndjsonRows reads the rows of an NDJSON file, with an object with the username, password and profile per line.
+// The line of each row is its line in the file, the blank lines are skipped.
+func ndjsonRows(r io.Reader) iter.Seq2[users.ImportRow, error] {
+	return func(yield func(users.ImportRow, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxNDJSONLineSize)
+
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+
+			row := users.ImportRow{Line: line}
+
+			var user CreateUser
+
+			err := json.Unmarshal(scanner.Bytes(), &user)
+			if err != nil {
+				row.Err = fmt.Errorf("%w: %w", users.ErrInvalidRow, err)
+			} else {
+				row.Username, row.Password, row.Profile = user.Username, user.Password, transformCreateUserProfile(user)
+			}
+
+			if !yield(row, nil) {
+				return
+			}
+		}
+
+		err := scanner.Err()
+		if err != nil {
+			yield(users.ImportRow{}, importReadError{err: err})
+		}
+	}
+}
+
+// writeUsers writes the users in the media type, NDJSON or CSV, as they are iterated.
+func writeUsers(
+	w io.Writer,
+	mediaType, host string,
+	fieldNode gofieldselect.Node,
+	all iter.Seq2[users.User, error],
+) error {
+	if mediaType == mediaTypeCSV {
+		cw := csv.NewWriter(w)
+		columns := csvHeader(fieldNode)
+
+		err := cw.Write(columns)
+		if err != nil {
+			return fmt.Errorf("error writing csv: %w", err)
+		}
+
+		for user, errAll := range all {
+			if errAll != nil {
+				return fmt.Errorf("error getting users: %w", errAll)
+			}
+
+			err = cw.Write(csvRecord(columns, user))
+			if err != nil {
+				return fmt.Errorf("error writing csv: %w", err)
+			}
+		}
+
+		cw.Flush()
+
+		err = cw.Error()
+		if err != nil {
+			return fmt.Errorf("error writing csv: %w", err)
+		}
+
+		return nil
+	}
+
+	encoder := json.NewEncoder(w)
+
+	for user, err := range all {
+		if err != nil {
+			return fmt.Errorf("error getting users: %w", err)
+		}
+
+		err = encoder.Encode(transformUserDaoToDto(host, fieldNode, user))
+		if err != nil {
+			return fmt.Errorf("error writing json: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func transformImportResults(host string, results []users.ImportResult) ImportUsersResult {
+	dto := ImportUsersResult{
+		Results: make([]ImportUserResult, 0, len(results)),
+	}
+
+	for _, result := range results {
+		//gosec:disable G115 -- Not expecting to overflow
+		r := ImportUserResult{Line: int32(result.Line)}
+
+		switch {
+		case result.Err == nil:
+			r.User = new(transformUserDaoToDto(host, gofieldselect.AllIdentifiers{}, result.User))
+			dto.Created++
+		case users.IsValidationError(result.Err), users.IsTakenError(result.Err):
+			r.Error = new(result.Err.Error())
+			dto.Failed++
+		default:
+			r.Error = new("error creating user")
+			dto.Failed++
+		}
+
+		dto.Results = append(dto.Results, r)
+	}
+
+	return dto
+}
@@ -0,0 +1,201 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestUsersHandler_ImportUsers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		contentType    string
+		filename       string
+		file           string
+		expectedRows   []users.ImportRow
+		expectedStatus int
+		expectedBody   string
+	}{
+		"csv": {
+			contentType: "text/csv",
+			filename:    "users.csv",
+			file:        "password,username\n12345678,john\n12345678,jo\n12345678\n",
+			expectedRows: []users.ImportRow{
+				{Line: 2, Username: "john", Password: "12345678"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"created":1,"failed":2,"results":[` +
+				`{"line":2,"user":{"kind":"User","self":"//api/v1/users/08ec89b3-288c-4b38-ba25-b91c81004699",` +
+				`"id":"08ec89b3-288c-4b38-ba25-b91c81004699","username":"john",` +
+				`"createdAt":"2025-01-01T00:00:00Z","updatedAt":"2025-01-01T00:00:00Z"}},` +
+				`{"line":3,"error":"username too short"},` +
+				`{"line":4,"error":"invalid row: wrong number of fields"}]}`,
+		},
+		"ndjson by extension": {
+			contentType: "application/octet-stream",
+			filename:    "users.jsonl",
+			file:        "{\"username\":\"john\",\"password\":\"12345678\"}\n\nnot json\n",
+			expectedRows: []users.ImportRow{
+				{Line: 1, Username: "john", Password: "12345678"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"created":1,"failed":1,"results":[` +
+				`{"line":1,"user":{"kind":"User","self":"//api/v1/users/08ec89b3-288c-4b38-ba25-b91c81004699",` +
+				`"id":"08ec89b3-288c-4b38-ba25-b91c81004699","username":"john",` +
+				`"createdAt":"2025-01-01T00:00:00Z","updatedAt":"2025-01-01T00:00:00Z"}},` +
+				`{"line":3,"error":"invalid row: invalid character 'o' in literal null (expecting 'u')"}]}`,
+		},
+		"csv without password column": {
+			contentType:    "text/csv",
+			filename:       "users.csv",
+			file:           "username\njohn\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unsupported file": {
+			contentType:    "application/pdf",
+			filename:       "users.pdf",
+			file:           "%PDF",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{ImportBatchSize: 10}
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+
+			if test.expectedRows != nil {
+				repository.EXPECT().CreateMany(gomock.Any(), test.expectedRows).
+					DoAndReturn(func(_ context.Context, rows []users.ImportRow) ([]users.ImportResult, error) {
+						return []users.ImportResult{{Line: rows[0].Line, User: newTestUser()}}, nil
+					})
+			}
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), embed.FS{}, []byte("openapi"),
+			)
+
+			var body bytes.Buffer
+
+			mw := multipart.NewWriter(&body)
+			part, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Disposition": {`form-data; name="file"; filename="` + test.filename + `"`},
+				"Content-Type":        {test.contentType},
+			})
+			require.NoError(t, err)
+
+			_, err = part.Write([]byte(test.file))
+			require.NoError(t, err)
+			require.NoError(t, mw.Close())
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/users/import", &body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+
+			if test.expectedBody != "" {
+				assert.JSONEq(t, test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUsersHandler_ExportUsers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		accept              string
+		expectedContentType string
+		expectedBody        string
+	}{
+		"ndjson": {
+			expectedContentType: mediaTypeNDJSON,
+			expectedBody: `{"id":"08ec89b3-288c-4b38-ba25-b91c81004699","kind":"User",` +
+				`"self":"//api/v1/users/08ec89b3-288c-4b38-ba25-b91c81004699","username":"john"}` + "\n",
+		},
+		"csv": {
+			accept:              "text/csv",
+			expectedContentType: mediaTypeCSV,
+			expectedBody:        "id,username\n08ec89b3-288c-4b38-ba25-b91c81004699,john\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{}
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().All(gomock.Any()).Return(iter.Seq2[users.User, error](
+				func(yield func(users.User, error) bool) {
+					yield(newTestUser(), nil)
+				},
+			))
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), embed.FS{}, []byte("openapi"),
+			)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(
+				t.Context(), http.MethodGet, "/api/v1/users/export?fields=id,username", http.NoBody,
+			)
+			require.NoError(t, err)
+			req.Header.Set("Accept", test.accept)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func newTestUser() users.User {
+	return users.NewUser(
+		users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699")),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"john",
+		users.Profile{},
+		1,
+	)
+}
@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+)
+
+// anyOrigin allows the cross-origin requests from any origin.
+const anyOrigin = "*"
+
+// cors are the CORS headers of the responses to the allowed origins.
+type cors struct {
+	cfg            config.AppEnv
+	allowedMethods string
+	allowedHeaders string
+	exposedHeaders string
+}
+
+// CORS allows the browsers to call the server from the configured origins (Cross-Origin Resource Sharing),
+// e.g. the Swagger UI served from another host.
+// The preflight requests are answered with 204 No Content, with the CORS headers only if their origin is allowed,
+// and the requests from the origins that are not allowed are served without them, so the browsers block them.
+// Nothing is done if there are no allowed origins.
+func CORS(cfg config.AppEnv) func(http.Handler) http.Handler {
+	c := cors{
+		cfg:            cfg,
+		allowedMethods: strings.Join(cfg.CORSAllowedMethods, ", "),
+		allowedHeaders: strings.Join(cfg.CORSAllowedHeaders, ", "),
+		exposedHeaders: strings.Join(cfg.CORSExposedHeaders, ", "),
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(cfg.CORSAllowedOrigins) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+
+			allowed := c.allowed(r.Header.Get("Origin"))
+			if allowed {
+				c.setAllowOrigin(w.Header(), r.Header.Get("Origin"))
+			}
+
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				if allowed && c.exposedHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", c.exposedHeaders)
+				}
+
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			if allowed {
+				c.setPreflight(w.Header())
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// allowed returns whether the cross-origin requests from the origin are allowed,
+// the requests without origin are not cross-origin.
+func (c cors) allowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	return slices.Contains(c.cfg.CORSAllowedOrigins, anyOrigin) || slices.Contains(c.cfg.CORSAllowedOrigins, origin)
+}
+
+// setAllowOrigin sets the allowed origin: * if any origin is allowed, unless the credentials are,
+// as the browsers reject it then, or the origin otherwise.
+func (c cors) setAllowOrigin(h http.Header, origin string) {
+	if slices.Contains(c.cfg.CORSAllowedOrigins, anyOrigin) && !c.cfg.CORSAllowCredentials {
+		origin = anyOrigin
+	}
+
+	h.Set("Access-Control-Allow-Origin", origin)
+
+	if c.cfg.CORSAllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// setPreflight sets the methods and headers the cross-origin requests can use, and how long they are cached.
+func (c cors) setPreflight(h http.Header) {
+	h.Set("Access-Control-Allow-Methods", c.allowedMethods)
+
+	if c.allowedHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", c.allowedHeaders)
+	}
+
+	if c.cfg.CORSMaxAge > 0 {
+		h.Set("Access-Control-Max-Age", seconds(c.cfg.CORSMaxAge))
+	}
+}
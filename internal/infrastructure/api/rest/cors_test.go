@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+)
+
+func TestCORS(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		allowedOrigins      []string
+		allowCredentials    bool
+		method              string
+		origin              string
+		expected            int
+		expectedAllowOrigin string
+		expectedHeaders     http.Header
+	}{
+		"disabled": {
+			method:   http.MethodGet,
+			origin:   "https://example.com",
+			expected: http.StatusOK,
+		},
+		"allowed origin": {
+			allowedOrigins:      []string{"https://example.com"},
+			method:              http.MethodGet,
+			origin:              "https://example.com",
+			expected:            http.StatusOK,
+			expectedAllowOrigin: "https://example.com",
+			expectedHeaders:     http.Header{"Access-Control-Expose-Headers": {"ETag, Location"}},
+		},
+		"origin not allowed": {
+			allowedOrigins: []string{"https://example.com"},
+			method:         http.MethodGet,
+			origin:         "https://evil.example",
+			expected:       http.StatusOK,
+		},
+		"same origin": {
+			allowedOrigins: []string{"https://example.com"},
+			method:         http.MethodGet,
+			expected:       http.StatusOK,
+		},
+		"any origin": {
+			allowedOrigins:      []string{"*"},
+			method:              http.MethodGet,
+			origin:              "https://example.com",
+			expected:            http.StatusOK,
+			expectedAllowOrigin: "*",
+		},
+		"allowed origin with credentials": {
+			allowedOrigins:      []string{"https://example.com"},
+			allowCredentials:    true,
+			method:              http.MethodGet,
+			origin:              "https://example.com",
+			expected:            http.StatusOK,
+			expectedAllowOrigin: "https://example.com",
+			expectedHeaders:     http.Header{"Access-Control-Allow-Credentials": {"true"}},
+		},
+		"preflight of an allowed origin": {
+			allowedOrigins:      []string{"https://example.com"},
+			method:              http.MethodOptions,
+			origin:              "https://example.com",
+			expected:            http.StatusNoContent,
+			expectedAllowOrigin: "https://example.com",
+			expectedHeaders: http.Header{
+				"Access-Control-Allow-Methods": {"GET, POST"},
+				"Access-Control-Allow-Headers": {"Authorization, Content-Type"},
+				"Access-Control-Max-Age":       {"600"},
+			},
+		},
+		"preflight of an origin not allowed": {
+			allowedOrigins: []string{"https://example.com"},
+			method:         http.MethodOptions,
+			origin:         "https://evil.example",
+			expected:       http.StatusNoContent,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{
+				CORSAllowCredentials: test.allowCredentials,
+				CORSAllowedHeaders:   []string{"Authorization", "Content-Type"},
+				CORSAllowedMethods:   []string{http.MethodGet, http.MethodPost},
+				CORSAllowedOrigins:   test.allowedOrigins,
+				CORSExposedHeaders:   []string{"ETag", "Location"},
+				CORSMaxAge:           10 * time.Minute,
+			}
+			r := chi.NewRouter()
+			r.Use(CORS(cfg))
+			r.Get("/api/v1/users", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequestWithContext(t.Context(), test.method, "/api/v1/users", http.NoBody)
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+
+			if test.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			w := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expected, w.Code)
+			assert.Equal(t, test.expectedAllowOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+
+			for name, values := range test.expectedHeaders {
+				assert.Equal(t, values, w.Header().Values(name), name)
+			}
+
+			if test.expectedAllowOrigin == "" {
+				assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+				assert.Empty(t, w.Header().Get("Access-Control-Expose-Headers"))
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golaxo/gofieldselect"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usersv1 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// Media types of the representations of the users.
+const (
+	mediaTypeJSON     = "application/json"
+	mediaTypeYAML     = "application/yaml"
+	mediaTypeCSV      = "text/csv"
+	mediaTypeProtobuf = "application/x-protobuf"
+	mediaTypeNDJSON   = "application/x-ndjson"
+)
+
+var _ error = new(NotAcceptableError)
+
+type (
+	// NotAcceptableError is returned when none of the media types of the Accept header is supported.
+	NotAcceptableError struct {
+		Accept    string
+		Supported []string
+	}
+
+	mediaTypeKey struct{}
+
+	mediaRange struct {
+		typ     string
+		subtype string
+		q       float64
+	}
+)
+
+// representations are the media types, in order of preference, of the operations with content negotiation.
+//
+//nolint:gochecknoglobals // read-only map
+var representations = map[string][]string{
+	"ExportUsers": {mediaTypeNDJSON, mediaTypeCSV},
+	"GetUser":     {mediaTypeJSON, mediaTypeYAML, mediaTypeCSV, mediaTypeProtobuf},
+	"GetUsers":    {mediaTypeJSON, mediaTypeYAML, mediaTypeCSV, mediaTypeProtobuf},
+}
+
+// csvColumns are the fields of the users written in CSV, in order.
+//
+//nolint:gochecknoglobals // read-only list
+var csvColumns = []string{
+	"id", "createdAt", "updatedAt", "username", "email", "displayName", "locale", "avatarUrl", "deletedAt",
+	"emailVerifiedAt",
+}
+
+func (e NotAcceptableError) Error() string {
+	return fmt.Sprintf(
+		"none of the media types %q is supported, expected one of %s", e.Accept, strings.Join(e.Supported, ", "),
+	)
+}
+
+func (e NotAcceptableError) ErrorResponse(requestID string) ErrorResponse {
+	return ErrorResponse{
+		Type:      "NotAcceptable",
+		Title:     "Not Acceptable",
+		Detail:    e.Error(),
+		Status:    http.StatusNotAcceptable,
+		RequestId: requestID,
+	}
+}
+
+// negotiateContent chooses, for the operations with several representations, the media type of the response
+// from the Accept header, failing with NotAcceptableError if none is supported.
+func negotiateContent(f StrictHandlerFunc, operationID string) StrictHandlerFunc {
+	offers, ok := representations[operationID]
+	if !ok {
+		return f
+	}
+
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		w.Header().Add("Vary", "Accept")
+
+		accept := r.Header.Get("Accept")
+
+		mediaType, acceptable := negotiate(accept, offers)
+		if !acceptable {
+			return nil, NotAcceptableError{Accept: accept, Supported: offers}
+		}
+
+		return f(context.WithValue(ctx, mediaTypeKey{}, mediaType), w, r, request)
+	}
+}
+
+// mediaTypeFromContext returns the negotiated media type of the response, JSON by default.
+func mediaTypeFromContext(ctx context.Context) string {
+	mediaType, ok := ctx.Value(mediaTypeKey{}).(string)
+	if !ok {
+		return mediaTypeJSON
+	}
+
+	return mediaType
+}
+
+// negotiate returns the offer with the highest quality in the Accept header, the first offer winning the ties.
+// An empty Accept header accepts any media type.
+func negotiate(accept string, offers []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+
+	for _, offer := range offers {
+		if q := quality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best, bestQ > 0
+}
+
+// parseAccept parses the media ranges of the Accept header, e.g. "text/csv, application/*;q=0.5".
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+		if !ok {
+			continue
+		}
+
+		mr := mediaRange{typ: typ, subtype: subtype, q: 1}
+
+		for param := range strings.SplitSeq(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				q, err := strconv.ParseFloat(value, 64)
+				if err == nil {
+					mr.q = q
+				}
+			}
+		}
+
+		ranges = append(ranges, mr)
+	}
+
+	return ranges
+}
+
+// quality returns the quality of the offer given by the most specific media range matching it.
+func quality(ranges []mediaRange, offer string) float64 {
+	typ, subtype, _ := strings.Cut(offer, "/")
+	q, specificity := 0.0, -1
+
+	for _, mr := range ranges {
+		var s int
+
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 2
+		case mr.typ == typ && mr.subtype == "*":
+			s = 1
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			q, specificity = mr.q, s
+		}
+	}
+
+	return q
+}
+
+// getUserResponse returns the response with the user in the negotiated media type.
+func getUserResponse(
+	mediaType string, fieldNode gofieldselect.Node, dto User, user users.User, headers GetUser200ResponseHeaders,
+) (GetUserResponseObject, error) {
+	if mediaType == mediaTypeJSON {
+		return GetUser200JSONResponse{Body: dto, Headers: headers}, nil
+	}
+
+	body, err := encodeUser(mediaType, fieldNode, dto, user)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding user: %w", err)
+	}
+
+	switch mediaType {
+	case mediaTypeYAML:
+		return GetUser200ApplicationyamlResponse{
+			Body: bytes.NewReader(body), Headers: headers, ContentLength: int64(len(body)),
+		}, nil
+	case mediaTypeCSV:
+		return GetUser200TextcsvResponse{
+			Body: bytes.NewReader(body), Headers: headers, ContentLength: int64(len(body)),
+		}, nil
+	default:
+		return GetUser200ApplicationxProtobufResponse{
+			Body: bytes.NewReader(body), Headers: headers, ContentLength: int64(len(body)),
+		}, nil
+	}
+}
+
+// getUsersResponse returns the response with the page of users in the negotiated media type.
+func getUsersResponse(
+	mediaType string, fieldNode gofieldselect.Node, dto PageUsers, us []users.User, headers GetUsers200ResponseHeaders,
+) (GetUsersResponseObject, error) {
+	if mediaType == mediaTypeJSON {
+		return GetUsers200JSONResponse{Body: dto, Headers: headers}, nil
+	}
+
+	body, err := encodeUsers(mediaType, fieldNode, dto, us)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding users: %w", err)
+	}
+
+	switch mediaType {
+	case mediaTypeYAML:
+		return GetUsers200ApplicationyamlResponse{
+			Body: bytes.NewReader(body), Headers: headers, ContentLength: int64(len(body)),
+		}, nil
+	case mediaTypeCSV:
+		return GetUsers200TextcsvResponse{
+			Body: bytes.NewReader(body), Headers: headers, ContentLength: int64(len(body)),
+		}, nil
+	default:
+		return GetUsers200ApplicationxProtobufResponse{
+			Body: bytes.NewReader(body), Headers: headers, ContentLength: int64(len(body)),
+		}, nil
+	}
+}
+
+// encodeUser encodes the user in the media type, other than JSON, with the selected fields.
+func encodeUser(mediaType string, fieldNode gofieldselect.Node, dto User, user users.User) ([]byte, error) {
+	switch mediaType {
+	case mediaTypeYAML:
+		return encodeYAML(dto)
+	case mediaTypeCSV:
+		return encodeCSV(fieldNode, []users.User{user})
+	case mediaTypeProtobuf:
+		return encodeProtobuf(fieldNode, user)
+	default:
+		return nil, NotAcceptableError{Accept: mediaType, Supported: representations["GetUser"]}
+	}
+}
+
+// encodeUsers encodes the page of users in the media type, other than JSON, with the selected fields.
+// The page metadata is only kept in YAML, CSV and protobuf contain just the users.
+func encodeUsers(mediaType string, fieldNode gofieldselect.Node, dto PageUsers, us []users.User) ([]byte, error) {
+	switch mediaType {
+	case mediaTypeYAML:
+		return encodeYAML(dto)
+	case mediaTypeCSV:
+		return encodeCSV(fieldNode, us)
+	case mediaTypeProtobuf:
+		return encodeDelimitedProtobuf(fieldNode, us)
+	default:
+		return nil, NotAcceptableError{Accept: mediaType, Supported: representations["GetUsers"]}
+	}
+}
+
+// encodeYAML encodes the value as YAML, with the same field names and omitted fields as in JSON.
+func encodeYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling to json: %w", err)
+	}
+
+	var generic any
+
+	err = json.Unmarshal(data, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling json: %w", err)
+	}
+
+	data, err = yaml.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling to yaml: %w", err)
+	}
+
+	return data, nil
+}
+
+// encodeCSV encodes the users as CSV, with a header row with the selected fields.
+func encodeCSV(fieldNode gofieldselect.Node, us []users.User) ([]byte, error) {
+	columns := csvHeader(fieldNode)
+
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	_ = w.Write(columns)
+
+	for _, user := range us {
+		_ = w.Write(csvRecord(columns, user))
+	}
+
+	w.Flush()
+
+	err := w.Error()
+	if err != nil {
+		return nil, fmt.Errorf("error writing csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// csvHeader returns the CSV columns of the selected fields.
+func csvHeader(fieldNode gofieldselect.Node) []string {
+	columns := make([]string, 0, len(csvColumns))
+	for _, column := range csvColumns {
+		if _, ok := fieldNode.SelectField(column); ok {
+			columns = append(columns, column)
+		}
+	}
+
+	return columns
+}
+
+// csvRecord returns the values of the user in the CSV columns.
+func csvRecord(columns []string, user users.User) []string {
+	profile := user.Profile()
+	values := map[string]string{
+		"id":              user.ID().String(),
+		"createdAt":       user.CreatedAt().Format(time.RFC3339Nano),
+		"updatedAt":       user.UpdatedAt().Format(time.RFC3339Nano),
+		"username":        csvSafe(string(user.Username())),
+		"email":           csvSafe(string(profile.Email)),
+		"displayName":     csvSafe(string(profile.DisplayName)),
+		"locale":          csvSafe(string(profile.Locale)),
+		"avatarUrl":       csvSafe(string(profile.AvatarURL)),
+		"deletedAt":       csvTime(user.DeletedAt()),
+		"emailVerifiedAt": csvTime(user.EmailVerifiedAt()),
+	}
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = values[column]
+	}
+
+	return record
+}
+
+// csvTime returns the time as written in CSV, empty if it is not set.
+func csvTime(at time.Time, ok bool) string {
+	if !ok {
+		return ""
+	}
+
+	return at.Format(time.RFC3339Nano)
+}
+
+// csvSafe escapes the values that spreadsheets would interpret as formulas (CSV injection).
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+
+	return value
+}
+
+// encodeProtobuf encodes the user as a users.v1.User message, with the selected fields.
+func encodeProtobuf(fieldNode gofieldselect.Node, user users.User) ([]byte, error) {
+	data, err := proto.Marshal(transformUserToProto(fieldNode, user))
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling to protobuf: %w", err)
+	}
+
+	return data, nil
+}
+
+// encodeDelimitedProtobuf encodes the users as length-delimited users.v1.User messages, with the selected fields.
+func encodeDelimitedProtobuf(fieldNode gofieldselect.Node, us []users.User) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, user := range us {
+		_, err := protodelim.MarshalTo(&buf, transformUserToProto(fieldNode, user))
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling to protobuf: %w", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func transformUserToProto(fieldNode gofieldselect.Node, user users.User) *usersv1.User {
+	profile := user.Profile()
+
+	return &usersv1.User{
+		Id:          gofieldselect.Get(fieldNode, "id", user.ID().String()),
+		CreatedAt:   gofieldselect.Get(fieldNode, "createdAt", timestamppb.New(user.CreatedAt())),
+		UpdatedAt:   gofieldselect.Get(fieldNode, "updatedAt", timestamppb.New(user.UpdatedAt())),
+		Username:    gofieldselect.Get(fieldNode, "username", string(user.Username())),
+		Email:       gofieldselect.Get(fieldNode, "email", string(profile.Email)),
+		DisplayName: gofieldselect.Get(fieldNode, "displayName", string(profile.DisplayName)),
+		Locale:      gofieldselect.Get(fieldNode, "locale", string(profile.Locale)),
+		AvatarUrl:   gofieldselect.Get(fieldNode, "avatarUrl", string(profile.AvatarURL)),
+		DeletedAt:   gofieldselect.Get(fieldNode, "deletedAt", optionalTimestamp(user.DeletedAt())),
+		EmailVerifiedAt: gofieldselect.Get(
+			fieldNode, "emailVerifiedAt", optionalTimestamp(user.EmailVerifiedAt()),
+		),
+	}
+}
+
+// optionalTimestamp returns the timestamp of the time, nil if it is not set.
+func optionalTimestamp(at time.Time, ok bool) *timestamppb.Timestamp {
+	if !ok {
+		return nil
+	}
+
+	return timestamppb.New(at)
+}
@@ -0,0 +1,277 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golaxo/gofieldselect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	usersv1 "github.com/manuelarte/go-web-layout/internal/infrastructure/api/grpc/users/v1"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	offers := []string{mediaTypeJSON, mediaTypeYAML, mediaTypeCSV, mediaTypeProtobuf}
+
+	tests := map[string]struct {
+		accept     string
+		expected   string
+		expectedOK bool
+	}{
+		"no accept": {
+			expected:   mediaTypeJSON,
+			expectedOK: true,
+		},
+		"any": {
+			accept:     "*/*",
+			expected:   mediaTypeJSON,
+			expectedOK: true,
+		},
+		"exact": {
+			accept:     "text/csv",
+			expected:   mediaTypeCSV,
+			expectedOK: true,
+		},
+		"highest quality": {
+			accept:     "application/yaml;q=0.5, application/x-protobuf",
+			expected:   mediaTypeProtobuf,
+			expectedOK: true,
+		},
+		"most specific range": {
+			accept:     "application/*;q=0.8, application/json;q=0.1",
+			expected:   mediaTypeYAML,
+			expectedOK: true,
+		},
+		"excluded": {
+			accept:     "text/csv;q=0",
+			expectedOK: false,
+		},
+		"not supported": {
+			accept:     "application/xml",
+			expectedOK: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual, ok := negotiate(test.accept, offers)
+
+			// Assert
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestUsersHandler_GetUser_Representations(t *testing.T) {
+	t.Parallel()
+
+	user := users.NewUser(
+		users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699")),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		"john",
+		users.Profile{DisplayName: "John Doe", Locale: "en-US"},
+		1,
+	)
+
+	tests := map[string]struct {
+		accept              string
+		fields              string
+		expectedStatus      int
+		expectedContentType string
+		assertBody          func(t *testing.T, body []byte)
+	}{
+		"yaml": {
+			accept:              "application/yaml",
+			fields:              "id,username",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: mediaTypeYAML,
+			assertBody: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				assert.Contains(t, string(body), "username: john\n")
+				assert.NotContains(t, string(body), "createdAt")
+			},
+		},
+		"csv": {
+			accept:              "text/csv",
+			fields:              "id,username",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: mediaTypeCSV,
+			assertBody: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				assert.Equal(t, "id,username\n08ec89b3-288c-4b38-ba25-b91c81004699,john\n", string(body))
+			},
+		},
+		"csv with profile": {
+			accept:              "text/csv",
+			fields:              "username,displayName,locale,email",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: mediaTypeCSV,
+			assertBody: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				assert.Equal(t, "username,email,displayName,locale\njohn,,John Doe,en-US\n", string(body))
+			},
+		},
+		"protobuf": {
+			accept:              "application/x-protobuf",
+			fields:              "username",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: mediaTypeProtobuf,
+			assertBody: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				var actual usersv1.User
+				require.NoError(t, proto.Unmarshal(body, &actual))
+				assert.True(t, proto.Equal(&usersv1.User{Username: "john"}, &actual))
+			},
+		},
+		"not acceptable": {
+			accept:              "application/xml",
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/problem+json",
+			assertBody:          func(*testing.T, []byte) {},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{}
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil).AnyTimes()
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			w := httptest.NewRecorder()
+			url := "/api/v1/users/" + user.ID().String()
+
+			if test.fields != "" {
+				url += "?fields=" + test.fields
+			}
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+			require.NoError(t, err)
+			req.Header.Set("Accept", test.accept)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			test.assertBody(t, w.Body.Bytes())
+		})
+	}
+}
+
+func TestEncodeUsers_OptionalTimes(t *testing.T) {
+	t.Parallel()
+
+	deletedAt := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	verifiedAt := time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		deleted  bool
+		verified bool
+	}{
+		"live user":                {},
+		"deleted user":             {deleted: true},
+		"user with verified email": {verified: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			user := users.NewUser(
+				users.UserID(uuid.New()),
+				time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+				"john",
+				users.Profile{Email: "john@example.com"},
+				1,
+			)
+			if test.deleted {
+				user = user.WithDeletedAt(deletedAt)
+			}
+
+			if test.verified {
+				user = user.WithEmailVerifiedAt(verifiedAt)
+			}
+
+			// Act
+			csvBody, errCSV := encodeCSV(gofieldselect.AllIdentifiers{}, []users.User{user})
+			protoBody, errProto := encodeProtobuf(gofieldselect.AllIdentifiers{}, user)
+
+			// Assert
+			require.NoError(t, errCSV)
+
+			records, err := csv.NewReader(bytes.NewReader(csvBody)).ReadAll()
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+
+			row := make(map[string]string, len(records[0]))
+			for i, column := range records[0] {
+				row[column] = records[1][i]
+			}
+
+			require.NoError(t, errProto)
+
+			var actual usersv1.User
+			require.NoError(t, proto.Unmarshal(protoBody, &actual))
+
+			assertOptionalTime(t, test.deleted, deletedAt, row["deletedAt"], actual.GetDeletedAt())
+			assertOptionalTime(t, test.verified, verifiedAt, row["emailVerifiedAt"], actual.GetEmailVerifiedAt())
+		})
+	}
+}
+
+// assertOptionalTime asserts that the CSV value and the protobuf timestamp are the expected time if it is set,
+// and empty if it is not.
+func assertOptionalTime(t *testing.T, set bool, expected time.Time, csvValue string, timestamp *timestamppb.Timestamp) {
+	t.Helper()
+
+	if !set {
+		assert.Empty(t, csvValue)
+		assert.Nil(t, timestamp)
+
+		return
+	}
+
+	actual, err := time.Parse(time.RFC3339Nano, csvValue)
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(actual))
+	assert.True(t, expected.Equal(timestamp.AsTime()))
+}
@@ -26,14 +26,14 @@ func (v ValidationError) Error() string {
 }
 
 func (v ValidationError) ErrorResponse(requestID string) ErrorResponse {
-	errors := make([]Error, len(v.errors))
+	errors := make([]Error, 0, len(v.errors))
 
 	for key, errs := range v.errors {
-		for i, err := range errs {
-			errors[i] = Error{
+		for _, err := range errs {
+			errors = append(errors, Error{
 				Detail:  err.Error(),
 				Pointer: key,
-			}
+			})
 		}
 	}
 
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golaxo/gofieldselect"
+
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// etagLength is the number of bytes of the hash used as ETag.
+const etagLength = 16
+
+// allFields is the normalized field selection of every field.
+const allFields = "*"
+
+// userETag returns the strong ETag of the user, that changes every time the user is modified, e.g. updated,
+// deleted or restored, as its version does.
+// It is the ETag of its JSON representation with every field, the one the If-Match headers are compared with.
+func userETag(user users.User) string {
+	return etag(
+		user.ID().String(),
+		strconv.FormatInt(user.Version(), 10),
+		user.UpdatedAt().UTC().Format(time.RFC3339Nano),
+	)
+}
+
+// pageETag returns the strong ETag of the page of users, that changes if any of its users, or the total, changes.
+func pageETag(page pagination.Page[users.User]) string {
+	parts := make([]string, 0, 1+len(page.Content()))
+	parts = append(parts, strconv.FormatInt(page.TotalElements(), 10))
+
+	for _, user := range page.Content() {
+		parts = append(parts, userETag(user))
+	}
+
+	return etag(parts...)
+}
+
+// representationETag returns the strong ETag of a representation of the resource with the ETag, as each media type
+// and field selection has different bytes, so they can't share a strong ETag.
+// The JSON representation with every field keeps the ETag of the resource.
+func representationETag(resourceETag, mediaType string, fieldNode gofieldselect.Node) string {
+	selection := fieldSelection(fieldNode)
+	if mediaType == mediaTypeJSON && selection == allFields {
+		return resourceETag
+	}
+
+	return etag(resourceETag, mediaType, selection)
+}
+
+// fieldSelection returns the field selection normalized, with the fields sorted and without duplicates,
+// so the same selection written differently, e.g. "username,id" and "id,username", has the same ETag.
+func fieldSelection(fieldNode gofieldselect.Node) string {
+	identifiers, ok := fieldNode.(gofieldselect.Identifiers)
+	if !ok {
+		return allFields
+	}
+
+	fields := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		field := identifier.Value
+		if child := fieldSelection(identifier.Child); child != allFields {
+			field += "(" + child + ")"
+		}
+
+		fields = append(fields, field)
+	}
+
+	slices.Sort(fields)
+
+	return strings.Join(slices.Compact(fields), ",")
+}
+
+func etag(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:etagLength]) + `"`
+}
+
+// noneMatch returns whether the If-None-Match header does not match the ETag, so the resource has to be sent.
+// The comparison is weak, as defined in RFC 9110.
+func noneMatch(ifNoneMatch *string, etag string) bool {
+	if ifNoneMatch == nil {
+		return true
+	}
+
+	for candidate := range strings.SplitSeq(*ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match returns whether the If-Match header matches the ETag, so the resource can be modified.
+// The comparison is strong, as defined in RFC 9110.
+func match(ifMatch, etag string) bool {
+	for candidate := range strings.SplitSeq(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
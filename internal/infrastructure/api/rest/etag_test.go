@@ -0,0 +1,166 @@
+package rest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golaxo/gofieldselect"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestUserETag_DeleteAndRestore(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	id := users.UserID(uuid.New())
+	createdAt := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	deletedAt := createdAt.Add(time.Minute)
+
+	// The users as returned by the repository: deleting and restoring bump the version and the update time.
+	created := users.NewUser(id, createdAt, createdAt, "john", users.Profile{}, 1)
+	deleted := users.NewUser(id, createdAt, deletedAt, "john", users.Profile{}, 2)
+	deleted = deleted.WithDeletedAt(deletedAt)
+	restored := users.NewUser(id, createdAt, deletedAt.Add(time.Minute), "john", users.Profile{}, 3)
+	// Restored within the same clock tick as deleted, only the version differs.
+	restoredSameTime := users.NewUser(id, createdAt, deletedAt, "john", users.Profile{}, 3)
+
+	page := func(user users.User) pagination.Page[users.User] {
+		return pagination.MustPage([]users.User{user}, pagination.MustPageRequest(0, 20), 1)
+	}
+
+	// Act
+	etags := []string{userETag(created), userETag(deleted), userETag(restored), userETag(restoredSameTime)}
+	pageETags := []string{pageETag(page(created)), pageETag(page(deleted)), pageETag(page(restored))}
+
+	// Assert
+	assert.Len(t, lo.Uniq(etags), len(etags))
+	assert.Len(t, lo.Uniq(pageETags), len(pageETags))
+}
+
+func TestRepresentationETag(t *testing.T) {
+	t.Parallel()
+
+	resourceETag := etag("user", "1")
+
+	tests := map[string]struct {
+		mediaType    string
+		fields       string
+		expectedSame bool
+	}{
+		"json with every field keeps the etag of the resource": {
+			mediaType:    mediaTypeJSON,
+			expectedSame: true,
+		},
+		"yaml with every field": {
+			mediaType: mediaTypeYAML,
+		},
+		"csv with every field": {
+			mediaType: mediaTypeCSV,
+		},
+		"protobuf with every field": {
+			mediaType: mediaTypeProtobuf,
+		},
+		"json with some fields": {
+			mediaType: mediaTypeJSON,
+			fields:    "id,username",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			fieldNode, err := gofieldselect.Parse(test.fields)
+			require.NoError(t, err)
+
+			// Act
+			actual := representationETag(resourceETag, test.mediaType, fieldNode)
+
+			// Assert
+			assert.Equal(t, test.expectedSame, actual == resourceETag)
+		})
+	}
+}
+
+func TestRepresentationETag_Distinct(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	resourceETag := etag("user", "1")
+	representations := map[string]struct {
+		mediaType string
+		fields    string
+	}{
+		"json":                {mediaType: mediaTypeJSON},
+		"json with username":  {mediaType: mediaTypeJSON, fields: "username"},
+		"json with id":        {mediaType: mediaTypeJSON, fields: "id"},
+		"yaml":                {mediaType: mediaTypeYAML},
+		"yaml with username":  {mediaType: mediaTypeYAML, fields: "username"},
+		"csv":                 {mediaType: mediaTypeCSV},
+		"csv with username":   {mediaType: mediaTypeCSV, fields: "username"},
+		"protobuf":            {mediaType: mediaTypeProtobuf},
+		"protobuf with links": {mediaType: mediaTypeProtobuf, fields: "_links(self)"},
+	}
+
+	// Act
+	etags := make(map[string]string, len(representations))
+
+	for name, representation := range representations {
+		fieldNode, err := gofieldselect.Parse(representation.fields)
+		require.NoError(t, err)
+
+		actual := representationETag(resourceETag, representation.mediaType, fieldNode)
+
+		// Assert
+		other, ok := etags[actual]
+		assert.Falsef(t, ok, "%s has the same ETag as %s", name, other)
+
+		etags[actual] = name
+	}
+}
+
+func TestFieldSelection(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		fields   string
+		expected string
+	}{
+		"every field": {
+			expected: allFields,
+		},
+		"sorted": {
+			fields:   "username,id",
+			expected: "id,username",
+		},
+		"without duplicates": {
+			fields:   "id,username,id",
+			expected: "id,username",
+		},
+		"nested": {
+			fields:   "username,_links(self,collection)",
+			expected: "_links(collection,self),username",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			fieldNode, err := gofieldselect.Parse(test.fields)
+			require.NoError(t, err)
+
+			// Act
+			actual := fieldSelection(fieldNode)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/golaxo/gofieldselect"
+	"github.com/google/uuid"
+
+	"github.com/manuelarte/go-web-layout/internal/events"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// heartbeatInterval is how often a comment is sent in the event stream, so the idle connections are kept alive.
+const heartbeatInterval = 15 * time.Second
+
+var _ GetUserEventsResponseObject = new(userEventsResponse)
+
+// userEventsResponse streams the user events as Server-Sent Events, flushing each of them as it is received.
+// The stream ends when the channel of messages is closed, because the client went away or did not keep up.
+type userEventsResponse struct {
+	host     string
+	messages <-chan events.Message
+}
+
+func (response userEventsResponse) VisitGetUserEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disables the buffering of the proxies, as nginx, that would delay the events.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+
+	err := rc.Flush()
+	if err != nil {
+		return streamError{err: fmt.Errorf("error flushing the event stream: %w", err)}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case message, ok := <-response.messages:
+			if !ok {
+				return nil
+			}
+
+			err = writeUserEvent(w, response.host, message)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+
+		if err == nil {
+			err = rc.Flush()
+		}
+
+		if err != nil {
+			// The client went away, there is no one to tell.
+			if errors.Is(err, http.ErrNotSupported) {
+				return streamError{err: err}
+			}
+
+			return nil
+		}
+	}
+}
+
+// writeUserEvent writes the message as an event, with its resume token as id, so the EventSource sends it back
+// in the Last-Event-ID header when reconnecting.
+func writeUserEvent(w io.Writer, host string, message events.Message) error {
+	data, err := json.Marshal(transformEventToDto(host, message.Event))
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", message.ResumeToken, message.Event.Type, data)
+	if err != nil {
+		return fmt.Errorf("error writing event: %w", err)
+	}
+
+	return nil
+}
+
+func transformEventToDto(host string, event users.Event) UserEvent {
+	dto := UserEvent{
+		Type:       UserEventType(event.Type),
+		UserId:     uuid.UUID(event.UserID),
+		OccurredAt: event.OccurredAt,
+	}
+
+	if event.Type != users.EventDeleted {
+		dto.User = new(transformUserDaoToDto(host, gofieldselect.AllIdentifiers{}, event.User))
+	}
+
+	return dto
+}
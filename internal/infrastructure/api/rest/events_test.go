@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"context"
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/events"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestUsersHandler_GetUserEvents(t *testing.T) {
+	t.Parallel()
+
+	deletedAt := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	replayed := func(second events.Message) string {
+		return "id: " + second.ResumeToken + "\nevent: user.deleted\n" +
+			`data: {"occurredAt":"2025-01-02T00:00:00Z","type":"user.deleted",` +
+			`"userId":"08ec89b3-288c-4b38-ba25-b91c81004699"}` + "\n\n"
+	}
+
+	tests := map[string]struct {
+		query          func(first events.Message) string
+		lastEventID    func(first events.Message) string
+		expectedStatus int
+		expectedBody   func(second events.Message) string
+	}{
+		"missed events are replayed after the resume token": {
+			query: func(first events.Message) string {
+				return "?resumeToken=" + first.ResumeToken
+			},
+			lastEventID: func(events.Message) string {
+				return ""
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   replayed,
+		},
+		"last event id takes precedence over the resume token": {
+			query: func(events.Message) string {
+				return "?resumeToken=invalid"
+			},
+			lastEventID: func(first events.Message) string {
+				return first.ResumeToken
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   replayed,
+		},
+		"invalid resume token": {
+			query: func(events.Message) string {
+				return "?resumeToken=invalid"
+			},
+			lastEventID: func(events.Message) string {
+				return ""
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"resume token from before a restart": {
+			query: func(events.Message) string {
+				return ""
+			},
+			lastEventID: func(events.Message) string {
+				return "restarted.1"
+			},
+			expectedStatus: http.StatusGone,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{EventsHistorySize: 10}
+			r := chi.NewRouter()
+			api := newAPI(t, cfg, users.NewMockRepository(gomock.NewController(t)))
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, api, services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), embed.FS{}, []byte("openapi"),
+			)
+
+			messages, err := api.bus.Subscribe(t.Context(), "")
+			require.NoError(t, err)
+
+			user := newTestUser()
+			require.NoError(t, api.bus.Publish(t.Context(), users.NewCreatedEvent(user)))
+			require.NoError(t, api.bus.Publish(t.Context(), users.NewDeletedEvent(user.ID(), deletedAt)))
+
+			first, second := <-messages, <-messages
+
+			// The request is cancelled beforehand, so the stream ends once the missed events are replayed.
+			ctx, cancel := context.WithCancel(t.Context())
+			cancel()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/users/events"+test.query(first), http.NoBody)
+			require.NoError(t, err)
+
+			if lastEventID := test.lastEventID(first); lastEventID != "" {
+				req.Header.Set("Last-Event-ID", lastEventID)
+			}
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, test.expectedStatus, w.Code)
+
+			if test.expectedBody == nil {
+				return
+			}
+
+			assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedBody(second), w.Body.String())
+		})
+	}
+}
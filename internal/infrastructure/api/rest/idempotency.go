@@ -0,0 +1,240 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+const (
+	// idempotencyKeyHeader is the header with the idempotency key of the request.
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader is set to true in the responses replayed from a previous request.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// replayedHeaders are the headers stored with the response, to be replayed with it.
+//
+//nolint:gochecknoglobals // read-only list
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// idempotentRoutes are the routes of the operations declaring the IdempotencyKey parameter, createUser and
+// importUsers. The key is ignored in the others, so their responses, e.g. the secrets of the API keys or the tokens
+// of the logins, are never stored nor replayed.
+//
+//nolint:gochecknoglobals // read-only list
+var idempotentRoutes = []string{"POST /api/v1/users", "POST /api/v1/users/import"}
+
+// storedResponse is the response stored with the idempotency key.
+type storedResponse struct {
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// idempotent makes the requests to the idempotent routes sent with an Idempotency-Key header safe to retry:
+// the response of the first request is stored for the ttl and replayed to the retries.
+// Reusing the key with a different request responds 422 Unprocessable Content,
+// and retrying while the first request is in progress responds 409 Conflict.
+// The server errors are not stored, so the request can be retried.
+func idempotent(store idempotency.Store, ttl time.Duration) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			route := r.Method + " " + chi.RouteContext(ctx).RoutePattern()
+
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" || !slices.Contains(idempotentRoutes, route) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			logger := logging.FromContext(ctx)
+
+			body, err := io.ReadAll(r.Body)
+			if maxBytesErr, ok := errors.AsType[*http.MaxBytesError](err); ok {
+				writeContentTooLarge(w, r, maxBytesErr.Limit)
+
+				return
+			}
+
+			if err != nil {
+				writeProblem(w, r, http.StatusBadRequest, "InvalidBody", "Invalid Body", "Error reading the request body")
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			principal, _ := auth.FromContext(ctx)
+
+			record, reserved, err := store.Reserve(ctx, idempotency.Record{
+				// The same key can be used by the principal in each tenant.
+				Scope:       idempotency.Scope(route, principal.ID, tenant.FromContext(ctx).String()),
+				Key:         key,
+				Fingerprint: idempotency.Fingerprint([]byte(route), body),
+				ExpiresAt:   time.Now().Add(ttl),
+			})
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to reserve idempotency key", slog.Any("err", err))
+				logging.AddError(ctx, "db", err)
+				writeProblem(w, r, http.StatusInternalServerError,
+					"DatabaseError", "Internal Server Error", "Error reserving idempotency key")
+
+				return
+			}
+
+			if !reserved {
+				replay(w, r, record, idempotency.Fingerprint([]byte(route), body))
+
+				return
+			}
+
+			// The key is released if the handler panics, so the request can be retried instead of being in progress
+			// until it expires.
+			defer func() {
+				if p := recover(); p != nil {
+					release(ctx, store, record)
+					panic(p)
+				}
+			}()
+
+			var buf bytes.Buffer
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			next.ServeHTTP(ww, r)
+
+			complete(r, store, record, ww, buf.Bytes())
+		})
+	}
+}
+
+// replay writes the stored response of the record, if the request is the same and it is completed.
+func replay(w http.ResponseWriter, r *http.Request, record idempotency.Record, fingerprint string) {
+	err := record.Replayable(fingerprint)
+
+	switch {
+	case errors.Is(err, idempotency.ErrFingerprintMismatch):
+		writeProblem(w, r, http.StatusUnprocessableEntity, "IdempotencyKeyMismatch", "Idempotency Key Mismatch",
+			"The Idempotency-Key was already used with a different request")
+
+		return
+	case errors.Is(err, idempotency.ErrInProgress):
+		writeProblem(w, r, http.StatusConflict, "IdempotencyKeyInProgress", "Idempotency Key In Progress",
+			"A request with the same Idempotency-Key is in progress, retry later")
+
+		return
+	}
+
+	var stored storedResponse
+
+	err = json.Unmarshal(record.Response, &stored)
+	if err != nil {
+		logging.FromContext(r.Context()).ErrorContext(
+			r.Context(),
+			"Failed to unmarshal stored response",
+			slog.Any("err", err),
+		)
+		writeProblem(w, r, http.StatusInternalServerError,
+			"InternalServerError", "Internal Server Error", "Error replaying the stored response")
+
+		return
+	}
+
+	maps.Copy(w.Header(), stored.Header)
+
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(stored.Body) // #nosec G705
+}
+
+// complete stores the response of the reserved request, or releases the key if it failed with a server error.
+func complete(r *http.Request, store idempotency.Store, record idempotency.Record, ww middleware.WrapResponseWriter,
+	body []byte,
+) {
+	// The response is already sent, so the key is completed even if the request is cancelled.
+	ctx := context.WithoutCancel(r.Context())
+	logger := logging.FromContext(ctx)
+
+	statusCode := ww.Status()
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	if statusCode >= http.StatusInternalServerError {
+		release(ctx, store, record)
+
+		return
+	}
+
+	stored := storedResponse{Header: make(http.Header), Body: body}
+	for _, name := range replayedHeaders {
+		if value := ww.Header().Get(name); value != "" {
+			stored.Header.Set(name, value)
+		}
+	}
+
+	response, err := json.Marshal(stored)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to marshal response", slog.Any("err", err))
+
+		return
+	}
+
+	err = store.Complete(ctx, record.Scope, record.Key, statusCode, response)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to complete idempotency key", slog.Any("err", err))
+	}
+}
+
+// release releases the key of the reserved request, so it can be retried.
+func release(ctx context.Context, store idempotency.Store, record idempotency.Record) {
+	ctx = context.WithoutCancel(ctx)
+
+	err := store.Release(ctx, record.Scope, record.Key)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to release idempotency key", slog.Any("err", err))
+	}
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int32, typ, title, detail string) {
+	resp := ErrorResponse{
+		Type:      typ,
+		Title:     title,
+		Detail:    detail,
+		Status:    status,
+		RequestId: middleware.GetReqID(r.Context()),
+	}
+
+	bytes, errMarshal := json.Marshal(resp)
+	if errMarshal != nil {
+		logging.FromContext(r.Context()).ErrorContext(
+			r.Context(),
+			"Failed to marshal error response",
+			slog.Any("err", errMarshal),
+		)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(int(status))
+	_, _ = w.Write(bytes) // #nosec G705
+}
@@ -0,0 +1,329 @@
+package rest
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+	"github.com/manuelarte/go-web-layout/internal/webhooks"
+)
+
+func TestIdempotent(t *testing.T) {
+	t.Parallel()
+
+	userID := users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699"))
+	body := `{"username":"john","password":"password"}`
+
+	tests := map[string]struct {
+		idempotencyKey   string
+		expectedMockCall func(ms *idempotency.MockStore, mr *users.MockRepository)
+		expectedStatus   int
+		expectedReplayed bool
+	}{
+		"without idempotency key": {
+			expectedMockCall: func(_ *idempotency.MockStore, mr *users.MockRepository) {
+				mr.EXPECT().Create(gomock.Any(), users.Username("john"), users.Password("password"), users.Profile{}).
+					Return(users.NewUser(userID, time.Now(), time.Now(), "john", users.Profile{}, 1), nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		"first request is completed": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore, mr *users.MockRepository) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						return record, true, nil
+					})
+				mr.EXPECT().Create(gomock.Any(), users.Username("john"), users.Password("password"), users.Profile{}).
+					Return(users.NewUser(userID, time.Now(), time.Now(), "john", users.Profile{}, 1), nil)
+				ms.EXPECT().Complete(gomock.Any(), gomock.Any(), "key", http.StatusCreated, gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		"first request failing is released": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore, mr *users.MockRepository) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						return record, true, nil
+					})
+				mr.EXPECT().Create(gomock.Any(), users.Username("john"), users.Password("password"), users.Profile{}).
+					Return(users.User{}, errors.New("db error"))
+				ms.EXPECT().Release(gomock.Any(), gomock.Any(), "key").Return(nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		"retry is replayed": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore, _ *users.MockRepository) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						record.Completed = true
+						record.StatusCode = http.StatusCreated
+						record.Response = []byte(`{"header":{"Content-Type":["application/json"]},"body":"e30="}`)
+
+						return record, false, nil
+					})
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedReplayed: true,
+		},
+		"retry while in progress": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore, _ *users.MockRepository) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						return record, false, nil
+					})
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		"key reused with a different request": {
+			idempotencyKey: "key",
+			expectedMockCall: func(ms *idempotency.MockStore, _ *users.MockRepository) {
+				ms.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+						record.Fingerprint = "different"
+						record.Completed = true
+
+						return record, false, nil
+					})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(ctrl)
+			store := idempotency.NewMockStore(ctrl)
+			cfg := config.AppEnv{IdempotencyKeyTTL: time.Hour}
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), services.APIKeys{}, oidc.Verifier{},
+				limiter, store, embed.FS{}, []byte("openapi"),
+			)
+			test.expectedMockCall(store, repository)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/users", strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			if test.idempotencyKey != "" {
+				req.Header.Set(idempotencyKeyHeader, test.idempotencyKey)
+			}
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedReplayed, w.Header().Get(idempotentReplayedHeader) == "true")
+		})
+	}
+}
+
+func TestIdempotent_HandlerPanics(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	store := idempotency.NewMockStore(gomock.NewController(t))
+	store.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+			return record, true, nil
+		})
+	store.EXPECT().Release(gomock.Any(), "POST /api/v1/users principal:anonymous tenant:default", "key").Return(nil)
+
+	r := chi.NewRouter()
+	r.With(idempotent(store, time.Hour)).Post("/api/v1/users", func(http.ResponseWriter, *http.Request) {
+		panic("handler panic")
+	})
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/users", strings.NewReader("{}"))
+	req.Header.Set(idempotencyKeyHeader, "key")
+
+	// Act & Assert
+	assert.PanicsWithValue(t, "handler panic", func() {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
+
+func TestIdempotent_ScopeIgnoresClientAddress(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	var scopes []string
+
+	store := idempotency.NewMockStore(gomock.NewController(t))
+	store.EXPECT().Reserve(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+			scopes = append(scopes, record.Scope)
+
+			return record, false, nil
+		}).
+		Times(2)
+
+	r := chi.NewRouter()
+	r.With(idempotent(store, time.Hour)).Post("/api/v1/users", func(http.ResponseWriter, *http.Request) {})
+
+	// Act
+	for _, remoteAddr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/users", strings.NewReader("{}"))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(idempotencyKeyHeader, "key")
+
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Assert
+	require.Len(t, scopes, 2)
+	assert.Equal(t, scopes[0], scopes[1])
+}
+
+func TestIdempotent_NotDeclaredOperations(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		route string
+	}{
+		"login":          {route: "/api/v1/login"},
+		"password reset": {route: "/api/v1/password-reset"},
+		"api keys":       {route: "/api/v1/api-keys"},
+		"webhooks":       {route: "/api/v1/webhooks"},
+		"webhook test":   {route: "/api/v1/webhooks/{webhookId}/test"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			// The store has no expectations, so the test fails if the key is reserved or the response stored.
+			store := idempotency.NewMockStore(gomock.NewController(t))
+
+			var calls int
+
+			r := chi.NewRouter()
+			r.With(idempotent(store, time.Hour)).Post(test.route, func(w http.ResponseWriter, _ *http.Request) {
+				calls++
+
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			// Act
+			for range 2 {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, test.route, strings.NewReader("{}"))
+				req.Header.Set(idempotencyKeyHeader, "key")
+
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusCreated, w.Code)
+				assert.Empty(t, w.Header().Get(idempotentReplayedHeader))
+			}
+
+			// Assert
+			assert.Equal(t, 2, calls)
+		})
+	}
+}
+
+func TestCreateRestAPI_IdempotencyKeyWithSecrets(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		url          string
+		body         string
+		secretPrefix string
+	}{
+		"api key": {
+			url:          "/api/v1/api-keys",
+			body:         `{"name":"ci","roles":["reader"]}`,
+			secretPrefix: `"gwl_`,
+		},
+		"webhook": {
+			url:          "/api/v1/webhooks",
+			body:         `{"url":"https://example.com/hook","events":["user.created"]}`,
+			secretPrefix: `"whsec_`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			cfg := config.AppEnv{IdempotencyKeyTTL: time.Hour}
+			r := chi.NewRouter()
+
+			keyRepository := apikeys.NewMockRepository(ctrl)
+			keyRepository.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, key apikeys.APIKey) (apikeys.APIKey, error) {
+					return key, nil
+				}).AnyTimes()
+
+			webhookRepository := webhooks.NewMockRepository(ctrl)
+			webhookRepository.EXPECT().Create(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, s webhooks.Subscription) (webhooks.Subscription, error) {
+					return s, nil
+				}).AnyTimes()
+
+			dispatcher := webhooks.NewDispatcher(
+				webhookRepository, http.DefaultClient, slog.New(slog.DiscardHandler), time.Second,
+			)
+			api := API{
+				APIKeysHandler:  NewAPIKeysHandler(cfg, keyRepository, services.NewAPIKeys(keyRepository)),
+				WebhooksHandler: NewWebhooksHandler(cfg, webhookRepository, dispatcher),
+			}
+			apiKeys, headers := newAPIKeysWithRole(t, auth.RoleAdmin, "admin")
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			// The store has no expectations, so the test fails if a response with a secret is stored.
+			CreateRestAPI(
+				r, cfg, api, apiKeys, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(ctrl), embed.FS{}, []byte("openapi"),
+			)
+
+			for range 2 {
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, test.url, strings.NewReader(test.body))
+				req.Header = headers["admin"].Clone()
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set(idempotencyKeyHeader, "key")
+
+				w := httptest.NewRecorder()
+
+				// Act
+				r.ServeHTTP(w, req)
+
+				// Assert
+				assert.Equal(t, http.StatusCreated, w.Code)
+				assert.Contains(t, w.Body.String(), test.secretPrefix)
+				assert.Empty(t, w.Header().Get(idempotentReplayedHeader))
+			}
+		})
+	}
+}
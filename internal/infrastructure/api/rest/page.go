@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/manuelarte/ptrutils"
+
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+)
+
+// newPageRequest returns the page request of the page and size query parameters,
+// or an InvalidParamFormatError if they are out of range.
+func newPageRequest(page, size *int32) (pagination.PageRequest, error) {
+	p := ptrutils.DerefOr(page, 0)
+	if p < 0 || p > 1000 {
+		return pagination.PageRequest{}, &InvalidParamFormatError{
+			ParamName: "page",
+			Err:       errors.New("page must be between 0 and 1000"),
+		}
+	}
+
+	s := ptrutils.DerefOr(size, 20)
+	if s < 1 || s > 50 {
+		return pagination.PageRequest{}, &InvalidParamFormatError{
+			ParamName: "size",
+			Err:       errors.New("size must be between 1 and 50"),
+		}
+	}
+
+	pr, err := pagination.NewPageRequest(int(p), int(s))
+	if err != nil {
+		if errors.Is(err, pagination.ErrPageMustBeGreaterOrEqualThanZero) {
+			return pagination.PageRequest{}, &InvalidParamFormatError{
+				ParamName: "page",
+				Err:       err,
+			}
+		}
+
+		if errors.Is(err, pagination.ErrSizeMustBeGreaterOrEqualThanZero) {
+			return pagination.PageRequest{}, &InvalidParamFormatError{
+				ParamName: "size",
+				Err:       err,
+			}
+		}
+
+		return pagination.PageRequest{}, fmt.Errorf("error creating page request: %w", err)
+	}
+
+	return pr, nil
+}
+
+// transformPage returns the page info of the page, with the links to the other pages built with urlBuilder.
+func transformPage[T any](p pagination.Page[T], urlBuilder func(page, size int32) string) Page {
+	//gosec:disable G115 -- Not expecting to overflow
+	page, size, totalPages := int32(p.Number()), int32(p.Size()), int32(p.TotalPages())
+
+	prev := new(urlBuilder(page-1, size))
+
+	next := new(urlBuilder(page+1, size))
+	if page == 0 {
+		prev = nil
+	}
+
+	if page == totalPages-1 {
+		next = nil
+	}
+
+	return Page{
+		Number:        page,
+		Size:          size,
+		TotalElements: p.TotalElements(),
+		TotalPages:    totalPages,
+		Self:          urlBuilder(page, size),
+		Prev:          prev,
+		Next:          next,
+		First:         urlBuilder(0, size),
+		Last:          urlBuilder(totalPages-1, size),
+	}
+}
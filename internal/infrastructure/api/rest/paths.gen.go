@@ -21,12 +21,47 @@ func (p ActuatorsInfoEndpoint) Path() string {
 	return message
 }
 
+type GetAPIKeysEndpoint struct{}
+
+type GetAPIKeysEndpointQueryParams struct {
+	Page string
+	Size string
+}
+
+func (q GetAPIKeysEndpointQueryParams) ToQueryString() string {
+	values := url.Values{}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values.Encode()
+}
+
+func (p GetAPIKeysEndpoint) Path(queryParams GetAPIKeysEndpointQueryParams) string {
+	message := "/api/v1/api-keys"
+	if queryString := queryParams.ToQueryString(); queryString != "" {
+		message = message + "?" + queryString
+	}
+	return message
+}
+
+type GetUsernameAvailabilityEndpoint struct{}
+
+func (p GetUsernameAvailabilityEndpoint) Path(name string) string {
+	message := "/api/v1/usernames/{name}/availability"
+	message = strings.Replace(message, "{name}", name, -1)
+	return message
+}
+
 type GetUsersEndpoint struct{}
 
 type GetUsersEndpointQueryParams struct {
-	Page   string
-	Size   string
-	Fields []string
+	Page           string
+	Size           string
+	Fields         []string
+	IncludeDeleted string
 }
 
 func (q GetUsersEndpointQueryParams) ToQueryString() string {
@@ -40,6 +75,9 @@ func (q GetUsersEndpointQueryParams) ToQueryString() string {
 	if len(q.Fields) > 0 {
 		values.Set("fields", strings.Join(q.Fields, ","))
 	}
+	if q.IncludeDeleted != "" {
+		values.Set("includeDeleted", q.IncludeDeleted)
+	}
 	return values.Encode()
 }
 
@@ -51,6 +89,50 @@ func (p GetUsersEndpoint) Path(queryParams GetUsersEndpointQueryParams) string {
 	return message
 }
 
+type GetUserEventsEndpoint struct{}
+
+type GetUserEventsEndpointQueryParams struct {
+	ResumeToken string
+}
+
+func (q GetUserEventsEndpointQueryParams) ToQueryString() string {
+	values := url.Values{}
+	if q.ResumeToken != "" {
+		values.Set("resumeToken", q.ResumeToken)
+	}
+	return values.Encode()
+}
+
+func (p GetUserEventsEndpoint) Path(queryParams GetUserEventsEndpointQueryParams) string {
+	message := "/api/v1/users/events"
+	if queryString := queryParams.ToQueryString(); queryString != "" {
+		message = message + "?" + queryString
+	}
+	return message
+}
+
+type ExportUsersEndpoint struct{}
+
+type ExportUsersEndpointQueryParams struct {
+	Fields []string
+}
+
+func (q ExportUsersEndpointQueryParams) ToQueryString() string {
+	values := url.Values{}
+	if len(q.Fields) > 0 {
+		values.Set("fields", strings.Join(q.Fields, ","))
+	}
+	return values.Encode()
+}
+
+func (p ExportUsersEndpoint) Path(queryParams ExportUsersEndpointQueryParams) string {
+	message := "/api/v1/users/export"
+	if queryString := queryParams.ToQueryString(); queryString != "" {
+		message = message + "?" + queryString
+	}
+	return message
+}
+
 type GetUserEndpoint struct{}
 
 type GetUserEndpointQueryParams struct {
@@ -74,9 +156,114 @@ func (p GetUserEndpoint) Path(userId string, queryParams GetUserEndpointQueryPar
 	return message
 }
 
+type GetUserAuditEndpoint struct{}
+
+type GetUserAuditEndpointQueryParams struct {
+	Page string
+	Size string
+}
+
+func (q GetUserAuditEndpointQueryParams) ToQueryString() string {
+	values := url.Values{}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values.Encode()
+}
+
+func (p GetUserAuditEndpoint) Path(userId string, queryParams GetUserAuditEndpointQueryParams) string {
+	message := "/api/v1/users/{userId}/audit"
+	message = strings.Replace(message, "{userId}", userId, -1)
+	if queryString := queryParams.ToQueryString(); queryString != "" {
+		message = message + "?" + queryString
+	}
+	return message
+}
+
+type GetUserLockoutEndpoint struct{}
+
+func (p GetUserLockoutEndpoint) Path(userId string) string {
+	message := "/api/v1/users/{userId}/lockout"
+	message = strings.Replace(message, "{userId}", userId, -1)
+	return message
+}
+
+type GetWebhooksEndpoint struct{}
+
+type GetWebhooksEndpointQueryParams struct {
+	Page string
+	Size string
+}
+
+func (q GetWebhooksEndpointQueryParams) ToQueryString() string {
+	values := url.Values{}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values.Encode()
+}
+
+func (p GetWebhooksEndpoint) Path(queryParams GetWebhooksEndpointQueryParams) string {
+	message := "/api/v1/webhooks"
+	if queryString := queryParams.ToQueryString(); queryString != "" {
+		message = message + "?" + queryString
+	}
+	return message
+}
+
+type GetWebhookEndpoint struct{}
+
+func (p GetWebhookEndpoint) Path(webhookId string) string {
+	message := "/api/v1/webhooks/{webhookId}"
+	message = strings.Replace(message, "{webhookId}", webhookId, -1)
+	return message
+}
+
+type GetWebhookDeliveriesEndpoint struct{}
+
+type GetWebhookDeliveriesEndpointQueryParams struct {
+	Page string
+	Size string
+}
+
+func (q GetWebhookDeliveriesEndpointQueryParams) ToQueryString() string {
+	values := url.Values{}
+	if q.Page != "" {
+		values.Set("page", q.Page)
+	}
+	if q.Size != "" {
+		values.Set("size", q.Size)
+	}
+	return values.Encode()
+}
+
+func (p GetWebhookDeliveriesEndpoint) Path(webhookId string, queryParams GetWebhookDeliveriesEndpointQueryParams) string {
+	message := "/api/v1/webhooks/{webhookId}/deliveries"
+	message = strings.Replace(message, "{webhookId}", webhookId, -1)
+	if queryString := queryParams.ToQueryString(); queryString != "" {
+		message = message + "?" + queryString
+	}
+	return message
+}
+
 type Paths struct {
-	ActuatorsHealthEndpoint ActuatorsHealthEndpoint
-	ActuatorsInfoEndpoint   ActuatorsInfoEndpoint
-	GetUserEndpoint         GetUserEndpoint
-	GetUsersEndpoint        GetUsersEndpoint
+	ActuatorsHealthEndpoint         ActuatorsHealthEndpoint
+	ActuatorsInfoEndpoint           ActuatorsInfoEndpoint
+	ExportUsersEndpoint             ExportUsersEndpoint
+	GetAPIKeysEndpoint              GetAPIKeysEndpoint
+	GetUserAuditEndpoint            GetUserAuditEndpoint
+	GetUserEndpoint                 GetUserEndpoint
+	GetUserEventsEndpoint           GetUserEventsEndpoint
+	GetUserLockoutEndpoint          GetUserLockoutEndpoint
+	GetUsernameAvailabilityEndpoint GetUsernameAvailabilityEndpoint
+	GetUsersEndpoint                GetUsersEndpoint
+	GetWebhookDeliveriesEndpoint    GetWebhookDeliveriesEndpoint
+	GetWebhookEndpoint              GetWebhookEndpoint
+	GetWebhooksEndpoint             GetWebhooksEndpoint
 }
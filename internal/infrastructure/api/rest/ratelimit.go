@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+)
+
+// rateLimit limits the requests of each client to every route (e.g. "GET /api/v1/users"),
+// responding 429 Too Many Requests once the quota is exhausted.
+// The requests are allowed if the limiter fails, so its store is not a single point of failure.
+func rateLimit(limiter ratelimit.Limiter) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			route := r.Method + " " + chi.RouteContext(ctx).RoutePattern()
+
+			result, err := limiter.Allow(ctx, route, clientKey(r))
+			if err != nil {
+				logging.FromContext(ctx).WarnContext(ctx, "Failed to rate limit request", slog.Any("err", err))
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			setRateLimitHeaders(w.Header(), result)
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", seconds(result.RetryAfter))
+				writeTooManyRequests(w, r, result)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey identifies the client by its authenticated principal, or by its IP if it is anonymous.
+// The credentials are only trusted once verified, so a client can't get a new bucket by sending made-up ones.
+func clientKey(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return ratelimit.KeyForPrincipal(principal.ID)
+	}
+
+	ip := middleware.GetClientIP(r.Context())
+	if ip == "" {
+		ip = r.RemoteAddr
+	}
+
+	return ratelimit.KeyForIP(ip)
+}
+
+// setRateLimitHeaders sets the RateLimit headers of the IETF draft "RateLimit header fields for HTTP".
+func setRateLimitHeaders(h http.Header, result ratelimit.Result) {
+	if result.Limit.IsUnlimited() {
+		return
+	}
+
+	h.Set("Ratelimit-Limit", strconv.Itoa(result.Limit.Requests))
+	h.Set("Ratelimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("Ratelimit-Reset", seconds(result.Reset))
+	h.Set("Ratelimit-Policy", fmt.Sprintf("%d;w=%s", result.Limit.Requests, seconds(result.Limit.Period)))
+}
+
+func writeTooManyRequests(w http.ResponseWriter, r *http.Request, result ratelimit.Result) {
+	resp := ErrorResponse{
+		Type:  "TooManyRequests",
+		Title: "Too Many Requests",
+		Detail: fmt.Sprintf(
+			"Rate limit of %d requests per %s exceeded, retry after %s seconds",
+			result.Limit.Requests, result.Limit.Period, seconds(result.RetryAfter),
+		),
+		Status:    http.StatusTooManyRequests,
+		RequestId: middleware.GetReqID(r.Context()),
+	}
+
+	bytes, errMarshal := json.Marshal(resp)
+	if errMarshal != nil {
+		logging.FromContext(r.Context()).ErrorContext(
+			r.Context(),
+			"Failed to marshal error response",
+			slog.Any("err", errMarshal),
+		)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_, _ = w.Write(bytes) // #nosec G705
+}
+
+// seconds returns the duration in seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
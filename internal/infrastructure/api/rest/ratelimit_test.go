@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		firstKey  string
+		secondKey string
+		expected  int
+	}{
+		"same ip is limited": {
+			expected: http.StatusTooManyRequests,
+		},
+		"same api key is limited": {
+			firstKey:  "abc",
+			secondKey: "abc",
+			expected:  http.StatusTooManyRequests,
+		},
+		"different api keys are not limited": {
+			firstKey:  "abc",
+			secondKey: "def",
+			expected:  http.StatusOK,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+				Return(users.NewUser(users.UserID(uuid.New()), time.Now(), time.Now(), "John", users.Profile{}, 1), nil).
+				AnyTimes()
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, map[string]ratelimit.Limit{
+				"GET /api/v1/users/{userId}": {Requests: 1, Period: time.Minute},
+			})
+			apiKeys, headers := newAPIKeys(t, test.firstKey, test.secondKey)
+			CreateRestAPI(
+				r, config.AppEnv{}, newAPI(t, config.AppEnv{}, repository), apiKeys, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			url := fmt.Sprintf("/api/v1/users/%s", uuid.NewString())
+			first := httptest.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+			first.Header = headers[test.firstKey].Clone()
+			second := httptest.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+			second.Header = headers[test.secondKey].Clone()
+
+			// Act
+			r.ServeHTTP(httptest.NewRecorder(), first)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, second)
+
+			// Assert
+			require.Equal(t, test.expected, w.Code)
+			assert.Equal(t, "1", w.Header().Get("Ratelimit-Limit"))
+			assert.Equal(t, "0", w.Header().Get("Ratelimit-Remaining"))
+
+			if test.expected != http.StatusTooManyRequests {
+				return
+			}
+
+			assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+			var actual ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+			assert.Equal(t, "TooManyRequests", actual.Type)
+			assert.Equal(t, int32(http.StatusTooManyRequests), actual.Status)
+		})
+	}
+}
+
+// newAPIKeys returns the service authenticating a reader API key for each of the names,
+// with the headers to authenticate with them by name.
+func newAPIKeys(t *testing.T, names ...string) (services.APIKeys, map[string]http.Header) {
+	t.Helper()
+
+	return newAPIKeysWithRole(t, auth.RoleReader, names...)
+}
+
+// newAPIKeysWithRole returns the service authenticating an API key with the role for each of the names,
+// with the headers to authenticate with them by name.
+func newAPIKeysWithRole(t *testing.T, role auth.Role, names ...string) (services.APIKeys, map[string]http.Header) {
+	t.Helper()
+
+	keys := make(map[string]apikeys.APIKey)
+	headers := make(map[string]http.Header)
+
+	for _, name := range names {
+		if name == "" || headers[name] != nil {
+			continue
+		}
+
+		key, secret, err := apikeys.New(name, []auth.Role{role})
+		require.NoError(t, err)
+
+		keys[key.Prefix] = key
+		headers[name] = http.Header{"Authorization": []string{apikeys.Scheme + string(secret)}}
+	}
+
+	repository := apikeys.NewMockRepository(gomock.NewController(t))
+	repository.EXPECT().GetByPrefix(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, prefix string) (apikeys.APIKey, error) {
+			key, ok := keys[prefix]
+			if !ok {
+				return apikeys.APIKey{}, apikeys.ErrInvalidKey
+			}
+
+			return key, nil
+		}).
+		AnyTimes()
+	repository.EXPECT().MarkUsed(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return services.NewAPIKeys(repository), headers
+}
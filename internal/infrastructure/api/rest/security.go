@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"maps"
+	"net/http"
+	"strconv"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+)
+
+const (
+	// contentSecurityPolicyHeader is the header with the Content-Security-Policy of the responses.
+	contentSecurityPolicyHeader = "Content-Security-Policy"
+	// swaggerContentSecurityPolicy overrides the Content-Security-Policy of the Swagger UI, that needs its own
+	// scripts and styles, the inline styles, and the inline images of its icons.
+	swaggerContentSecurityPolicy = "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; " +
+		"frame-ancestors 'none'"
+)
+
+// SecurityHeaders sets the security headers of the responses: Strict-Transport-Security (HSTS),
+// Content-Security-Policy and X-Content-Type-Options, the ones that are not configured are not set.
+// The routes can override them with their own middleware, e.g. the Swagger UI needs a less strict policy.
+func SecurityHeaders(cfg config.AppEnv) func(http.Handler) http.Handler {
+	headers := http.Header{}
+	headers.Set("X-Content-Type-Options", "nosniff")
+
+	if cfg.HSTSMaxAge > 0 {
+		headers.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(int(cfg.HSTSMaxAge.Seconds())))
+	}
+
+	if cfg.ContentSecurityPolicy != "" {
+		headers.Set(contentSecurityPolicyHeader, cfg.ContentSecurityPolicy)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			maps.Copy(w.Header(), headers)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		hstsMaxAge      time.Duration
+		path            string
+		expectedHSTS    string
+		expectedContent string
+	}{
+		"api": {
+			hstsMaxAge:      time.Hour,
+			path:            "/api/docs",
+			expectedHSTS:    "max-age=3600",
+			expectedContent: "default-src 'none'",
+		},
+		"swagger ui overrides the content security policy": {
+			hstsMaxAge:      time.Hour,
+			path:            "/swagger/swagger-initializer.js",
+			expectedHSTS:    "max-age=3600",
+			expectedContent: swaggerContentSecurityPolicy,
+		},
+		"hsts disabled": {
+			path:            "/api/docs",
+			expectedContent: "default-src 'none'",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{
+				ContentSecurityPolicy: "default-src 'none'",
+				HSTSMaxAge:            test.hstsMaxAge,
+			}
+			r := chi.NewRouter()
+			r.Use(SecurityHeaders(cfg))
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, users.NewMockRepository(gomock.NewController(t))),
+				services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, test.path, http.NoBody)
+			w := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, test.expectedHSTS, w.Header().Get("Strict-Transport-Security"))
+			assert.Equal(t, test.expectedContent, w.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+// tenantHeader is the header of the tenant of the requests, e.g. "X-Tenant-ID: acme", in its canonical form.
+const tenantHeader = "X-Tenant-Id"
+
+// resolveTenant sets the tenant of the requests: the one of the X-Tenant-ID header, or the one their principal
+// is bound to, or the default tenant.
+// The invalid tenants are rejected with 400 Bad Request, and the tenants other than the one of the principal
+// with 403 Forbidden.
+// It needs to be placed after the authenticate middleware.
+func resolveTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := auth.FromContext(r.Context())
+
+		id, err := tenant.Resolve(r.Header.Get(tenantHeader), principal.Tenant)
+		if err != nil {
+			if errors.Is(err, tenant.ErrForbidden) {
+				writeProblem(w, r, http.StatusForbidden, "Forbidden", "Forbidden", err.Error())
+			} else {
+				writeProblem(w, r, http.StatusBadRequest, "InvalidTenant", "Invalid Tenant", err.Error())
+			}
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), id)))
+	})
+}
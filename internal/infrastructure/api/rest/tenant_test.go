@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestResolveTenant(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		authorization string
+		tenantHeader  string
+		expected      int
+		expectedType  string
+		wantTenant    tenant.ID
+	}{
+		"default tenant": {
+			expected:   http.StatusOK,
+			wantTenant: tenant.Default,
+		},
+		"tenant of the header": {
+			tenantHeader: "globex",
+			expected:     http.StatusOK,
+			wantTenant:   "globex",
+		},
+		"invalid tenant rejected": {
+			tenantHeader: "Globex Corp",
+			expected:     http.StatusBadRequest,
+			expectedType: "InvalidTenant",
+		},
+		"tenant of the bearer token": {
+			authorization: "reader token",
+			expected:      http.StatusOK,
+			wantTenant:    "acme",
+		},
+		"tenant of the bearer token in the header": {
+			authorization: "reader token",
+			tenantHeader:  "acme",
+			expected:      http.StatusOK,
+			wantTenant:    "acme",
+		},
+		"other tenant than the one of the bearer token forbidden": {
+			authorization: "reader token",
+			tenantHeader:  "globex",
+			expected:      http.StatusForbidden,
+			expectedType:  "Forbidden",
+		},
+		"any tenant with an api key": {
+			authorization: "reader",
+			tenantHeader:  "globex",
+			expected:      http.StatusOK,
+			wantTenant:    "globex",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			r := chi.NewRouter()
+			cfg := config.AppEnv{}
+
+			var actualTenant tenant.ID
+
+			repository := users.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().GetByID(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, id users.UserID) (users.User, error) {
+					actualTenant = tenant.FromContext(ctx)
+
+					return users.NewUser(id, time.Now(), time.Now(), "John", users.Profile{}, 1), nil
+				}).
+				MaxTimes(1)
+
+			apiKeys, headers := newAPIKeys(t, "reader")
+			tokens, tokenHeaders := newBearerTokens(t, "reader")
+			maps.Copy(headers, tokenHeaders)
+
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), apiKeys, tokens,
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/users/"+uuid.NewString(), http.NoBody)
+			if header, ok := headers[test.authorization]; ok {
+				req.Header = header.Clone()
+			}
+
+			if test.tenantHeader != "" {
+				req.Header.Set(tenantHeader, test.tenantHeader)
+			}
+
+			w := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, test.expected, w.Code)
+			assert.Equal(t, test.wantTenant, actualTenant)
+
+			if test.expectedType == "" {
+				return
+			}
+
+			var actual ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+			assert.Equal(t, test.expectedType, actual.Type)
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/golaxo/gofieldselect"
@@ -17,22 +19,328 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	oteltrace "go.opentelemetry.io/otel/trace"
 
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/auth"
 	"github.com/manuelarte/go-web-layout/internal/config"
 	"github.com/manuelarte/go-web-layout/internal/config/logging"
 	"github.com/manuelarte/go-web-layout/internal/config/observability"
-	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/events"
+	"github.com/manuelarte/go-web-layout/internal/services"
 	"github.com/manuelarte/go-web-layout/internal/users"
 )
 
 type UsersHandler struct {
-	cfg        config.AppEnv
-	repository users.Repository
+	cfg                config.AppEnv
+	repository         users.Repository
+	auditRepository    audit.Repository
+	createUserService  services.CreateUser
+	updateUserService  services.UpdateUser
+	deleteUserService  services.DeleteUser
+	importUsersService services.ImportUsers
+	bus                *events.Bus
 }
 
-func NewUsersHandler(cfg config.AppEnv, repository users.Repository) UsersHandler {
+func NewUsersHandler(
+	cfg config.AppEnv,
+	repository users.Repository,
+	auditRepository audit.Repository,
+	createUserService services.CreateUser,
+	updateUserService services.UpdateUser,
+	deleteUserService services.DeleteUser,
+	importUsersService services.ImportUsers,
+	bus *events.Bus,
+) UsersHandler {
 	return UsersHandler{
-		cfg:        cfg,
-		repository: repository,
+		cfg:                cfg,
+		repository:         repository,
+		auditRepository:    auditRepository,
+		createUserService:  createUserService,
+		updateUserService:  updateUserService,
+		deleteUserService:  deleteUserService,
+		importUsersService: importUsersService,
+		bus:                bus,
+	}
+}
+
+func (h UsersHandler) CreateUser(
+	ctx context.Context,
+	request CreateUserRequestObject,
+) (CreateUserResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "UsersHandler.CreateUser")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	logging.AddAttrs(ctx, slog.Any("username", request.Body.Username))
+
+	validationErrors := make(map[string][]error)
+	if err := request.Body.Username.IsValid(); err != nil {
+		validationErrors["/username"] = append(validationErrors["/username"], err)
+	}
+
+	if err := request.Body.Password.IsValid(); err != nil {
+		validationErrors["/password"] = append(validationErrors["/password"], err)
+	}
+
+	profile := transformCreateUserProfile(*request.Body)
+	validateProfile(validationErrors, profile)
+
+	if len(validationErrors) > 0 {
+		h.createUserService.ValidationFailed(ctx)
+
+		return nil, ValidationError{errors: validationErrors}
+	}
+
+	user, err := h.createUserService.CreateUser(ctx, request.Body.Username, request.Body.Password, profile)
+	if err != nil {
+		problem := modificationProblem(ctx, "Error creating user", err)
+		if problem.Status == http.StatusInternalServerError {
+			return CreateUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return CreateUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("userId", user.ID().String()))
+
+	dto := transformUserDaoToDto(host, gofieldselect.AllIdentifiers{}, user)
+
+	return CreateUser201JSONResponse{
+		Body:    dto,
+		Headers: CreateUser201ResponseHeaders{ETag: new(userETag(user)), Location: new(dto.Self)},
+	}, nil
+}
+
+func (h UsersHandler) UpdateUser(
+	ctx context.Context,
+	request UpdateUserRequestObject,
+) (UpdateUserResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"UsersHandler.UpdateUser",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	update := users.Update{
+		Username:    request.Body.Username,
+		Password:    request.Body.Password,
+		Email:       request.Body.Email,
+		DisplayName: request.Body.DisplayName,
+		Locale:      request.Body.Locale,
+		AvatarURL:   request.Body.AvatarUrl,
+	}
+
+	validationErrors := make(map[string][]error)
+
+	if update.Username != nil {
+		if err := update.Username.IsValid(); err != nil {
+			validationErrors["/username"] = append(validationErrors["/username"], err)
+		}
+	}
+
+	if update.Password != nil {
+		if err := update.Password.IsValid(); err != nil {
+			validationErrors["/password"] = append(validationErrors["/password"], err)
+		}
+	}
+
+	// The profile fields sent empty clear them, so they are validated as not set.
+	validateProfile(validationErrors, users.Profile{
+		Email:       lo.FromPtr(update.Email),
+		DisplayName: lo.FromPtr(update.DisplayName),
+		Locale:      lo.FromPtr(update.Locale),
+		AvatarURL:   lo.FromPtr(update.AvatarURL),
+	})
+
+	if len(validationErrors) > 0 {
+		return nil, ValidationError{errors: validationErrors}
+	}
+
+	current, problem := h.precondition(ctx, users.UserID(request.UserId), request.Params.IfMatch)
+	if problem != nil {
+		if problem.Status == http.StatusInternalServerError {
+			return UpdateUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return UpdateUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	user, err := h.updateUserService.UpdateUser(ctx, current.ID(), current.Version(), update)
+	if err != nil {
+		problem = modificationProblem(ctx, "Error updating user", err)
+		if problem.Status == http.StatusInternalServerError {
+			return UpdateUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return UpdateUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return UpdateUser200JSONResponse{
+		Body:    transformUserDaoToDto(host, gofieldselect.AllIdentifiers{}, user),
+		Headers: UpdateUser200ResponseHeaders{ETag: new(userETag(user))},
+	}, nil
+}
+
+func (h UsersHandler) DeleteUser(
+	ctx context.Context,
+	request DeleteUserRequestObject,
+) (DeleteUserResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"UsersHandler.DeleteUser",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	current, problem := h.precondition(ctx, users.UserID(request.UserId), request.Params.IfMatch)
+	if problem != nil {
+		if problem.Status == http.StatusInternalServerError {
+			return DeleteUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return DeleteUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	err := h.deleteUserService.DeleteUser(ctx, current.ID(), current.Version())
+	if err != nil {
+		problem = modificationProblem(ctx, "Error deleting user", err)
+		if problem.Status == http.StatusInternalServerError {
+			return DeleteUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return DeleteUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return DeleteUser204Response{}, nil
+}
+
+func (h UsersHandler) RestoreUser(
+	ctx context.Context,
+	request RestoreUserRequestObject,
+) (RestoreUserResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"UsersHandler.RestoreUser",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	user, err := h.repository.Restore(ctx, users.UserID(request.UserId))
+	if err != nil {
+		problem := modificationProblem(ctx, "Error restoring user", err)
+		if problem.Status == http.StatusInternalServerError {
+			return RestoreUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return RestoreUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return RestoreUser200JSONResponse{
+		Body:    transformUserDaoToDto(host, gofieldselect.AllIdentifiers{}, user),
+		Headers: RestoreUser200ResponseHeaders{ETag: new(userETag(user))},
+	}, nil
+}
+
+func (h UsersHandler) PurgeUser(
+	ctx context.Context,
+	request PurgeUserRequestObject,
+) (PurgeUserResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"UsersHandler.PurgeUser",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	err := h.repository.Purge(ctx, users.UserID(request.UserId))
+	if err != nil {
+		problem := modificationProblem(ctx, "Error purging user", err)
+		if problem.Status == http.StatusInternalServerError {
+			return PurgeUser500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return PurgeUser4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return PurgeUser204Response{}, nil
+}
+
+// modificationProblem returns the problem to respond when a user could not be read, created or modified.
+func modificationProblem(ctx context.Context, msg string, err error) *ErrorResponse {
+	requestID := middleware.GetReqID(ctx)
+
+	if notFoundError, ok := errors.AsType[users.NotFoundError](err); ok {
+		return &ErrorResponse{
+			Type:      "NotFound",
+			Title:     "User not found",
+			Detail:    notFoundError.Error(),
+			Status:    http.StatusNotFound,
+			RequestId: requestID,
+		}
+	}
+
+	if _, ok := errors.AsType[users.VersionMismatchError](err); ok {
+		return &ErrorResponse{
+			Type:      "PreconditionFailed",
+			Title:     "Precondition Failed",
+			Detail:    "The user was modified, get it again to have its current ETag",
+			Status:    http.StatusPreconditionFailed,
+			RequestId: requestID,
+		}
+	}
+
+	if usernameTakenError, ok := errors.AsType[users.UsernameTakenError](err); ok {
+		return &ErrorResponse{
+			Type:      "UsernameTaken",
+			Title:     "Username taken",
+			Detail:    usernameTakenError.Error(),
+			Status:    http.StatusConflict,
+			RequestId: requestID,
+		}
+	}
+
+	if emailTakenError, ok := errors.AsType[users.EmailTakenError](err); ok {
+		return &ErrorResponse{
+			Type:      "EmailTaken",
+			Title:     "Email taken",
+			Detail:    emailTakenError.Error(),
+			Status:    http.StatusConflict,
+			RequestId: requestID,
+		}
+	}
+
+	if notDeletedError, ok := errors.AsType[users.NotDeletedError](err); ok {
+		return &ErrorResponse{
+			Type:      "NotDeleted",
+			Title:     "User not deleted",
+			Detail:    notDeletedError.Error(),
+			Status:    http.StatusConflict,
+			RequestId: requestID,
+		}
+	}
+
+	logging.FromContext(ctx).ErrorContext(ctx, msg, slog.Any("err", err))
+	logging.AddError(ctx, "db", err)
+
+	return &ErrorResponse{
+		Type:      "DatabaseError",
+		Title:     "Internal Server Error",
+		Detail:    msg,
+		Status:    http.StatusInternalServerError,
+		RequestId: requestID,
 	}
 }
 
@@ -47,6 +355,7 @@ func (h UsersHandler) GetUser(ctx context.Context, request GetUserRequestObject)
 	host, _ := ctx.Value("host").(string)
 
 	logger := logging.FromContext(ctx)
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
 
 	fields := ptrutils.DerefOr(request.Params.Fields, []string{})
 
@@ -74,6 +383,7 @@ func (h UsersHandler) GetUser(ctx context.Context, request GetUserRequestObject)
 		}
 
 		logger.ErrorContext(ctx, "Error getting user", slog.Any("err", err))
+		logging.AddError(ctx, "db", err)
 
 		return GetUser500ApplicationProblemPlusJSONResponse(
 			ErrorResponse{
@@ -86,7 +396,15 @@ func (h UsersHandler) GetUser(ctx context.Context, request GetUserRequestObject)
 		), nil
 	}
 
-	return GetUser200JSONResponse(transformUserDaoToDto(host, fieldNode, user)), nil
+	etag := representationETag(userETag(user), mediaTypeFromContext(ctx), fieldNode)
+	if !noneMatch(request.Params.IfNoneMatch, etag) {
+		return GetUser304Response{Headers: GetUser304ResponseHeaders{ETag: &etag}}, nil
+	}
+
+	dto := transformUserDaoToDto(host, fieldNode, user)
+	headers := GetUser200ResponseHeaders{ETag: &etag}
+
+	return getUserResponse(mediaTypeFromContext(ctx), fieldNode, dto, user, headers)
 }
 
 func (h UsersHandler) GetUsers(ctx context.Context, request GetUsersRequestObject) (GetUsersResponseObject, error) {
@@ -99,94 +417,323 @@ func (h UsersHandler) GetUsers(ctx context.Context, request GetUsersRequestObjec
 	host, _ := ctx.Value("host").(string)
 	requestID := middleware.GetReqID(ctx)
 
-	page := ptrutils.DerefOr(request.Params.Page, 0)
-	if page < 0 || page > 1000 {
+	fields := ptrutils.DerefOr(request.Params.Fields, []string{})
+
+	fieldNode, err := gofieldselect.Parse(strings.Join(fields, ","))
+	if err != nil {
 		return nil, &InvalidParamFormatError{
-			ParamName: "page",
-			Err:       errors.New("page must be between 0 and 1000"),
+			ParamName: "fields",
+			Err:       err,
 		}
 	}
 
-	size := ptrutils.DerefOr(request.Params.Size, 20)
-	if size < 1 || size > 50 {
-		return nil, &InvalidParamFormatError{
-			ParamName: "size",
-			Err:       errors.New("size must be between 1 and 50"),
+	pr, err := newPageRequest(request.Params.Page, request.Params.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := users.Filter{IncludeDeleted: ptrutils.DerefOr(request.Params.IncludeDeleted, false)}
+	if filter.IncludeDeleted {
+		if problem := authorizationProblem(ctx, auth.RoleAdmin); problem != nil {
+			return GetUsers4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
 		}
 	}
 
+	logging.AddAttrs(
+		ctx,
+		slog.Int("page", pr.Page()),
+		slog.Int("size", pr.Size()),
+		slog.Bool("includeDeleted", filter.IncludeDeleted),
+	)
+
+	pageUsers, err := h.repository.GetAll(ctx, pr, filter)
+	if err != nil {
+		logging.AddError(ctx, "db", err)
+
+		return nil, fmt.Errorf("error getting users: %w", err)
+	}
+
+	logging.AddAttrs(ctx, slog.Int64("totalElements", pageUsers.TotalElements()))
+
+	urlBuilder := func(page, size int32) string {
+		return fmt.Sprintf("%s%s", host, Paths{}.GetUsersEndpoint.Path(GetUsersEndpointQueryParams{
+			Page:           strconv.FormatInt(int64(page), 10),
+			Size:           strconv.FormatInt(int64(size), 10),
+			Fields:         nil,
+			IncludeDeleted: includeDeletedQueryParam(filter),
+		}))
+	}
+
+	etag := representationETag(pageETag(pageUsers), mediaTypeFromContext(ctx), fieldNode)
+	if !noneMatch(request.Params.IfNoneMatch, etag) {
+		return GetUsers304Response{Headers: GetUsers304ResponseHeaders{ETag: &etag}}, nil
+	}
+
+	headers := GetUsers200ResponseHeaders{ETag: &etag}
+	dto := PageUsers{
+		Kind:    KindPage,
+		Content: transformUserDaosToDtos(host, fieldNode, pageUsers.Content()),
+		Page:    transformPage(pageUsers, urlBuilder),
+		Metadata: RequestMetadata{
+			Environment: h.cfg.Env,
+			RequestId:   requestID,
+			ServerId:    h.cfg.ServerID,
+			ApiVersion:  "v1",
+		},
+	}
+
+	return getUsersResponse(mediaTypeFromContext(ctx), fieldNode, dto, pageUsers.Content(), headers)
+}
+
+func (h UsersHandler) GetUsernameAvailability(
+	ctx context.Context,
+	request GetUsernameAvailabilityRequestObject,
+) (GetUsernameAvailabilityResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "UsersHandler.GetUsernameAvailability")
+	defer span.End()
+
+	username := users.Username(request.Name)
+
+	logging.AddAttrs(ctx, slog.Any("username", username))
+
+	dto := UsernameAvailability{
+		Kind:       KindUsernameAvailability,
+		Username:   request.Name,
+		Normalized: string(username.Normalize()),
+		Available:  true,
+	}
+
+	err := username.IsValid()
+
+	switch {
+	case errors.Is(err, users.ErrUsernameReserved):
+		dto.Available, dto.Reason = false, new(Reserved)
+	case err != nil:
+		dto.Available, dto.Reason = false, new(Invalid)
+	default:
+		taken, errTaken := h.repository.IsUsernameTaken(ctx, username)
+		if errTaken != nil {
+			logging.AddError(ctx, "db", errTaken)
+
+			return nil, fmt.Errorf("error checking username availability: %w", errTaken)
+		}
+
+		if taken {
+			dto.Available, dto.Reason = false, new(Taken)
+		}
+	}
+
+	return GetUsernameAvailability200JSONResponse(dto), nil
+}
+
+func (h UsersHandler) ImportUsers(
+	ctx context.Context,
+	request ImportUsersRequestObject,
+) (ImportUsersResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "UsersHandler.ImportUsers")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+	requestID := middleware.GetReqID(ctx)
+
+	part, err := importFile(request.Body)
+	if err != nil {
+		return importBodyProblem(requestID, err), nil
+	}
+	defer part.Close()
+
+	rows, ok := importRows(part)
+	if !ok {
+		return ImportUsers4XXApplicationProblemPlusJSONResponse{
+			StatusCode: http.StatusUnsupportedMediaType,
+			Body: ErrorResponse{
+				Type:      "UnsupportedMediaType",
+				Title:     "Unsupported Media Type",
+				Detail:    "The file must be CSV (text/csv) or NDJSON (application/x-ndjson)",
+				Status:    http.StatusUnsupportedMediaType,
+				RequestId: requestID,
+			},
+		}, nil
+	}
+
+	results, err := h.importUsersService.ImportUsers(ctx, rows)
+	if err != nil {
+		if readErr, isReadErr := errors.AsType[importReadError](err); isReadErr {
+			return importBodyProblem(requestID, readErr), nil
+		}
+
+		logging.FromContext(ctx).ErrorContext(ctx, "Error importing users", slog.Any("err", err))
+		logging.AddError(ctx, "db", err)
+
+		return ImportUsers500ApplicationProblemPlusJSONResponse(
+			ErrorResponse{
+				Type:      "DatabaseError",
+				Title:     "Internal Server Error",
+				Detail:    "Error importing users",
+				Status:    http.StatusInternalServerError,
+				RequestId: requestID,
+			},
+		), nil
+	}
+
+	dto := transformImportResults(host, results)
+	logging.AddAttrs(ctx, slog.Int("created", int(dto.Created)), slog.Int("failed", int(dto.Failed)))
+
+	return ImportUsers200JSONResponse(dto), nil
+}
+
+func (h UsersHandler) ExportUsers(
+	ctx context.Context,
+	request ExportUsersRequestObject,
+) (ExportUsersResponseObject, error) {
+	// The span ends when the users are written, after the handler returns.
+	ctx, span := observability.StartSpan(ctx, "UsersHandler.ExportUsers")
+
+	host, _ := ctx.Value("host").(string)
+
 	fields := ptrutils.DerefOr(request.Params.Fields, []string{})
 
 	fieldNode, err := gofieldselect.Parse(strings.Join(fields, ","))
 	if err != nil {
+		span.End()
+
 		return nil, &InvalidParamFormatError{
 			ParamName: "fields",
 			Err:       err,
 		}
 	}
 
-	pr, err := pagination.NewPageRequest(int(page), int(size))
-	if err != nil {
-		if errors.Is(err, pagination.ErrPageMustBeGreaterOrEqualThanZero) {
-			return nil, &InvalidParamFormatError{
-				ParamName: "page",
-				Err:       err,
+	mediaType := mediaTypeFromContext(ctx)
+
+	// The users are written to the pipe as they are read, while the response copies them from it.
+	// The pipe is closed by the response when it is sent, or the client goes away, stopping the export.
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer span.End()
+
+		errWrite := writeUsers(pw, mediaType, host, fieldNode, h.repository.All(ctx))
+		if errWrite != nil {
+			if !errors.Is(errWrite, io.ErrClosedPipe) && ctx.Err() == nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "Error exporting users", slog.Any("err", errWrite))
+				logging.AddError(ctx, "db", errWrite)
 			}
+
+			_ = pw.CloseWithError(streamError{err: errWrite})
+
+			return
 		}
 
-		if errors.Is(err, pagination.ErrSizeMustBeGreaterOrEqualThanZero) {
-			return nil, &InvalidParamFormatError{
-				ParamName: "size",
-				Err:       err,
-			}
+		_ = pw.Close()
+	}()
+
+	if mediaType == mediaTypeCSV {
+		return ExportUsers200TextcsvResponse{Body: pr}, nil
+	}
+
+	return ExportUsers200ApplicationxNdjsonResponse{Body: pr}, nil
+}
+
+func (h UsersHandler) GetUserEvents(
+	ctx context.Context,
+	request GetUserEventsRequestObject,
+) (GetUserEventsResponseObject, error) {
+	// The span ends when the subscription is made, the events are streamed after the handler returns.
+	ctx, span := observability.StartSpan(ctx, "UsersHandler.GetUserEvents")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	resumeToken := ptrutils.DerefOr(request.Params.LastEventID, ptrutils.DerefOr(request.Params.ResumeToken, ""))
+
+	messages, err := h.bus.Subscribe(ctx, resumeToken)
+	if err != nil {
+		problem := ErrorResponse{
+			Type:      "InvalidResumeToken",
+			Title:     "Invalid Resume Token",
+			Detail:    err.Error(),
+			Status:    http.StatusBadRequest,
+			RequestId: middleware.GetReqID(ctx),
+		}
+		if errors.Is(err, events.ErrResumeTokenExpired) {
+			problem.Type, problem.Title, problem.Status = "ResumeTokenExpired", "Resume Token Expired", http.StatusGone
 		}
 
-		return nil, fmt.Errorf("error creating page request: %w", err)
+		return GetUserEvents4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: problem}, nil
 	}
 
-	pageUsers, err := h.repository.GetAll(ctx, pr)
+	return userEventsResponse{host: host, messages: messages}, nil
+}
+
+func (h UsersHandler) GetUserAudit(
+	ctx context.Context,
+	request GetUserAuditRequestObject,
+) (GetUserAuditResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"UsersHandler.GetUserAudit",
+		oteltrace.WithAttributes(attribute.String("id", request.UserId.String())),
+	)
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+	requestID := middleware.GetReqID(ctx)
+
+	logging.AddAttrs(ctx, slog.String("userId", request.UserId.String()))
+
+	pr, err := newPageRequest(request.Params.Page, request.Params.Size)
 	if err != nil {
-		return nil, fmt.Errorf("error getting users: %w", err)
+		return nil, err
 	}
 
-	urlBuilder := func(page, size int32) string {
-		return fmt.Sprintf("%s%s", host, Paths{}.GetUsersEndpoint.Path(GetUsersEndpointQueryParams{
-			Page:   strconv.FormatInt(int64(page), 10),
-			Size:   strconv.FormatInt(int64(size), 10),
-			Fields: nil,
-		}))
+	id := users.UserID(request.UserId)
+
+	pageEntries, err := h.auditRepository.GetByUserID(ctx, id, pr)
+	if err != nil {
+		logging.AddError(ctx, "db", err)
+
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
 	}
-	self := urlBuilder(page, size)
-	first := urlBuilder(0, size)
-	//gosec:disable G115 -- Not expecting to overflow
-	last := urlBuilder(int32(pageUsers.TotalPages()-1), size)
 
-	prev := new(urlBuilder(page-1, size))
+	// The deleted users keep their audit log, so the user is only looked up when there are no entries.
+	if pageEntries.TotalElements() == 0 {
+		_, err = h.repository.GetByID(ctx, id)
+		if notFoundError, ok := errors.AsType[users.NotFoundError](err); ok {
+			return GetUserAudit4XXApplicationProblemPlusJSONResponse{
+				StatusCode: http.StatusNotFound,
+				Body: ErrorResponse{
+					Type:      "NotFound",
+					Title:     "User not found",
+					Detail:    notFoundError.Error(),
+					Status:    http.StatusNotFound,
+					RequestId: requestID,
+				},
+			}, nil
+		}
 
-	next := new(urlBuilder(page+1, size))
-	if page == 0 {
-		prev = nil
+		if err != nil {
+			logging.AddError(ctx, "db", err)
+
+			return nil, fmt.Errorf("error getting user: %w", err)
+		}
 	}
-	//gosec:disable G115 -- Not expecting to overflow
-	if page == int32(pageUsers.TotalPages()-1) {
-		next = nil
+
+	urlBuilder := func(page, size int32) string {
+		return fmt.Sprintf("%s%s", host, Paths{}.GetUserAuditEndpoint.Path(
+			request.UserId.String(),
+			GetUserAuditEndpointQueryParams{
+				Page: strconv.FormatInt(int64(page), 10),
+				Size: strconv.FormatInt(int64(size), 10),
+			},
+		))
 	}
 
-	return GetUsers200JSONResponse{
-		Kind:    KindPage,
-		Content: transformUserDaosToDtos(host, fieldNode, pageUsers.Content()),
-		Page: Page{
-			Number:        page,
-			Size:          size,
-			TotalElements: pageUsers.TotalElements(),
-			//gosec:disable G115 -- Not expecting to overflow
-			TotalPages: int32(pageUsers.TotalPages()),
-			Self:       self,
-			Prev:       prev,
-			Next:       next,
-			First:      first,
-			Last:       last,
-		},
+	return GetUserAudit200JSONResponse{
+		Kind: KindPage,
+		Content: lo.Map(pageEntries.Content(), func(item audit.Entry, _ int) AuditEntry {
+			return transformAuditEntryToDto(item)
+		}),
+		Page: transformPage(pageEntries, urlBuilder),
 		Metadata: RequestMetadata{
 			Environment: h.cfg.Env,
 			RequestId:   requestID,
@@ -196,6 +743,44 @@ func (h UsersHandler) GetUsers(ctx context.Context, request GetUsersRequestObjec
 	}, nil
 }
 
+// precondition returns the user to be modified if the If-Match header matches its ETag,
+// otherwise the problem to respond: 428 if the header is missing, 404 if the user does not exist,
+// or 412 if the user was modified.
+func (h UsersHandler) precondition(ctx context.Context, id users.UserID, ifMatch *string) (users.User, *ErrorResponse) {
+	requestID := middleware.GetReqID(ctx)
+
+	if ifMatch == nil || *ifMatch == "" {
+		return users.User{}, &ErrorResponse{
+			Type:      "PreconditionRequired",
+			Title:     "Precondition Required",
+			Detail:    "The If-Match header with the ETag of the user is required",
+			Status:    http.StatusPreconditionRequired,
+			RequestId: requestID,
+		}
+	}
+
+	user, err := h.repository.GetByID(ctx, id)
+	if err != nil {
+		return users.User{}, modificationProblem(ctx, "Error getting user", err)
+	}
+
+	if !match(*ifMatch, userETag(user)) {
+		return users.User{}, modificationProblem(ctx, "", users.VersionMismatchError{ID: id, Version: user.Version()})
+	}
+
+	return user, nil
+}
+
+// includeDeletedQueryParam returns the includeDeleted query parameter of the links to the pages of the filter,
+// empty if it is the default.
+func includeDeletedQueryParam(filter users.Filter) string {
+	if !filter.IncludeDeleted {
+		return ""
+	}
+
+	return strconv.FormatBool(filter.IncludeDeleted)
+}
+
 func transformUserDaosToDtos(host string, fieldNode gofieldselect.Node, daos []users.User) []User {
 	return lo.Map(daos, func(dao users.User, _ int) User {
 		return transformUserDaoToDto(host, fieldNode, dao)
@@ -203,12 +788,75 @@ func transformUserDaosToDtos(host string, fieldNode gofieldselect.Node, daos []u
 }
 
 func transformUserDaoToDto(host string, fieldNode gofieldselect.Node, dao users.User) User {
+	profile := dao.Profile()
+	self := Paths{}.GetUserEndpoint.Path(dao.ID().String(), GetUserEndpointQueryParams{})
+
 	return User{
-		Self:      fmt.Sprintf("%s/%s", host, Paths{}.GetUserEndpoint.Path(dao.ID().String(), GetUserEndpointQueryParams{})),
-		Kind:      KindUser,
-		Id:        gofieldselect.Get(fieldNode, "id", new(uuid.UUID(dao.ID()))),
-		CreatedAt: gofieldselect.Get(fieldNode, "createdAt", new(dao.CreatedAt())),
-		UpdatedAt: gofieldselect.Get(fieldNode, "updatedAt", new(dao.UpdatedAt())),
-		Username:  gofieldselect.Get(fieldNode, "username", new(dao.Username())),
+		Self:            fmt.Sprintf("%s/%s", host, self),
+		Kind:            KindUser,
+		Id:              gofieldselect.Get(fieldNode, "id", new(uuid.UUID(dao.ID()))),
+		CreatedAt:       gofieldselect.Get(fieldNode, "createdAt", new(dao.CreatedAt())),
+		UpdatedAt:       gofieldselect.Get(fieldNode, "updatedAt", new(dao.UpdatedAt())),
+		DeletedAt:       gofieldselect.Get(fieldNode, "deletedAt", deletedAt(dao)),
+		Username:        gofieldselect.Get(fieldNode, "username", new(dao.Username())),
+		Email:           gofieldselect.Get(fieldNode, "email", lo.EmptyableToPtr(profile.Email)),
+		EmailVerifiedAt: gofieldselect.Get(fieldNode, "emailVerifiedAt", emailVerifiedAt(dao)),
+		DisplayName:     gofieldselect.Get(fieldNode, "displayName", lo.EmptyableToPtr(profile.DisplayName)),
+		Locale:          gofieldselect.Get(fieldNode, "locale", lo.EmptyableToPtr(profile.Locale)),
+		AvatarUrl:       gofieldselect.Get(fieldNode, "avatarUrl", lo.EmptyableToPtr(profile.AvatarURL)),
 	}
 }
+
+// transformCreateUserProfile returns the profile of the user to be created, with the fields not sent empty.
+func transformCreateUserProfile(body CreateUser) users.Profile {
+	return users.Profile{
+		Email:       lo.FromPtr(body.Email),
+		DisplayName: lo.FromPtr(body.DisplayName),
+		Locale:      lo.FromPtr(body.Locale),
+		AvatarURL:   lo.FromPtr(body.AvatarUrl),
+	}
+}
+
+// validateProfile adds the validation errors of the profile fields that are set, by their path.
+func validateProfile(validationErrors map[string][]error, profile users.Profile) {
+	fields := []struct {
+		path    string
+		set     bool
+		isValid func() error
+	}{
+		{path: "/email", set: profile.Email != "", isValid: profile.Email.IsValid},
+		{path: "/displayName", set: profile.DisplayName != "", isValid: profile.DisplayName.IsValid},
+		{path: "/locale", set: profile.Locale != "", isValid: profile.Locale.IsValid},
+		{path: "/avatarUrl", set: profile.AvatarURL != "", isValid: profile.AvatarURL.IsValid},
+	}
+
+	for _, field := range fields {
+		if !field.set {
+			continue
+		}
+
+		if err := field.isValid(); err != nil {
+			validationErrors[field.path] = append(validationErrors[field.path], err)
+		}
+	}
+}
+
+// deletedAt returns when the user was deleted, nil if it was not.
+func deletedAt(user users.User) *time.Time {
+	at, ok := user.DeletedAt()
+	if !ok {
+		return nil
+	}
+
+	return &at
+}
+
+// emailVerifiedAt returns when the email of the user was verified, nil if it was not.
+func emailVerifiedAt(user users.User) *time.Time {
+	at, ok := user.EmailVerifiedAt()
+	if !ok {
+		return nil
+	}
+
+	return &at
+}
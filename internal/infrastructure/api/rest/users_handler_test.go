@@ -5,16 +5,33 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/mock/gomock"
 
 	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/auth"
 	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/events"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/services"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
 	"github.com/manuelarte/go-web-layout/internal/users"
 )
 
@@ -63,7 +80,11 @@ func TestUsersHandler_GetUser_Error(t *testing.T) {
 			cfg := config.AppEnv{}
 			r := chi.NewRouter()
 			userService := users.NewMockRepository(gomock.NewController(t))
-			CreateRestAPI(r, cfg, userService, goweblayout.SwaggerUI, goweblayout.OpenAPI)
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, userService), services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
 
 			w := httptest.NewRecorder()
 			url := fmt.Sprintf("/api/v1/users/%s", test.id)
@@ -83,3 +104,463 @@ func TestUsersHandler_GetUser_Error(t *testing.T) {
 		})
 	}
 }
+
+// newAPI creates the API with the services using the repository.
+func newAPI(t *testing.T, cfg config.AppEnv, repository users.Repository) API {
+	t.Helper()
+
+	return newAPIWithMetrics(t, cfg, repository, noop.NewMeterProvider())
+}
+
+// newAPIWithMetrics creates the API with the services using the repository, recording their metrics in mp.
+func newAPIWithMetrics(t *testing.T, cfg config.AppEnv, repository users.Repository, mp metric.MeterProvider) API {
+	t.Helper()
+
+	metrics, err := services.NewMetrics(mp)
+	require.NoError(t, err)
+
+	return API{
+		UsersHandler: NewUsersHandler(
+			cfg,
+			repository,
+			audit.NewMockRepository(gomock.NewController(t)),
+			services.NewCreateUser(repository, metrics),
+			services.NewUpdateUser(repository),
+			services.NewDeleteUser(repository, metrics),
+			services.NewImportUsers(repository, metrics, cfg.ImportBatchSize),
+			events.NewBus(cfg.EventsHistorySize),
+		),
+	}
+}
+
+func TestUsersHandler_CreateUser_ValidationMetrics(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	cfg := config.AppEnv{}
+	r := chi.NewRouter()
+	reader := sdkmetric.NewManualReader()
+	repository := users.NewMockRepository(gomock.NewController(t))
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+	CreateRestAPI(
+		r, cfg, newAPIWithMetrics(t, cfg, repository, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		services.APIKeys{}, oidc.Verifier{},
+		limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+	)
+
+	ctx := observability.WithChannel(t.Context(), observability.ChannelREST)
+	body := strings.NewReader(`{"username":"jo","password":"12345678"}`)
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/api/v1/users", body)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+
+	// Act
+	r.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+	got := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "users.creation.failures", got.Name)
+
+	sum, ok := got.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+
+	expected := attribute.NewSet(
+		attribute.String("channel", observability.ChannelREST),
+		attribute.String("tenantId", string(tenant.Default)),
+		attribute.String("reason", services.FailureReasonValidation),
+	)
+	assert.True(t, expected.Equals(&sum.DataPoints[0].Attributes))
+}
+
+func TestUsersHandler_ConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	user := users.NewUser(
+		users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699")),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"john",
+		users.Profile{},
+		1,
+	)
+	url := "/api/v1/users/" + user.ID().String()
+
+	tests := map[string]struct {
+		method           string
+		headers          http.Header
+		body             string
+		expectedMockCall func(ms *users.MockRepository)
+		expectedStatus   int
+	}{
+		"get without If-None-Match": {
+			method: http.MethodGet,
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"get with matching If-None-Match": {
+			method:  http.MethodGet,
+			headers: http.Header{"If-None-Match": []string{userETag(user)}},
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		"get another representation with the If-None-Match of json": {
+			method:  http.MethodGet,
+			headers: http.Header{"If-None-Match": []string{userETag(user)}, "Accept": []string{mediaTypeYAML}},
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"get with stale If-None-Match": {
+			method:  http.MethodGet,
+			headers: http.Header{"If-None-Match": []string{`"stale"`}},
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"patch without If-Match": {
+			method:           http.MethodPatch,
+			body:             `{"username":"johnny"}`,
+			expectedMockCall: func(*users.MockRepository) {},
+			expectedStatus:   http.StatusPreconditionRequired,
+		},
+		"patch with stale If-Match": {
+			method:  http.MethodPatch,
+			headers: http.Header{"If-Match": []string{`"stale"`}},
+			body:    `{"username":"johnny"}`,
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		"patch modified concurrently": {
+			method:  http.MethodPatch,
+			headers: http.Header{"If-Match": []string{userETag(user)}},
+			body:    `{"username":"johnny"}`,
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+				ms.EXPECT().Update(gomock.Any(), user.ID(), user.Version(), gomock.Any()).
+					Return(users.User{}, users.VersionMismatchError{ID: user.ID(), Version: user.Version()})
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		"patch with matching If-Match": {
+			method:  http.MethodPatch,
+			headers: http.Header{"If-Match": []string{userETag(user)}},
+			body:    `{"username":"johnny"}`,
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+				ms.EXPECT().Update(gomock.Any(), user.ID(), user.Version(), gomock.Any()).
+					Return(users.NewUser(user.ID(), user.CreatedAt(), time.Now(), "johnny", users.Profile{}, 2), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"delete with any If-Match": {
+			method:  http.MethodDelete,
+			headers: http.Header{"If-Match": []string{"*"}},
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), user.ID()).Return(user, nil)
+				ms.EXPECT().Delete(gomock.Any(), user.ID(), user.Version()).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{}
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+			test.expectedMockCall(repository)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), test.method, url, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			req.Header = test.headers.Clone()
+			if req.Header == nil {
+				req.Header = make(http.Header)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUsersHandler_GetUserAudit(t *testing.T) {
+	t.Parallel()
+
+	userID := users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699"))
+	entry := audit.Entry{
+		ID:         1,
+		Actor:      audit.AnonymousActor,
+		Action:     audit.ActionDelete,
+		UserID:     userID,
+		Changes:    []audit.Change{{Field: "username", Before: new("john")}},
+		RequestID:  "request-id",
+		OccurredAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := map[string]struct {
+		entries          []audit.Entry
+		expectedMockCall func(ms *users.MockRepository)
+		expectedStatus   int
+		expectedContent  []AuditEntry
+	}{
+		"entries of a deleted user": {
+			entries:          []audit.Entry{entry},
+			expectedMockCall: func(*users.MockRepository) {},
+			expectedStatus:   http.StatusOK,
+			expectedContent: []AuditEntry{
+				{
+					Kind:       KindAuditEntry,
+					Id:         1,
+					Actor:      audit.AnonymousActor,
+					Action:     Delete,
+					UserId:     uuid.UUID(userID),
+					Changes:    []AuditChange{{Field: "username", Before: new("john")}},
+					RequestId:  "request-id",
+					OccurredAt: entry.OccurredAt,
+				},
+			},
+		},
+		"no entries of an existing user": {
+			entries: []audit.Entry{},
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), userID).
+					Return(users.NewUser(userID, time.Now(), time.Now(), "john", users.Profile{}, 1), nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedContent: []AuditEntry{},
+		},
+		"not existing user": {
+			entries: []audit.Entry{},
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().GetByID(gomock.Any(), userID).Return(users.User{}, users.NotFoundError{ID: userID})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{}
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+			auditRepository := audit.NewMockRepository(gomock.NewController(t))
+			api := newAPI(t, cfg, repository)
+			api.auditRepository = auditRepository
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, api, services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			pr := pagination.MustPageRequest(0, 20)
+			auditRepository.EXPECT().GetByUserID(gomock.Any(), userID, pr).
+				Return(pagination.MustPage(test.entries, pr, int64(len(test.entries))), nil)
+			test.expectedMockCall(repository)
+
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/users/%s/audit", userID)
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+			require.NoError(t, err)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var actual PageAuditEntries
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+			assert.Equal(t, test.expectedContent, actual.Content)
+			assert.Equal(t, int64(len(test.entries)), actual.Page.TotalElements)
+		})
+	}
+}
+
+func TestUsersHandler_RestoreAndPurge(t *testing.T) {
+	t.Parallel()
+
+	user := users.NewUser(
+		users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699")),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"john",
+		users.Profile{},
+		3,
+	)
+
+	tests := map[string]struct {
+		url              string
+		expectedMockCall func(ms *users.MockRepository)
+		expectedStatus   int
+	}{
+		"restore deleted user": {
+			url: "/api/v1/users/" + user.ID().String() + "/restore",
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().Restore(gomock.Any(), user.ID()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"restore not deleted user": {
+			url: "/api/v1/users/" + user.ID().String() + "/restore",
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().Restore(gomock.Any(), user.ID()).Return(users.User{}, users.NotDeletedError{ID: user.ID()})
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		"purge deleted user": {
+			url: "/api/v1/users/" + user.ID().String() + "/purge",
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().Purge(gomock.Any(), user.ID()).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		"purge not existing user": {
+			url: "/api/v1/users/" + user.ID().String() + "/purge",
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().Purge(gomock.Any(), user.ID()).Return(users.NotFoundError{ID: user.ID()})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{}
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+			apiKeys, headers := newAPIKeysWithRole(t, auth.RoleAdmin, "admin")
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), apiKeys, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+			test.expectedMockCall(repository)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, test.url, http.NoBody)
+			require.NoError(t, err)
+
+			req.Header = headers["admin"].Clone()
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestUsersHandler_GetUsernameAvailability(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		name             string
+		expectedMockCall func(ms *users.MockRepository)
+		expected         UsernameAvailability
+	}{
+		"available": {
+			name: "Johnny",
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().IsUsernameTaken(gomock.Any(), users.Username("Johnny")).Return(false, nil)
+			},
+			expected: UsernameAvailability{
+				Kind: KindUsernameAvailability, Username: "Johnny", Normalized: "johnny", Available: true,
+			},
+		},
+		"taken": {
+			name: "John",
+			expectedMockCall: func(ms *users.MockRepository) {
+				ms.EXPECT().IsUsernameTaken(gomock.Any(), users.Username("John")).Return(true, nil)
+			},
+			expected: UsernameAvailability{
+				Kind: KindUsernameAvailability, Username: "John", Normalized: "john", Reason: new(Taken),
+			},
+		},
+		"reserved": {
+			name:             "Admin",
+			expectedMockCall: func(*users.MockRepository) {},
+			expected: UsernameAvailability{
+				Kind: KindUsernameAvailability, Username: "Admin", Normalized: "admin", Reason: new(Reserved),
+			},
+		},
+		"invalid": {
+			name:             "jo",
+			expectedMockCall: func(*users.MockRepository) {},
+			expected: UsernameAvailability{
+				Kind: KindUsernameAvailability, Username: "jo", Normalized: "jo", Reason: new(Invalid),
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{}
+			r := chi.NewRouter()
+			repository := users.NewMockRepository(gomock.NewController(t))
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			CreateRestAPI(
+				r, cfg, newAPI(t, cfg, repository), services.APIKeys{}, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+			test.expectedMockCall(repository)
+
+			w := httptest.NewRecorder()
+			url := fmt.Sprintf("/api/v1/usernames/%s/availability", test.name)
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+			require.NoError(t, err)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var actual UsernameAvailability
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
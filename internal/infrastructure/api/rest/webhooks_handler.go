@@ -0,0 +1,387 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/webhooks"
+)
+
+type WebhooksHandler struct {
+	cfg        config.AppEnv
+	repository webhooks.Repository
+	dispatcher webhooks.Dispatcher
+}
+
+func NewWebhooksHandler(
+	cfg config.AppEnv,
+	repository webhooks.Repository,
+	dispatcher webhooks.Dispatcher,
+) WebhooksHandler {
+	return WebhooksHandler{
+		cfg:        cfg,
+		repository: repository,
+		dispatcher: dispatcher,
+	}
+}
+
+func (h WebhooksHandler) CreateWebhook(
+	ctx context.Context,
+	request CreateWebhookRequestObject,
+) (CreateWebhookResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "WebhooksHandler.CreateWebhook")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	validationErrors := make(map[string][]error)
+	if err := webhooks.ValidateURL(request.Body.Url); err != nil {
+		validationErrors["/url"] = append(validationErrors["/url"], err)
+	}
+
+	if err := webhooks.ValidateEvents(request.Body.Events); err != nil {
+		validationErrors["/events"] = append(validationErrors["/events"], err)
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, ValidationError{errors: validationErrors}
+	}
+
+	subscription, err := webhooks.NewSubscription(request.Body.Url, request.Body.Events)
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	created, err := h.repository.Create(ctx, subscription)
+	if err != nil {
+		problem := webhookProblem(ctx, "Error creating webhook", err)
+
+		return CreateWebhook500ApplicationProblemPlusJSONResponse(*problem), nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("webhookId", created.ID.String()))
+
+	// The secret is only shown when the webhook is created, so it is not leaked by the listings.
+	dto := transformSubscriptionToDto(host, created)
+	dto.Secret = new(created.Secret)
+
+	return CreateWebhook201JSONResponse{
+		Body:    dto,
+		Headers: CreateWebhook201ResponseHeaders{Location: new(dto.Self)},
+	}, nil
+}
+
+func (h WebhooksHandler) GetWebhooks(
+	ctx context.Context,
+	request GetWebhooksRequestObject,
+) (GetWebhooksResponseObject, error) {
+	ctx, span := observability.StartSpan(ctx, "WebhooksHandler.GetWebhooks")
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	pr, err := newPageRequest(request.Params.Page, request.Params.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.AddAttrs(ctx, slog.Int("page", pr.Page()), slog.Int("size", pr.Size()))
+
+	page, err := h.repository.GetAll(ctx, pr)
+	if err != nil {
+		problem := webhookProblem(ctx, "Error getting webhooks", err)
+
+		return GetWebhooks500ApplicationProblemPlusJSONResponse(*problem), nil
+	}
+
+	urlBuilder := func(page, size int32) string {
+		return fmt.Sprintf("%s%s", host, Paths{}.GetWebhooksEndpoint.Path(GetWebhooksEndpointQueryParams{
+			Page: strconv.FormatInt(int64(page), 10),
+			Size: strconv.FormatInt(int64(size), 10),
+		}))
+	}
+
+	return GetWebhooks200JSONResponse{
+		Kind: KindPage,
+		Content: lo.Map(page.Content(), func(item webhooks.Subscription, _ int) Webhook {
+			return transformSubscriptionToDto(host, item)
+		}),
+		Page:     transformPage(page, urlBuilder),
+		Metadata: h.metadata(ctx),
+	}, nil
+}
+
+func (h WebhooksHandler) GetWebhook(
+	ctx context.Context,
+	request GetWebhookRequestObject,
+) (GetWebhookResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhooksHandler.GetWebhook",
+		oteltrace.WithAttributes(attribute.String("id", request.WebhookId.String())),
+	)
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	logging.AddAttrs(ctx, slog.String("webhookId", request.WebhookId.String()))
+
+	subscription, err := h.repository.GetByID(ctx, webhooks.SubscriptionID(request.WebhookId))
+	if err != nil {
+		problem := webhookProblem(ctx, "Error getting webhook", err)
+		if problem.Status == http.StatusInternalServerError {
+			return GetWebhook500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return GetWebhook4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return GetWebhook200JSONResponse(transformSubscriptionToDto(host, subscription)), nil
+}
+
+func (h WebhooksHandler) UpdateWebhook(
+	ctx context.Context,
+	request UpdateWebhookRequestObject,
+) (UpdateWebhookResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhooksHandler.UpdateWebhook",
+		oteltrace.WithAttributes(attribute.String("id", request.WebhookId.String())),
+	)
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	logging.AddAttrs(ctx, slog.String("webhookId", request.WebhookId.String()))
+
+	update := webhooks.Update{URL: request.Body.Url}
+	if request.Body.Events != nil {
+		update.Events = *request.Body.Events
+	}
+
+	validationErrors := make(map[string][]error)
+
+	if update.URL != nil {
+		if err := webhooks.ValidateURL(*update.URL); err != nil {
+			validationErrors["/url"] = append(validationErrors["/url"], err)
+		}
+	}
+
+	if request.Body.Events != nil {
+		if err := webhooks.ValidateEvents(update.Events); err != nil {
+			validationErrors["/events"] = append(validationErrors["/events"], err)
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, ValidationError{errors: validationErrors}
+	}
+
+	subscription, err := h.repository.Update(ctx, webhooks.SubscriptionID(request.WebhookId), update)
+	if err != nil {
+		problem := webhookProblem(ctx, "Error updating webhook", err)
+		if problem.Status == http.StatusInternalServerError {
+			return UpdateWebhook500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return UpdateWebhook4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return UpdateWebhook200JSONResponse(transformSubscriptionToDto(host, subscription)), nil
+}
+
+func (h WebhooksHandler) DeleteWebhook(
+	ctx context.Context,
+	request DeleteWebhookRequestObject,
+) (DeleteWebhookResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhooksHandler.DeleteWebhook",
+		oteltrace.WithAttributes(attribute.String("id", request.WebhookId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("webhookId", request.WebhookId.String()))
+
+	err := h.repository.Delete(ctx, webhooks.SubscriptionID(request.WebhookId))
+	if err != nil {
+		problem := webhookProblem(ctx, "Error deleting webhook", err)
+		if problem.Status == http.StatusInternalServerError {
+			return DeleteWebhook500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return DeleteWebhook4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	return DeleteWebhook204Response{}, nil
+}
+
+func (h WebhooksHandler) GetWebhookDeliveries(
+	ctx context.Context,
+	request GetWebhookDeliveriesRequestObject,
+) (GetWebhookDeliveriesResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhooksHandler.GetWebhookDeliveries",
+		oteltrace.WithAttributes(attribute.String("id", request.WebhookId.String())),
+	)
+	defer span.End()
+
+	host, _ := ctx.Value("host").(string)
+
+	logging.AddAttrs(ctx, slog.String("webhookId", request.WebhookId.String()))
+
+	pr, err := newPageRequest(request.Params.Page, request.Params.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.repository.GetDeliveries(ctx, webhooks.SubscriptionID(request.WebhookId), pr)
+	if err != nil {
+		problem := webhookProblem(ctx, "Error getting webhook deliveries", err)
+		if problem.Status == http.StatusInternalServerError {
+			return GetWebhookDeliveries500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return GetWebhookDeliveries4XXApplicationProblemPlusJSONResponse{
+			StatusCode: int(problem.Status),
+			Body:       *problem,
+		}, nil
+	}
+
+	urlBuilder := func(page, size int32) string {
+		return fmt.Sprintf("%s%s", host, Paths{}.GetWebhookDeliveriesEndpoint.Path(
+			request.WebhookId.String(),
+			GetWebhookDeliveriesEndpointQueryParams{
+				Page: strconv.FormatInt(int64(page), 10),
+				Size: strconv.FormatInt(int64(size), 10),
+			},
+		))
+	}
+
+	return GetWebhookDeliveries200JSONResponse{
+		Kind: KindPage,
+		Content: lo.Map(page.Content(), func(item webhooks.Delivery, _ int) WebhookDelivery {
+			return transformDeliveryToDto(item)
+		}),
+		Page:     transformPage(page, urlBuilder),
+		Metadata: h.metadata(ctx),
+	}, nil
+}
+
+func (h WebhooksHandler) TestWebhook(
+	ctx context.Context,
+	request TestWebhookRequestObject,
+) (TestWebhookResponseObject, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhooksHandler.TestWebhook",
+		oteltrace.WithAttributes(attribute.String("id", request.WebhookId.String())),
+	)
+	defer span.End()
+
+	logging.AddAttrs(ctx, slog.String("webhookId", request.WebhookId.String()))
+
+	delivery, err := h.dispatcher.Test(ctx, webhooks.SubscriptionID(request.WebhookId))
+	if err != nil {
+		problem := webhookProblem(ctx, "Error testing webhook", err)
+		if problem.Status == http.StatusInternalServerError {
+			return TestWebhook500ApplicationProblemPlusJSONResponse(*problem), nil
+		}
+
+		return TestWebhook4XXApplicationProblemPlusJSONResponse{StatusCode: int(problem.Status), Body: *problem}, nil
+	}
+
+	logging.AddAttrs(ctx, slog.String("status", string(delivery.Status)))
+
+	return TestWebhook200JSONResponse(transformDeliveryToDto(delivery)), nil
+}
+
+func (h WebhooksHandler) metadata(ctx context.Context) RequestMetadata {
+	return RequestMetadata{
+		Environment: h.cfg.Env,
+		RequestId:   middleware.GetReqID(ctx),
+		ServerId:    h.cfg.ServerID,
+		ApiVersion:  "v1",
+	}
+}
+
+// webhookProblem returns the problem to respond when a webhook could not be read or modified.
+func webhookProblem(ctx context.Context, msg string, err error) *ErrorResponse {
+	requestID := middleware.GetReqID(ctx)
+
+	if notFoundError, ok := errors.AsType[webhooks.NotFoundError](err); ok {
+		return &ErrorResponse{
+			Type:      "NotFound",
+			Title:     "Webhook not found",
+			Detail:    notFoundError.Error(),
+			Status:    http.StatusNotFound,
+			RequestId: requestID,
+		}
+	}
+
+	logging.FromContext(ctx).ErrorContext(ctx, msg, slog.Any("err", err))
+	logging.AddError(ctx, "db", err)
+
+	return &ErrorResponse{
+		Type:      "DatabaseError",
+		Title:     "Internal Server Error",
+		Detail:    msg,
+		Status:    http.StatusInternalServerError,
+		RequestId: requestID,
+	}
+}
+
+func transformSubscriptionToDto(host string, s webhooks.Subscription) Webhook {
+	return Webhook{
+		Self:      fmt.Sprintf("%s%s", host, Paths{}.GetWebhookEndpoint.Path(s.ID.String())),
+		Kind:      KindWebhook,
+		Id:        uuid.UUID(s.ID),
+		Url:       s.URL,
+		Events:    s.Events,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func transformDeliveryToDto(d webhooks.Delivery) WebhookDelivery {
+	dto := WebhookDelivery{
+		Kind:      KindWebhookDelivery,
+		Id:        uuid.UUID(d.ID),
+		WebhookId: uuid.UUID(d.SubscriptionID),
+		Event:     d.EventType,
+		Status:    WebhookDeliveryStatus(d.Status),
+		//gosec:disable G115 -- Not expecting to overflow
+		Attempts:  int32(d.Attempts),
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+
+	if d.Status == webhooks.DeliveryPending {
+		dto.NextAttemptAt = new(d.NextAttemptAt)
+	}
+
+	if d.StatusCode != 0 {
+		//gosec:disable G115 -- Status codes fit in an int32
+		dto.StatusCode = new(int32(d.StatusCode))
+	}
+
+	if d.Error != "" {
+		dto.Error = new(d.Error)
+	}
+
+	return dto
+}
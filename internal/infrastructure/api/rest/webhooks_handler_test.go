@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/ratelimit"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/oidc"
+	"github.com/manuelarte/go-web-layout/internal/users"
+	"github.com/manuelarte/go-web-layout/internal/webhooks"
+)
+
+func TestWebhooksHandler(t *testing.T) {
+	t.Parallel()
+
+	id := webhooks.SubscriptionID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699"))
+
+	tests := map[string]struct {
+		method           string
+		url              string
+		body             string
+		expectedMockCall func(mr *webhooks.MockRepository)
+		expectedStatus   int
+		expectedBody     func(t *testing.T, body string)
+	}{
+		"create returns the secret": {
+			method: http.MethodPost,
+			url:    "/api/v1/webhooks",
+			body:   `{"url":"https://example.com/hook","events":["user.created"]}`,
+			expectedMockCall: func(mr *webhooks.MockRepository) {
+				mr.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, s webhooks.Subscription) (webhooks.Subscription, error) {
+						return s, nil
+					})
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: func(t *testing.T, body string) {
+				t.Helper()
+
+				var dto Webhook
+				require.NoError(t, json.Unmarshal([]byte(body), &dto))
+				assert.Equal(t, "https://example.com/hook", dto.Url)
+				assert.Equal(t, []users.EventType{users.EventCreated}, dto.Events)
+				require.NotNil(t, dto.Secret)
+				assert.True(t, strings.HasPrefix(*dto.Secret, "whsec_"))
+			},
+		},
+		"create with invalid fields": {
+			method:           http.MethodPost,
+			url:              "/api/v1/webhooks",
+			body:             `{"url":"ftp://example.com","events":["user.renamed"]}`,
+			expectedMockCall: func(*webhooks.MockRepository) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				t.Helper()
+
+				assert.Contains(t, body, `"pointer":"/url"`)
+				assert.Contains(t, body, `"pointer":"/events"`)
+			},
+		},
+		"get does not return the secret": {
+			method: http.MethodGet,
+			url:    "/api/v1/webhooks/" + id.String(),
+			expectedMockCall: func(mr *webhooks.MockRepository) {
+				mr.EXPECT().GetByID(gomock.Any(), id).Return(webhooks.Subscription{
+					ID:     id,
+					URL:    "https://example.com/hook",
+					Events: webhooks.Events(),
+					Secret: "whsec_secret",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				t.Helper()
+
+				assert.NotContains(t, body, "whsec_secret")
+			},
+		},
+		"delete not existing webhook": {
+			method: http.MethodDelete,
+			url:    "/api/v1/webhooks/" + id.String(),
+			expectedMockCall: func(mr *webhooks.MockRepository) {
+				mr.EXPECT().Delete(gomock.Any(), id).Return(webhooks.NotFoundError{ID: id})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   func(*testing.T, string) {},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			cfg := config.AppEnv{}
+			r := chi.NewRouter()
+			repository := webhooks.NewMockRepository(gomock.NewController(t))
+			dispatcher := webhooks.NewDispatcher(repository, http.DefaultClient, slog.New(slog.DiscardHandler), time.Second)
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+			apiKeys, headers := newAPIKeysWithRole(t, auth.RoleAdmin, "admin")
+			CreateRestAPI(
+				r, cfg, API{WebhooksHandler: NewWebhooksHandler(cfg, repository, dispatcher)},
+				apiKeys, oidc.Verifier{},
+				limiter, idempotency.NewMockStore(gomock.NewController(t)), goweblayout.SwaggerUI, goweblayout.OpenAPI,
+			)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(t.Context(), test.method, test.url, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			req.Header = headers["admin"].Clone()
+			req.Header.Set("Content-Type", "application/json")
+			test.expectedMockCall(repository)
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatus, w.Code)
+			test.expectedBody(t, w.Body.String())
+		})
+	}
+}
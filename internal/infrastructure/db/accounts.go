@@ -0,0 +1,265 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+var _ accounts.Repository = new(AccountRepository)
+
+// AccountRepository keeps the account tokens in the user_tokens table.
+// The users changed with the tokens are changed as with the users repository,
+// recording their events and audit entries in the same transaction.
+type AccountRepository struct {
+	users Repository
+}
+
+func NewAccountRepository(repository Repository) AccountRepository {
+	return AccountRepository{
+		users: repository,
+	}
+}
+
+func (r AccountRepository) CreateToken(
+	ctx context.Context,
+	id users.UserID,
+	purpose accounts.Purpose,
+	token accounts.Token,
+	expiresAt time.Time,
+) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"AccountRepository.CreateToken",
+		oteltrace.WithAttributes(attribute.String("id", id.String()), attribute.String("purpose", string(purpose))),
+	)
+	defer span.End()
+
+	err := r.users.transaction(ctx, func(queries *sqlc.Queries) error {
+		user, errGet := queries.GetUserByID(ctx, getUserByIDParams(ctx, id))
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		errDelete := queries.DeleteUserTokens(ctx, sqlc.DeleteUserTokensParams{
+			UserID:  uuid.UUID(id),
+			Purpose: string(purpose),
+		})
+		if errDelete != nil {
+			return fmt.Errorf("error deleting user tokens: %w", errDelete)
+		}
+
+		errCreate := queries.CreateUserToken(ctx, sqlc.CreateUserTokenParams{
+			TokenHash: token.Hash(),
+			UserID:    uuid.UUID(id),
+			Purpose:   string(purpose),
+			Email:     user.EmailNormalized,
+			CreatedAt: time.Now().UTC(),
+			ExpiresAt: expiresAt.UTC(),
+		})
+		if errCreate != nil {
+			return fmt.Errorf("error creating user token: %w", errCreate)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.NotFoundError{ID: id}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r AccountRepository) VerifyEmail(ctx context.Context, token accounts.Token) (users.User, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountRepository.VerifyEmail")
+	defer span.End()
+
+	var verified users.User
+
+	err := r.users.transaction(ctx, func(queries *sqlc.Queries) error {
+		now := time.Now().UTC()
+
+		used, errUse := useToken(ctx, queries, token, accounts.PurposeEmailVerification, now)
+		if errUse != nil {
+			return errUse
+		}
+
+		current, errGet := queries.GetUserByID(ctx, getUserByIDParams(ctx, users.UserID(used.UserID)))
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		// No user is verified, so the token is invalid, if its email changed since the token was sent.
+		dao, errVerify := queries.VerifyUserEmail(ctx, sqlc.VerifyUserEmailParams{
+			EmailVerifiedAt: &now,
+			UpdatedAt:       now,
+			TenantID:        tenantID(ctx),
+			ID:              used.UserID,
+			EmailNormalized: used.Email,
+		})
+		if errVerify != nil {
+			return fmt.Errorf("error verifying user email: %w", errVerify)
+		}
+
+		before := transformModel(current)
+		verified = transformModel(dao)
+
+		entry := audit.NewEntry(ctx, audit.ActionUpdate, &before, &verified, now)
+
+		return recordChange(ctx, queries, users.NewUpdatedEvent(verified), entry)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, accounts.ErrInvalidToken
+		}
+
+		return users.User{}, err
+	}
+
+	return verified, nil
+}
+
+func (r AccountRepository) ResetPassword(
+	ctx context.Context,
+	token accounts.Token,
+	password users.Password,
+) (users.User, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountRepository.ResetPassword")
+	defer span.End()
+
+	err := password.IsValid()
+	if err != nil {
+		return users.User{}, fmt.Errorf("error validating password: %w", err)
+	}
+
+	hashedPassword, err := password.Hash()
+	if err != nil {
+		return users.User{}, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	var reset users.User
+
+	err = r.users.transaction(ctx, func(queries *sqlc.Queries) error {
+		now := time.Now().UTC()
+
+		used, errUse := useToken(ctx, queries, token, accounts.PurposePasswordReset, now)
+		if errUse != nil {
+			return errUse
+		}
+
+		current, errGet := queries.GetUserByID(ctx, getUserByIDParams(ctx, users.UserID(used.UserID)))
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		dao, errReset := queries.ResetUserPassword(ctx, sqlc.ResetUserPasswordParams{
+			Password:  hashedPassword,
+			UpdatedAt: now,
+			TenantID:  tenantID(ctx),
+			ID:        used.UserID,
+		})
+		if errReset != nil {
+			return fmt.Errorf("error resetting user password: %w", errReset)
+		}
+
+		errDelete := queries.DeleteUserTokens(ctx, sqlc.DeleteUserTokensParams{
+			UserID:  used.UserID,
+			Purpose: string(accounts.PurposePasswordReset),
+		})
+		if errDelete != nil {
+			return fmt.Errorf("error deleting user tokens: %w", errDelete)
+		}
+
+		// The users locked out can reset their password to log in again, so the failed logins are forgotten.
+		errLogin := deleteLoginAttempts(ctx, queries, login.Key{Scope: login.ScopeUsername, Value: dao.UsernameNormalized})
+		if errLogin != nil {
+			return errLogin
+		}
+
+		before := transformModel(current)
+		reset = transformModel(dao)
+
+		entry := audit.NewEntry(ctx, audit.ActionUpdate, &before, &reset, now)
+		entry.Changes = append(entry.Changes, audit.DiffPassword(current.Password, dao.Password)...)
+
+		return recordChange(ctx, queries, users.NewUpdatedEvent(reset), entry)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, accounts.ErrInvalidToken
+		}
+
+		return users.User{}, err
+	}
+
+	return reset, nil
+}
+
+func (r AccountRepository) GetByEmail(ctx context.Context, email users.Email) (users.User, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountRepository.GetByEmail")
+	defer span.End()
+
+	dao, err := r.users.queries.GetUserByEmail(ctx, sqlc.GetUserByEmailParams{
+		TenantID:        tenantID(ctx),
+		EmailNormalized: string(email.Normalize()),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, users.NotFoundError{}
+		}
+
+		return users.User{}, fmt.Errorf("error getting user by email: %w", err)
+	}
+
+	return transformModel(dao), nil
+}
+
+func (r AccountRepository) DeleteExpiredTokens(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := observability.StartSpan(ctx, "AccountRepository.DeleteExpiredTokens")
+	defer span.End()
+
+	deleted, err := r.users.queries.DeleteExpiredUserTokens(ctx, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired user tokens: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// useToken deletes the token of the purpose if it is not expired, returning it, so it can only be used once.
+// Returns sql.ErrNoRows if there is no such token.
+func useToken(
+	ctx context.Context,
+	queries *sqlc.Queries,
+	token accounts.Token,
+	purpose accounts.Purpose,
+	now time.Time,
+) (sqlc.UserToken, error) {
+	used, err := queries.UseUserToken(ctx, sqlc.UseUserTokenParams{
+		TokenHash: token.Hash(),
+		Purpose:   string(purpose),
+		Now:       now,
+	})
+	if err != nil {
+		return sqlc.UserToken{}, fmt.Errorf("error using user token: %w", err)
+	}
+
+	return used, nil
+}
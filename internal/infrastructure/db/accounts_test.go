@@ -0,0 +1,192 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestAccountRepository_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		purpose   accounts.Purpose
+		expiresIn time.Duration
+		newEmail  *users.Email
+		uses      int
+		wantErr   error
+	}{
+		"email verified": {
+			purpose:   accounts.PurposeEmailVerification,
+			expiresIn: time.Hour,
+			uses:      1,
+		},
+		"token used twice": {
+			purpose:   accounts.PurposeEmailVerification,
+			expiresIn: time.Hour,
+			uses:      2,
+			wantErr:   accounts.ErrInvalidToken,
+		},
+		"token expired": {
+			purpose:   accounts.PurposeEmailVerification,
+			expiresIn: -time.Minute,
+			uses:      1,
+			wantErr:   accounts.ErrInvalidToken,
+		},
+		"token of another purpose": {
+			purpose:   accounts.PurposePasswordReset,
+			expiresIn: time.Hour,
+			uses:      1,
+			wantErr:   accounts.ErrInvalidToken,
+		},
+		"email changed after the token was sent": {
+			purpose:   accounts.PurposeEmailVerification,
+			expiresIn: time.Hour,
+			newEmail:  new(users.Email("johnny@example.com")),
+			uses:      1,
+			wantErr:   accounts.ErrInvalidToken,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+			require.NoError(t, err)
+
+			r, err := NewRepository(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			ar := NewAccountRepository(r)
+
+			john, err := r.Create(t.Context(), "john", "password", users.Profile{Email: "John@example.com"})
+			require.NoError(t, err)
+
+			token := accounts.NewToken()
+			err = ar.CreateToken(t.Context(), john.ID(), test.purpose, token, time.Now().Add(test.expiresIn))
+			require.NoError(t, err)
+
+			if test.newEmail != nil {
+				_, err = r.Update(t.Context(), john.ID(), john.Version(), users.Update{Email: test.newEmail})
+				require.NoError(t, err)
+			}
+
+			// Act
+			var verified users.User
+			for range test.uses {
+				verified, err = ar.VerifyEmail(t.Context(), token)
+			}
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			verifiedAt, ok := verified.EmailVerifiedAt()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now(), verifiedAt, time.Minute)
+			assert.Equal(t, john.Version()+1, verified.Version())
+
+			// The changed emails have to be verified again.
+			changed, err := r.Update(t.Context(), john.ID(), verified.Version(), users.Update{
+				Email: new(users.Email("johnny@example.com")),
+			})
+			require.NoError(t, err)
+
+			_, ok = changed.EmailVerifiedAt()
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestAccountRepository_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	ar := NewAccountRepository(r)
+
+	john, err := r.Create(t.Context(), "john", "password", users.Profile{Email: "john@example.com"})
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour)
+	first, second := accounts.NewToken(), accounts.NewToken()
+	require.NoError(t, ar.CreateToken(t.Context(), john.ID(), accounts.PurposePasswordReset, first, expiresAt))
+	require.NoError(t, ar.CreateToken(t.Context(), john.ID(), accounts.PurposePasswordReset, second, expiresAt))
+
+	lr := NewLoginRepository(r)
+	key := login.KeyForUsername("john")
+	locked, err := lr.Fail(t.Context(), key, login.Policy{MaxFailures: 1, LockoutDuration: time.Hour}, time.Now())
+	require.NoError(t, err)
+	require.True(t, locked.IsLocked(time.Now()))
+
+	// Act
+	_, errFirst := ar.ResetPassword(t.Context(), first, "new-password")
+	_, errInvalid := ar.ResetPassword(t.Context(), second, "short")
+	reset, errSecond := ar.ResetPassword(t.Context(), second, "new-password")
+	_, errReused := ar.ResetPassword(t.Context(), second, "other-password")
+
+	// Assert
+	require.ErrorIs(t, errFirst, accounts.ErrInvalidToken)
+	assert.True(t, users.IsValidationError(errInvalid))
+	require.NoError(t, errSecond)
+	assert.Equal(t, john.ID(), reset.ID())
+	assert.Equal(t, john.Version()+1, reset.Version())
+	require.ErrorIs(t, errReused, accounts.ErrInvalidToken)
+
+	attempts, err := lr.GetAttempts(t.Context(), key)
+	require.NoError(t, err)
+	assert.False(t, attempts.IsLocked(time.Now()))
+}
+
+func TestAccountRepository_DeleteExpiredTokens(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	ar := NewAccountRepository(r)
+
+	john, err := r.Create(t.Context(), "john", "password", users.Profile{Email: "john@example.com"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	expired, valid := accounts.NewToken(), accounts.NewToken()
+	err = ar.CreateToken(t.Context(), john.ID(), accounts.PurposePasswordReset, expired, now.Add(-time.Hour))
+	require.NoError(t, err)
+	err = ar.CreateToken(t.Context(), john.ID(), accounts.PurposeEmailVerification, valid, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	// Act
+	deleted, err := ar.DeleteExpiredTokens(t.Context(), now)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, err = ar.VerifyEmail(t.Context(), valid)
+	require.NoError(t, err)
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+)
+
+var _ apikeys.Repository = new(APIKeyRepository)
+
+// rolesSeparator separates the roles of a key in the roles column.
+const rolesSeparator = ","
+
+// APIKeyRepository keeps the API keys in the api_keys table.
+type APIKeyRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewAPIKeyRepository(db *sql.DB, mp metric.MeterProvider) (APIKeyRepository, error) {
+	metrics, err := newQueryMetrics(mp)
+	if err != nil {
+		return APIKeyRepository{}, fmt.Errorf("error creating query metrics: %w", err)
+	}
+
+	return APIKeyRepository{
+		queries: sqlc.New(newInstrumentedDBTX(db, metrics)),
+	}, nil
+}
+
+func (r APIKeyRepository) Create(ctx context.Context, k apikeys.APIKey) (apikeys.APIKey, error) {
+	ctx, span := observability.StartSpan(ctx, "APIKeyRepository.Create")
+	defer span.End()
+
+	dao, err := r.queries.CreateAPIKey(ctx, sqlc.CreateAPIKeyParams{
+		ID:      uuid.UUID(k.ID),
+		Name:    k.Name,
+		Prefix:  k.Prefix,
+		KeyHash: k.Hash,
+		Roles:   joinRoles(k.Roles),
+	})
+	if err != nil {
+		return apikeys.APIKey{}, fmt.Errorf("error creating api key: %w", err)
+	}
+
+	return transformAPIKey(dao), nil
+}
+
+func (r APIKeyRepository) GetAll(
+	ctx context.Context,
+	pr pagination.PageRequest,
+) (pagination.Page[apikeys.APIKey], error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"APIKeyRepository.GetAll",
+		oteltrace.WithAttributes(attribute.Int("page", pr.Page()), attribute.Int("size", pr.Size())),
+	)
+	defer span.End()
+
+	daos, err := r.queries.GetAPIKeys(ctx, sqlc.GetAPIKeysParams{
+		Limit:  int64(pr.Size()),
+		Offset: int64(pr.Offset()),
+	})
+	if err != nil {
+		return pagination.Page[apikeys.APIKey]{}, fmt.Errorf("error getting api keys: %w", err)
+	}
+
+	count, err := r.queries.CountAPIKeys(ctx)
+	if err != nil {
+		return pagination.Page[apikeys.APIKey]{}, fmt.Errorf("error counting api keys: %w", err)
+	}
+
+	keys := lo.Map(daos, func(item sqlc.ApiKey, _ int) apikeys.APIKey {
+		return transformAPIKey(item)
+	})
+
+	return pagination.MustPage(keys, pr, count), nil
+}
+
+func (r APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (apikeys.APIKey, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"APIKeyRepository.GetByPrefix",
+		oteltrace.WithAttributes(attribute.String("prefix", prefix)),
+	)
+	defer span.End()
+
+	dao, err := r.queries.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apikeys.APIKey{}, apikeys.ErrInvalidKey
+		}
+
+		return apikeys.APIKey{}, fmt.Errorf("error getting api key by prefix: %w", err)
+	}
+
+	return transformAPIKey(dao), nil
+}
+
+func (r APIKeyRepository) Revoke(ctx context.Context, id apikeys.KeyID, now time.Time) (apikeys.APIKey, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"APIKeyRepository.Revoke",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	dao, err := r.queries.RevokeAPIKey(ctx, sqlc.RevokeAPIKeyParams{
+		RevokedAt: new(now.UTC()),
+		ID:        uuid.UUID(id),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apikeys.APIKey{}, apikeys.NotFoundError{ID: id}
+		}
+
+		return apikeys.APIKey{}, fmt.Errorf("error revoking api key: %w", err)
+	}
+
+	return transformAPIKey(dao), nil
+}
+
+func (r APIKeyRepository) MarkUsed(ctx context.Context, id apikeys.KeyID, now time.Time) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"APIKeyRepository.MarkUsed",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	err := r.queries.UpdateAPIKeyLastUsedAt(ctx, sqlc.UpdateAPIKeyLastUsedAtParams{
+		LastUsedAt: new(now.UTC()),
+		ID:         uuid.UUID(id),
+	})
+	if err != nil {
+		return fmt.Errorf("error updating api key last used at: %w", err)
+	}
+
+	return nil
+}
+
+func joinRoles(roles []auth.Role) string {
+	return strings.Join(lo.Map(roles, func(item auth.Role, _ int) string {
+		return string(item)
+	}), rolesSeparator)
+}
+
+func transformAPIKey(dao sqlc.ApiKey) apikeys.APIKey {
+	return apikeys.APIKey{
+		ID:     apikeys.KeyID(dao.ID),
+		Name:   dao.Name,
+		Prefix: dao.Prefix,
+		Hash:   dao.KeyHash,
+		Roles: lo.Map(strings.Split(dao.Roles, rolesSeparator), func(item string, _ int) auth.Role {
+			return auth.Role(item)
+		}),
+		CreatedAt:  dao.CreatedAt,
+		LastUsedAt: lo.FromPtr(dao.LastUsedAt),
+		RevokedAt:  lo.FromPtr(dao.RevokedAt),
+	}
+}
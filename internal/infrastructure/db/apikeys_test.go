@@ -0,0 +1,113 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+)
+
+func TestAPIKeyRepository_GetByPrefix(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewAPIKeyRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	key, secret, err := apikeys.New("ci", []auth.Role{auth.RoleReader, auth.RoleWriter})
+	require.NoError(t, err)
+
+	created, err := r.Create(t.Context(), key)
+	require.NoError(t, err)
+
+	// Act
+	actual, err := r.GetByPrefix(t.Context(), key.Prefix)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, created, actual)
+	assert.Equal(t, []auth.Role{auth.RoleReader, auth.RoleWriter}, actual.Roles)
+	assert.True(t, actual.Matches(secret))
+	assert.True(t, actual.LastUsedAt.IsZero())
+	assert.False(t, actual.IsRevoked())
+
+	_, err = r.GetByPrefix(t.Context(), "gwl_unknown")
+	require.ErrorIs(t, err, apikeys.ErrInvalidKey)
+}
+
+func TestAPIKeyRepository_RevokeAndMarkUsed(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewAPIKeyRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	key, _, err := apikeys.New("ci", []auth.Role{auth.RoleAdmin})
+	require.NoError(t, err)
+
+	_, err = r.Create(t.Context(), key)
+	require.NoError(t, err)
+
+	usedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	revokedAt := time.Now().Truncate(time.Second)
+
+	// Act
+	errUsed := r.MarkUsed(t.Context(), key.ID, usedAt)
+	revoked, errRevoked := r.Revoke(t.Context(), key.ID, revokedAt)
+	revokedAgain, errRevokedAgain := r.Revoke(t.Context(), key.ID, revokedAt.Add(time.Hour))
+	_, errNotFound := r.Revoke(t.Context(), apikeys.KeyID(uuid.New()), revokedAt)
+
+	// Assert
+	require.NoError(t, errUsed)
+	require.NoError(t, errRevoked)
+	require.NoError(t, errRevokedAgain)
+	assert.True(t, revoked.LastUsedAt.Equal(usedAt))
+	assert.True(t, revoked.RevokedAt.Equal(revokedAt))
+	assert.True(t, revokedAgain.RevokedAt.Equal(revokedAt), "the keys keep the time they were first revoked at")
+	require.ErrorAs(t, errNotFound, new(apikeys.NotFoundError))
+}
+
+func TestAPIKeyRepository_GetAll(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewAPIKeyRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	for _, name := range []string{"first", "second", "third"} {
+		key, _, errNew := apikeys.New(name, []auth.Role{auth.RoleReader})
+		require.NoError(t, errNew)
+
+		_, err = r.Create(t.Context(), key)
+		require.NoError(t, err)
+	}
+
+	pr, err := pagination.NewPageRequest(0, 2)
+	require.NoError(t, err)
+
+	// Act
+	page, err := r.GetAll(t.Context(), pr)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, page.Content(), 2)
+	assert.Equal(t, int64(3), page.TotalElements())
+}
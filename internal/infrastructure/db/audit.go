@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+var _ audit.Repository = new(AuditRepository)
+
+// AuditRepository reads the audit log of the users in the audit_log table.
+type AuditRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewAuditRepository(db *sql.DB, mp metric.MeterProvider) (AuditRepository, error) {
+	metrics, err := newQueryMetrics(mp)
+	if err != nil {
+		return AuditRepository{}, fmt.Errorf("error creating query metrics: %w", err)
+	}
+
+	return AuditRepository{
+		queries: sqlc.New(newInstrumentedDBTX(db, metrics)),
+	}, nil
+}
+
+func (r AuditRepository) GetByUserID(
+	ctx context.Context,
+	id users.UserID,
+	pr pagination.PageRequest,
+) (pagination.Page[audit.Entry], error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"AuditRepository.GetByUserID",
+		oteltrace.WithAttributes(
+			attribute.String("id", id.String()),
+			attribute.Int("page", pr.Page()),
+			attribute.Int("size", pr.Size()),
+		),
+	)
+	defer span.End()
+
+	daos, err := r.queries.GetAuditEntriesByUserID(ctx, sqlc.GetAuditEntriesByUserIDParams{
+		TenantID: tenantID(ctx),
+		UserID:   uuid.UUID(id),
+		Limit:    int64(pr.Size()),
+		Offset:   int64(pr.Offset()),
+	})
+	if err != nil {
+		return pagination.Page[audit.Entry]{}, fmt.Errorf("error getting audit entries: %w", err)
+	}
+
+	count, err := r.queries.CountAuditEntriesByUserID(ctx, sqlc.CountAuditEntriesByUserIDParams{
+		TenantID: tenantID(ctx),
+		UserID:   uuid.UUID(id),
+	})
+	if err != nil {
+		return pagination.Page[audit.Entry]{}, fmt.Errorf("error counting audit entries: %w", err)
+	}
+
+	entries := make([]audit.Entry, 0, len(daos))
+
+	for _, dao := range daos {
+		entry, errEntry := transformAuditEntry(dao)
+		if errEntry != nil {
+			return pagination.Page[audit.Entry]{}, errEntry
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return pagination.MustPage(entries, pr, count), nil
+}
+
+// recordAudit records the audit entries, with the queries of the transaction of the changes.
+func recordAudit(ctx context.Context, queries *sqlc.Queries, entries ...audit.Entry) error {
+	for _, entry := range entries {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return fmt.Errorf("error recording %s audit entry: %w", entry.Action, err)
+		}
+
+		err = queries.CreateAuditEntry(ctx, sqlc.CreateAuditEntryParams{
+			TenantID:   string(entry.TenantID),
+			UserID:     uuid.UUID(entry.UserID),
+			Actor:      entry.Actor,
+			Action:     string(entry.Action),
+			Changes:    changes,
+			RequestID:  entry.RequestID,
+			TraceID:    entry.TraceID,
+			OccurredAt: entry.OccurredAt.UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("error recording %s audit entry: %w", entry.Action, err)
+		}
+	}
+
+	return nil
+}
+
+func transformAuditEntry(dao sqlc.AuditLog) (audit.Entry, error) {
+	var changes []audit.Change
+
+	err := json.Unmarshal(dao.Changes, &changes)
+	if err != nil {
+		return audit.Entry{}, fmt.Errorf("error reading audit entry %d: %w", dao.ID, err)
+	}
+
+	return audit.Entry{
+		ID:         dao.ID,
+		TenantID:   tenant.ID(dao.TenantID),
+		Actor:      dao.Actor,
+		Action:     audit.Action(dao.Action),
+		UserID:     users.UserID(dao.UserID),
+		Changes:    changes,
+		RequestID:  dao.RequestID,
+		TraceID:    dao.TraceID,
+		OccurredAt: dao.OccurredAt,
+	}, nil
+}
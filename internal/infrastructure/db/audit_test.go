@@ -0,0 +1,67 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestAuditRepository_GetByUserID(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	a, err := NewAuditRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	created, err := r.Create(t.Context(), "john", "12345678", users.Profile{})
+	require.NoError(t, err)
+
+	updated, err := r.Update(t.Context(), created.ID(), created.Version(), users.Update{
+		Username: new(users.Username("jane")),
+		Password: new(users.Password("87654321")),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Delete(t.Context(), updated.ID(), updated.Version()))
+
+	// Act
+	page, err := a.GetByUserID(t.Context(), created.ID(), pagination.MustPageRequest(0, 10))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.TotalElements())
+
+	entries := page.Content()
+	require.Len(t, entries, 3)
+	assert.Equal(t, audit.ActionDelete, entries[0].Action)
+	assert.Equal(t, audit.ActionUpdate, entries[1].Action)
+	assert.Equal(t, audit.ActionCreate, entries[2].Action)
+
+	for _, entry := range entries {
+		assert.Equal(t, created.ID(), entry.UserID)
+		assert.Equal(t, audit.AnonymousActor, entry.Actor)
+	}
+
+	assert.Contains(t, entries[1].Changes, audit.Change{Field: "username", Before: new("john"), After: new("jane")})
+	assert.Contains(t, entries[1].Changes, audit.Change{Field: audit.FieldPassword})
+	assert.NotContains(t, entries[1].Changes, audit.Change{Field: "id"})
+	assert.Contains(t, entries[2].Changes, audit.Change{Field: audit.FieldPassword})
+	assert.NotContains(t, entries[0].Changes, audit.Change{Field: audit.FieldPassword})
+
+	_, err = db.ExecContext(t.Context(), "DELETE FROM audit_log")
+	require.ErrorContains(t, err, "append-only")
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+)
+
+var _ idempotency.Store = new(IdempotencyStore)
+
+// IdempotencyStore keeps the idempotency records in the idempotency_keys table.
+type IdempotencyStore struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+	metrics queryMetrics
+}
+
+func NewIdempotencyStore(db *sql.DB, mp metric.MeterProvider) (IdempotencyStore, error) {
+	metrics, err := newQueryMetrics(mp)
+	if err != nil {
+		return IdempotencyStore{}, fmt.Errorf("error creating query metrics: %w", err)
+	}
+
+	return IdempotencyStore{
+		db:      db,
+		queries: sqlc.New(newInstrumentedDBTX(db, metrics)),
+		metrics: metrics,
+	}, nil
+}
+
+func (s IdempotencyStore) Reserve(ctx context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+	ctx, span := observability.StartSpan(ctx, "IdempotencyStore.Reserve")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return idempotency.Record{}, false, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := sqlc.New(newInstrumentedDBTX(tx, s.metrics))
+
+	err = qtx.DeleteIdempotencyKeyIfExpired(ctx, sqlc.DeleteIdempotencyKeyIfExpiredParams{
+		Scope:     record.Scope,
+		Key:       record.Key,
+		ExpiresAt: time.Now(),
+	})
+	if err != nil {
+		return idempotency.Record{}, false, fmt.Errorf("error deleting expired idempotency key: %w", err)
+	}
+
+	created, err := qtx.CreateIdempotencyKey(ctx, sqlc.CreateIdempotencyKeyParams{
+		Scope:       record.Scope,
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		ExpiresAt:   record.ExpiresAt,
+	})
+	if err != nil {
+		return idempotency.Record{}, false, fmt.Errorf("error creating idempotency key: %w", err)
+	}
+
+	reserved := created > 0
+	if !reserved {
+		dao, errGet := qtx.GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{Scope: record.Scope, Key: record.Key})
+		if errGet != nil {
+			return idempotency.Record{}, false, fmt.Errorf("error getting idempotency key: %w", errGet)
+		}
+
+		record = transformIdempotencyKey(dao)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return idempotency.Record{}, false, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return record, reserved, nil
+}
+
+func (s IdempotencyStore) Complete(
+	ctx context.Context,
+	scope, key string,
+	statusCode int,
+	response []byte,
+) error {
+	ctx, span := observability.StartSpan(ctx, "IdempotencyStore.Complete")
+	defer span.End()
+
+	err := s.queries.CompleteIdempotencyKey(ctx, sqlc.CompleteIdempotencyKeyParams{
+		StatusCode: new(int64(statusCode)),
+		Response:   response,
+		Scope:      scope,
+		Key:        key,
+	})
+	if err != nil {
+		return fmt.Errorf("error completing idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (s IdempotencyStore) Release(ctx context.Context, scope, key string) error {
+	ctx, span := observability.StartSpan(ctx, "IdempotencyStore.Release")
+	defer span.End()
+
+	err := s.queries.DeleteIdempotencyKey(ctx, sqlc.DeleteIdempotencyKeyParams{Scope: scope, Key: key})
+	if err != nil {
+		return fmt.Errorf("error deleting idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (s IdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := observability.StartSpan(ctx, "IdempotencyStore.DeleteExpired")
+	defer span.End()
+
+	deleted, err := s.queries.DeleteExpiredIdempotencyKeys(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired idempotency keys: %w", err)
+	}
+
+	return deleted, nil
+}
+
+func transformIdempotencyKey(dao sqlc.IdempotencyKey) idempotency.Record {
+	record := idempotency.Record{
+		Scope:       dao.Scope,
+		Key:         dao.Key,
+		Fingerprint: dao.Fingerprint,
+		Response:    dao.Response,
+		ExpiresAt:   dao.ExpiresAt,
+	}
+
+	if dao.StatusCode != nil {
+		record.Completed = true
+		record.StatusCode = int(*dao.StatusCode)
+	}
+
+	return record
+}
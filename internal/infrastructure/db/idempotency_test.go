@@ -0,0 +1,127 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/idempotency"
+)
+
+func TestIdempotencyStore_Reserve(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		first            func(t *testing.T, s IdempotencyStore, record idempotency.Record)
+		expectedReserved bool
+		expected         idempotency.Record
+	}{
+		"new key is reserved": {
+			first:            func(*testing.T, IdempotencyStore, idempotency.Record) {},
+			expectedReserved: true,
+		},
+		"in progress key is not reserved": {
+			first: func(t *testing.T, s IdempotencyStore, record idempotency.Record) {
+				t.Helper()
+
+				_, _, err := s.Reserve(t.Context(), record)
+				require.NoError(t, err)
+			},
+			expectedReserved: false,
+		},
+		"completed key returns the response": {
+			first: func(t *testing.T, s IdempotencyStore, record idempotency.Record) {
+				t.Helper()
+
+				_, _, err := s.Reserve(t.Context(), record)
+				require.NoError(t, err)
+				require.NoError(t, s.Complete(t.Context(), record.Scope, record.Key, 201, []byte("response")))
+			},
+			expectedReserved: false,
+			expected:         idempotency.Record{Completed: true, StatusCode: 201, Response: []byte("response")},
+		},
+		"released key is reserved": {
+			first: func(t *testing.T, s IdempotencyStore, record idempotency.Record) {
+				t.Helper()
+
+				_, _, err := s.Reserve(t.Context(), record)
+				require.NoError(t, err)
+				require.NoError(t, s.Release(t.Context(), record.Scope, record.Key))
+			},
+			expectedReserved: true,
+		},
+		"expired key is reserved": {
+			first: func(t *testing.T, s IdempotencyStore, record idempotency.Record) {
+				t.Helper()
+
+				record.ExpiresAt = time.Now().Add(-time.Minute)
+				_, _, err := s.Reserve(t.Context(), record)
+				require.NoError(t, err)
+				require.NoError(t, s.Complete(t.Context(), record.Scope, record.Key, 201, []byte("response")))
+			},
+			expectedReserved: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+			require.NoError(t, err)
+
+			s, err := NewIdempotencyStore(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			record := idempotency.Record{
+				Scope:       "POST /api/v1/users ip:127.0.0.1",
+				Key:         "key",
+				Fingerprint: "fingerprint",
+				ExpiresAt:   time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+			}
+			test.first(t, s, record)
+
+			// Act
+			actual, reserved, err := s.Reserve(t.Context(), record)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedReserved, reserved)
+			assert.Equal(t, test.expected.Completed, actual.Completed)
+			assert.Equal(t, test.expected.StatusCode, actual.StatusCode)
+			assert.Equal(t, test.expected.Response, actual.Response)
+			assert.Equal(t, record.Fingerprint, actual.Fingerprint)
+		})
+	}
+}
+
+func TestIdempotencyStore_DeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	s, err := NewIdempotencyStore(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	for key, expiresAt := range map[string]time.Time{
+		"expired":     time.Now().Add(-time.Minute),
+		"not expired": time.Now().Add(time.Hour),
+	} {
+		_, _, err = s.Reserve(t.Context(), idempotency.Record{Scope: "scope", Key: key, ExpiresAt: expiresAt})
+		require.NoError(t, err)
+	}
+
+	// Act
+	deleted, err := s.DeleteExpired(t.Context(), time.Now())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
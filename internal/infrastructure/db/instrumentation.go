@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/info"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+)
+
+// unnamedQuery is the operation name for the queries not generated by sqlc.
+const unnamedQuery = "query"
+
+var _ sqlc.DBTX = new(instrumentedDBTX)
+
+type (
+	// instrumentedDBTX decorates a sqlc.DBTX, creating a span and recording metrics for each query.
+	instrumentedDBTX struct {
+		db      sqlc.DBTX
+		metrics queryMetrics
+	}
+
+	queryMetrics struct {
+		duration metric.Float64Histogram
+		failures metric.Int64Counter
+	}
+)
+
+func newQueryMetrics(mp metric.MeterProvider) (queryMetrics, error) {
+	meter := mp.Meter(info.AppName)
+
+	duration, err := meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of database client operations."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		return queryMetrics{}, fmt.Errorf("error creating duration histogram: %w", err)
+	}
+
+	failures, err := meter.Int64Counter(
+		"db.client.operation.errors",
+		metric.WithDescription("Number of database client operations that failed."),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		return queryMetrics{}, fmt.Errorf("error creating errors counter: %w", err)
+	}
+
+	return queryMetrics{
+		duration: duration,
+		failures: failures,
+	}, nil
+}
+
+func newInstrumentedDBTX(db sqlc.DBTX, metrics queryMetrics) instrumentedDBTX {
+	return instrumentedDBTX{
+		db:      db,
+		metrics: metrics,
+	}
+}
+
+//nolint:wrapcheck // decorator
+func (i instrumentedDBTX) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, end := i.start(ctx, query)
+
+	result, err := i.db.ExecContext(ctx, query, args...)
+	end(err)
+
+	return result, err
+}
+
+//nolint:wrapcheck // decorator
+func (i instrumentedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, end := i.start(ctx, query)
+
+	stmt, err := i.db.PrepareContext(ctx, query)
+	end(err)
+
+	return stmt, err
+}
+
+//nolint:wrapcheck // decorator
+func (i instrumentedDBTX) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, end := i.start(ctx, query)
+	// The query ends once its rows are read and closed, if they are tracked, so the span and the duration
+	// include fetching them, and the errors reading them are recorded.
+	ctx, tracked := observability.TrackRows(ctx, end)
+
+	rows, err := i.db.QueryContext(ctx, query, args...)
+	if err != nil || !tracked() {
+		end(err)
+	}
+
+	return rows, err
+}
+
+func (i instrumentedDBTX) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, end := i.start(ctx, query)
+	// The row is closed once it is scanned.
+	ctx, tracked := observability.TrackRows(ctx, end)
+
+	row := i.db.QueryRowContext(ctx, query, args...)
+	if row.Err() != nil || !tracked() {
+		end(row.Err())
+	}
+
+	return row
+}
+
+// start starts the span of the query, and returns the function to end it and record the metrics.
+func (i instrumentedDBTX) start(ctx context.Context, query string) (context.Context, func(error)) {
+	name := queryName(query)
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameSQLite,
+		semconv.DBOperationName(name),
+	}
+
+	ctx, span := observability.StartSpan(
+		ctx,
+		name,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attrs...),
+		oteltrace.WithAttributes(semconv.DBQueryText(query)),
+	)
+	start := time.Now()
+
+	return ctx, func(err error) {
+		defer span.End()
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			attrs = append(attrs, semconv.ErrorTypeOther)
+			i.metrics.failures.Add(ctx, 1, metric.WithAttributes(attrs...))
+		}
+
+		i.metrics.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+	}
+}
+
+// queryName returns the sqlc query name, e.g. GetUsers for "-- name: GetUsers :many".
+func queryName(query string) string {
+	header, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return unnamedQuery
+	}
+
+	name, _, _ := strings.Cut(header, " ")
+
+	return name
+}
+
+// RegisterPoolMetrics exports the connection pool statistics of the database as metrics.
+func RegisterPoolMetrics(db *sql.DB, mp metric.MeterProvider) error {
+	meter := mp.Meter(info.AppName)
+
+	connections, err := meter.Int64ObservableUpDownCounter(
+		"db.client.connection.count",
+		metric.WithDescription("The number of connections that are currently in state described by the state attribute."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating connection count: %w", err)
+	}
+
+	maxConnections, err := meter.Int64ObservableUpDownCounter(
+		"db.client.connection.max",
+		metric.WithDescription("The maximum number of open connections allowed."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating max connections: %w", err)
+	}
+
+	waitCount, err := meter.Int64ObservableCounter(
+		"db.client.connection.wait_count",
+		metric.WithDescription("The total number of connections waited for."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating wait count: %w", err)
+	}
+
+	waitDuration, err := meter.Float64ObservableCounter(
+		"db.client.connection.wait_time",
+		metric.WithDescription("The total time blocked waiting for a new connection."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating wait time: %w", err)
+	}
+
+	_, err = meter.RegisterCallback(
+		func(_ context.Context, o metric.Observer) error {
+			stats := db.Stats()
+			system := metric.WithAttributes(semconv.DBSystemNameSQLite)
+
+			o.ObserveInt64(
+				connections,
+				int64(stats.Idle),
+				metric.WithAttributes(semconv.DBSystemNameSQLite, semconv.DBClientConnectionStateIdle),
+			)
+			o.ObserveInt64(
+				connections,
+				int64(stats.InUse),
+				metric.WithAttributes(semconv.DBSystemNameSQLite, semconv.DBClientConnectionStateUsed),
+			)
+			o.ObserveInt64(maxConnections, int64(stats.MaxOpenConnections), system)
+			o.ObserveInt64(waitCount, stats.WaitCount, system)
+			o.ObserveFloat64(waitDuration, stats.WaitDuration.Seconds(), system)
+
+			return nil
+		},
+		connections, maxConnections, waitCount, waitDuration,
+	)
+	if err != nil {
+		return fmt.Errorf("error registering pool metrics callback: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestRepositoryQueryMetrics(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	r, err := NewRepository(db, mp)
+	require.NoError(t, err)
+
+	require.NoError(t, RegisterPoolMetrics(db, mp))
+
+	// Act
+	_, err = r.GetAll(t.Context(), pagination.MustPageRequest(0, 10), users.Filter{})
+	require.NoError(t, err)
+
+	// Assert
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+
+	operations := map[string]uint64{}
+	names := map[string]bool{}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names[m.Name] = true
+
+			histogram, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok || m.Name != "db.client.operation.duration" {
+				continue
+			}
+
+			for _, dp := range histogram.DataPoints {
+				operation, _ := dp.Attributes.Value(semconv.DBOperationNameKey)
+				operations[operation.AsString()] += dp.Count
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]uint64{"GetUsers": 1, "CountUsers": 1}, operations)
+	assert.True(t, names["db.client.connection.count"])
+}
+
+func TestRepositoryQueryMetrics_Exemplars(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	)
+
+	r, err := NewRepository(db, mp)
+	require.NoError(t, err)
+
+	tracer := sdktrace.NewTracerProvider().Tracer(t.Name())
+	ctx, span := tracer.Start(observability.AddContext(t.Context(), tracer), "request")
+
+	// Act
+	_, err = r.Count(ctx)
+	require.NoError(t, err)
+	span.End()
+
+	// Assert
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(t.Context(), &rm))
+
+	var exemplars []metricdata.Exemplar[float64]
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			histogram, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok || m.Name != "db.client.operation.duration" {
+				continue
+			}
+
+			for _, dp := range histogram.DataPoints {
+				exemplars = append(exemplars, dp.Exemplars...)
+			}
+		}
+	}
+
+	require.Len(t, exemplars, 1)
+
+	traceID := span.SpanContext().TraceID()
+	assert.Equal(t, traceID[:], exemplars[0].TraceID)
+}
+
+func TestQueryName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query    string
+		expected string
+	}{
+		"sqlc query": {
+			query:    "-- name: GetUsers :many\nSELECT * FROM users",
+			expected: "GetUsers",
+		},
+		"not sqlc query": {
+			query:    "SELECT 1",
+			expected: unnamedQuery,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual := queryName(test.query)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
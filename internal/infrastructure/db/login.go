@@ -0,0 +1,394 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+var _ login.Repository = new(LoginRepository)
+
+// dummyPasswordHash is compared with the passwords of the usernames no user has,
+// so the logins take as long whether the username exists or not, and the usernames are not disclosed.
+const dummyPasswordHash = "$2a$14$95QBeAWxrhRHtfWlp5DkZum6Yi/YjuKgk.dNPZqcZhYjJsYhxzYge" // #nosec G101
+
+// LoginRepository keeps the failed logins in the login_attempts table.
+// The lockouts and unlocks of the usernames of the users are recorded in their audit log in the same transaction.
+type LoginRepository struct {
+	users Repository
+	// mu serializes the changes of the login attempts, read and written in the same transaction,
+	// as SQLite fails the concurrent ones with the table locked instead of waiting for it.
+	mu *sync.Mutex
+}
+
+func NewLoginRepository(repository Repository) LoginRepository {
+	return LoginRepository{
+		users: repository,
+		mu:    new(sync.Mutex),
+	}
+}
+
+func (r LoginRepository) Authenticate(
+	ctx context.Context,
+	username users.Username,
+	password users.Password,
+) (users.User, error) {
+	ctx, span := observability.StartSpan(ctx, "LoginRepository.Authenticate")
+	defer span.End()
+
+	dao, err := r.users.queries.GetUserByUsername(ctx, sqlc.GetUserByUsernameParams{
+		TenantID:           tenantID(ctx),
+		UsernameNormalized: string(username.Normalize()),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			_ = password.Matches(dummyPasswordHash)
+
+			return users.User{}, users.ErrInvalidCredentials
+		}
+
+		return users.User{}, fmt.Errorf("error getting user by username: %w", err)
+	}
+
+	if !password.Matches(dao.Password) {
+		return users.User{}, users.ErrInvalidCredentials
+	}
+
+	return transformModel(dao), nil
+}
+
+func (r LoginRepository) GetAttempts(ctx context.Context, key login.Key) (login.Attempts, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"LoginRepository.GetAttempts",
+		oteltrace.WithAttributes(attribute.String("scope", string(key.Scope))),
+	)
+	defer span.End()
+
+	return getLoginAttempts(ctx, r.users.queries, key)
+}
+
+func (r LoginRepository) Reserve(
+	ctx context.Context,
+	key login.Key,
+	policy login.Policy,
+	now time.Time,
+) (login.Attempts, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"LoginRepository.Reserve",
+		oteltrace.WithAttributes(attribute.String("scope", string(key.Scope))),
+	)
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now = now.UTC()
+
+	var attempts login.Attempts
+
+	err := r.users.transaction(ctx, func(queries *sqlc.Queries) error {
+		current, errGet := getLoginAttempts(ctx, queries, key)
+		if errGet != nil {
+			return errGet
+		}
+
+		var errReserve error
+
+		attempts, errReserve = policy.Reserve(key, current, now)
+		if errReserve != nil {
+			return errReserve //nolint:wrapcheck // login.ThrottledError
+		}
+
+		return upsertLoginAttempts(ctx, queries, key, policy, attempts)
+	})
+	if err != nil {
+		return login.Attempts{}, err
+	}
+
+	return attempts, nil
+}
+
+func (r LoginRepository) Fail(
+	ctx context.Context,
+	key login.Key,
+	policy login.Policy,
+	now time.Time,
+) (login.Attempts, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"LoginRepository.Fail",
+		oteltrace.WithAttributes(attribute.String("scope", string(key.Scope))),
+	)
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now = now.UTC()
+
+	var attempts login.Attempts
+
+	err := r.users.transaction(ctx, func(queries *sqlc.Queries) error {
+		current, errGet := getLoginAttempts(ctx, queries, key)
+		if errGet != nil {
+			return errGet
+		}
+
+		attempts = policy.Fail(current, now)
+
+		errUpsert := upsertLoginAttempts(ctx, queries, key, policy, attempts)
+		if errUpsert != nil {
+			return errUpsert
+		}
+
+		if key.Scope != login.ScopeUsername || !attempts.IsLocked(now) || current.IsLocked(now) {
+			return nil
+		}
+
+		dao, errUser := queries.GetUserByUsername(ctx, sqlc.GetUserByUsernameParams{
+			TenantID:           tenantID(ctx),
+			UsernameNormalized: key.Value,
+		})
+		if errUser != nil {
+			// The usernames no user has are locked out too, so they can't be told apart, but they have no audit log.
+			if errors.Is(errUser, sql.ErrNoRows) {
+				return nil
+			}
+
+			return fmt.Errorf("error getting user by username: %w", errUser)
+		}
+
+		user := transformModel(dao)
+		entry := audit.NewEntry(ctx, audit.ActionLock, &user, &user, now)
+		entry.Changes = []audit.Change{{Field: "lockedUntil", After: new(formatLockedUntil(attempts))}}
+
+		return recordAudit(ctx, queries, entry)
+	})
+	if err != nil {
+		return login.Attempts{}, err
+	}
+
+	return attempts, nil
+}
+
+func (r LoginRepository) Release(ctx context.Context, key login.Key, policy login.Policy, now time.Time) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"LoginRepository.Release",
+		oteltrace.WithAttributes(attribute.String("scope", string(key.Scope))),
+	)
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.users.transaction(ctx, func(queries *sqlc.Queries) error {
+		current, errGet := getLoginAttempts(ctx, queries, key)
+		if errGet != nil {
+			return errGet
+		}
+
+		if current == (login.Attempts{}) {
+			return nil
+		}
+
+		return upsertLoginAttempts(ctx, queries, key, policy, current.Release(now.UTC()))
+	})
+}
+
+func (r LoginRepository) Reset(ctx context.Context, key login.Key) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"LoginRepository.Reset",
+		oteltrace.WithAttributes(attribute.String("scope", string(key.Scope))),
+	)
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return deleteLoginAttempts(ctx, r.users.queries, key)
+}
+
+func (r LoginRepository) GetLockout(ctx context.Context, id users.UserID) (login.Attempts, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"LoginRepository.GetLockout",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	dao, err := r.users.queries.GetUserByID(ctx, getUserByIDParams(ctx, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return login.Attempts{}, users.NotFoundError{ID: id}
+		}
+
+		return login.Attempts{}, fmt.Errorf("error getting user by id: %w", err)
+	}
+
+	return getLoginAttempts(ctx, r.users.queries, login.Key{Scope: login.ScopeUsername, Value: dao.UsernameNormalized})
+}
+
+func (r LoginRepository) Unlock(ctx context.Context, id users.UserID) (login.Attempts, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"LoginRepository.Unlock",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var attempts login.Attempts
+
+	err := r.users.transaction(ctx, func(queries *sqlc.Queries) error {
+		dao, errGet := queries.GetUserByID(ctx, getUserByIDParams(ctx, id))
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		key := login.Key{Scope: login.ScopeUsername, Value: dao.UsernameNormalized}
+
+		var errAttempts error
+
+		attempts, errAttempts = getLoginAttempts(ctx, queries, key)
+		if errAttempts != nil {
+			return errAttempts
+		}
+
+		errDelete := deleteLoginAttempts(ctx, queries, key)
+		if errDelete != nil {
+			return errDelete
+		}
+
+		now := time.Now().UTC()
+		if !attempts.IsLocked(now) {
+			return nil
+		}
+
+		user := transformModel(dao)
+		entry := audit.NewEntry(ctx, audit.ActionUnlock, &user, &user, now)
+		entry.Changes = []audit.Change{{Field: "lockedUntil", Before: new(formatLockedUntil(attempts))}}
+
+		return recordAudit(ctx, queries, entry)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return login.Attempts{}, users.NotFoundError{ID: id}
+		}
+
+		return login.Attempts{}, err
+	}
+
+	return attempts, nil
+}
+
+func (r LoginRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := observability.StartSpan(ctx, "LoginRepository.DeleteExpired")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted, err := r.users.queries.DeleteExpiredLoginAttempts(ctx, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired login attempts: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// getLoginAttempts gets the failed attempts of the key, zero if there are none.
+func getLoginAttempts(ctx context.Context, queries *sqlc.Queries, key login.Key) (login.Attempts, error) {
+	dao, err := queries.GetLoginAttempts(ctx, sqlc.GetLoginAttemptsParams{
+		Scope: string(key.Scope),
+		Value: attemptsValue(ctx, key),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return login.Attempts{}, nil
+		}
+
+		return login.Attempts{}, fmt.Errorf("error getting login attempts: %w", err)
+	}
+
+	return login.Attempts{
+		Failures:      int(dao.Failures),
+		LastFailureAt: dao.LastFailureAt,
+		RetryAt:       dao.RetryAt,
+		LockedUntil:   lo.FromPtr(dao.LockedUntil),
+		InFlight:      int(dao.InFlight),
+		InFlightUntil: dao.InFlightUntil.Time,
+	}, nil
+}
+
+// upsertLoginAttempts stores the attempts of the key, until they are forgotten with the policy.
+func upsertLoginAttempts(
+	ctx context.Context,
+	queries *sqlc.Queries,
+	key login.Key,
+	policy login.Policy,
+	attempts login.Attempts,
+) error {
+	err := queries.UpsertLoginAttempts(ctx, sqlc.UpsertLoginAttemptsParams{
+		Scope:         string(key.Scope),
+		Value:         attemptsValue(ctx, key),
+		Failures:      int64(attempts.Failures),
+		LastFailureAt: attempts.LastFailureAt,
+		RetryAt:       attempts.RetryAt,
+		LockedUntil:   lo.EmptyableToPtr(attempts.LockedUntil),
+		InFlight:      int64(attempts.InFlight),
+		InFlightUntil: sql.NullTime{Time: attempts.InFlightUntil, Valid: !attempts.InFlightUntil.IsZero()},
+		ExpiresAt:     attempts.ExpiresAt(policy),
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// deleteLoginAttempts deletes the login attempts of the key, ending its lockout.
+func deleteLoginAttempts(ctx context.Context, queries *sqlc.Queries, key login.Key) error {
+	err := queries.DeleteLoginAttempts(ctx, sqlc.DeleteLoginAttemptsParams{
+		Scope: string(key.Scope),
+		Value: attemptsValue(ctx, key),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// attemptsValue returns the value the failed attempts of the key are stored with.
+// The usernames are only unique per tenant, so theirs are prefixed with the tenant, e.g. "acme/john".
+func attemptsValue(ctx context.Context, key login.Key) string {
+	if key.Scope != login.ScopeUsername {
+		return key.Value
+	}
+
+	return tenantID(ctx) + "/" + key.Value
+}
+
+// formatLockedUntil returns the end of the lockout as recorded in the audit log.
+func formatLockedUntil(attempts login.Attempts) string {
+	return attempts.LockedUntil.UTC().Format(time.RFC3339Nano)
+}
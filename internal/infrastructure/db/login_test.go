@@ -0,0 +1,230 @@
+package db
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/audit"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestLoginRepository_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		username users.Username
+		password users.Password
+		wantErr  error
+	}{
+		"authenticated": {
+			username: "john",
+			password: "password",
+		},
+		"authenticated with the username in another case": {
+			username: "John",
+			password: "password",
+		},
+		"wrong password": {
+			username: "john",
+			password: "wrong-password",
+			wantErr:  users.ErrInvalidCredentials,
+		},
+		"unknown username": {
+			username: "jane",
+			password: "password",
+			wantErr:  users.ErrInvalidCredentials,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+			require.NoError(t, err)
+
+			r, err := NewRepository(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			john, err := r.Create(t.Context(), "john", "password", users.Profile{})
+			require.NoError(t, err)
+
+			// Act
+			user, err := NewLoginRepository(r).Authenticate(t.Context(), test.username, test.password)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, john.ID(), user.ID())
+		})
+	}
+}
+
+func TestLoginRepository_FailAndUnlock(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	a, err := NewAuditRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	lr := NewLoginRepository(r)
+
+	john, err := r.Create(t.Context(), "John", "password", users.Profile{})
+	require.NoError(t, err)
+
+	policy := login.Policy{MaxFailures: 2, LockoutDuration: time.Hour, BaseDelay: time.Second, Window: time.Hour}
+	now := time.Now()
+
+	// Act
+	first, errFirst := lr.Fail(t.Context(), login.KeyForUsername("john"), policy, now)
+	second, errSecond := lr.Fail(t.Context(), login.KeyForUsername("JOHN"), policy, now)
+	lockout, errLockout := lr.GetLockout(t.Context(), john.ID())
+	unlocked, errUnlock := lr.Unlock(t.Context(), john.ID())
+	afterUnlock, errAfterUnlock := lr.GetLockout(t.Context(), john.ID())
+
+	// Assert
+	require.NoError(t, errFirst)
+	assert.Equal(t, 1, first.Failures)
+	assert.False(t, first.IsLocked(now))
+
+	require.NoError(t, errSecond)
+	assert.Equal(t, 2, second.Failures)
+	assert.True(t, second.IsLocked(now))
+
+	require.NoError(t, errLockout)
+	assert.Equal(t, 2, lockout.Failures)
+	assert.True(t, lockout.IsLocked(now))
+
+	require.NoError(t, errUnlock)
+	assert.True(t, unlocked.IsLocked(now))
+
+	require.NoError(t, errAfterUnlock)
+	assert.Equal(t, login.Attempts{}, afterUnlock)
+
+	page, err := a.GetByUserID(t.Context(), john.ID(), pagination.MustPageRequest(0, 10))
+	require.NoError(t, err)
+
+	entries := page.Content()
+	require.Len(t, entries, 3)
+	assert.Equal(t, audit.ActionUnlock, entries[0].Action)
+	assert.Equal(t, audit.ActionLock, entries[1].Action)
+	assert.Equal(t, audit.ActionCreate, entries[2].Action)
+
+	lockedUntil := second.LockedUntil.UTC().Format(time.RFC3339Nano)
+	assert.Equal(t, []audit.Change{{Field: "lockedUntil", Before: new(lockedUntil)}}, entries[0].Changes)
+	assert.Equal(t, []audit.Change{{Field: "lockedUntil", After: new(lockedUntil)}}, entries[1].Changes)
+}
+
+func TestLoginRepository_DeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	lr := NewLoginRepository(r)
+
+	now := time.Now()
+	policy := login.Policy{MaxFailures: 5, Window: time.Hour}
+	_, err = lr.Fail(t.Context(), login.KeyForIP("192.0.2.1"), policy, now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	_, err = lr.Fail(t.Context(), login.KeyForIP("192.0.2.2"), policy, now)
+	require.NoError(t, err)
+
+	// Act
+	deleted, err := lr.DeleteExpired(t.Context(), now)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	expired, err := lr.GetAttempts(t.Context(), login.KeyForIP("192.0.2.1"))
+	require.NoError(t, err)
+	assert.Equal(t, login.Attempts{}, expired)
+
+	remaining, err := lr.GetAttempts(t.Context(), login.KeyForIP("192.0.2.2"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, remaining.Failures)
+}
+
+func TestLoginRepository_Reserve_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	lr := NewLoginRepository(r)
+
+	policy := login.Policy{MaxFailures: 5, LockoutDuration: time.Hour, BaseDelay: time.Second, Window: time.Hour}
+	key := login.KeyForUsername("john")
+	now := time.Now()
+
+	const concurrent = 10
+
+	var (
+		wg        sync.WaitGroup
+		reserved  atomic.Int32
+		throttled atomic.Int32
+		errs      = make(chan error, concurrent)
+	)
+
+	// Act
+	for range concurrent {
+		wg.Go(func() {
+			_, errReserve := lr.Reserve(t.Context(), key, policy, now)
+
+			switch _, ok := errors.AsType[login.ThrottledError](errReserve); {
+			case errReserve == nil:
+				reserved.Add(1)
+			case ok:
+				throttled.Add(1)
+			default:
+				errs <- errReserve
+			}
+		})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for errReserve := range errs {
+		require.NoError(t, errReserve)
+	}
+
+	assert.Equal(t, int32(1), reserved.Load())
+	assert.Equal(t, int32(concurrent-1), throttled.Load())
+
+	attempts, err := lr.GetAttempts(t.Context(), key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.InFlight)
+}
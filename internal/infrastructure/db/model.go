@@ -1,23 +1,41 @@
 package db
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/manuelarte/go-web-layout/internal/users"
 )
 
-type newUser struct {
-	username       users.Username
-	hashedPassword string
-}
+type (
+	newUser struct {
+		username       users.Username
+		hashedPassword string
+		profile        users.Profile
+	}
+
+	userUpdate struct {
+		username           sql.NullString
+		usernameNormalized sql.NullString
+		hashedPassword     sql.NullString
+		email              sql.NullString
+		emailNormalized    sql.NullString
+		displayName        sql.NullString
+		locale             sql.NullString
+		avatarURL          sql.NullString
+	}
+)
 
 // newNewUser creates a new user. Returns the user or a validation error.
-func newNewUser(u users.Username, p users.Password) (newUser, error) {
+func newNewUser(u users.Username, p users.Password, profile users.Profile) (newUser, error) {
 	errUsername := u.IsValid()
 	errPassword := p.IsValid()
+	errProfile := profile.IsValid()
 
-	if err := errors.Join(errUsername, errPassword); err != nil {
+	if err := errors.Join(errUsername, errPassword, errProfile); err != nil {
 		return newUser{}, err
 	}
 
@@ -26,5 +44,79 @@ func newNewUser(u users.Username, p users.Password) (newUser, error) {
 		return newUser{}, fmt.Errorf("error hashing password: %w", errHash)
 	}
 
-	return newUser{username: u, hashedPassword: hashedPassword}, nil
+	return newUser{username: u, hashedPassword: hashedPassword, profile: profile}, nil
+}
+
+// newNewUsers creates the new users of the rows, hashing their passwords concurrently.
+// Returns, for each row, either the new user or its validation error.
+func newNewUsers(rows []users.ImportRow) ([]newUser, []error) {
+	nus := make([]newUser, len(rows))
+	errs := make([]error, len(rows))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+
+	var wg sync.WaitGroup
+
+	for i, row := range rows {
+		if row.Err != nil {
+			errs[i] = row.Err
+
+			continue
+		}
+
+		sem <- struct{}{}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			nus[i], errs[i] = newNewUser(row.Username, row.Password, row.Profile)
+		})
+	}
+
+	wg.Wait()
+
+	return nus, errs
+}
+
+// newUserUpdate creates the fields to be updated. Returns them or a validation error.
+func newUserUpdate(update users.Update) (userUpdate, error) {
+	err := update.IsValid()
+	if err != nil {
+		return userUpdate{}, fmt.Errorf("invalid user update: %w", err)
+	}
+
+	var uu userUpdate
+
+	if update.Username != nil {
+		uu.username = sql.NullString{String: string(*update.Username), Valid: true}
+		uu.usernameNormalized = sql.NullString{String: string(update.Username.Normalize()), Valid: true}
+	}
+
+	if update.Password != nil {
+		hashedPassword, errHash := update.Password.Hash()
+		if errHash != nil {
+			return userUpdate{}, fmt.Errorf("error hashing password: %w", errHash)
+		}
+
+		uu.hashedPassword = sql.NullString{String: hashedPassword, Valid: true}
+	}
+
+	if update.Email != nil {
+		uu.email = sql.NullString{String: string(*update.Email), Valid: true}
+		uu.emailNormalized = sql.NullString{String: string(update.Email.Normalize()), Valid: true}
+	}
+
+	uu.displayName = nullString(update.DisplayName)
+	uu.locale = nullString(update.Locale)
+	uu.avatarURL = nullString(update.AvatarURL)
+
+	return uu, nil
+}
+
+// nullString returns the value as a string, null if it is nil.
+func nullString[T ~string](value *T) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(*value), Valid: true}
 }
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+	"github.com/manuelarte/go-web-layout/internal/outbox"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// publishedSeparator separates the publishers that delivered an entry in the published column.
+const publishedSeparator = ','
+
+var _ outbox.Store = new(OutboxStore)
+
+// OutboxStore keeps the user events pending to be delivered in the outbox table.
+type OutboxStore struct {
+	queries *sqlc.Queries
+}
+
+func NewOutboxStore(db *sql.DB, mp metric.MeterProvider) (OutboxStore, error) {
+	metrics, err := newQueryMetrics(mp)
+	if err != nil {
+		return OutboxStore{}, fmt.Errorf("error creating query metrics: %w", err)
+	}
+
+	return OutboxStore{
+		queries: sqlc.New(newInstrumentedDBTX(db, metrics)),
+	}, nil
+}
+
+func (s OutboxStore) Pending(ctx context.Context, now time.Time, limit int) ([]outbox.Entry, error) {
+	ctx, span := observability.StartSpan(ctx, "OutboxStore.Pending")
+	defer span.End()
+
+	daos, err := s.queries.GetPendingOutboxEntries(ctx, sqlc.GetPendingOutboxEntriesParams{
+		Now:   now.UTC(),
+		Limit: int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting pending outbox entries: %w", err)
+	}
+
+	entries := make([]outbox.Entry, 0, len(daos))
+
+	for _, dao := range daos {
+		event, errEvent := outbox.UnmarshalEvent(dao.Payload)
+		if errEvent != nil {
+			return nil, fmt.Errorf("error reading outbox entry %d: %w", dao.ID, errEvent)
+		}
+
+		entries = append(entries, outbox.Entry{
+			ID:        dao.ID,
+			Event:     event,
+			Attempts:  int(dao.Attempts),
+			Published: strings.FieldsFunc(dao.Published, func(r rune) bool { return r == publishedSeparator }),
+			CreatedAt: dao.CreatedAt,
+		})
+	}
+
+	return entries, nil
+}
+
+func (s OutboxStore) Published(ctx context.Context, id int64, publisher string) error {
+	ctx, span := observability.StartSpan(ctx, "OutboxStore.Published")
+	defer span.End()
+
+	err := s.queries.PublishOutboxEntry(ctx, sqlc.PublishOutboxEntryParams{
+		Publisher: publisher,
+		ID:        id,
+	})
+	if err != nil {
+		return fmt.Errorf("error updating outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s OutboxStore) Delivered(ctx context.Context, id int64) error {
+	ctx, span := observability.StartSpan(ctx, "OutboxStore.Delivered")
+	defer span.End()
+
+	err := s.queries.DeleteOutboxEntry(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error deleting outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s OutboxStore) Failed(ctx context.Context, id int64, retryAt time.Time, reason string) error {
+	ctx, span := observability.StartSpan(ctx, "OutboxStore.Failed")
+	defer span.End()
+
+	err := s.queries.FailOutboxEntry(ctx, sqlc.FailOutboxEntryParams{
+		NextAttemptAt: retryAt.UTC(),
+		LastError:     sql.NullString{String: reason, Valid: true},
+		ID:            id,
+	})
+	if err != nil {
+		return fmt.Errorf("error updating outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (s OutboxStore) Lag(ctx context.Context) (outbox.Lag, error) {
+	ctx, span := observability.StartSpan(ctx, "OutboxStore.Lag")
+	defer span.End()
+
+	pending, err := s.queries.CountOutboxEntries(ctx)
+	if err != nil {
+		return outbox.Lag{}, fmt.Errorf("error counting outbox entries: %w", err)
+	}
+
+	oldest, err := s.queries.GetOldestOutboxEntry(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return outbox.Lag{}, nil
+		}
+
+		return outbox.Lag{}, fmt.Errorf("error getting oldest outbox entry: %w", err)
+	}
+
+	return outbox.Lag{Pending: pending, Oldest: oldest.CreatedAt}, nil
+}
+
+// recordEvents records the events in the outbox, with the queries of the transaction of the changes.
+func recordEvents(ctx context.Context, queries *sqlc.Queries, events ...users.Event) error {
+	now := time.Now().UTC()
+
+	for _, event := range events {
+		payload, err := outbox.MarshalEvent(event)
+		if err != nil {
+			return fmt.Errorf("error recording %s event: %w", event.Type, err)
+		}
+
+		err = queries.CreateOutboxEntry(ctx, sqlc.CreateOutboxEntryParams{
+			AggregateID: uuid.UUID(event.UserID),
+			EventType:   string(event.Type),
+			Payload:     payload,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return fmt.Errorf("error recording %s event: %w", event.Type, err)
+		}
+	}
+
+	return nil
+}
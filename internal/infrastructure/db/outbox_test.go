@@ -0,0 +1,151 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/outbox"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestOutboxStore_Pending(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		arrange      func(t *testing.T, s OutboxStore, first outbox.Entry)
+		expectedType []users.EventType
+	}{
+		"only the first event of the user is pending": {
+			arrange:      func(*testing.T, OutboxStore, outbox.Entry) {},
+			expectedType: []users.EventType{users.EventCreated},
+		},
+		"the next event is pending once the first is delivered": {
+			arrange: func(t *testing.T, s OutboxStore, first outbox.Entry) {
+				t.Helper()
+
+				require.NoError(t, s.Delivered(t.Context(), first.ID))
+			},
+			expectedType: []users.EventType{users.EventUpdated},
+		},
+		"a failed event holds back the next ones until it is retried": {
+			arrange: func(t *testing.T, s OutboxStore, first outbox.Entry) {
+				t.Helper()
+
+				require.NoError(t, s.Failed(t.Context(), first.ID, time.Now().Add(time.Hour), "unavailable"))
+			},
+			expectedType: []users.EventType{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+			require.NoError(t, err)
+
+			r, err := NewRepository(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			s, err := NewOutboxStore(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			created, err := r.Create(t.Context(), "john", "12345678", users.Profile{})
+			require.NoError(t, err)
+
+			_, err = r.Update(t.Context(), created.ID(), created.Version(), users.Update{Username: new(users.Username("jane"))})
+			require.NoError(t, err)
+
+			pending, err := s.Pending(t.Context(), time.Now(), 10)
+			require.NoError(t, err)
+			require.Len(t, pending, 1)
+			assert.Equal(t, created, pending[0].Event.User)
+
+			test.arrange(t, s, pending[0])
+
+			// Act
+			actual, err := s.Pending(t.Context(), time.Now(), 10)
+
+			// Assert
+			require.NoError(t, err)
+
+			types := make([]users.EventType, 0, len(actual))
+			for _, entry := range actual {
+				assert.Equal(t, created.ID(), entry.Event.UserID)
+
+				types = append(types, entry.Event.Type)
+			}
+
+			assert.Equal(t, test.expectedType, types)
+		})
+	}
+}
+
+func TestOutboxStore_Lag(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	s, err := NewOutboxStore(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	empty, err := s.Lag(t.Context())
+	require.NoError(t, err)
+
+	created, err := r.Create(t.Context(), "john", "12345678", users.Profile{})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Delete(t.Context(), created.ID(), created.Version()))
+
+	// Act
+	actual, err := s.Lag(t.Context())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, outbox.Lag{}, empty)
+	assert.Equal(t, int64(2), actual.Pending)
+	assert.WithinDuration(t, time.Now(), actual.Oldest, time.Minute)
+}
+
+func TestOutboxStore_Published(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	s, err := NewOutboxStore(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	_, err = r.Create(t.Context(), "john", "12345678", users.Profile{})
+	require.NoError(t, err)
+
+	pending, err := s.Pending(t.Context(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	// Act
+	require.NoError(t, s.Published(t.Context(), pending[0].ID, "bus"))
+	require.NoError(t, s.Published(t.Context(), pending[0].ID, "webhooks"))
+
+	// Assert
+	actual, err := s.Pending(t.Context(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Empty(t, pending[0].Published)
+	assert.Equal(t, []string{"bus", "webhooks"}, actual[0].Published)
+}
@@ -5,35 +5,90 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"iter"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
 	"github.com/samber/lo"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	oteltrace "go.opentelemetry.io/otel/trace"
 
+	"github.com/manuelarte/go-web-layout/internal/audit"
 	"github.com/manuelarte/go-web-layout/internal/config/logging"
 	"github.com/manuelarte/go-web-layout/internal/config/observability"
 	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
 	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
 	"github.com/manuelarte/go-web-layout/internal/users"
 )
 
 var _ users.Repository = new(Repository)
 
+// allBatchSize is the number of users fetched at once when iterating over all of them.
+const allBatchSize = 500
+
+// Repository is the users repository, scoped to the tenant of the context of each call.
 type Repository struct {
 	db      *sql.DB
 	queries *sqlc.Queries
+	metrics queryMetrics
 }
 
-func NewRepository(db *sql.DB) Repository {
+// NewRepository creates the users repository.
+// The queries are traced, and their metrics recorded with the meter provider.
+func NewRepository(db *sql.DB, mp metric.MeterProvider) (Repository, error) {
+	metrics, err := newQueryMetrics(mp)
+	if err != nil {
+		return Repository{}, fmt.Errorf("error creating query metrics: %w", err)
+	}
+
 	return Repository{
 		db:      db,
-		queries: sqlc.New(db),
+		queries: sqlc.New(newInstrumentedDBTX(db, metrics)),
+		metrics: metrics,
+	}, nil
+}
+
+// Count counts all the users of the tenant, except the soft-deleted ones.
+func (r Repository) Count(ctx context.Context) (int64, error) {
+	ctx, span := observability.StartSpan(ctx, "Repository.Count")
+	defer span.End()
+
+	count, err := r.queries.CountUsers(ctx, sqlc.CountUsersParams{TenantID: tenantID(ctx), IncludeDeleted: false})
+	if err != nil {
+		return 0, fmt.Errorf("error counting users: %w", err)
 	}
+
+	return count, nil
 }
 
-func (r Repository) Create(ctx context.Context, u users.Username, p users.Password) (users.User, error) {
+func (r Repository) CountByTenant(ctx context.Context) (map[tenant.ID]int64, error) {
+	ctx, span := observability.StartSpan(ctx, "Repository.CountByTenant")
+	defer span.End()
+
+	rows, err := r.queries.CountUsersByTenant(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error counting users by tenant: %w", err)
+	}
+
+	counts := make(map[tenant.ID]int64, len(rows))
+	for _, row := range rows {
+		counts[tenant.ID(row.TenantID)] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (r Repository) Create(
+	ctx context.Context,
+	u users.Username,
+	p users.Password,
+	profile users.Profile,
+) (users.User, error) {
 	ctx, span := observability.StartSpan(ctx, "Repository.Create")
 	defer span.End()
 
@@ -44,28 +99,128 @@ func (r Repository) Create(ctx context.Context, u users.Username, p users.Passwo
 		},
 	)
 
-	nu, err := newNewUser(u, p)
+	nu, err := newNewUser(u, p, profile)
 	if err != nil {
 		return users.User{}, fmt.Errorf("error validating new user fields: %w", err)
 	}
 
-	created, err := r.queries.CreateUser(ctx, sqlc.CreateUserParams{
-		ID:       uuid.New(),
-		Username: string(nu.username),
-		Password: nu.hashedPassword,
+	var created users.User
+
+	err = r.transaction(ctx, func(queries *sqlc.Queries) error {
+		dao, errCreate := queries.CreateUser(ctx, createUserParams(ctx, nu))
+		if errCreate != nil {
+			return takenError(errCreate, nu.username, nu.profile.Email, "error creating user")
+		}
+
+		created = transformModel(dao)
+		entry := audit.NewEntry(ctx, audit.ActionCreate, nil, &created, created.CreatedAt())
+		entry.Changes = append(entry.Changes, audit.DiffPassword("", dao.Password)...)
+
+		return recordChange(ctx, queries, users.NewCreatedEvent(created), entry)
 	})
 	if err != nil {
-		return users.User{}, fmt.Errorf("error creating user: %w", err)
+		return users.User{}, err
 	}
 
-	return transformModel(created), nil
+	return created, nil
 }
 
-func (r Repository) GetAll(ctx context.Context, pr pagination.PageRequest) (pagination.Page[users.User], error) {
+func (r Repository) CreateMany(ctx context.Context, rows []users.ImportRow) ([]users.ImportResult, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"Repository.CreateMany",
+		oteltrace.WithAttributes(attribute.Int("rows", len(rows))),
+	)
+	defer span.End()
+
+	nus, errs := newNewUsers(rows)
+
+	results := make([]users.ImportResult, len(rows))
+
+	err := r.transaction(ctx, func(queries *sqlc.Queries) error {
+		for i, row := range rows {
+			results[i].Line = row.Line
+
+			if errs[i] != nil {
+				results[i].Err = fmt.Errorf("error validating new user fields: %w", errs[i])
+
+				continue
+			}
+
+			created, errCreate := queries.CreateUser(ctx, createUserParams(ctx, nus[i]))
+			if errCreate != nil {
+				results[i].Err = takenError(errCreate, nus[i].username, nus[i].profile.Email, "error creating user")
+
+				continue
+			}
+
+			results[i].User = transformModel(created)
+			entry := audit.NewEntry(ctx, audit.ActionCreate, nil, &results[i].User, results[i].User.CreatedAt())
+			entry.Changes = append(entry.Changes, audit.DiffPassword("", created.Password)...)
+
+			errRecord := recordChange(ctx, queries, users.NewCreatedEvent(results[i].User), entry)
+			if errRecord != nil {
+				return errRecord
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (r Repository) All(ctx context.Context) iter.Seq2[users.User, error] {
+	return func(yield func(users.User, error) bool) {
+		spanCtx, span := observability.StartSpan(ctx, "Repository.All")
+		defer span.End()
+
+		// The users are walked in id order, each batch starting after the last id of the previous one.
+		after := uuid.Nil
+
+		for {
+			batch, err := r.queries.GetUsersAfterID(spanCtx, sqlc.GetUsersAfterIDParams{
+				TenantID: tenantID(spanCtx),
+				ID:       after,
+				Limit:    allBatchSize,
+			})
+			if err != nil {
+				yield(users.User{}, fmt.Errorf("error getting users: %w", err))
+
+				return
+			}
+
+			for _, dao := range batch {
+				if !yield(transformModel(dao), nil) {
+					return
+				}
+			}
+
+			if len(batch) < allBatchSize {
+				return
+			}
+
+			after = batch[len(batch)-1].ID
+		}
+	}
+}
+
+func (r Repository) GetAll(
+	ctx context.Context,
+	pr pagination.PageRequest,
+	filter users.Filter,
+) (pagination.Page[users.User], error) {
 	ctx, span := observability.StartSpan(
 		ctx,
 		"Repository.GetAll",
-		oteltrace.WithAttributes(attribute.Int("page", pr.Page()), attribute.Int("size", pr.Size())),
+		oteltrace.WithAttributes(
+			attribute.Int("page", pr.Page()),
+			attribute.Int("size", pr.Size()),
+			attribute.Bool("includeDeleted", filter.IncludeDeleted),
+		),
 	)
 	defer span.End()
 
@@ -80,18 +235,25 @@ func (r Repository) GetAll(ctx context.Context, pr pagination.PageRequest) (pagi
 		}
 	}(tx)
 
-	uDao, err := r.queries.WithTx(tx).GetUsers(
+	queries := r.withTx(tx)
+
+	uDao, err := queries.GetUsers(
 		ctx,
 		sqlc.GetUsersParams{
-			Limit:  int64(pr.Size()),
-			Offset: int64(pr.Offset()),
+			TenantID:       tenantID(ctx),
+			IncludeDeleted: filter.IncludeDeleted,
+			Limit:          int64(pr.Size()),
+			Offset:         int64(pr.Offset()),
 		},
 	)
 	if err != nil {
 		return pagination.Page[users.User]{}, fmt.Errorf("error getting users: %w", err)
 	}
 
-	count, err := r.queries.WithTx(tx).CountUsers(ctx)
+	count, err := queries.CountUsers(ctx, sqlc.CountUsersParams{
+		TenantID:       tenantID(ctx),
+		IncludeDeleted: filter.IncludeDeleted,
+	})
 	if err != nil {
 		return pagination.Page[users.User]{}, fmt.Errorf("error counting users: %w", err)
 	}
@@ -108,6 +270,21 @@ func (r Repository) GetAll(ctx context.Context, pr pagination.PageRequest) (pagi
 	return pagination.MustPage(usersMapped, pr, count), nil
 }
 
+func (r Repository) IsUsernameTaken(ctx context.Context, username users.Username) (bool, error) {
+	ctx, span := observability.StartSpan(ctx, "Repository.IsUsernameTaken")
+	defer span.End()
+
+	taken, err := r.queries.IsUsernameTaken(ctx, sqlc.IsUsernameTakenParams{
+		TenantID:           tenantID(ctx),
+		UsernameNormalized: string(username.Normalize()),
+	})
+	if err != nil {
+		return false, fmt.Errorf("error checking if username is taken: %w", err)
+	}
+
+	return taken, nil
+}
+
 func (r Repository) GetByID(ctx context.Context, id users.UserID) (users.User, error) {
 	ctx, span := observability.StartSpan(
 		ctx,
@@ -116,7 +293,7 @@ func (r Repository) GetByID(ctx context.Context, id users.UserID) (users.User, e
 	)
 	defer span.End()
 
-	dao, err := r.queries.GetUserByID(ctx, uuid.UUID(id))
+	dao, err := r.queries.GetUserByID(ctx, getUserByIDParams(ctx, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return users.User{}, users.NotFoundError{ID: id}
@@ -128,11 +305,375 @@ func (r Repository) GetByID(ctx context.Context, id users.UserID) (users.User, e
 	return transformModel(dao), nil
 }
 
+func (r Repository) Update(
+	ctx context.Context,
+	id users.UserID,
+	version int64,
+	update users.Update,
+) (users.User, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"Repository.Update",
+		oteltrace.WithAttributes(attribute.String("id", id.String()), attribute.Int64("version", version)),
+	)
+	defer span.End()
+
+	uu, err := newUserUpdate(update)
+	if err != nil {
+		return users.User{}, fmt.Errorf("error validating user fields: %w", err)
+	}
+
+	var updated users.User
+
+	err = r.transaction(ctx, func(queries *sqlc.Queries) error {
+		current, errGet := queries.GetUserByID(ctx, getUserByIDParams(ctx, id))
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		dao, errUpdate := queries.UpdateUser(ctx, sqlc.UpdateUserParams{
+			Username:           uu.username,
+			UsernameNormalized: uu.usernameNormalized,
+			Password:           uu.hashedPassword,
+			Email:              uu.email,
+			EmailNormalized:    uu.emailNormalized,
+			DisplayName:        uu.displayName,
+			Locale:             uu.locale,
+			AvatarUrl:          uu.avatarURL,
+			UpdatedAt:          time.Now().UTC(),
+			TenantID:           tenantID(ctx),
+			ID:                 uuid.UUID(id),
+			Version:            version,
+		})
+		if errUpdate != nil {
+			return takenError(
+				errUpdate,
+				users.Username(uu.username.String),
+				users.Email(uu.email.String),
+				"error updating user",
+			)
+		}
+
+		before := transformModel(current)
+		updated = transformModel(dao)
+
+		entry := audit.NewEntry(ctx, audit.ActionUpdate, &before, &updated, updated.UpdatedAt())
+		entry.Changes = append(entry.Changes, audit.DiffPassword(current.Password, dao.Password)...)
+
+		return recordChange(ctx, queries, users.NewUpdatedEvent(updated), entry)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, r.notModifiedError(ctx, id, version)
+		}
+
+		return users.User{}, err
+	}
+
+	return updated, nil
+}
+
+func (r Repository) Delete(ctx context.Context, id users.UserID, version int64) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"Repository.Delete",
+		oteltrace.WithAttributes(attribute.String("id", id.String()), attribute.Int64("version", version)),
+	)
+	defer span.End()
+
+	err := r.transaction(ctx, func(queries *sqlc.Queries) error {
+		current, errGet := queries.GetUserByID(ctx, getUserByIDParams(ctx, id))
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		now := time.Now().UTC()
+
+		deleted, errDelete := queries.DeleteUser(ctx, sqlc.DeleteUserParams{
+			DeletedAt: &now,
+			UpdatedAt: now,
+			TenantID:  tenantID(ctx),
+			ID:        uuid.UUID(id),
+			Version:   version,
+		})
+		if errDelete != nil {
+			return fmt.Errorf("error deleting user: %w", errDelete)
+		}
+
+		// As when updating, no rows means the user with the version does not exist.
+		if deleted == 0 {
+			return sql.ErrNoRows
+		}
+
+		before := transformModel(current)
+
+		return recordChange(
+			ctx,
+			queries,
+			users.NewDeletedEvent(id, now),
+			audit.NewEntry(ctx, audit.ActionDelete, &before, nil, now),
+		)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.notModifiedError(ctx, id, version)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r Repository) Restore(ctx context.Context, id users.UserID) (users.User, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"Repository.Restore",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	var restored users.User
+
+	err := r.transaction(ctx, func(queries *sqlc.Queries) error {
+		current, errGet := queries.GetUserByIDIncludingDeleted(ctx, sqlc.GetUserByIDIncludingDeletedParams{
+			TenantID: tenantID(ctx),
+			ID:       uuid.UUID(id),
+		})
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		if current.DeletedAt == nil {
+			return users.NotDeletedError{ID: id}
+		}
+
+		dao, errRestore := queries.RestoreUser(ctx, sqlc.RestoreUserParams{
+			UpdatedAt: time.Now().UTC(),
+			TenantID:  tenantID(ctx),
+			ID:        uuid.UUID(id),
+		})
+		if errRestore != nil {
+			return fmt.Errorf("error restoring user: %w", errRestore)
+		}
+
+		before := transformModel(current)
+		restored = transformModel(dao)
+
+		entry := audit.NewEntry(ctx, audit.ActionRestore, &before, &restored, restored.UpdatedAt())
+
+		return recordChange(ctx, queries, users.NewUpdatedEvent(restored), entry)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.User{}, users.NotFoundError{ID: id}
+		}
+
+		return users.User{}, err
+	}
+
+	return restored, nil
+}
+
+func (r Repository) Purge(ctx context.Context, id users.UserID) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"Repository.Purge",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	err := r.transaction(ctx, func(queries *sqlc.Queries) error {
+		current, errGet := queries.GetUserByIDIncludingDeleted(ctx, sqlc.GetUserByIDIncludingDeletedParams{
+			TenantID: tenantID(ctx),
+			ID:       uuid.UUID(id),
+		})
+		if errGet != nil {
+			return fmt.Errorf("error getting user by id: %w", errGet)
+		}
+
+		if current.DeletedAt == nil {
+			return users.NotDeletedError{ID: id}
+		}
+
+		dao, errPurge := queries.PurgeUser(ctx, sqlc.PurgeUserParams{TenantID: tenantID(ctx), ID: uuid.UUID(id)})
+		if errPurge != nil {
+			return fmt.Errorf("error purging user: %w", errPurge)
+		}
+
+		purged := transformModel(dao)
+
+		return recordAudit(ctx, queries, audit.NewEntry(ctx, audit.ActionPurge, &purged, nil, time.Now().UTC()))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.NotFoundError{ID: id}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// PurgeDeleted purges the users of all the tenants, it is run by the retention job.
+func (r Repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := observability.StartSpan(ctx, "Repository.PurgeDeleted")
+	defer span.End()
+
+	var purged int64
+
+	err := r.transaction(ctx, func(queries *sqlc.Queries) error {
+		deletedBefore := before.UTC()
+
+		daos, errPurge := queries.PurgeDeletedUsers(ctx, &deletedBefore)
+		if errPurge != nil {
+			return fmt.Errorf("error purging deleted users: %w", errPurge)
+		}
+
+		now := time.Now().UTC()
+		entries := make([]audit.Entry, 0, len(daos))
+
+		for _, dao := range daos {
+			user := transformModel(dao)
+			entry := audit.NewEntry(ctx, audit.ActionPurge, &user, nil, now)
+			entry.TenantID = tenant.ID(dao.TenantID)
+
+			entries = append(entries, entry)
+		}
+
+		purged = int64(len(daos))
+
+		return recordAudit(ctx, queries, entries...)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// notModifiedError returns why the user with the version was not modified:
+// either it does not exist, or it has a different version.
+func (r Repository) notModifiedError(ctx context.Context, id users.UserID, version int64) error {
+	_, err := r.queries.GetUserByID(ctx, getUserByIDParams(ctx, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return users.NotFoundError{ID: id}
+		}
+
+		return fmt.Errorf("error getting user by id: %w", err)
+	}
+
+	return users.VersionMismatchError{ID: id, Version: version}
+}
+
+// transaction runs fn with the queries of a transaction, that is committed if fn does not fail.
+// The user changes are made in a transaction to record their events in the outbox, and their audit entries, atomically.
+func (r Repository) transaction(ctx context.Context, fn func(queries *sqlc.Queries) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func(tx *sql.Tx) {
+		errRollback := tx.Rollback()
+		if errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to rollback transaction", slog.Any("err", errRollback))
+		}
+	}(tx)
+
+	err = fn(r.withTx(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// recordChange records the event and the audit entry of a change of a user, with the queries of its transaction.
+// The event is of the tenant of the context, as the users changed.
+func recordChange(ctx context.Context, queries *sqlc.Queries, event users.Event, entry audit.Entry) error {
+	event.TenantID = tenant.FromContext(ctx)
+
+	err := recordEvents(ctx, queries, event)
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(ctx, queries, entry)
+}
+
+// takenError returns EmailTakenError or UsernameTakenError if the error is the violation of the unique constraint
+// of the email or the username, otherwise the error wrapped with the message.
+func takenError(err error, username users.Username, email users.Email, msg string) error {
+	sqliteErr, ok := errors.AsType[sqlite3.Error](err)
+	if !ok || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	// SQLite only reports the violated constraint in the message, e.g. "UNIQUE constraint failed: users.email_normalized".
+	if strings.Contains(sqliteErr.Error(), "users.email_normalized") {
+		return users.EmailTakenError{Email: email}
+	}
+
+	return users.UsernameTakenError{Username: username}
+}
+
+func createUserParams(ctx context.Context, nu newUser) sqlc.CreateUserParams {
+	return sqlc.CreateUserParams{
+		ID:                 uuid.New(),
+		TenantID:           tenantID(ctx),
+		Username:           string(nu.username),
+		UsernameNormalized: string(nu.username.Normalize()),
+		Password:           nu.hashedPassword,
+		Email:              string(nu.profile.Email),
+		EmailNormalized:    string(nu.profile.Email.Normalize()),
+		DisplayName:        string(nu.profile.DisplayName),
+		Locale:             string(nu.profile.Locale),
+		AvatarUrl:          string(nu.profile.AvatarURL),
+	}
+}
+
+func getUserByIDParams(ctx context.Context, id users.UserID) sqlc.GetUserByIDParams {
+	return sqlc.GetUserByIDParams{TenantID: tenantID(ctx), ID: uuid.UUID(id)}
+}
+
+// tenantID returns the tenant of the context, whose users are the only ones the queries read and change.
+func tenantID(ctx context.Context) string {
+	return string(tenant.FromContext(ctx))
+}
+
+// withTx returns the queries to be run in the transaction.
+func (r Repository) withTx(tx *sql.Tx) *sqlc.Queries {
+	return sqlc.New(newInstrumentedDBTX(tx, r.metrics))
+}
+
 func transformModel(user sqlc.User) users.User {
-	return users.NewUser(
+	model := users.NewUser(
 		users.UserID(user.ID),
 		user.CreatedAt,
 		user.UpdatedAt,
 		users.Username(user.Username),
+		users.Profile{
+			Email:       users.Email(user.Email),
+			DisplayName: users.DisplayName(user.DisplayName),
+			Locale:      users.Locale(user.Locale),
+			AvatarURL:   users.AvatarURL(user.AvatarUrl),
+		},
+		user.Version,
 	)
+	if user.DeletedAt != nil {
+		model = model.WithDeletedAt(*user.DeletedAt)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		model = model.WithEmailVerifiedAt(*user.EmailVerifiedAt)
+	}
+
+	return model
 }
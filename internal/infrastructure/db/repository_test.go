@@ -2,14 +2,18 @@ package db
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
 
 	goweblayout "github.com/manuelarte/go-web-layout"
 	"github.com/manuelarte/go-web-layout/internal/config"
 	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
 	"github.com/manuelarte/go-web-layout/internal/users"
 )
 
@@ -36,11 +40,13 @@ func TestRepositoryGetAllSuccessful(t *testing.T) {
 			db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
 			require.NoError(t, err)
 
-			r := NewRepository(db)
+			r, err := NewRepository(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
 			pr := pagination.MustPageRequest(0, 10)
 
 			// Act
-			actual, err := r.GetAll(t.Context(), pr)
+			actual, err := r.GetAll(t.Context(), pr, users.Filter{})
 
 			// Assert
 			require.NoError(t, err)
@@ -56,7 +62,8 @@ func TestRepositoryGetByIDNotFound(t *testing.T) {
 	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
 	require.NoError(t, err)
 
-	r := NewRepository(db)
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
 
 	// Act
 	notFoundID := users.UserID(uuid.New())
@@ -66,3 +73,365 @@ func TestRepositoryGetByIDNotFound(t *testing.T) {
 	wantErr := users.NotFoundError{ID: notFoundID}
 	assert.Equal(t, wantErr, err)
 }
+
+func TestRepositoryUpdate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		id          func(created users.User) users.UserID
+		version     func(created users.User) int64
+		expectedErr func(created users.User) error
+	}{
+		"current version is updated": {
+			id:      func(created users.User) users.UserID { return created.ID() },
+			version: func(created users.User) int64 { return created.Version() },
+		},
+		"stale version is not updated": {
+			id:      func(created users.User) users.UserID { return created.ID() },
+			version: func(created users.User) int64 { return created.Version() - 1 },
+			expectedErr: func(created users.User) error {
+				return users.VersionMismatchError{ID: created.ID(), Version: created.Version() - 1}
+			},
+		},
+		"not existing user": {
+			id:      func(users.User) users.UserID { return users.UserID(uuid.Nil) },
+			version: func(created users.User) int64 { return created.Version() },
+			expectedErr: func(users.User) error {
+				return users.NotFoundError{ID: users.UserID(uuid.Nil)}
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+			require.NoError(t, err)
+
+			r, err := NewRepository(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			created, err := r.Create(t.Context(), "john", "password", users.Profile{})
+			require.NoError(t, err)
+
+			username := users.Username("johnny")
+
+			// Act
+			updated, err := r.Update(t.Context(), test.id(created), test.version(created), users.Update{Username: &username})
+
+			// Assert
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr(created), err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, username, updated.Username())
+			assert.Equal(t, created.Version()+1, updated.Version())
+			assert.True(t, updated.UpdatedAt().After(created.UpdatedAt()))
+		})
+	}
+}
+
+func TestRepositoryDelete(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	created, err := r.Create(t.Context(), "john", "password", users.Profile{})
+	require.NoError(t, err)
+
+	// Act
+	errStale := r.Delete(t.Context(), created.ID(), created.Version()+1)
+	errCurrent := r.Delete(t.Context(), created.ID(), created.Version())
+
+	// Assert
+	assert.Equal(t, users.VersionMismatchError{ID: created.ID(), Version: created.Version() + 1}, errStale)
+	require.NoError(t, errCurrent)
+
+	_, err = r.GetByID(t.Context(), created.ID())
+	assert.Equal(t, users.NotFoundError{ID: created.ID()}, err)
+}
+
+func TestRepositoryUsernameTaken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	john, err := r.Create(t.Context(), "John", "password", users.Profile{})
+	require.NoError(t, err)
+
+	jane, err := r.Create(t.Context(), "jane", "password", users.Profile{})
+	require.NoError(t, err)
+
+	// Act
+	_, errCreate := r.Create(t.Context(), "ＪＯＨＮ", "password", users.Profile{})
+	_, errUpdate := r.Update(t.Context(), jane.ID(), jane.Version(), users.Update{Username: new(users.Username("JOHN"))})
+	taken, errTaken := r.IsUsernameTaken(t.Context(), "john")
+	available, errAvailable := r.IsUsernameTaken(t.Context(), "johnny")
+
+	// Assert
+	assert.Equal(t, users.UsernameTakenError{Username: "ＪＯＨＮ"}, errCreate)
+	assert.Equal(t, users.UsernameTakenError{Username: "JOHN"}, errUpdate)
+	require.NoError(t, errTaken)
+	assert.True(t, taken)
+	require.NoError(t, errAvailable)
+	assert.False(t, available)
+
+	kept, err := r.GetByID(t.Context(), john.ID())
+	require.NoError(t, err)
+	assert.Equal(t, users.Username("John"), kept.Username())
+}
+
+func TestRepositoryEmailTaken(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	john, err := r.Create(t.Context(), "john", "password", users.Profile{
+		Email:       "John@example.com",
+		DisplayName: "John Doe",
+		Locale:      "en-US",
+		AvatarURL:   "https://example.com/john.png",
+	})
+	require.NoError(t, err)
+
+	// The users without email don't take it.
+	jane, err := r.Create(t.Context(), "jane", "password", users.Profile{})
+	require.NoError(t, err)
+
+	// Act
+	taken := users.Update{Email: new(users.Email("john@example.com"))}
+	_, errCreate := r.Create(t.Context(), "johnny", "password", users.Profile{Email: "john@EXAMPLE.com"})
+	_, errUpdate := r.Update(t.Context(), jane.ID(), jane.Version(), taken)
+	cleared, errClear := r.Update(t.Context(), john.ID(), john.Version(), users.Update{Email: new(users.Email(""))})
+
+	// Assert
+	assert.Equal(t, users.EmailTakenError{Email: "john@EXAMPLE.com"}, errCreate)
+	assert.Equal(t, users.EmailTakenError{Email: "john@example.com"}, errUpdate)
+	require.NoError(t, errClear)
+	assert.Equal(t, users.Profile{
+		DisplayName: "John Doe",
+		Locale:      "en-US",
+		AvatarURL:   "https://example.com/john.png",
+	}, cleared.Profile())
+
+	_, err = r.Update(t.Context(), jane.ID(), jane.Version(), taken)
+	require.NoError(t, err)
+}
+
+func TestRepositoryRestoreAndPurge(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	created, err := r.Create(t.Context(), "john", "password", users.Profile{})
+	require.NoError(t, err)
+
+	countBefore, err := r.Count(t.Context())
+	require.NoError(t, err)
+
+	// Act
+	errNotDeleted := r.Purge(t.Context(), created.ID())
+	require.NoError(t, r.Delete(t.Context(), created.ID(), created.Version()))
+
+	countDeleted, err := r.Count(t.Context())
+	require.NoError(t, err)
+
+	listed, err := r.GetAll(t.Context(), pagination.MustPageRequest(0, 50), users.Filter{IncludeDeleted: true})
+	require.NoError(t, err)
+
+	restored, errRestore := r.Restore(t.Context(), created.ID())
+	require.NoError(t, r.Delete(t.Context(), restored.ID(), restored.Version()))
+	errPurge := r.Purge(t.Context(), created.ID())
+
+	// Assert
+	assert.Equal(t, users.NotDeletedError{ID: created.ID()}, errNotDeleted)
+	assert.Equal(t, countBefore-1, countDeleted)
+
+	deleted, ok := lo.Find(listed.Content(), func(user users.User) bool { return user.ID() == created.ID() })
+	require.True(t, ok)
+
+	deletedAt, isDeleted := deleted.DeletedAt()
+	assert.True(t, isDeleted)
+	assert.Equal(t, created.Version()+1, deleted.Version())
+	assert.Equal(t, deletedAt, deleted.UpdatedAt())
+
+	require.NoError(t, errRestore)
+	assert.Equal(t, created.Version()+2, restored.Version())
+	assert.False(t, restored.UpdatedAt().Before(deletedAt))
+
+	_, isDeleted = restored.DeletedAt()
+	assert.False(t, isDeleted)
+
+	require.NoError(t, errPurge)
+
+	_, err = r.Restore(t.Context(), created.ID())
+	assert.Equal(t, users.NotFoundError{ID: created.ID()}, err)
+}
+
+func TestRepositoryPurgeDeleted(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	kept, err := r.Create(t.Context(), "john", "password", users.Profile{})
+	require.NoError(t, err)
+
+	purged, err := r.Create(t.Context(), "jane", "password", users.Profile{})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Delete(t.Context(), purged.ID(), purged.Version()))
+
+	// Act
+	retainedCount, errRetained := r.PurgeDeleted(t.Context(), time.Now().Add(-time.Hour))
+	purgedCount, errPurged := r.PurgeDeleted(t.Context(), time.Now())
+
+	// Assert
+	require.NoError(t, errRetained)
+	assert.Equal(t, int64(0), retainedCount)
+	require.NoError(t, errPurged)
+	assert.Equal(t, int64(1), purgedCount)
+
+	_, err = r.GetByID(t.Context(), kept.ID())
+	require.NoError(t, err)
+
+	_, err = r.Restore(t.Context(), purged.ID())
+	assert.Equal(t, users.NotFoundError{ID: purged.ID()}, err)
+}
+
+func TestRepositoryCreateMany(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	rows := []users.ImportRow{
+		{Line: 1, Username: "john", Password: "12345678"},
+		{Line: 2, Username: "jo", Password: "12345678"},
+		{Line: 3, Username: "manuelarte", Password: "12345678"},
+		{Line: 4, Err: assert.AnError},
+	}
+
+	// Act
+	actual, err := r.CreateMany(t.Context(), rows)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, actual, len(rows))
+
+	for i, result := range actual {
+		assert.Equal(t, rows[i].Line, result.Line)
+	}
+
+	require.NoError(t, actual[0].Err)
+	assert.Equal(t, users.Username("john"), actual[0].User.Username())
+	require.ErrorIs(t, actual[1].Err, users.ErrUsernameTooShort)
+	require.Error(t, actual[2].Err, "username already exists")
+	require.ErrorIs(t, actual[3].Err, assert.AnError)
+
+	created, err := r.GetByID(t.Context(), actual[0].User.ID())
+	require.NoError(t, err)
+	assert.Equal(t, actual[0].User.ID(), created.ID())
+}
+
+func TestRepositoryAll(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	count, err := r.Count(t.Context())
+	require.NoError(t, err)
+
+	// Act
+	var actual []users.User
+
+	for user, errAll := range r.All(t.Context()) {
+		require.NoError(t, errAll)
+
+		actual = append(actual, user)
+	}
+
+	// Assert
+	assert.Len(t, actual, int(count))
+	assert.IsIncreasing(t, lo.Map(actual, func(user users.User, _ int) string {
+		return user.ID().String()
+	}))
+}
+
+func TestRepositoryTenants(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	acme := tenant.WithID(t.Context(), "acme")
+	globex := tenant.WithID(t.Context(), "globex")
+
+	defaultCount, err := r.Count(t.Context())
+	require.NoError(t, err)
+
+	// Act
+	acmeJohn, errAcme := r.Create(acme, "john", "password", users.Profile{Email: "john@example.com"})
+	globexJohn, errGlobex := r.Create(globex, "John", "password", users.Profile{Email: "john@example.com"})
+	_, errTaken := r.Create(acme, "JOHN", "password", users.Profile{})
+
+	_, errOtherTenant := r.GetByID(globex, acmeJohn.ID())
+	errDeleteOtherTenant := r.Delete(globex, acmeJohn.ID(), acmeJohn.Version())
+
+	acmePage, errGetAll := r.GetAll(acme, pagination.MustPageRequest(0, 10), users.Filter{})
+	require.NoError(t, errGetAll)
+
+	counts, errCount := r.CountByTenant(t.Context())
+	require.NoError(t, errCount)
+
+	// Assert
+	require.NoError(t, errAcme)
+	require.NoError(t, errGlobex)
+	assert.Equal(t, users.UsernameTakenError{Username: "JOHN"}, errTaken)
+	assert.Equal(t, users.NotFoundError{ID: acmeJohn.ID()}, errOtherTenant)
+	assert.Equal(t, users.NotFoundError{ID: acmeJohn.ID()}, errDeleteOtherTenant)
+	assert.Equal(t, []users.User{acmeJohn}, acmePage.Content())
+	assert.Equal(t, map[tenant.ID]int64{tenant.Default: defaultCount, "acme": 1, "globex": 1}, counts)
+	assert.NotEqual(t, acmeJohn.ID(), globexJohn.ID())
+}
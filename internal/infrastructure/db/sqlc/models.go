@@ -5,15 +5,117 @@
 package sqlc
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID         uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Roles      string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+type AuditLog struct {
+	ID         int64
+	UserID     uuid.UUID
+	Actor      string
+	Action     string
+	Changes    []byte
+	RequestID  string
+	TraceID    string
+	OccurredAt time.Time
+	TenantID   string
+}
+
+type IdempotencyKey struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	StatusCode  *int64
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+type LoginAttempt struct {
+	Scope         string
+	Value         string
+	Failures      int64
+	LastFailureAt time.Time
+	RetryAt       time.Time
+	LockedUntil   *time.Time
+	ExpiresAt     time.Time
+	InFlight      int64
+	InFlightUntil sql.NullTime
+}
+
+type Outbox struct {
+	ID            int64
+	AggregateID   uuid.UUID
+	EventType     string
+	Payload       []byte
+	CreatedAt     time.Time
+	Attempts      int64
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	Published     string
+}
+
 type User struct {
+	ID                 uuid.UUID
+	TenantID           string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Username           string
+	Password           string
+	Version            int64
+	DeletedAt          *time.Time
+	UsernameNormalized string
+	Email              string
+	EmailNormalized    string
+	DisplayName        string
+	Locale             string
+	AvatarUrl          string
+	EmailVerifiedAt    *time.Time
+}
+
+type UserToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Purpose   string
+	Email     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int64
+	NextAttemptAt  time.Time
+	StatusCode     *int64
+	LastError      sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	TenantID       string
+}
+
+type WebhookSubscription struct {
 	ID        uuid.UUID
+	Url       string
+	Events    string
+	Secret    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Username  string
-	Password  string
+	TenantID  string
 }
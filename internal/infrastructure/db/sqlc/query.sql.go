@@ -7,90 +7,658 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys SET status_code = ?, response = ? WHERE scope = ? AND key = ?
+`
+
+type CompleteIdempotencyKeyParams struct {
+	StatusCode *int64
+	Response   []byte
+	Scope      string
+	Key        string
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.StatusCode,
+		arg.Response,
+		arg.Scope,
+		arg.Key,
+	)
+	return err
+}
+
+const countAPIKeys = `-- name: CountAPIKeys :one
+SELECT COUNT(*) FROM api_keys
+`
+
+func (q *Queries) CountAPIKeys(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAPIKeys)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countAuditEntriesByUserID = `-- name: CountAuditEntriesByUserID :one
+SELECT COUNT(*) FROM audit_log WHERE tenant_id = ? AND user_id = ?
+`
+
+type CountAuditEntriesByUserIDParams struct {
+	TenantID string
+	UserID   uuid.UUID
+}
+
+func (q *Queries) CountAuditEntriesByUserID(ctx context.Context, arg CountAuditEntriesByUserIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAuditEntriesByUserID, arg.TenantID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOutboxEntries = `-- name: CountOutboxEntries :one
+SELECT COUNT(*) FROM outbox
+`
+
+func (q *Queries) CountOutboxEntries(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOutboxEntries)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUsers = `-- name: CountUsers :one
 SELECT COUNT(*) FROM users
+WHERE tenant_id = ?1 AND (deleted_at IS NULL OR CAST(?2 AS boolean))
+`
+
+type CountUsersParams struct {
+	TenantID       string
+	IncludeDeleted bool
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers, arg.TenantID, arg.IncludeDeleted)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsersByTenant = `-- name: CountUsersByTenant :many
+SELECT tenant_id, COUNT(*) AS count FROM users WHERE deleted_at IS NULL GROUP BY tenant_id
+`
+
+type CountUsersByTenantRow struct {
+	TenantID string
+	Count    int64
+}
+
+func (q *Queries) CountUsersByTenant(ctx context.Context) ([]CountUsersByTenantRow, error) {
+	rows, err := q.db.QueryContext(ctx, countUsersByTenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountUsersByTenantRow
+	for rows.Next() {
+		var i CountUsersByTenantRow
+		if err := rows.Scan(&i.TenantID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countWebhookDeliveries = `-- name: CountWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries WHERE tenant_id = ? AND subscription_id = ?
+`
+
+type CountWebhookDeliveriesParams struct {
+	TenantID       string
+	SubscriptionID uuid.UUID
+}
+
+func (q *Queries) CountWebhookDeliveries(ctx context.Context, arg CountWebhookDeliveriesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countWebhookDeliveries, arg.TenantID, arg.SubscriptionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countWebhookSubscriptions = `-- name: CountWebhookSubscriptions :one
+SELECT COUNT(*) FROM webhook_subscriptions WHERE tenant_id = ?
 `
 
-func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countUsers)
+func (q *Queries) CountWebhookSubscriptions(ctx context.Context, tenantID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countWebhookSubscriptions, tenantID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    id, name, prefix, key_hash, roles
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING id, name, prefix, key_hash, roles, created_at, last_used_at, revoked_at
+`
+
+type CreateAPIKeyParams struct {
+	ID      uuid.UUID
+	Name    string
+	Prefix  string
+	KeyHash string
+	Roles   string
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, createAPIKey,
+		arg.ID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Roles,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Roles,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const createAuditEntry = `-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (
+    tenant_id, user_id, actor, action, changes, request_id, trace_id, occurred_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateAuditEntryParams struct {
+	TenantID   string
+	UserID     uuid.UUID
+	Actor      string
+	Action     string
+	Changes    []byte
+	RequestID  string
+	TraceID    string
+	OccurredAt time.Time
+}
+
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditEntry,
+		arg.TenantID,
+		arg.UserID,
+		arg.Actor,
+		arg.Action,
+		arg.Changes,
+		arg.RequestID,
+		arg.TraceID,
+		arg.OccurredAt,
+	)
+	return err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (
+    scope, key, fingerprint, expires_at
+) VALUES (
+  ?, ?, ?, ?
+)
+ON CONFLICT (scope, key) DO NOTHING
+`
+
+type CreateIdempotencyKeyParams struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	ExpiresAt   time.Time
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createIdempotencyKey,
+		arg.Scope,
+		arg.Key,
+		arg.Fingerprint,
+		arg.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createOutboxEntry = `-- name: CreateOutboxEntry :exec
+INSERT INTO outbox (
+    aggregate_id, event_type, payload, created_at, next_attempt_at
+) VALUES (
+  ?1, ?2, ?3, ?4, ?4
+)
+`
+
+type CreateOutboxEntryParams struct {
+	AggregateID uuid.UUID
+	EventType   string
+	Payload     []byte
+	CreatedAt   time.Time
+}
+
+func (q *Queries) CreateOutboxEntry(ctx context.Context, arg CreateOutboxEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createOutboxEntry,
+		arg.AggregateID,
+		arg.EventType,
+		arg.Payload,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
-    id, username, password
+    id, tenant_id, username, username_normalized, password, email, email_normalized, display_name, locale, avatar_url
 ) VALUES (
-  ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, created_at, updated_at, username, password
+RETURNING id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at
 `
 
 type CreateUserParams struct {
-	ID       uuid.UUID
-	Username string
-	Password string
+	ID                 uuid.UUID
+	TenantID           string
+	Username           string
+	UsernameNormalized string
+	Password           string
+	Email              string
+	EmailNormalized    string
+	DisplayName        string
+	Locale             string
+	AvatarUrl          string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.ID, arg.Username, arg.Password)
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.ID,
+		arg.TenantID,
+		arg.Username,
+		arg.UsernameNormalized,
+		arg.Password,
+		arg.Email,
+		arg.EmailNormalized,
+		arg.DisplayName,
+		arg.Locale,
+		arg.AvatarUrl,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
+		&i.TenantID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Username,
 		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT id, created_at, updated_at, username, password FROM users WHERE ID = ?
+const createUserToken = `-- name: CreateUserToken :exec
+INSERT INTO user_tokens (
+    token_hash, user_id, purpose, email, created_at, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
 `
 
-func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
-	var i User
+type CreateUserTokenParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Purpose   string
+	Email     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) error {
+	_, err := q.db.ExecContext(ctx, createUserToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.Purpose,
+		arg.Email,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    id, tenant_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, status_code, last_error,
+    created_at, updated_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateWebhookDeliveryParams struct {
+	ID             uuid.UUID
+	TenantID       string
+	SubscriptionID uuid.UUID
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int64
+	NextAttemptAt  time.Time
+	StatusCode     *int64
+	LastError      sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.ID,
+		arg.TenantID,
+		arg.SubscriptionID,
+		arg.EventType,
+		arg.Payload,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.StatusCode,
+		arg.LastError,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    id, tenant_id, url, events, secret
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING id, url, events, secret, created_at, updated_at, tenant_id
+`
+
+type CreateWebhookSubscriptionParams struct {
+	ID       uuid.UUID
+	TenantID string
+	Url      string
+	Events   string
+	Secret   string
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookSubscription,
+		arg.ID,
+		arg.TenantID,
+		arg.Url,
+		arg.Events,
+		arg.Secret,
+	)
+	var i WebhookSubscription
 	err := row.Scan(
 		&i.ID,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Username,
-		&i.Password,
+		&i.TenantID,
 	)
 	return i, err
 }
 
-const getUsers = `-- name: GetUsers :many
-SELECT id, created_at, updated_at, username, password FROM users LIMIT ? OFFSET ?
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= ?
 `
 
-type GetUsersParams struct {
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredLoginAttempts = `-- name: DeleteExpiredLoginAttempts :execrows
+DELETE FROM login_attempts WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredLoginAttempts(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredLoginAttempts, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredUserTokens = `-- name: DeleteExpiredUserTokens :execrows
+DELETE FROM user_tokens WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredUserTokens(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredUserTokens, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE scope = ? AND key = ?
+`
+
+type DeleteIdempotencyKeyParams struct {
+	Scope string
+	Key   string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.Scope, arg.Key)
+	return err
+}
+
+const deleteIdempotencyKeyIfExpired = `-- name: DeleteIdempotencyKeyIfExpired :exec
+DELETE FROM idempotency_keys WHERE scope = ? AND key = ? AND expires_at <= ?
+`
+
+type DeleteIdempotencyKeyIfExpiredParams struct {
+	Scope     string
+	Key       string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) DeleteIdempotencyKeyIfExpired(ctx context.Context, arg DeleteIdempotencyKeyIfExpiredParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKeyIfExpired, arg.Scope, arg.Key, arg.ExpiresAt)
+	return err
+}
+
+const deleteLoginAttempts = `-- name: DeleteLoginAttempts :exec
+DELETE FROM login_attempts WHERE scope = ? AND value = ?
+`
+
+type DeleteLoginAttemptsParams struct {
+	Scope string
+	Value string
+}
+
+func (q *Queries) DeleteLoginAttempts(ctx context.Context, arg DeleteLoginAttemptsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteLoginAttempts, arg.Scope, arg.Value)
+	return err
+}
+
+const deleteOutboxEntry = `-- name: DeleteOutboxEntry :exec
+DELETE FROM outbox WHERE id = ?
+`
+
+func (q *Queries) DeleteOutboxEntry(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteOutboxEntry, id)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :execrows
+UPDATE users SET
+    deleted_at = ?1,
+    updated_at = ?2,
+    version = version + 1
+WHERE tenant_id = ?3 AND id = ?4 AND version = ?5 AND deleted_at IS NULL
+`
+
+type DeleteUserParams struct {
+	DeletedAt *time.Time
+	UpdatedAt time.Time
+	TenantID  string
+	ID        uuid.UUID
+	Version   int64
+}
+
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser,
+		arg.DeletedAt,
+		arg.UpdatedAt,
+		arg.TenantID,
+		arg.ID,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUserTokens = `-- name: DeleteUserTokens :exec
+DELETE FROM user_tokens WHERE user_id = ? AND purpose = ?
+`
+
+type DeleteUserTokensParams struct {
+	UserID  uuid.UUID
+	Purpose string
+}
+
+func (q *Queries) DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTokens, arg.UserID, arg.Purpose)
+	return err
+}
+
+const deleteWebhookDeliveries = `-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE subscription_id = ?
+`
+
+func (q *Queries) DeleteWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhookDeliveries, subscriptionID)
+	return err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions WHERE tenant_id = ? AND id = ?
+`
+
+type DeleteWebhookSubscriptionParams struct {
+	TenantID string
+	ID       uuid.UUID
+}
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookSubscription, arg.TenantID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failOutboxEntry = `-- name: FailOutboxEntry :exec
+UPDATE outbox SET attempts = attempts + 1, next_attempt_at = ?, last_error = ? WHERE id = ?
+`
+
+type FailOutboxEntryParams struct {
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	ID            int64
+}
+
+func (q *Queries) FailOutboxEntry(ctx context.Context, arg FailOutboxEntryParams) error {
+	_, err := q.db.ExecContext(ctx, failOutboxEntry, arg.NextAttemptAt, arg.LastError, arg.ID)
+	return err
+}
+
+const getAPIKeyByPrefix = `-- name: GetAPIKeyByPrefix :one
+SELECT id, name, prefix, key_hash, roles, created_at, last_used_at, revoked_at FROM api_keys WHERE prefix = ?
+`
+
+func (q *Queries) GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyByPrefix, prefix)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Roles,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getAPIKeys = `-- name: GetAPIKeys :many
+SELECT id, name, prefix, key_hash, roles, created_at, last_used_at, revoked_at FROM api_keys ORDER BY created_at DESC, id LIMIT ? OFFSET ?
+`
+
+type GetAPIKeysParams struct {
 	Limit  int64
 	Offset int64
 }
 
-func (q *Queries) GetUsers(ctx context.Context, arg GetUsersParams) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getUsers, arg.Limit, arg.Offset)
+func (q *Queries) GetAPIKeys(ctx context.Context, arg GetAPIKeysParams) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, getAPIKeys, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []ApiKey
 	for rows.Next() {
-		var i User
+		var i ApiKey
 		if err := rows.Scan(
 			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Roles,
 			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Username,
-			&i.Password,
+			&i.LastUsedAt,
+			&i.RevokedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -104,3 +672,1095 @@ func (q *Queries) GetUsers(ctx context.Context, arg GetUsersParams) ([]User, err
 	}
 	return items, nil
 }
+
+const getAuditEntriesByUserID = `-- name: GetAuditEntriesByUserID :many
+SELECT id, user_id, actor, "action", changes, request_id, trace_id, occurred_at, tenant_id FROM audit_log WHERE tenant_id = ? AND user_id = ? ORDER BY id DESC LIMIT ? OFFSET ?
+`
+
+type GetAuditEntriesByUserIDParams struct {
+	TenantID string
+	UserID   uuid.UUID
+	Limit    int64
+	Offset   int64
+}
+
+func (q *Queries) GetAuditEntriesByUserID(ctx context.Context, arg GetAuditEntriesByUserIDParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditEntriesByUserID,
+		arg.TenantID,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Actor,
+			&i.Action,
+			&i.Changes,
+			&i.RequestID,
+			&i.TraceID,
+			&i.OccurredAt,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT scope, "key", fingerprint, status_code, response, created_at, expires_at FROM idempotency_keys WHERE scope = ? AND key = ?
+`
+
+type GetIdempotencyKeyParams struct {
+	Scope string
+	Key   string
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.Scope, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Scope,
+		&i.Key,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.Response,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getLoginAttempts = `-- name: GetLoginAttempts :one
+SELECT scope, value, failures, last_failure_at, retry_at, locked_until, expires_at, in_flight, in_flight_until FROM login_attempts WHERE scope = ? AND value = ?
+`
+
+type GetLoginAttemptsParams struct {
+	Scope string
+	Value string
+}
+
+func (q *Queries) GetLoginAttempts(ctx context.Context, arg GetLoginAttemptsParams) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, getLoginAttempts, arg.Scope, arg.Value)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Scope,
+		&i.Value,
+		&i.Failures,
+		&i.LastFailureAt,
+		&i.RetryAt,
+		&i.LockedUntil,
+		&i.ExpiresAt,
+		&i.InFlight,
+		&i.InFlightUntil,
+	)
+	return i, err
+}
+
+const getOldestOutboxEntry = `-- name: GetOldestOutboxEntry :one
+SELECT id, aggregate_id, event_type, payload, created_at, attempts, next_attempt_at, last_error, published FROM outbox ORDER BY id LIMIT 1
+`
+
+func (q *Queries) GetOldestOutboxEntry(ctx context.Context) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, getOldestOutboxEntry)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.AggregateID,
+		&i.EventType,
+		&i.Payload,
+		&i.CreatedAt,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+		&i.Published,
+	)
+	return i, err
+}
+
+const getPendingOutboxEntries = `-- name: GetPendingOutboxEntries :many
+SELECT id, aggregate_id, event_type, payload, created_at, attempts, next_attempt_at, last_error, published FROM outbox o
+WHERE o.next_attempt_at <= ?1
+  AND NOT EXISTS (SELECT 1 FROM outbox p WHERE p.aggregate_id = o.aggregate_id AND p.id < o.id)
+ORDER BY o.id
+LIMIT ?2
+`
+
+type GetPendingOutboxEntriesParams struct {
+	Now   time.Time
+	Limit int64
+}
+
+func (q *Queries) GetPendingOutboxEntries(ctx context.Context, arg GetPendingOutboxEntriesParams) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingOutboxEntries, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+			&i.Published,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingWebhookDeliveries = `-- name: GetPendingWebhookDeliveries :many
+SELECT id, subscription_id, event_type, payload, status, attempts, next_attempt_at, status_code, last_error, created_at, updated_at, tenant_id FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= ?1
+ORDER BY next_attempt_at, created_at
+LIMIT ?2
+`
+
+type GetPendingWebhookDeliveriesParams struct {
+	Now   time.Time
+	Limit int64
+}
+
+func (q *Queries) GetPendingWebhookDeliveries(ctx context.Context, arg GetPendingWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingWebhookDeliveries, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.StatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSubscribedWebhookSubscriptions = `-- name: GetSubscribedWebhookSubscriptions :many
+SELECT id, url, events, secret, created_at, updated_at, tenant_id FROM webhook_subscriptions
+WHERE tenant_id = ?1 AND ',' || events || ',' LIKE '%,' || CAST(?2 AS text) || ',%'
+ORDER BY created_at, id
+`
+
+type GetSubscribedWebhookSubscriptionsParams struct {
+	TenantID  string
+	EventType string
+}
+
+func (q *Queries) GetSubscribedWebhookSubscriptions(ctx context.Context, arg GetSubscribedWebhookSubscriptionsParams) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, getSubscribedWebhookSubscriptions, arg.TenantID, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at FROM users WHERE tenant_id = ? AND email_normalized = ? AND email_normalized != '' AND deleted_at IS NULL
+`
+
+type GetUserByEmailParams struct {
+	TenantID        string
+	EmailNormalized string
+}
+
+func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, arg.TenantID, arg.EmailNormalized)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at FROM users WHERE tenant_id = ? AND ID = ? AND deleted_at IS NULL
+`
+
+type GetUserByIDParams struct {
+	TenantID string
+	ID       uuid.UUID
+}
+
+func (q *Queries) GetUserByID(ctx context.Context, arg GetUserByIDParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, arg.TenantID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByIDIncludingDeleted = `-- name: GetUserByIDIncludingDeleted :one
+SELECT id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at FROM users WHERE tenant_id = ? AND ID = ?
+`
+
+type GetUserByIDIncludingDeletedParams struct {
+	TenantID string
+	ID       uuid.UUID
+}
+
+func (q *Queries) GetUserByIDIncludingDeleted(ctx context.Context, arg GetUserByIDIncludingDeletedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIDIncludingDeleted, arg.TenantID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at FROM users WHERE tenant_id = ? AND username_normalized = ? AND deleted_at IS NULL
+`
+
+type GetUserByUsernameParams struct {
+	TenantID           string
+	UsernameNormalized string
+}
+
+func (q *Queries) GetUserByUsername(ctx context.Context, arg GetUserByUsernameParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, arg.TenantID, arg.UsernameNormalized)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUsers = `-- name: GetUsers :many
+SELECT id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at FROM users
+WHERE tenant_id = ?1 AND (deleted_at IS NULL OR CAST(?2 AS boolean))
+LIMIT ?4 OFFSET ?3
+`
+
+type GetUsersParams struct {
+	TenantID       string
+	IncludeDeleted bool
+	Offset         int64
+	Limit          int64
+}
+
+func (q *Queries) GetUsers(ctx context.Context, arg GetUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsers,
+		arg.TenantID,
+		arg.IncludeDeleted,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Username,
+			&i.Password,
+			&i.Version,
+			&i.DeletedAt,
+			&i.UsernameNormalized,
+			&i.Email,
+			&i.EmailNormalized,
+			&i.DisplayName,
+			&i.Locale,
+			&i.AvatarUrl,
+			&i.EmailVerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersAfterID = `-- name: GetUsersAfterID :many
+SELECT id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at FROM users WHERE tenant_id = ? AND id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?
+`
+
+type GetUsersAfterIDParams struct {
+	TenantID string
+	ID       uuid.UUID
+	Limit    int64
+}
+
+func (q *Queries) GetUsersAfterID(ctx context.Context, arg GetUsersAfterIDParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersAfterID, arg.TenantID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Username,
+			&i.Password,
+			&i.Version,
+			&i.DeletedAt,
+			&i.UsernameNormalized,
+			&i.Email,
+			&i.EmailNormalized,
+			&i.DisplayName,
+			&i.Locale,
+			&i.AvatarUrl,
+			&i.EmailVerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
+SELECT id, subscription_id, event_type, payload, status, attempts, next_attempt_at, status_code, last_error, created_at, updated_at, tenant_id FROM webhook_deliveries
+WHERE tenant_id = ? AND subscription_id = ?
+ORDER BY created_at DESC, id
+LIMIT ? OFFSET ?
+`
+
+type GetWebhookDeliveriesParams struct {
+	TenantID       string
+	SubscriptionID uuid.UUID
+	Limit          int64
+	Offset         int64
+}
+
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveries,
+		arg.TenantID,
+		arg.SubscriptionID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.StatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookSubscriptionByID = `-- name: GetWebhookSubscriptionByID :one
+SELECT id, url, events, secret, created_at, updated_at, tenant_id FROM webhook_subscriptions WHERE tenant_id = ? AND id = ?
+`
+
+type GetWebhookSubscriptionByIDParams struct {
+	TenantID string
+	ID       uuid.UUID
+}
+
+func (q *Queries) GetWebhookSubscriptionByID(ctx context.Context, arg GetWebhookSubscriptionByIDParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookSubscriptionByID, arg.TenantID, arg.ID)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getWebhookSubscriptions = `-- name: GetWebhookSubscriptions :many
+SELECT id, url, events, secret, created_at, updated_at, tenant_id FROM webhook_subscriptions WHERE tenant_id = ? ORDER BY created_at, id LIMIT ? OFFSET ?
+`
+
+type GetWebhookSubscriptionsParams struct {
+	TenantID string
+	Limit    int64
+	Offset   int64
+}
+
+func (q *Queries) GetWebhookSubscriptions(ctx context.Context, arg GetWebhookSubscriptionsParams) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookSubscriptions, arg.TenantID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TenantID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isUsernameTaken = `-- name: IsUsernameTaken :one
+SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id = ? AND username_normalized = ?)
+`
+
+type IsUsernameTakenParams struct {
+	TenantID           string
+	UsernameNormalized string
+}
+
+func (q *Queries) IsUsernameTaken(ctx context.Context, arg IsUsernameTakenParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isUsernameTaken, arg.TenantID, arg.UsernameNormalized)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const publishOutboxEntry = `-- name: PublishOutboxEntry :exec
+UPDATE outbox SET published = published || ?1 || ',' WHERE id = ?2
+`
+
+type PublishOutboxEntryParams struct {
+	Publisher string
+	ID        int64
+}
+
+func (q *Queries) PublishOutboxEntry(ctx context.Context, arg PublishOutboxEntryParams) error {
+	_, err := q.db.ExecContext(ctx, publishOutboxEntry, arg.Publisher, arg.ID)
+	return err
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :many
+DELETE FROM users WHERE deleted_at <= ?1 RETURNING id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at
+`
+
+// The users are purged by the retention job, so those of all the tenants.
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, deletedBefore *time.Time) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedUsers, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Username,
+			&i.Password,
+			&i.Version,
+			&i.DeletedAt,
+			&i.UsernameNormalized,
+			&i.Email,
+			&i.EmailNormalized,
+			&i.DisplayName,
+			&i.Locale,
+			&i.AvatarUrl,
+			&i.EmailVerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeUser = `-- name: PurgeUser :one
+DELETE FROM users WHERE tenant_id = ? AND id = ? AND deleted_at IS NOT NULL RETURNING id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at
+`
+
+type PurgeUserParams struct {
+	TenantID string
+	ID       uuid.UUID
+}
+
+func (q *Queries) PurgeUser(ctx context.Context, arg PurgeUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, purgeUser, arg.TenantID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const resetUserPassword = `-- name: ResetUserPassword :one
+UPDATE users SET
+    password = ?1,
+    updated_at = ?2,
+    version = version + 1
+WHERE tenant_id = ?3 AND id = ?4 AND deleted_at IS NULL
+RETURNING id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at
+`
+
+type ResetUserPasswordParams struct {
+	Password  string
+	UpdatedAt time.Time
+	TenantID  string
+	ID        uuid.UUID
+}
+
+func (q *Queries) ResetUserPassword(ctx context.Context, arg ResetUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, resetUserPassword,
+		arg.Password,
+		arg.UpdatedAt,
+		arg.TenantID,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE users SET
+    deleted_at = NULL,
+    updated_at = ?1,
+    version = version + 1
+WHERE tenant_id = ?2 AND id = ?3 AND deleted_at IS NOT NULL
+RETURNING id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at
+`
+
+type RestoreUserParams struct {
+	UpdatedAt time.Time
+	TenantID  string
+	ID        uuid.UUID
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, restoreUser, arg.UpdatedAt, arg.TenantID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?1)
+WHERE id = ?2
+RETURNING id, name, prefix, key_hash, roles, created_at, last_used_at, revoked_at
+`
+
+type RevokeAPIKeyParams struct {
+	RevokedAt *time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, revokeAPIKey, arg.RevokedAt, arg.ID)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Roles,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const updateAPIKeyLastUsedAt = `-- name: UpdateAPIKeyLastUsedAt :exec
+UPDATE api_keys SET last_used_at = ? WHERE id = ?
+`
+
+type UpdateAPIKeyLastUsedAtParams struct {
+	LastUsedAt *time.Time
+	ID         uuid.UUID
+}
+
+func (q *Queries) UpdateAPIKeyLastUsedAt(ctx context.Context, arg UpdateAPIKeyLastUsedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateAPIKeyLastUsedAt, arg.LastUsedAt, arg.ID)
+	return err
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users SET
+    username = COALESCE(?1, username),
+    username_normalized = COALESCE(?2, username_normalized),
+    password = COALESCE(?3, password),
+    email = COALESCE(?4, email),
+    email_normalized = COALESCE(?5, email_normalized),
+    display_name = COALESCE(?6, display_name),
+    locale = COALESCE(?7, locale),
+    avatar_url = COALESCE(?8, avatar_url),
+    email_verified_at = CASE
+        WHEN COALESCE(?5, email_normalized) = email_normalized THEN email_verified_at
+    END,
+    updated_at = ?9,
+    version = version + 1
+WHERE tenant_id = ?10 AND id = ?11 AND version = ?12 AND deleted_at IS NULL
+RETURNING id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at
+`
+
+type UpdateUserParams struct {
+	Username           sql.NullString
+	UsernameNormalized sql.NullString
+	Password           sql.NullString
+	Email              sql.NullString
+	EmailNormalized    sql.NullString
+	DisplayName        sql.NullString
+	Locale             sql.NullString
+	AvatarUrl          sql.NullString
+	UpdatedAt          time.Time
+	TenantID           string
+	ID                 uuid.UUID
+	Version            int64
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Username,
+		arg.UsernameNormalized,
+		arg.Password,
+		arg.Email,
+		arg.EmailNormalized,
+		arg.DisplayName,
+		arg.Locale,
+		arg.AvatarUrl,
+		arg.UpdatedAt,
+		arg.TenantID,
+		arg.ID,
+		arg.Version,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries SET
+    status = ?, attempts = ?, next_attempt_at = ?, status_code = ?, last_error = ?, updated_at = ?
+WHERE id = ?
+`
+
+type UpdateWebhookDeliveryParams struct {
+	Status        string
+	Attempts      int64
+	NextAttemptAt time.Time
+	StatusCode    *int64
+	LastError     sql.NullString
+	UpdatedAt     time.Time
+	ID            uuid.UUID
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookDelivery,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.StatusCode,
+		arg.LastError,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions SET
+    url = COALESCE(?1, url),
+    events = COALESCE(?2, events),
+    updated_at = ?3
+WHERE tenant_id = ?4 AND id = ?5
+RETURNING id, url, events, secret, created_at, updated_at, tenant_id
+`
+
+type UpdateWebhookSubscriptionParams struct {
+	Url       sql.NullString
+	Events    sql.NullString
+	UpdatedAt time.Time
+	TenantID  string
+	ID        uuid.UUID
+}
+
+func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhookSubscription,
+		arg.Url,
+		arg.Events,
+		arg.UpdatedAt,
+		arg.TenantID,
+		arg.ID,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const upsertLoginAttempts = `-- name: UpsertLoginAttempts :exec
+INSERT INTO login_attempts (
+    scope, value, failures, last_failure_at, retry_at, locked_until, in_flight, in_flight_until, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (scope, value) DO UPDATE SET
+    failures = excluded.failures,
+    last_failure_at = excluded.last_failure_at,
+    retry_at = excluded.retry_at,
+    locked_until = excluded.locked_until,
+    in_flight = excluded.in_flight,
+    in_flight_until = excluded.in_flight_until,
+    expires_at = excluded.expires_at
+`
+
+type UpsertLoginAttemptsParams struct {
+	Scope         string
+	Value         string
+	Failures      int64
+	LastFailureAt time.Time
+	RetryAt       time.Time
+	LockedUntil   *time.Time
+	InFlight      int64
+	InFlightUntil sql.NullTime
+	ExpiresAt     time.Time
+}
+
+func (q *Queries) UpsertLoginAttempts(ctx context.Context, arg UpsertLoginAttemptsParams) error {
+	_, err := q.db.ExecContext(ctx, upsertLoginAttempts,
+		arg.Scope,
+		arg.Value,
+		arg.Failures,
+		arg.LastFailureAt,
+		arg.RetryAt,
+		arg.LockedUntil,
+		arg.InFlight,
+		arg.InFlightUntil,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const useUserToken = `-- name: UseUserToken :one
+DELETE FROM user_tokens
+WHERE token_hash = ?1 AND purpose = ?2 AND expires_at > ?3
+RETURNING token_hash, user_id, purpose, email, created_at, expires_at
+`
+
+type UseUserTokenParams struct {
+	TokenHash string
+	Purpose   string
+	Now       time.Time
+}
+
+func (q *Queries) UseUserToken(ctx context.Context, arg UseUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, useUserToken, arg.TokenHash, arg.Purpose, arg.Now)
+	var i UserToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.Purpose,
+		&i.Email,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users SET
+    email_verified_at = ?1,
+    updated_at = ?2,
+    version = version + 1
+WHERE tenant_id = ?3 AND id = ?4 AND email_normalized = ?5
+    AND deleted_at IS NULL
+RETURNING id, tenant_id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized, display_name, locale, avatar_url, email_verified_at
+`
+
+type VerifyUserEmailParams struct {
+	EmailVerifiedAt *time.Time
+	UpdatedAt       time.Time
+	TenantID        string
+	ID              uuid.UUID
+	EmailNormalized string
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmail,
+		arg.EmailVerifiedAt,
+		arg.UpdatedAt,
+		arg.TenantID,
+		arg.ID,
+		arg.EmailNormalized,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.Password,
+		&i.Version,
+		&i.DeletedAt,
+		&i.UsernameNormalized,
+		&i.Email,
+		&i.EmailNormalized,
+		&i.DisplayName,
+		&i.Locale,
+		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
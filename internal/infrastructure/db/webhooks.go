@@ -0,0 +1,423 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/infrastructure/db/sqlc"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+	"github.com/manuelarte/go-web-layout/internal/webhooks"
+)
+
+var _ webhooks.Repository = new(WebhookRepository)
+
+// eventsSeparator separates the events a subscription is subscribed to in the events column.
+const eventsSeparator = ","
+
+// WebhookRepository keeps the webhook subscriptions and their deliveries
+// in the webhook_subscriptions and webhook_deliveries tables.
+type WebhookRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+	metrics queryMetrics
+}
+
+func NewWebhookRepository(db *sql.DB, mp metric.MeterProvider) (WebhookRepository, error) {
+	metrics, err := newQueryMetrics(mp)
+	if err != nil {
+		return WebhookRepository{}, fmt.Errorf("error creating query metrics: %w", err)
+	}
+
+	return WebhookRepository{
+		db:      db,
+		queries: sqlc.New(newInstrumentedDBTX(db, metrics)),
+		metrics: metrics,
+	}, nil
+}
+
+func (r WebhookRepository) Create(ctx context.Context, s webhooks.Subscription) (webhooks.Subscription, error) {
+	ctx, span := observability.StartSpan(ctx, "WebhookRepository.Create")
+	defer span.End()
+
+	dao, err := r.queries.CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		ID:       uuid.UUID(s.ID),
+		TenantID: tenantID(ctx),
+		Url:      s.URL,
+		Events:   joinEvents(s.Events),
+		Secret:   s.Secret,
+	})
+	if err != nil {
+		return webhooks.Subscription{}, fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	return transformSubscription(dao), nil
+}
+
+func (r WebhookRepository) GetAll(
+	ctx context.Context,
+	pr pagination.PageRequest,
+) (pagination.Page[webhooks.Subscription], error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.GetAll",
+		oteltrace.WithAttributes(attribute.Int("page", pr.Page()), attribute.Int("size", pr.Size())),
+	)
+	defer span.End()
+
+	daos, err := r.queries.GetWebhookSubscriptions(ctx, sqlc.GetWebhookSubscriptionsParams{
+		TenantID: tenantID(ctx),
+		Limit:    int64(pr.Size()),
+		Offset:   int64(pr.Offset()),
+	})
+	if err != nil {
+		return pagination.Page[webhooks.Subscription]{}, fmt.Errorf("error getting webhooks: %w", err)
+	}
+
+	count, err := r.queries.CountWebhookSubscriptions(ctx, tenantID(ctx))
+	if err != nil {
+		return pagination.Page[webhooks.Subscription]{}, fmt.Errorf("error counting webhooks: %w", err)
+	}
+
+	subscriptions := lo.Map(daos, func(item sqlc.WebhookSubscription, _ int) webhooks.Subscription {
+		return transformSubscription(item)
+	})
+
+	return pagination.MustPage(subscriptions, pr, count), nil
+}
+
+func (r WebhookRepository) GetByID(ctx context.Context, id webhooks.SubscriptionID) (webhooks.Subscription, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.GetByID",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	dao, err := r.queries.GetWebhookSubscriptionByID(ctx, sqlc.GetWebhookSubscriptionByIDParams{
+		TenantID: tenantID(ctx),
+		ID:       uuid.UUID(id),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhooks.Subscription{}, webhooks.NotFoundError{ID: id}
+		}
+
+		return webhooks.Subscription{}, fmt.Errorf("error getting webhook by id: %w", err)
+	}
+
+	return transformSubscription(dao), nil
+}
+
+func (r WebhookRepository) Subscribed(
+	ctx context.Context,
+	tenantID tenant.ID,
+	eventType users.EventType,
+) ([]webhooks.Subscription, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.Subscribed",
+		oteltrace.WithAttributes(attribute.String("event", string(eventType)), tenantID.Attribute()),
+	)
+	defer span.End()
+
+	daos, err := r.queries.GetSubscribedWebhookSubscriptions(ctx, sqlc.GetSubscribedWebhookSubscriptionsParams{
+		TenantID:  string(tenantID),
+		EventType: string(eventType),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscribed webhooks: %w", err)
+	}
+
+	return lo.Map(daos, func(item sqlc.WebhookSubscription, _ int) webhooks.Subscription {
+		return transformSubscription(item)
+	}), nil
+}
+
+func (r WebhookRepository) Update(
+	ctx context.Context,
+	id webhooks.SubscriptionID,
+	update webhooks.Update,
+) (webhooks.Subscription, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.Update",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	err := update.IsValid()
+	if err != nil {
+		return webhooks.Subscription{}, fmt.Errorf("error validating webhook fields: %w", err)
+	}
+
+	params := sqlc.UpdateWebhookSubscriptionParams{
+		UpdatedAt: time.Now().UTC(),
+		TenantID:  tenantID(ctx),
+		ID:        uuid.UUID(id),
+	}
+	if update.URL != nil {
+		params.Url = sql.NullString{String: *update.URL, Valid: true}
+	}
+
+	if update.Events != nil {
+		params.Events = sql.NullString{String: joinEvents(update.Events), Valid: true}
+	}
+
+	dao, err := r.queries.UpdateWebhookSubscription(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhooks.Subscription{}, webhooks.NotFoundError{ID: id}
+		}
+
+		return webhooks.Subscription{}, fmt.Errorf("error updating webhook: %w", err)
+	}
+
+	return transformSubscription(dao), nil
+}
+
+func (r WebhookRepository) Delete(ctx context.Context, id webhooks.SubscriptionID) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.Delete",
+		oteltrace.WithAttributes(attribute.String("id", id.String())),
+	)
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func(tx *sql.Tx) {
+		errRollback := tx.Rollback()
+		if errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to rollback transaction", slog.Any("err", errRollback))
+		}
+	}(tx)
+
+	queries := sqlc.New(newInstrumentedDBTX(tx, r.metrics))
+
+	deleted, err := queries.DeleteWebhookSubscription(ctx, sqlc.DeleteWebhookSubscriptionParams{
+		TenantID: tenantID(ctx),
+		ID:       uuid.UUID(id),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+
+	if deleted == 0 {
+		return webhooks.NotFoundError{ID: id}
+	}
+
+	err = queries.DeleteWebhookDeliveries(ctx, uuid.UUID(id))
+	if err != nil {
+		return fmt.Errorf("error deleting webhook deliveries: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r WebhookRepository) CreateDeliveries(ctx context.Context, deliveries ...webhooks.Delivery) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.CreateDeliveries",
+		oteltrace.WithAttributes(attribute.Int("deliveries", len(deliveries))),
+	)
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func(tx *sql.Tx) {
+		errRollback := tx.Rollback()
+		if errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to rollback transaction", slog.Any("err", errRollback))
+		}
+	}(tx)
+
+	queries := sqlc.New(newInstrumentedDBTX(tx, r.metrics))
+
+	for _, d := range deliveries {
+		err = queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			ID:             uuid.UUID(d.ID),
+			TenantID:       string(d.TenantID),
+			SubscriptionID: uuid.UUID(d.SubscriptionID),
+			EventType:      string(d.EventType),
+			Payload:        d.Payload,
+			Status:         string(d.Status),
+			Attempts:       int64(d.Attempts),
+			NextAttemptAt:  d.NextAttemptAt.UTC(),
+			StatusCode:     statusCode(d.StatusCode),
+			LastError:      sql.NullString{String: d.Error, Valid: d.Error != ""},
+			CreatedAt:      d.CreatedAt.UTC(),
+			UpdatedAt:      d.UpdatedAt.UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("error creating webhook delivery: %w", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r WebhookRepository) PendingDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]webhooks.Delivery, error) {
+	ctx, span := observability.StartSpan(ctx, "WebhookRepository.PendingDeliveries")
+	defer span.End()
+
+	daos, err := r.queries.GetPendingWebhookDeliveries(ctx, sqlc.GetPendingWebhookDeliveriesParams{
+		Now:   now.UTC(),
+		Limit: int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting pending webhook deliveries: %w", err)
+	}
+
+	return lo.Map(daos, func(item sqlc.WebhookDelivery, _ int) webhooks.Delivery {
+		return transformDelivery(item)
+	}), nil
+}
+
+func (r WebhookRepository) UpdateDelivery(ctx context.Context, d webhooks.Delivery) error {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.UpdateDelivery",
+		oteltrace.WithAttributes(attribute.String("id", d.ID.String())),
+	)
+	defer span.End()
+
+	err := r.queries.UpdateWebhookDelivery(ctx, sqlc.UpdateWebhookDeliveryParams{
+		Status:        string(d.Status),
+		Attempts:      int64(d.Attempts),
+		NextAttemptAt: d.NextAttemptAt.UTC(),
+		StatusCode:    statusCode(d.StatusCode),
+		LastError:     sql.NullString{String: d.Error, Valid: d.Error != ""},
+		UpdatedAt:     d.UpdatedAt.UTC(),
+		ID:            uuid.UUID(d.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("error updating webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r WebhookRepository) GetDeliveries(
+	ctx context.Context,
+	id webhooks.SubscriptionID,
+	pr pagination.PageRequest,
+) (pagination.Page[webhooks.Delivery], error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"WebhookRepository.GetDeliveries",
+		oteltrace.WithAttributes(
+			attribute.String("id", id.String()),
+			attribute.Int("page", pr.Page()),
+			attribute.Int("size", pr.Size()),
+		),
+	)
+	defer span.End()
+
+	_, err := r.GetByID(ctx, id)
+	if err != nil {
+		return pagination.Page[webhooks.Delivery]{}, err
+	}
+
+	daos, err := r.queries.GetWebhookDeliveries(ctx, sqlc.GetWebhookDeliveriesParams{
+		TenantID:       tenantID(ctx),
+		SubscriptionID: uuid.UUID(id),
+		Limit:          int64(pr.Size()),
+		Offset:         int64(pr.Offset()),
+	})
+	if err != nil {
+		return pagination.Page[webhooks.Delivery]{}, fmt.Errorf("error getting webhook deliveries: %w", err)
+	}
+
+	count, err := r.queries.CountWebhookDeliveries(ctx, sqlc.CountWebhookDeliveriesParams{
+		TenantID:       tenantID(ctx),
+		SubscriptionID: uuid.UUID(id),
+	})
+	if err != nil {
+		return pagination.Page[webhooks.Delivery]{}, fmt.Errorf("error counting webhook deliveries: %w", err)
+	}
+
+	deliveries := lo.Map(daos, func(item sqlc.WebhookDelivery, _ int) webhooks.Delivery {
+		return transformDelivery(item)
+	})
+
+	return pagination.MustPage(deliveries, pr, count), nil
+}
+
+func joinEvents(events []users.EventType) string {
+	return strings.Join(lo.Map(events, func(item users.EventType, _ int) string {
+		return string(item)
+	}), eventsSeparator)
+}
+
+// statusCode returns the status code to be stored, nil if there was no response.
+func statusCode(code int) *int64 {
+	if code == 0 {
+		return nil
+	}
+
+	return new(int64(code))
+}
+
+func transformSubscription(dao sqlc.WebhookSubscription) webhooks.Subscription {
+	return webhooks.Subscription{
+		ID:       webhooks.SubscriptionID(dao.ID),
+		TenantID: tenant.ID(dao.TenantID),
+		URL:      dao.Url,
+		Events: lo.Map(strings.Split(dao.Events, eventsSeparator), func(item string, _ int) users.EventType {
+			return users.EventType(item)
+		}),
+		Secret:    dao.Secret,
+		CreatedAt: dao.CreatedAt,
+		UpdatedAt: dao.UpdatedAt,
+	}
+}
+
+func transformDelivery(dao sqlc.WebhookDelivery) webhooks.Delivery {
+	return webhooks.Delivery{
+		ID:             webhooks.DeliveryID(dao.ID),
+		TenantID:       tenant.ID(dao.TenantID),
+		SubscriptionID: webhooks.SubscriptionID(dao.SubscriptionID),
+		EventType:      users.EventType(dao.EventType),
+		Payload:        dao.Payload,
+		Status:         webhooks.DeliveryStatus(dao.Status),
+		Attempts:       int(dao.Attempts),
+		NextAttemptAt:  dao.NextAttemptAt,
+		StatusCode:     int(lo.FromPtr(dao.StatusCode)),
+		Error:          dao.LastError.String,
+		CreatedAt:      dao.CreatedAt,
+		UpdatedAt:      dao.UpdatedAt,
+	}
+}
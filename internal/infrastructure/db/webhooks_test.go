@@ -0,0 +1,193 @@
+package db
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/config"
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+	"github.com/manuelarte/go-web-layout/internal/webhooks"
+)
+
+func TestWebhookRepository_Subscribed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		tenantID  tenant.ID
+		eventType users.EventType
+		expected  []string
+	}{
+		"subscribed to all": {
+			tenantID:  "acme",
+			eventType: users.EventCreated,
+			expected:  []string{"https://all.example.com"},
+		},
+		"subscribed to some": {
+			tenantID:  "acme",
+			eventType: users.EventDeleted,
+			expected:  []string{"https://all.example.com", "https://deleted.example.com"},
+		},
+		"subscribed in other tenant": {
+			tenantID:  "globex",
+			eventType: users.EventDeleted,
+			expected:  []string{"https://globex.example.com"},
+		},
+		"not subscribed in tenant": {
+			tenantID:  tenant.Default,
+			eventType: users.EventDeleted,
+			expected:  []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+			require.NoError(t, err)
+
+			r, err := NewWebhookRepository(db, noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			for url, subscribed := range map[string]struct {
+				tenantID tenant.ID
+				events   []users.EventType
+			}{
+				"https://all.example.com": {tenantID: "acme", events: webhooks.Events()},
+				"https://deleted.example.com": {
+					tenantID: "acme",
+					events:   []users.EventType{users.EventDeleted, users.EventUpdated},
+				},
+				"https://globex.example.com": {tenantID: "globex", events: webhooks.Events()},
+			} {
+				subscription, errNew := webhooks.NewSubscription(url, subscribed.events)
+				require.NoError(t, errNew)
+
+				_, errCreate := r.Create(tenant.WithID(t.Context(), subscribed.tenantID), subscription)
+				require.NoError(t, errCreate)
+			}
+
+			// Act
+			actual, err := r.Subscribed(t.Context(), test.tenantID, test.eventType)
+
+			// Assert
+			require.NoError(t, err)
+
+			urls := make([]string, 0, len(actual))
+			for _, subscription := range actual {
+				assert.Equal(t, test.tenantID, subscription.TenantID)
+
+				urls = append(urls, subscription.URL)
+			}
+
+			assert.ElementsMatch(t, test.expected, urls)
+		})
+	}
+}
+
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewWebhookRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	subscription, err := webhooks.NewSubscription("https://example.com", webhooks.Events())
+	require.NoError(t, err)
+
+	subscription, err = r.Create(t.Context(), subscription)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	delivered := webhooks.NewDelivery(subscription, users.EventCreated, []byte(`{}`), now)
+	retried := webhooks.NewDelivery(subscription, users.EventUpdated, []byte(`{}`), now)
+	require.NoError(t, r.CreateDeliveries(t.Context(), delivered, retried))
+
+	delivered.Status = webhooks.DeliverySucceeded
+	delivered.Attempts = 1
+	delivered.StatusCode = 204
+	require.NoError(t, r.UpdateDelivery(t.Context(), delivered))
+
+	retried.Attempts = 1
+	retried.NextAttemptAt = now.Add(time.Minute)
+	retried.Error = "responded with status 500"
+	retried.StatusCode = 500
+	require.NoError(t, r.UpdateDelivery(t.Context(), retried))
+
+	// Act
+	notDue, errNotDue := r.PendingDeliveries(t.Context(), now, 10)
+	due, errDue := r.PendingDeliveries(t.Context(), now.Add(time.Minute), 10)
+	page, errPage := r.GetDeliveries(t.Context(), subscription.ID, pagination.MustPageRequest(0, 10))
+
+	// Assert
+	require.NoError(t, errNotDue)
+	assert.Empty(t, notDue)
+
+	require.NoError(t, errDue)
+	require.Len(t, due, 1)
+	assert.Equal(t, retried.ID, due[0].ID)
+	assert.Equal(t, 500, due[0].StatusCode)
+	assert.Equal(t, "responded with status 500", due[0].Error)
+
+	require.NoError(t, errPage)
+	assert.Equal(t, int64(2), page.TotalElements())
+
+	_, err = r.GetDeliveries(tenant.WithID(t.Context(), "globex"), subscription.ID, pagination.MustPageRequest(0, 10))
+	require.ErrorAs(t, err, new(webhooks.NotFoundError))
+
+	require.NoError(t, r.Delete(t.Context(), subscription.ID))
+
+	_, err = r.GetDeliveries(t.Context(), subscription.ID, pagination.MustPageRequest(0, 10))
+	require.ErrorAs(t, err, new(webhooks.NotFoundError))
+}
+
+func TestWebhookRepository_PublishOtherTenant(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	db, err := config.Migrate(goweblayout.ResourcesFolder, t.Name())
+	require.NoError(t, err)
+
+	r, err := NewWebhookRepository(db, noop.NewMeterProvider())
+	require.NoError(t, err)
+
+	subscription, err := webhooks.NewSubscription("https://acme.example.com", webhooks.Events())
+	require.NoError(t, err)
+
+	subscription, err = r.Create(tenant.WithID(t.Context(), "acme"), subscription)
+	require.NoError(t, err)
+
+	dispatcher := webhooks.NewDispatcher(r, http.DefaultClient, slog.New(slog.DiscardHandler), time.Second)
+
+	event := users.NewDeletedEvent(users.UserID(uuid.New()), time.Now().UTC())
+	event.TenantID = "globex"
+
+	// Act
+	errOther := dispatcher.Publish(t.Context(), event)
+
+	event.TenantID = "acme"
+	errSame := dispatcher.Publish(t.Context(), event)
+
+	// Assert
+	require.NoError(t, errOther)
+	require.NoError(t, errSame)
+
+	pending, err := r.PendingDeliveries(t.Context(), time.Now().UTC().Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, subscription.ID, pending[0].SubscriptionID)
+	assert.Equal(t, tenant.ID("acme"), pending[0].TenantID)
+}
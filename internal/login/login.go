@@ -0,0 +1,228 @@
+package login
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// Scopes of the failed attempts.
+const (
+	ScopeUsername Scope = "username"
+	ScopeIP       Scope = "ip"
+)
+
+// inFlightTimeout is how long a reserved attempt is counted in flight, if its outcome is never recorded.
+const inFlightTimeout = time.Minute
+
+var _ error = new(ThrottledError)
+
+//go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package login -destination ./mock.gen.$GOFILE
+type (
+	// Scope is what the failed attempts are tracked by.
+	Scope string
+
+	// Key identifies whose failed attempts are tracked, a username or an IP.
+	Key struct {
+		Scope Scope
+		Value string
+	}
+
+	// Attempts are the failed attempts of a key.
+	Attempts struct {
+		// Failures is the number of consecutive failed attempts, since the last lockout.
+		Failures int
+		// LastFailureAt is when the last attempt failed, zero if none did.
+		LastFailureAt time.Time
+		// RetryAt is when the next attempt is allowed, delayed progressively after each failure.
+		RetryAt time.Time
+		// LockedUntil is when the lockout ends, zero if the key was never locked.
+		LockedUntil time.Time
+		// InFlight is the number of attempts reserved, whose password is being checked.
+		InFlight int
+		// InFlightUntil is when the attempts in flight are forgotten, if their outcome is never recorded.
+		InFlightUntil time.Time
+	}
+
+	// Policy is how the failed attempts of a key are delayed and locked out, to protect the logins from brute force:
+	// the next attempts are delayed progressively, and locked out temporarily after too many.
+	Policy struct {
+		// MaxFailures is the number of consecutive failures locking the key out, zero never locks it.
+		MaxFailures int
+		// LockoutDuration is how long the key is locked out.
+		LockoutDuration time.Duration
+		// BaseDelay is the delay after the first failure, doubled after each of the following ones.
+		BaseDelay time.Duration
+		// MaxDelay caps the delay after a failure.
+		MaxDelay time.Duration
+		// Window is how long the failures are remembered, they are forgotten if there is none in it.
+		Window time.Duration
+	}
+
+	// ThrottledError is returned for the logins attempted before the key is allowed to retry.
+	ThrottledError struct {
+		Scope Scope
+		// Locked is whether the key is locked out, instead of delayed.
+		Locked     bool
+		RetryAfter time.Duration
+	}
+
+	// Repository interface with the methods of the logins.
+	Repository interface {
+		// Authenticate gets the user with the username, compared in its normalized form, if the password is its.
+		// The soft-deleted users are not found.
+		// Can return either users.ErrInvalidCredentials, also if there is no user with the username,
+		// or any other database error.
+		Authenticate(context.Context, users.Username, users.Password) (users.User, error)
+		// GetAttempts gets the failed attempts of the key, zero if there are none.
+		GetAttempts(context.Context, Key) (Attempts, error)
+		// Reserve reserves an attempt of the key at the time, applying the policy, before checking its password,
+		// so the concurrent attempts can't bypass the delays and the lockouts. Its outcome is recorded with Fail,
+		// or with Release or Reset if it succeeded.
+		// Can return either ThrottledError, the key can't attempt a login yet, or any other database error.
+		Reserve(context.Context, Key, Policy, time.Time) (Attempts, error)
+		// Fail records a failed attempt of the key at the time, applying the policy, and releases its reservation.
+		// When the username of a user is locked out, the lockout is recorded in its audit log.
+		Fail(context.Context, Key, Policy, time.Time) (Attempts, error)
+		// Release releases the reservation of a succeeded attempt of the key at the time, applying the policy.
+		Release(context.Context, Key, Policy, time.Time) error
+		// Reset forgets the failed attempts of the key.
+		Reset(context.Context, Key) error
+		// GetLockout gets the failed attempts of the username of the user.
+		// Can return either users.NotFoundError, or any other database error.
+		GetLockout(context.Context, users.UserID) (Attempts, error)
+		// Unlock forgets the failed attempts of the username of the user, recording it in its audit log if it was locked.
+		// Can return either users.NotFoundError, or any other database error.
+		Unlock(context.Context, users.UserID) (Attempts, error)
+		// DeleteExpired deletes the failed attempts forgotten or unlocked at the time, returning how many.
+		DeleteExpired(context.Context, time.Time) (int64, error)
+	}
+)
+
+// KeyForUsername returns the key of the username, in its normalized form.
+func KeyForUsername(username users.Username) Key {
+	return Key{Scope: ScopeUsername, Value: string(username.Normalize())}
+}
+
+// KeyForIP returns the key of the IP.
+func KeyForIP(ip string) Key {
+	return Key{Scope: ScopeIP, Value: ip}
+}
+
+func (k Key) String() string {
+	return string(k.Scope) + ":" + k.Value
+}
+
+// IsLocked returns whether the key is locked out at the time.
+func (a Attempts) IsLocked(now time.Time) bool {
+	return a.LockedUntil.After(now)
+}
+
+// RetryAfter returns how long until the key is allowed to attempt a login, zero if it is allowed at the time.
+func (a Attempts) RetryAfter(now time.Time) time.Duration {
+	retryAt := a.RetryAt
+	if a.LockedUntil.After(retryAt) {
+		retryAt = a.LockedUntil
+	}
+
+	return max(retryAt.Sub(now), 0)
+}
+
+// ExpiresAt returns when the attempts are forgotten with the policy: the failures leave the window,
+// the key is unlocked, and the attempts in flight time out.
+func (a Attempts) ExpiresAt(p Policy) time.Time {
+	expiresAt := a.LastFailureAt.Add(p.Window)
+	if a.LockedUntil.After(expiresAt) {
+		expiresAt = a.LockedUntil
+	}
+
+	if a.InFlightUntil.After(expiresAt) {
+		return a.InFlightUntil
+	}
+
+	return expiresAt
+}
+
+// Release returns the attempts after the outcome of an attempt in flight is recorded at the time.
+func (a Attempts) Release(now time.Time) Attempts {
+	if !a.InFlightUntil.After(now) {
+		a.InFlight = 0
+	}
+
+	a.InFlight = max(a.InFlight-1, 0)
+	if a.InFlight == 0 {
+		a.InFlightUntil = time.Time{}
+	}
+
+	return a
+}
+
+// Reserve returns the attempts after reserving an attempt at the time, counted in flight until its outcome is
+// recorded. Every attempt in flight could fail, so the next ones are throttled until they are recorded,
+// if a failure would delay them, or if the failures would lock the key out.
+// It returns ThrottledError if the key can't attempt a login yet.
+func (p Policy) Reserve(key Key, a Attempts, now time.Time) (Attempts, error) {
+	if retryAfter := a.RetryAfter(now); retryAfter > 0 {
+		return a, ThrottledError{Scope: key.Scope, Locked: a.IsLocked(now), RetryAfter: retryAfter}
+	}
+
+	if !a.InFlightUntil.After(now) {
+		a.InFlight = 0
+	}
+
+	failures := p.forget(a, now).Failures
+	if a.InFlight > 0 && (p.BaseDelay > 0 || (p.MaxFailures > 0 && failures+a.InFlight >= p.MaxFailures)) {
+		return a, ThrottledError{Scope: key.Scope, RetryAfter: max(p.BaseDelay, time.Second)}
+	}
+
+	a.InFlight++
+	a.InFlightUntil = now.Add(inFlightTimeout)
+
+	return a, nil
+}
+
+// Fail returns the attempts after a failed attempt at the time, releasing it if it was in flight.
+// The failures are forgotten after a lockout, or if the previous one left the window.
+// The key is locked out when it reaches the maximum failures, otherwise the next attempt is delayed.
+func (p Policy) Fail(a Attempts, now time.Time) Attempts {
+	a = p.forget(a.Release(now), now)
+	a.Failures++
+	a.LastFailureAt = now
+	a.RetryAt = now.Add(p.delay(a.Failures))
+
+	if p.MaxFailures > 0 && a.Failures >= p.MaxFailures {
+		a.LockedUntil = now.Add(p.LockoutDuration)
+	}
+
+	return a
+}
+
+// forget returns the attempts without the failures forgotten at the time: after a lockout,
+// or if the last one left the window.
+func (p Policy) forget(a Attempts, now time.Time) Attempts {
+	if now.Sub(a.LastFailureAt) > p.Window || (!a.LockedUntil.IsZero() && !a.IsLocked(now)) {
+		return Attempts{InFlight: a.InFlight, InFlightUntil: a.InFlightUntil}
+	}
+
+	return a
+}
+
+// delay returns the delay after the failures, doubled after each one up to the maximum delay.
+func (p Policy) delay(failures int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
+
+func (e ThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed logins, %s locked out for %s", e.Scope, e.RetryAfter.Round(time.Second))
+	}
+
+	return fmt.Sprintf("too many failed logins, %s can retry in %s", e.Scope, e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,281 @@
+package login
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Fail(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := Policy{
+		MaxFailures:     3,
+		LockoutDuration: 15 * time.Minute,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Second,
+		Window:          15 * time.Minute,
+	}
+
+	tests := map[string]struct {
+		policy   Policy
+		attempts Attempts
+		expected Attempts
+	}{
+		"first failure is delayed the base delay": {
+			policy: policy,
+			expected: Attempts{
+				Failures:      1,
+				LastFailureAt: now,
+				RetryAt:       now.Add(time.Second),
+			},
+		},
+		"delay is doubled after each failure": {
+			policy: Policy{MaxFailures: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second, Window: time.Hour},
+			attempts: Attempts{
+				Failures:      2,
+				LastFailureAt: now.Add(-time.Minute),
+				RetryAt:       now.Add(-58 * time.Second),
+			},
+			expected: Attempts{
+				Failures:      3,
+				LastFailureAt: now,
+				RetryAt:       now.Add(4 * time.Second),
+			},
+		},
+		"delay is capped at the max delay": {
+			policy: Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second, Window: time.Hour},
+			attempts: Attempts{
+				Failures:      9,
+				LastFailureAt: now.Add(-time.Minute),
+			},
+			expected: Attempts{
+				Failures:      10,
+				LastFailureAt: now,
+				RetryAt:       now.Add(5 * time.Second),
+			},
+		},
+		"max failures locks out": {
+			policy: policy,
+			attempts: Attempts{
+				Failures:      2,
+				LastFailureAt: now.Add(-time.Minute),
+			},
+			expected: Attempts{
+				Failures:      3,
+				LastFailureAt: now,
+				RetryAt:       now.Add(4 * time.Second),
+				LockedUntil:   now.Add(15 * time.Minute),
+			},
+		},
+		"failures outside the window are forgotten": {
+			policy: policy,
+			attempts: Attempts{
+				Failures:      2,
+				LastFailureAt: now.Add(-time.Hour),
+			},
+			expected: Attempts{
+				Failures:      1,
+				LastFailureAt: now,
+				RetryAt:       now.Add(time.Second),
+			},
+		},
+		"failures before an expired lockout are forgotten": {
+			policy: policy,
+			attempts: Attempts{
+				Failures:      3,
+				LastFailureAt: now.Add(-10 * time.Minute),
+				LockedUntil:   now.Add(-time.Minute),
+			},
+			expected: Attempts{
+				Failures:      1,
+				LastFailureAt: now,
+				RetryAt:       now.Add(time.Second),
+			},
+		},
+		"attempt in flight is released": {
+			policy:   policy,
+			attempts: Attempts{InFlight: 2, InFlightUntil: now.Add(time.Minute)},
+			expected: Attempts{
+				Failures:      1,
+				LastFailureAt: now,
+				RetryAt:       now.Add(time.Second),
+				InFlight:      1,
+				InFlightUntil: now.Add(time.Minute),
+			},
+		},
+		"no max failures never locks out": {
+			policy: Policy{Window: time.Hour},
+			attempts: Attempts{
+				Failures:      99,
+				LastFailureAt: now.Add(-time.Minute),
+			},
+			expected: Attempts{
+				Failures:      100,
+				LastFailureAt: now,
+				RetryAt:       now,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual := test.policy.Fail(test.attempts, now)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestAttempts_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		attempts Attempts
+		expected time.Duration
+	}{
+		"no attempts": {},
+		"delayed": {
+			attempts: Attempts{RetryAt: now.Add(2 * time.Second)},
+			expected: 2 * time.Second,
+		},
+		"delay passed": {
+			attempts: Attempts{RetryAt: now.Add(-time.Second)},
+		},
+		"locked out": {
+			attempts: Attempts{RetryAt: now.Add(2 * time.Second), LockedUntil: now.Add(time.Minute)},
+			expected: time.Minute,
+		},
+		"lockout expired": {
+			attempts: Attempts{RetryAt: now.Add(-time.Minute), LockedUntil: now.Add(-time.Second)},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual := test.attempts.RetryAfter(now)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestPolicy_Reserve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	usernamePolicy := Policy{MaxFailures: 3, LockoutDuration: time.Hour, BaseDelay: time.Second, Window: time.Hour}
+	ipPolicy := Policy{MaxFailures: 3, LockoutDuration: time.Hour, Window: time.Hour}
+	key := KeyForUsername("john")
+
+	tests := map[string]struct {
+		policy        Policy
+		attempts      Attempts
+		expected      Attempts
+		wantThrottled *ThrottledError
+	}{
+		"first attempt is in flight": {
+			policy:   usernamePolicy,
+			expected: Attempts{InFlight: 1, InFlightUntil: now.Add(inFlightTimeout)},
+		},
+		"delayed": {
+			policy:        usernamePolicy,
+			attempts:      Attempts{Failures: 1, LastFailureAt: now, RetryAt: now.Add(time.Second)},
+			wantThrottled: &ThrottledError{Scope: ScopeUsername, RetryAfter: time.Second},
+		},
+		"locked out": {
+			policy:        usernamePolicy,
+			attempts:      Attempts{Failures: 3, LastFailureAt: now, RetryAt: now, LockedUntil: now.Add(time.Hour)},
+			wantThrottled: &ThrottledError{Scope: ScopeUsername, Locked: true, RetryAfter: time.Hour},
+		},
+		"attempt in flight would delay it": {
+			policy:        usernamePolicy,
+			attempts:      Attempts{InFlight: 1, InFlightUntil: now.Add(time.Second)},
+			wantThrottled: &ThrottledError{Scope: ScopeUsername, RetryAfter: time.Second},
+		},
+		"attempts in flight would not delay it": {
+			policy:   ipPolicy,
+			attempts: Attempts{Failures: 1, LastFailureAt: now, InFlight: 1, InFlightUntil: now.Add(time.Second)},
+			expected: Attempts{
+				Failures:      1,
+				LastFailureAt: now,
+				InFlight:      2,
+				InFlightUntil: now.Add(inFlightTimeout),
+			},
+		},
+		"attempts in flight would lock it out": {
+			policy:        ipPolicy,
+			attempts:      Attempts{Failures: 1, LastFailureAt: now, InFlight: 2, InFlightUntil: now.Add(time.Second)},
+			wantThrottled: &ThrottledError{Scope: ScopeUsername, RetryAfter: time.Second},
+		},
+		"attempts in flight timed out": {
+			policy:   usernamePolicy,
+			attempts: Attempts{InFlight: 1, InFlightUntil: now},
+			expected: Attempts{InFlight: 1, InFlightUntil: now.Add(inFlightTimeout)},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual, err := test.policy.Reserve(key, test.attempts, now)
+
+			// Assert
+			if test.wantThrottled != nil {
+				require.ErrorIs(t, err, *test.wantThrottled)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestAttempts_Release(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		attempts Attempts
+		expected Attempts
+	}{
+		"no attempts in flight": {},
+		"last attempt in flight": {
+			attempts: Attempts{Failures: 1, InFlight: 1, InFlightUntil: now.Add(time.Minute)},
+			expected: Attempts{Failures: 1},
+		},
+		"attempts in flight": {
+			attempts: Attempts{InFlight: 2, InFlightUntil: now.Add(time.Minute)},
+			expected: Attempts{InFlight: 1, InFlightUntil: now.Add(time.Minute)},
+		},
+		"attempts in flight timed out": {
+			attempts: Attempts{InFlight: 2, InFlightUntil: now},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual := test.attempts.Release(now)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
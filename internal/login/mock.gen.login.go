@@ -0,0 +1,392 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: login.go
+//
+// Generated by this command:
+//
+//	mockgen -typed -package login -source login.go -package login -destination ./mock.gen.login.go
+//
+
+// Package login is a generated GoMock package.
+package login
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	users "github.com/manuelarte/go-web-layout/internal/users"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockRepository) Authenticate(arg0 context.Context, arg1 users.Username, arg2 users.Password) (users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockRepositoryMockRecorder) Authenticate(arg0, arg1, arg2 any) *MockRepositoryAuthenticateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockRepository)(nil).Authenticate), arg0, arg1, arg2)
+	return &MockRepositoryAuthenticateCall{Call: call}
+}
+
+// MockRepositoryAuthenticateCall wrap *gomock.Call
+type MockRepositoryAuthenticateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryAuthenticateCall) Return(arg0 users.User, arg1 error) *MockRepositoryAuthenticateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryAuthenticateCall) Do(f func(context.Context, users.Username, users.Password) (users.User, error)) *MockRepositoryAuthenticateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryAuthenticateCall) DoAndReturn(f func(context.Context, users.Username, users.Password) (users.User, error)) *MockRepositoryAuthenticateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteExpired mocks base method.
+func (m *MockRepository) DeleteExpired(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockRepositoryMockRecorder) DeleteExpired(arg0, arg1 any) *MockRepositoryDeleteExpiredCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockRepository)(nil).DeleteExpired), arg0, arg1)
+	return &MockRepositoryDeleteExpiredCall{Call: call}
+}
+
+// MockRepositoryDeleteExpiredCall wrap *gomock.Call
+type MockRepositoryDeleteExpiredCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryDeleteExpiredCall) Return(arg0 int64, arg1 error) *MockRepositoryDeleteExpiredCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryDeleteExpiredCall) Do(f func(context.Context, time.Time) (int64, error)) *MockRepositoryDeleteExpiredCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryDeleteExpiredCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockRepositoryDeleteExpiredCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Fail mocks base method.
+func (m *MockRepository) Fail(arg0 context.Context, arg1 Key, arg2 Policy, arg3 time.Time) (Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockRepositoryMockRecorder) Fail(arg0, arg1, arg2, arg3 any) *MockRepositoryFailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockRepository)(nil).Fail), arg0, arg1, arg2, arg3)
+	return &MockRepositoryFailCall{Call: call}
+}
+
+// MockRepositoryFailCall wrap *gomock.Call
+type MockRepositoryFailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryFailCall) Return(arg0 Attempts, arg1 error) *MockRepositoryFailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryFailCall) Do(f func(context.Context, Key, Policy, time.Time) (Attempts, error)) *MockRepositoryFailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryFailCall) DoAndReturn(f func(context.Context, Key, Policy, time.Time) (Attempts, error)) *MockRepositoryFailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetAttempts mocks base method.
+func (m *MockRepository) GetAttempts(arg0 context.Context, arg1 Key) (Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttempts", arg0, arg1)
+	ret0, _ := ret[0].(Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttempts indicates an expected call of GetAttempts.
+func (mr *MockRepositoryMockRecorder) GetAttempts(arg0, arg1 any) *MockRepositoryGetAttemptsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttempts", reflect.TypeOf((*MockRepository)(nil).GetAttempts), arg0, arg1)
+	return &MockRepositoryGetAttemptsCall{Call: call}
+}
+
+// MockRepositoryGetAttemptsCall wrap *gomock.Call
+type MockRepositoryGetAttemptsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetAttemptsCall) Return(arg0 Attempts, arg1 error) *MockRepositoryGetAttemptsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetAttemptsCall) Do(f func(context.Context, Key) (Attempts, error)) *MockRepositoryGetAttemptsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetAttemptsCall) DoAndReturn(f func(context.Context, Key) (Attempts, error)) *MockRepositoryGetAttemptsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetLockout mocks base method.
+func (m *MockRepository) GetLockout(arg0 context.Context, arg1 users.UserID) (Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLockout", arg0, arg1)
+	ret0, _ := ret[0].(Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLockout indicates an expected call of GetLockout.
+func (mr *MockRepositoryMockRecorder) GetLockout(arg0, arg1 any) *MockRepositoryGetLockoutCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLockout", reflect.TypeOf((*MockRepository)(nil).GetLockout), arg0, arg1)
+	return &MockRepositoryGetLockoutCall{Call: call}
+}
+
+// MockRepositoryGetLockoutCall wrap *gomock.Call
+type MockRepositoryGetLockoutCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetLockoutCall) Return(arg0 Attempts, arg1 error) *MockRepositoryGetLockoutCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetLockoutCall) Do(f func(context.Context, users.UserID) (Attempts, error)) *MockRepositoryGetLockoutCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetLockoutCall) DoAndReturn(f func(context.Context, users.UserID) (Attempts, error)) *MockRepositoryGetLockoutCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Release mocks base method.
+func (m *MockRepository) Release(arg0 context.Context, arg1 Key, arg2 Policy, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockRepositoryMockRecorder) Release(arg0, arg1, arg2, arg3 any) *MockRepositoryReleaseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockRepository)(nil).Release), arg0, arg1, arg2, arg3)
+	return &MockRepositoryReleaseCall{Call: call}
+}
+
+// MockRepositoryReleaseCall wrap *gomock.Call
+type MockRepositoryReleaseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryReleaseCall) Return(arg0 error) *MockRepositoryReleaseCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryReleaseCall) Do(f func(context.Context, Key, Policy, time.Time) error) *MockRepositoryReleaseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryReleaseCall) DoAndReturn(f func(context.Context, Key, Policy, time.Time) error) *MockRepositoryReleaseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Reserve mocks base method.
+func (m *MockRepository) Reserve(arg0 context.Context, arg1 Key, arg2 Policy, arg3 time.Time) (Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockRepositoryMockRecorder) Reserve(arg0, arg1, arg2, arg3 any) *MockRepositoryReserveCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockRepository)(nil).Reserve), arg0, arg1, arg2, arg3)
+	return &MockRepositoryReserveCall{Call: call}
+}
+
+// MockRepositoryReserveCall wrap *gomock.Call
+type MockRepositoryReserveCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryReserveCall) Return(arg0 Attempts, arg1 error) *MockRepositoryReserveCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryReserveCall) Do(f func(context.Context, Key, Policy, time.Time) (Attempts, error)) *MockRepositoryReserveCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryReserveCall) DoAndReturn(f func(context.Context, Key, Policy, time.Time) (Attempts, error)) *MockRepositoryReserveCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Reset mocks base method.
+func (m *MockRepository) Reset(arg0 context.Context, arg1 Key) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockRepositoryMockRecorder) Reset(arg0, arg1 any) *MockRepositoryResetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockRepository)(nil).Reset), arg0, arg1)
+	return &MockRepositoryResetCall{Call: call}
+}
+
+// MockRepositoryResetCall wrap *gomock.Call
+type MockRepositoryResetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryResetCall) Return(arg0 error) *MockRepositoryResetCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryResetCall) Do(f func(context.Context, Key) error) *MockRepositoryResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryResetCall) DoAndReturn(f func(context.Context, Key) error) *MockRepositoryResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Unlock mocks base method.
+func (m *MockRepository) Unlock(arg0 context.Context, arg1 users.UserID) (Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", arg0, arg1)
+	ret0, _ := ret[0].(Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockRepositoryMockRecorder) Unlock(arg0, arg1 any) *MockRepositoryUnlockCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockRepository)(nil).Unlock), arg0, arg1)
+	return &MockRepositoryUnlockCall{Call: call}
+}
+
+// MockRepositoryUnlockCall wrap *gomock.Call
+type MockRepositoryUnlockCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryUnlockCall) Return(arg0 Attempts, arg1 error) *MockRepositoryUnlockCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryUnlockCall) Do(f func(context.Context, users.UserID) (Attempts, error)) *MockRepositoryUnlockCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryUnlockCall) DoAndReturn(f func(context.Context, users.UserID) (Attempts, error)) *MockRepositoryUnlockCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,89 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+)
+
+var (
+	_ Mailer = new(FileMailer)
+	_ Mailer = new(MemoryMailer)
+)
+
+type (
+	// FileMailer writes the emails, instead of sending them, as .eml files in a directory, to be opened in local runs.
+	FileMailer struct {
+		dir  string
+		from string
+	}
+
+	// MemoryMailer keeps the emails, instead of sending them, to be checked in the tests.
+	MemoryMailer struct {
+		mu       sync.Mutex
+		messages []Message
+	}
+)
+
+// NewFileMailer creates the mailer writing the emails from the address in the directory, created if it does not exist.
+func NewFileMailer(dir, from string) FileMailer {
+	return FileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m FileMailer) Send(ctx context.Context, message Message) error {
+	_, span := observability.StartSpan(ctx, "FileMailer.Send")
+	defer span.End()
+
+	now := time.Now()
+
+	data, err := message.format(m.from, now)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(m.dir, 0o750)
+	if err != nil {
+		return fmt.Errorf("error creating mail directory: %w", err)
+	}
+
+	// The files are named by their date first, so they are listed in the order they were sent.
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), uuid.NewString())
+
+	err = os.WriteFile(filepath.Join(m.dir, name), data, 0o600)
+	if err != nil {
+		return fmt.Errorf("error writing mail: %w", err)
+	}
+
+	return nil
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(_ context.Context, message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, message)
+
+	return nil
+}
+
+// Messages returns the emails sent, in order.
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHeader is returned for the messages with a recipient or a sender with line breaks,
+// that could inject headers.
+var ErrInvalidHeader = errors.New("invalid mail header")
+
+type (
+	// Message is a plain text email to a single recipient.
+	Message struct {
+		To      string
+		Subject string
+		Body    string
+	}
+
+	// Mailer sends the emails.
+	Mailer interface {
+		// Send sends the message, returning once it was accepted to be delivered.
+		Send(context.Context, Message) error
+	}
+)
+
+// format returns the message from the sender in the Internet Message Format (RFC 5322),
+// with the body in UTF-8, quoted-printable encoded.
+func (m Message) format(from string, date time.Time) ([]byte, error) {
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(from, "\r\n") {
+		return nil, ErrInvalidHeader
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+
+	_, err := body.Write([]byte(m.Body))
+	if err != nil {
+		return nil, fmt.Errorf("error encoding mail body: %w", err)
+	}
+
+	err = body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding mail body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,138 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+)
+
+func TestTemplates_Render(t *testing.T) {
+	t.Parallel()
+
+	data := struct {
+		Name      string
+		Link      string
+		ExpiresAt time.Time
+	}{
+		Name:      "John",
+		Link:      "http://localhost:3000/path?token=abc",
+		ExpiresAt: time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC),
+	}
+
+	tests := map[string]struct {
+		name    string
+		data    any
+		subject string
+		wantErr bool
+	}{
+		"email verification": {
+			name:    "email_verification",
+			data:    data,
+			subject: "Verify your email",
+		},
+		"password reset": {
+			name:    "password_reset",
+			data:    data,
+			subject: "Reset your password",
+		},
+		"unknown template": {
+			name:    "unknown",
+			data:    data,
+			wantErr: true,
+		},
+		"missing data": {
+			name:    "password_reset",
+			data:    map[string]string{"Name": "John"},
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			templates, err := ParseTemplates(goweblayout.ResourcesFolder, "resources/mail/*.tmpl")
+			require.NoError(t, err)
+
+			// Act
+			message, err := templates.Render(test.name, "john@example.com", test.data)
+
+			// Assert
+			if test.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "john@example.com", message.To)
+			assert.Equal(t, test.subject, message.Subject)
+			assert.Contains(t, message.Body, "Hi John,")
+			assert.Contains(t, message.Body, "http://localhost:3000/path?token=abc")
+			assert.Contains(t, message.Body, "2026-01-02 15:04 UTC")
+		})
+	}
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		message Message
+		want    []string
+		wantErr error
+	}{
+		"message written": {
+			message: Message{To: "john@example.com", Subject: "Héllo", Body: "Hi John,\n"},
+			want: []string{
+				"From: Go Web Layout <no-reply@localhost>\r\n",
+				"To: john@example.com\r\n",
+				"Subject: =?utf-8?q?H=C3=A9llo?=\r\n",
+				"Content-Type: text/plain; charset=utf-8\r\n",
+				"\r\n\r\nHi John,\r\n",
+			},
+		},
+		"header injection": {
+			message: Message{To: "john@example.com\r\nBcc: jane@example.com", Subject: "Hello", Body: "Hi John,\n"},
+			wantErr: ErrInvalidHeader,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			dir := filepath.Join(t.TempDir(), "mails")
+			m := NewFileMailer(dir, "Go Web Layout <no-reply@localhost>")
+
+			// Act
+			err := m.Send(t.Context(), test.message)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			data, err := os.ReadFile(files[0])
+			require.NoError(t, err)
+
+			for _, want := range test.want {
+				assert.Contains(t, string(data), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+)
+
+var _ Mailer = new(SMTPMailer)
+
+// SMTPMailer sends the emails to an SMTP server, using STARTTLS if the server supports it.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates the mailer sending the emails from the address to the SMTP server at addr (host:port).
+// The server is authenticated with PLAIN if the username is not empty.
+func NewSMTPMailer(addr, from, username, password string) (SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return SMTPMailer{}, fmt.Errorf("error parsing smtp address: %w", err)
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return SMTPMailer{
+		addr: addr,
+		from: from,
+		auth: auth,
+	}, nil
+}
+
+func (m SMTPMailer) Send(ctx context.Context, message Message) error {
+	_, span := observability.StartSpan(
+		ctx,
+		"SMTPMailer.Send",
+		oteltrace.WithAttributes(attribute.String("subject", message.Subject)),
+	)
+	defer span.End()
+
+	data, err := message.format(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = smtp.SendMail(m.addr, m.auth, m.from, []string{message.To}, data)
+	if err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+
+	return nil
+}
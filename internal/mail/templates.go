@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+)
+
+// Templates renders the messages from text templates.
+// Each message is defined by a "<name>.subject" and a "<name>.body" template.
+type Templates struct {
+	templates *template.Template
+}
+
+// ParseTemplates parses the templates of the files of the file system matching the patterns.
+func ParseTemplates(fsys fs.FS, patterns ...string) (Templates, error) {
+	templates, err := template.New("").Option("missingkey=error").ParseFS(fsys, patterns...)
+	if err != nil {
+		return Templates{}, fmt.Errorf("error parsing mail templates: %w", err)
+	}
+
+	return Templates{templates: templates}, nil
+}
+
+// Render returns the message to the recipient, with the subject and body of the name rendered with the data.
+func (t Templates) Render(name, to string, data any) (Message, error) {
+	var subject, body bytes.Buffer
+
+	err := t.templates.ExecuteTemplate(&subject, name+".subject", data)
+	if err != nil {
+		return Message{}, fmt.Errorf("error rendering %s mail subject: %w", name, err)
+	}
+
+	err = t.templates.ExecuteTemplate(&body, name+".body", data)
+	if err != nil {
+		return Message{}, fmt.Errorf("error rendering %s mail body: %w", name, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()) + "\n",
+	}, nil
+}
@@ -0,0 +1,303 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// minRefreshInterval is how long the keys are not fetched again after an attempt, even for an unknown key id,
+	// so the tokens with made-up key ids can't flood the provider.
+	minRefreshInterval = 10 * time.Second
+	// maxResponseSize caps the responses of the provider that are read.
+	maxResponseSize = 1 << 20
+	// discoveryPath is the path of the OpenID Connect discovery document, relative to the issuer.
+	discoveryPath = "/.well-known/openid-configuration"
+)
+
+var (
+	// ErrUnknownKey is returned for the tokens signed with a key that is not in the key set of the provider.
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrKeySetUnavailable is returned when the key set of the provider can't be fetched.
+	ErrKeySetUnavailable = errors.New("the key set of the identity provider is unavailable")
+)
+
+type (
+	// KeySet is the cache of the public keys (JWKS) of the provider, by key id.
+	// The keys are fetched again once they expire, or when a token is signed with an unknown key, e.g. after the
+	// provider rotated them, at most once every minRefreshInterval.
+	// If the provider is unavailable, the expired keys are used until they can be fetched again.
+	KeySet struct {
+		client             *http.Client
+		issuer             string
+		ttl                time.Duration
+		minRefreshInterval time.Duration
+
+		mu sync.Mutex
+		// url of the key set, discovered from the issuer if it is not configured.
+		url         string
+		keys        map[string]crypto.PublicKey
+		fetchedAt   time.Time
+		attemptedAt time.Time
+		lastErr     error
+	}
+
+	// jwk is a JSON Web Key (RFC 7517), only the fields of the supported public keys.
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+// NewKeySet creates the cache of the keys in the url, or in the jwks_uri of the discovery document of the issuer
+// if the url is empty, that are kept for the ttl.
+func NewKeySet(client *http.Client, issuer, url string, ttl time.Duration) *KeySet {
+	return &KeySet{
+		client:             client,
+		issuer:             issuer,
+		ttl:                ttl,
+		minRefreshInterval: minRefreshInterval,
+		url:                url,
+	}
+}
+
+// Key returns the public key with the id.
+// It either returns the key or one of the following errors:
+// - ErrUnknownKey, there is no key with the id.
+// - ErrKeySetUnavailable, the keys can't be fetched.
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[kid]
+	if ok && time.Since(s.fetchedAt) < s.ttl {
+		return key, nil
+	}
+
+	if time.Since(s.attemptedAt) < s.minRefreshInterval {
+		if ok {
+			return key, nil
+		}
+
+		if s.lastErr != nil {
+			return nil, s.lastErr
+		}
+
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	s.attemptedAt = time.Now()
+
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		s.lastErr = fmt.Errorf("%w: %w", ErrKeySetUnavailable, err)
+
+		if ok {
+			return key, nil
+		}
+
+		return nil, s.lastErr
+	}
+
+	s.keys, s.fetchedAt, s.lastErr = keys, s.attemptedAt, nil
+
+	key, ok = s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// fetch gets the signing keys of the provider, the keys that are not supported are ignored.
+func (s *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	if s.url == "" {
+		url, err := s.discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		s.url = url
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := s.get(ctx, s.url, &jwks); err != nil {
+		return nil, fmt.Errorf("error getting key set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+// discover returns the url of the key set in the discovery document of the issuer.
+func (s *KeySet) discover(ctx context.Context) (string, error) {
+	var document struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"` //nolint:tagliatelle // OpenID Connect discovery field
+	}
+	if err := s.get(ctx, strings.TrimSuffix(s.issuer, "/")+discoveryPath, &document); err != nil {
+		return "", fmt.Errorf("error getting discovery document: %w", err)
+	}
+
+	if document.Issuer != s.issuer {
+		return "", fmt.Errorf("the discovery document is of the issuer %q, not %q", document.Issuer, s.issuer)
+	}
+
+	if document.JWKSURI == "" {
+		return "", errors.New("the discovery document has no jwks_uri")
+	}
+
+	return document.JWKSURI, nil
+}
+
+// get gets the JSON document in the url into v.
+func (s *KeySet) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("error decoding %s: %w", url, err)
+	}
+
+	return nil
+}
+
+// publicKey returns the RSA, EC (P-256, P-384 or P-521) or Ed25519 public key.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		return k.rsaPublicKey()
+	case "EC":
+		return k.ecPublicKey()
+	case "OKP":
+		return k.okpPublicKey()
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+		return nil, errors.New("rsa exponent too large")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecPublicKey() (*ecdsa.PublicKey, error) {
+	curve, ok := map[string]elliptic.Curve{
+		"P-256": elliptic.P256(),
+		"P-384": elliptic.P384(),
+		"P-521": elliptic.P521(),
+	}[k.Crv]
+	if !ok {
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+
+	if err := errors.Join(errX, errY); err != nil {
+		return nil, fmt.Errorf("error decoding ec point: %w", err)
+	}
+
+	// The coordinates have the size of the curve, the uncompressed point is 0x04 || x || y.
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) > size || len(y) > size {
+		return nil, errors.New("ec point too large")
+	}
+
+	point := make([]byte, 1+2*size)
+	point[0] = 4
+	copy(point[1+size-len(x):1+size], x)
+	copy(point[1+2*size-len(y):], y)
+
+	key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ec key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (k jwk) okpPublicKey() (ed25519.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding okp key: %w", err)
+	}
+
+	if k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	return ed25519.PublicKey(x), nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding integer: %w", err)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
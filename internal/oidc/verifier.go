@@ -0,0 +1,193 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+var (
+	// ErrInvalidToken is returned for the tokens that are malformed, expired, not signed by the provider,
+	// or not issued by the issuer for the audience.
+	ErrInvalidToken = errors.New("invalid bearer token")
+	// ErrNoIssuer is returned for the configurations without issuer.
+	ErrNoIssuer = errors.New("the issuer is required")
+	// ErrNoAudience is returned for the configurations without audience, the tokens issued for any client
+	// of the provider would be accepted.
+	ErrNoAudience = errors.New("the audience is required")
+)
+
+type (
+	// Config is the configuration of the provider whose tokens are accepted.
+	Config struct {
+		// Issuer is the issuer (iss) of the tokens, e.g. "https://idp.example.com/realms/main".
+		Issuer string
+		// Audience is the audience (aud) the tokens must be issued for, e.g. "go-web-layout".
+		Audience string
+		// JWKSURL is the url of the public keys of the provider, discovered from the issuer if empty.
+		JWKSURL string
+		// ClockSkew is the leeway of the expiration, not before and issued at times of the tokens.
+		ClockSkew time.Duration
+		// CacheTTL is how long the public keys of the provider are kept.
+		CacheTTL time.Duration
+		// RolesClaim is the claim with the roles of the principal, nested claims are separated by dots,
+		// e.g. "realm_access.roles". It is either an array or a string with the roles separated by spaces.
+		RolesClaim string
+		// RoleMapping maps the values of RolesClaim to roles, e.g. "gwl-admins" to "admin".
+		// The values that are not mapped are kept if they are roles, and ignored otherwise.
+		RoleMapping map[string]auth.Role
+		// TenantClaim is the claim with the tenant of the principal, nested claims are separated by dots,
+		// e.g. "org.tenant". The tokens without a valid tenant are rejected if it is set,
+		// otherwise the principals are not bound to a tenant.
+		TenantClaim string
+	}
+
+	// Verifier verifies the JWT access tokens issued by an OpenID Connect provider,
+	// as a resource server, returning the principals they were issued to.
+	Verifier struct {
+		cfg    Config
+		keys   *KeySet
+		parser *jwt.Parser
+	}
+)
+
+// NewVerifier creates the verifier of the tokens of the provider, fetching its keys with the client.
+func NewVerifier(client *http.Client, cfg Config) (Verifier, error) {
+	if cfg.Issuer == "" {
+		return Verifier{}, ErrNoIssuer
+	}
+
+	if cfg.Audience == "" {
+		return Verifier{}, ErrNoAudience
+	}
+
+	for value, role := range cfg.RoleMapping {
+		if err := role.IsValid(); err != nil {
+			return Verifier{}, fmt.Errorf("error mapping %q: %w", value, err)
+		}
+	}
+
+	return Verifier{
+		cfg:  cfg,
+		keys: NewKeySet(client, cfg.Issuer, cfg.JWKSURL, cfg.CacheTTL),
+		parser: jwt.NewParser(
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithLeeway(cfg.ClockSkew),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			// Only asymmetric algorithms, the keys of the provider are public.
+			jwt.WithValidMethods([]string{
+				"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA",
+			}),
+		),
+	}, nil
+}
+
+// Enabled returns whether the verifier was created with a provider, the zero verifier rejects all the tokens.
+func (v Verifier) Enabled() bool {
+	return v.keys != nil
+}
+
+// Verify returns the principal of the token, identified by its subject, e.g. "oidc:248289761001",
+// with the roles of its claims.
+// It either returns the principal or one of the following errors:
+// - ErrInvalidToken, the token is not valid.
+// - ErrKeySetUnavailable, the keys of the provider can't be fetched to verify it.
+func (v Verifier) Verify(ctx context.Context, token string) (auth.Principal, error) {
+	if !v.Enabled() {
+		return auth.Principal{}, fmt.Errorf("%w: bearer tokens are not accepted", ErrInvalidToken)
+	}
+
+	claims := jwt.MapClaims{}
+
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		if errors.Is(err, ErrKeySetUnavailable) {
+			return auth.Principal{}, fmt.Errorf("error verifying token: %w", err)
+		}
+
+		return auth.Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return auth.Principal{}, fmt.Errorf("%w: no subject", ErrInvalidToken)
+	}
+
+	principal := auth.Principal{
+		ID:    "oidc:" + subject,
+		Roles: v.roles(claims),
+	}
+
+	if v.cfg.TenantClaim != "" {
+		value, _ := nestedClaim(claims, v.cfg.TenantClaim).(string)
+
+		principal.Tenant, err = tenant.Parse(value)
+		if err != nil {
+			return auth.Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+		}
+	}
+
+	return principal, nil
+}
+
+// roles returns the roles of the roles claim, mapped with the role mapping.
+func (v Verifier) roles(claims jwt.MapClaims) []auth.Role {
+	var values []string
+
+	switch claim := nestedClaim(claims, v.cfg.RolesClaim).(type) {
+	case string:
+		values = strings.Fields(claim)
+	case []any:
+		for _, item := range claim {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+
+	roles := make([]auth.Role, 0, len(values))
+
+	for _, value := range values {
+		role, ok := v.cfg.RoleMapping[value]
+		if !ok {
+			role = auth.Role(value)
+		}
+
+		if role.IsValid() == nil {
+			roles = append(roles, role)
+		}
+	}
+
+	return slices.Compact(slices.Sorted(slices.Values(roles)))
+}
+
+// nestedClaim returns the value of the claim, whose nested claims are separated by dots, nil if there is none.
+func nestedClaim(claims jwt.MapClaims, name string) any {
+	var claim any = map[string]any(claims)
+
+	for part := range strings.SplitSeq(name, ".") {
+		object, ok := claim.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		claim = object[part]
+	}
+
+	return claim
+}
@@ -0,0 +1,535 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "go-web-layout"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, errRSA := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, errEC := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, errEd := ed25519.GenerateKey(rand.Reader)
+	otherKey, errOther := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, errors.Join(errRSA, errEC, errEd, errOther))
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   testIssuer,
+			"aud":   testAudience,
+			"sub":   "248289761001",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"gwl-writers", "reader", "unknown"},
+		}
+	}
+
+	tests := map[string]struct {
+		method  jwt.SigningMethod
+		kid     string
+		key     crypto.PrivateKey
+		claims  func(jwt.MapClaims)
+		want    auth.Principal
+		wantErr error
+	}{
+		"valid rsa token": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			want:   auth.Principal{ID: "oidc:248289761001", Roles: []auth.Role{auth.RoleReader, auth.RoleWriter}},
+		},
+		"valid ec token": {
+			method: jwt.SigningMethodES256,
+			kid:    "ec",
+			key:    ecKey,
+			want:   auth.Principal{ID: "oidc:248289761001", Roles: []auth.Role{auth.RoleReader, auth.RoleWriter}},
+		},
+		"valid ed25519 token": {
+			method: jwt.SigningMethodEdDSA,
+			kid:    "ed",
+			key:    edKey,
+			want:   auth.Principal{ID: "oidc:248289761001", Roles: []auth.Role{auth.RoleReader, auth.RoleWriter}},
+		},
+		"audience in an array": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				c["aud"] = []string{"other", testAudience}
+			},
+			want: auth.Principal{ID: "oidc:248289761001", Roles: []auth.Role{auth.RoleReader, auth.RoleWriter}},
+		},
+		"roles separated by spaces": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				c["roles"] = "admin gwl-writers"
+			},
+			want: auth.Principal{ID: "oidc:248289761001", Roles: []auth.Role{auth.RoleAdmin, auth.RoleWriter}},
+		},
+		"without roles": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				delete(c, "roles")
+			},
+			want: auth.Principal{ID: "oidc:248289761001"},
+		},
+		"expired within the clock skew": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				c["exp"] = time.Now().Add(-30 * time.Second).Unix()
+			},
+			want: auth.Principal{ID: "oidc:248289761001", Roles: []auth.Role{auth.RoleReader, auth.RoleWriter}},
+		},
+		"expired": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				c["exp"] = time.Now().Add(-2 * time.Minute).Unix()
+			},
+			wantErr: ErrInvalidToken,
+		},
+		"without expiration": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				delete(c, "exp")
+			},
+			wantErr: ErrInvalidToken,
+		},
+		"not valid yet": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				c["nbf"] = time.Now().Add(5 * time.Minute).Unix()
+			},
+			wantErr: ErrInvalidToken,
+		},
+		"other issuer": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				c["iss"] = "https://other.example.com"
+			},
+			wantErr: ErrInvalidToken,
+		},
+		"other audience": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				c["aud"] = "other"
+			},
+			wantErr: ErrInvalidToken,
+		},
+		"without subject": {
+			method: jwt.SigningMethodRS256,
+			kid:    "rsa",
+			key:    rsaKey,
+			claims: func(c jwt.MapClaims) {
+				delete(c, "sub")
+			},
+			wantErr: ErrInvalidToken,
+		},
+		"signed with another key": {
+			method:  jwt.SigningMethodRS256,
+			kid:     "rsa",
+			key:     otherKey,
+			wantErr: ErrInvalidToken,
+		},
+		"unknown key id": {
+			method:  jwt.SigningMethodRS256,
+			kid:     "other",
+			key:     otherKey,
+			wantErr: ErrInvalidToken,
+		},
+		"symmetric algorithm": {
+			method:  jwt.SigningMethodHS256,
+			kid:     "rsa",
+			key:     []byte("secret"),
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			idp := newTestProvider(t, map[string]crypto.PublicKey{
+				"rsa": rsaKey.Public(),
+				"ec":  ecKey.Public(),
+				"ed":  edKey.Public(),
+			})
+
+			verifier, err := NewVerifier(idp.server.Client(), Config{
+				Issuer:      testIssuer,
+				Audience:    testAudience,
+				JWKSURL:     idp.server.URL + "/jwks",
+				ClockSkew:   time.Minute,
+				CacheTTL:    time.Hour,
+				RolesClaim:  "roles",
+				RoleMapping: map[string]auth.Role{"gwl-writers": auth.RoleWriter},
+			})
+			require.NoError(t, err)
+
+			claims := validClaims()
+			if test.claims != nil {
+				test.claims(claims)
+			}
+
+			token := sign(t, test.method, test.kid, test.key, claims)
+
+			// Act
+			actual, err := verifier.Verify(t.Context(), token)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, actual)
+		})
+	}
+}
+
+func TestVerifier_Verify_NestedRolesClaim(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	idp := newTestProvider(t, map[string]crypto.PublicKey{"ec": key.Public()})
+
+	verifier, err := NewVerifier(idp.server.Client(), Config{
+		Issuer:     testIssuer,
+		Audience:   testAudience,
+		JWKSURL:    idp.server.URL + "/jwks",
+		CacheTTL:   time.Hour,
+		RolesClaim: "realm_access.roles",
+	})
+	require.NoError(t, err)
+
+	token := sign(t, jwt.SigningMethodES256, "ec", key, jwt.MapClaims{
+		"iss":          testIssuer,
+		"aud":          testAudience,
+		"sub":          "248289761001",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]any{"roles": []string{"admin"}},
+	})
+
+	// Act
+	actual, err := verifier.Verify(t.Context(), token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Role{auth.RoleAdmin}, actual.Roles)
+}
+
+func TestVerifier_Verify_TenantClaim(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	idp := newTestProvider(t, map[string]crypto.PublicKey{"ec": key.Public()})
+
+	tests := map[string]struct {
+		claims     jwt.MapClaims
+		wantTenant tenant.ID
+		wantErr    error
+	}{
+		"with tenant": {
+			claims:     jwt.MapClaims{"org": map[string]any{"tenant": "acme"}},
+			wantTenant: "acme",
+		},
+		"without tenant": {
+			claims:  jwt.MapClaims{},
+			wantErr: ErrInvalidToken,
+		},
+		"invalid tenant": {
+			claims:  jwt.MapClaims{"org": map[string]any{"tenant": "ACME Inc."}},
+			wantErr: ErrInvalidToken,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			verifier, errVerifier := NewVerifier(idp.server.Client(), Config{
+				Issuer:      testIssuer,
+				Audience:    testAudience,
+				JWKSURL:     idp.server.URL + "/jwks",
+				CacheTTL:    time.Hour,
+				TenantClaim: "org.tenant",
+			})
+			require.NoError(t, errVerifier)
+
+			claims := jwt.MapClaims{
+				"iss": testIssuer,
+				"aud": testAudience,
+				"sub": "248289761001",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}
+			maps.Copy(claims, test.claims)
+
+			// Act
+			actual, errVerify := verifier.Verify(t.Context(), sign(t, jwt.SigningMethodES256, "ec", key, claims))
+
+			// Assert
+			require.ErrorIs(t, errVerify, test.wantErr)
+			assert.Equal(t, test.wantTenant, actual.Tenant)
+		})
+	}
+}
+
+func TestVerifier_Verify_KeyRotation(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	idp := newTestProvider(t, map[string]crypto.PublicKey{"old": oldKey.Public()})
+
+	// The key set is only discovered from the issuer.
+	verifier, err := NewVerifier(idp.server.Client(), Config{
+		Issuer:   idp.server.URL,
+		Audience: testAudience,
+		CacheTTL: time.Hour,
+	})
+	require.NoError(t, err)
+
+	verifier.keys.minRefreshInterval = 0
+
+	claims := jwt.MapClaims{
+		"iss": idp.server.URL,
+		"aud": testAudience,
+		"sub": "248289761001",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	oldToken := sign(t, jwt.SigningMethodES256, "old", oldKey, claims)
+	newToken := sign(t, jwt.SigningMethodES256, "new", newKey, claims)
+
+	// Act
+	_, errOld := verifier.Verify(t.Context(), oldToken)
+	_, errOldCached := verifier.Verify(t.Context(), oldToken)
+	fetchesBeforeRotation := idp.fetches.Load()
+
+	idp.setKeys(map[string]crypto.PublicKey{"old": oldKey.Public(), "new": newKey.Public()})
+
+	_, errNew := verifier.Verify(t.Context(), newToken)
+
+	// Assert
+	require.NoError(t, errOld)
+	require.NoError(t, errOldCached)
+	require.NoError(t, errNew)
+	assert.Equal(t, int32(1), fetchesBeforeRotation, "the keys are cached")
+	assert.Equal(t, int32(2), idp.fetches.Load(), "the keys are fetched again for an unknown key id")
+}
+
+func TestVerifier_Verify_KeySetUnavailable(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := NewVerifier(server.Client(), Config{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		JWKSURL:  server.URL + "/jwks",
+		CacheTTL: time.Hour,
+	})
+	require.NoError(t, err)
+
+	token := sign(t, jwt.SigningMethodES256, "ec", key, jwt.MapClaims{
+		"iss": testIssuer,
+		"aud": testAudience,
+		"sub": "248289761001",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	// Act
+	_, err = verifier.Verify(t.Context(), token)
+
+	// Assert
+	require.ErrorIs(t, err, ErrKeySetUnavailable)
+	require.NotErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewVerifier(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg     Config
+		wantErr error
+	}{
+		"without issuer": {
+			cfg:     Config{Audience: testAudience},
+			wantErr: ErrNoIssuer,
+		},
+		"without audience": {
+			cfg:     Config{Issuer: testIssuer},
+			wantErr: ErrNoAudience,
+		},
+		"mapped to an unknown role": {
+			cfg: Config{
+				Issuer:      testIssuer,
+				Audience:    testAudience,
+				RoleMapping: map[string]auth.Role{"gwl-admins": "root"},
+			},
+			wantErr: auth.ErrUnknownRole,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := NewVerifier(http.DefaultClient, test.cfg)
+
+			// Assert
+			require.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+// testProvider serves the discovery document and the key set of an identity provider, counting the key set fetches.
+type testProvider struct {
+	server  *httptest.Server
+	fetches atomic.Int32
+
+	mu   sync.Mutex
+	keys map[string]crypto.PublicKey
+}
+
+func newTestProvider(t *testing.T, keys map[string]crypto.PublicKey) *testProvider {
+	t.Helper()
+
+	p := &testProvider{keys: keys}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+discoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   p.server.URL,
+			"jwks_uri": p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, _ *http.Request) {
+		p.fetches.Add(1)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		jwks := make([]map[string]string, 0, len(p.keys))
+		for kid, key := range p.keys {
+			jwks = append(jwks, toJWK(t, kid, key))
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": jwks})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *testProvider) setKeys(keys map[string]crypto.PublicKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.keys = keys
+}
+
+func toJWK(t *testing.T, kid string, key crypto.PublicKey) map[string]string {
+	t.Helper()
+
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA", "kid": kid, "use": "sig",
+			"n": encode(key.N.Bytes()), "e": encode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		point, err := key.Bytes()
+		require.NoError(t, err)
+
+		size := (len(point) - 1) / 2
+
+		return map[string]string{
+			"kty": "EC", "kid": kid, "crv": key.Curve.Params().Name,
+			"x": encode(point[1 : 1+size]), "y": encode(point[1+size:]),
+		}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": encode(key)}
+	default:
+		t.Fatalf("unsupported key %T", key)
+
+		return nil
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key crypto.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
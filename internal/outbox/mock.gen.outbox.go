@@ -0,0 +1,297 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: outbox.go
+//
+// Generated by this command:
+//
+//	mockgen -typed -package outbox -source outbox.go -package outbox -destination ./mock.gen.outbox.go
+//
+
+// Package outbox is a generated GoMock package.
+package outbox
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	users "github.com/manuelarte/go-web-layout/internal/users"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// Delivered mocks base method.
+func (m *MockStore) Delivered(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delivered", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delivered indicates an expected call of Delivered.
+func (mr *MockStoreMockRecorder) Delivered(ctx, id any) *MockStoreDeliveredCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delivered", reflect.TypeOf((*MockStore)(nil).Delivered), ctx, id)
+	return &MockStoreDeliveredCall{Call: call}
+}
+
+// MockStoreDeliveredCall wrap *gomock.Call
+type MockStoreDeliveredCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreDeliveredCall) Return(arg0 error) *MockStoreDeliveredCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreDeliveredCall) Do(f func(context.Context, int64) error) *MockStoreDeliveredCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreDeliveredCall) DoAndReturn(f func(context.Context, int64) error) *MockStoreDeliveredCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Failed mocks base method.
+func (m *MockStore) Failed(ctx context.Context, id int64, retryAt time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Failed", ctx, id, retryAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Failed indicates an expected call of Failed.
+func (mr *MockStoreMockRecorder) Failed(ctx, id, retryAt, reason any) *MockStoreFailedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failed", reflect.TypeOf((*MockStore)(nil).Failed), ctx, id, retryAt, reason)
+	return &MockStoreFailedCall{Call: call}
+}
+
+// MockStoreFailedCall wrap *gomock.Call
+type MockStoreFailedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreFailedCall) Return(arg0 error) *MockStoreFailedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreFailedCall) Do(f func(context.Context, int64, time.Time, string) error) *MockStoreFailedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreFailedCall) DoAndReturn(f func(context.Context, int64, time.Time, string) error) *MockStoreFailedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Lag mocks base method.
+func (m *MockStore) Lag(ctx context.Context) (Lag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lag", ctx)
+	ret0, _ := ret[0].(Lag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lag indicates an expected call of Lag.
+func (mr *MockStoreMockRecorder) Lag(ctx any) *MockStoreLagCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lag", reflect.TypeOf((*MockStore)(nil).Lag), ctx)
+	return &MockStoreLagCall{Call: call}
+}
+
+// MockStoreLagCall wrap *gomock.Call
+type MockStoreLagCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStoreLagCall) Return(arg0 Lag, arg1 error) *MockStoreLagCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStoreLagCall) Do(f func(context.Context) (Lag, error)) *MockStoreLagCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStoreLagCall) DoAndReturn(f func(context.Context) (Lag, error)) *MockStoreLagCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Pending mocks base method.
+func (m *MockStore) Pending(ctx context.Context, now time.Time, limit int) ([]Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending", ctx, now, limit)
+	ret0, _ := ret[0].([]Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockStoreMockRecorder) Pending(ctx, now, limit any) *MockStorePendingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockStore)(nil).Pending), ctx, now, limit)
+	return &MockStorePendingCall{Call: call}
+}
+
+// MockStorePendingCall wrap *gomock.Call
+type MockStorePendingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStorePendingCall) Return(arg0 []Entry, arg1 error) *MockStorePendingCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStorePendingCall) Do(f func(context.Context, time.Time, int) ([]Entry, error)) *MockStorePendingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStorePendingCall) DoAndReturn(f func(context.Context, time.Time, int) ([]Entry, error)) *MockStorePendingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Published mocks base method.
+func (m *MockStore) Published(ctx context.Context, id int64, publisher string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Published", ctx, id, publisher)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Published indicates an expected call of Published.
+func (mr *MockStoreMockRecorder) Published(ctx, id, publisher any) *MockStorePublishedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Published", reflect.TypeOf((*MockStore)(nil).Published), ctx, id, publisher)
+	return &MockStorePublishedCall{Call: call}
+}
+
+// MockStorePublishedCall wrap *gomock.Call
+type MockStorePublishedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStorePublishedCall) Return(arg0 error) *MockStorePublishedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStorePublishedCall) Do(f func(context.Context, int64, string) error) *MockStorePublishedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStorePublishedCall) DoAndReturn(f func(context.Context, int64, string) error) *MockStorePublishedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockPublisher is a mock of Publisher interface.
+type MockPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockPublisherMockRecorder is the mock recorder for MockPublisher.
+type MockPublisherMockRecorder struct {
+	mock *MockPublisher
+}
+
+// NewMockPublisher creates a new mock instance.
+func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
+	mock := &MockPublisher{ctrl: ctrl}
+	mock.recorder = &MockPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPublisher) Publish(ctx context.Context, event users.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPublisherMockRecorder) Publish(ctx, event any) *MockPublisherPublishCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), ctx, event)
+	return &MockPublisherPublishCall{Call: call}
+}
+
+// MockPublisherPublishCall wrap *gomock.Call
+type MockPublisherPublishCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPublisherPublishCall) Return(arg0 error) *MockPublisherPublishCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPublisherPublishCall) Do(f func(context.Context, users.Event) error) *MockPublisherPublishCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPublisherPublishCall) DoAndReturn(f func(context.Context, users.Event) error) *MockPublisherPublishCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -0,0 +1,168 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+//go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package outbox -destination ./mock.gen.$GOFILE
+type (
+	// Entry is a user event recorded in the outbox, pending to be delivered.
+	Entry struct {
+		ID    int64
+		Event users.Event
+		// Attempts is the number of failed deliveries.
+		Attempts int
+		// Published are the names of the publishers that already delivered the event.
+		Published []string
+		CreatedAt time.Time
+	}
+
+	// Lag is how far behind the delivery of the events is.
+	Lag struct {
+		// Pending is the number of events pending to be delivered.
+		Pending int64
+		// Oldest is when the oldest pending event was recorded, the zero value if there is none.
+		Oldest time.Time
+	}
+
+	// Store keeps the events pending to be delivered.
+	// The events are recorded in the same transaction as the user changes,
+	// so no event is lost if the application stops right after a change is committed.
+	Store interface {
+		// Pending returns, oldest first, up to limit events due to be delivered at now.
+		// Only the oldest pending event of each user is returned, so the events of a user are delivered in order.
+		Pending(ctx context.Context, now time.Time, limit int) ([]Entry, error)
+		// Published records that the publisher delivered the entry, so it is not delivered to it again if retried.
+		Published(ctx context.Context, id int64, publisher string) error
+		// Delivered removes the delivered entry.
+		Delivered(ctx context.Context, id int64) error
+		// Failed records that the delivery of the entry failed, to be retried at retryAt.
+		Failed(ctx context.Context, id int64, retryAt time.Time, reason string) error
+		// Lag returns how far behind the delivery of the events is.
+		Lag(ctx context.Context) (Lag, error)
+	}
+
+	// Publisher delivers the user events.
+	// The events are delivered at least once, so they could be delivered again if the application stops
+	// before recording the delivery.
+	Publisher interface {
+		// Publish delivers the event, returning an error if it has to be retried.
+		Publish(ctx context.Context, event users.Event) error
+	}
+
+	// payload is the JSON representation of a user event, as recorded in the outbox and sent to the webhooks.
+	payload struct {
+		Type       users.EventType `json:"type"`
+		TenantID   tenant.ID       `json:"tenantId"`
+		UserID     uuid.UUID       `json:"userId"`
+		User       *userPayload    `json:"user,omitempty"`
+		OccurredAt time.Time       `json:"occurredAt"`
+	}
+
+	userPayload struct {
+		ID          uuid.UUID `json:"id"`
+		Username    string    `json:"username"`
+		Email       string    `json:"email,omitempty"`
+		DisplayName string    `json:"displayName,omitempty"`
+		Locale      string    `json:"locale,omitempty"`
+		AvatarURL   string    `json:"avatarUrl,omitempty"`
+		CreatedAt   time.Time `json:"createdAt"`
+		UpdatedAt   time.Time `json:"updatedAt"`
+		// DeletedAt is only set for the deleted users, so restoring a user clears it.
+		DeletedAt *time.Time `json:"deletedAt,omitempty"`
+		// EmailVerifiedAt is only set for the verified emails, so changing the email clears it.
+		EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+		Version         int64      `json:"version"`
+	}
+)
+
+// MarshalEvent returns the JSON representation of the event, without any secret of the user.
+func MarshalEvent(event users.Event) ([]byte, error) {
+	p := payload{
+		Type:       event.Type,
+		TenantID:   event.TenantID,
+		UserID:     uuid.UUID(event.UserID),
+		OccurredAt: event.OccurredAt,
+	}
+
+	if event.Type != users.EventDeleted {
+		profile := event.User.Profile()
+		p.User = &userPayload{
+			ID:          uuid.UUID(event.User.ID()),
+			Username:    string(event.User.Username()),
+			Email:       string(profile.Email),
+			DisplayName: string(profile.DisplayName),
+			Locale:      string(profile.Locale),
+			AvatarURL:   string(profile.AvatarURL),
+			CreatedAt:   event.User.CreatedAt(),
+			UpdatedAt:   event.User.UpdatedAt(),
+			Version:     event.User.Version(),
+		}
+
+		if deletedAt, ok := event.User.DeletedAt(); ok {
+			p.User.DeletedAt = &deletedAt
+		}
+
+		if verifiedAt, ok := event.User.EmailVerifiedAt(); ok {
+			p.User.EmailVerifiedAt = &verifiedAt
+		}
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalEvent returns the event of its JSON representation.
+func UnmarshalEvent(data []byte) (users.Event, error) {
+	var p payload
+
+	err := json.Unmarshal(data, &p)
+	if err != nil {
+		return users.Event{}, fmt.Errorf("error unmarshalling event: %w", err)
+	}
+
+	event := users.Event{
+		Type:       p.Type,
+		TenantID:   p.TenantID,
+		UserID:     users.UserID(p.UserID),
+		OccurredAt: p.OccurredAt,
+	}
+
+	if p.User != nil {
+		event.User = users.NewUser(
+			users.UserID(p.User.ID),
+			p.User.CreatedAt,
+			p.User.UpdatedAt,
+			users.Username(p.User.Username),
+			users.Profile{
+				Email:       users.Email(p.User.Email),
+				DisplayName: users.DisplayName(p.User.DisplayName),
+				Locale:      users.Locale(p.User.Locale),
+				AvatarURL:   users.AvatarURL(p.User.AvatarURL),
+			},
+			p.User.Version,
+		)
+
+		if p.User.DeletedAt != nil {
+			event.User = event.User.WithDeletedAt(*p.User.DeletedAt)
+		}
+
+		if p.User.EmailVerifiedAt != nil {
+			event.User = event.User.WithEmailVerifiedAt(*p.User.EmailVerifiedAt)
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+var (
+	_ Publisher = new(LogPublisher)
+	_ Publisher = new(WebhookPublisher)
+	_ Publisher = new(MemoryPublisher)
+)
+
+type (
+	// Publishers are the publishers the events are delivered to, by name.
+	// The delivery to each of them is tracked by its name, so if one fails the event is only retried in that one.
+	Publishers map[string]Publisher
+
+	// LogPublisher logs the events.
+	LogPublisher struct {
+		logger *slog.Logger
+	}
+
+	// WebhookPublisher posts the events, as JSON, to a URL.
+	WebhookPublisher struct {
+		client *http.Client
+		url    string
+	}
+
+	// MemoryPublisher keeps the events in memory, e.g. to check them in the tests.
+	MemoryPublisher struct {
+		mu     sync.Mutex
+		events []users.Event
+	}
+)
+
+// NewLogPublisher creates the publisher that logs the events with the logger.
+func NewLogPublisher(logger *slog.Logger) LogPublisher {
+	return LogPublisher{
+		logger: logger,
+	}
+}
+
+func (p LogPublisher) Publish(ctx context.Context, event users.Event) error {
+	p.logger.InfoContext(
+		ctx,
+		"User event",
+		slog.String("type", string(event.Type)),
+		slog.String("userId", event.UserID.String()),
+		slog.Time("occurredAt", event.OccurredAt),
+	)
+
+	return nil
+}
+
+// NewWebhookPublisher creates the publisher that posts the events to the url with the client.
+func NewWebhookPublisher(client *http.Client, url string) WebhookPublisher {
+	return WebhookPublisher{
+		client: client,
+		url:    url,
+	}
+}
+
+// Publish posts the event, failing if the response is not successful.
+func (p WebhookPublisher) Publish(ctx context.Context, event users.Event) error {
+	body, err := MarshalEvent(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting webhook: %w", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event users.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+
+	return nil
+}
+
+// Events returns the events published, in order.
+func (p *MemoryPublisher) Events() []users.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.events)
+}
@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		status  int
+		wantErr bool
+	}{
+		"accepted": {
+			status: http.StatusAccepted,
+		},
+		"server error is retried": {
+			status:  http.StatusServiceUnavailable,
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			user := users.NewUser(
+				users.UserID(uuid.MustParse("08ec89b3-288c-4b38-ba25-b91c81004699")),
+				time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				"john",
+				users.Profile{},
+				1,
+			)
+
+			var received []byte
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, string(users.EventCreated), r.Header.Get("X-Event-Type"))
+
+				received, _ = io.ReadAll(r.Body)
+
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			publisher := NewWebhookPublisher(server.Client(), server.URL)
+
+			// Act
+			err := publisher.Publish(t.Context(), users.NewCreatedEvent(user))
+
+			// Assert
+			if test.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			var actual map[string]any
+			require.NoError(t, json.Unmarshal(received, &actual))
+			assert.Equal(t, "user.created", actual["type"])
+			assert.Equal(t, "08ec89b3-288c-4b38-ba25-b91c81004699", actual["userId"])
+			assert.NotContains(t, actual["user"], "password")
+		})
+	}
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		event users.Event
+	}{
+		"created": {
+			event: users.NewCreatedEvent(users.NewUser(
+				users.UserID(uuid.New()),
+				time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+				"john",
+				users.Profile{},
+				2,
+			)),
+		},
+		"of a deleted user": {
+			event: users.NewUpdatedEvent(deletedUser(
+				users.NewUser(
+					users.UserID(uuid.New()),
+					time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+					time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+					"john",
+					users.Profile{},
+					3,
+				),
+				time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+			)),
+		},
+		"of a user with a verified email": {
+			event: users.NewUpdatedEvent(verifiedUser(
+				users.NewUser(
+					users.UserID(uuid.New()),
+					time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+					time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+					"john",
+					users.Profile{Email: "john@example.com"},
+					2,
+				),
+				time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+			)),
+		},
+		"deleted": {
+			event: users.NewDeletedEvent(users.UserID(uuid.New()), time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)),
+		},
+		"of a tenant": {
+			event: users.Event{
+				Type:       users.EventDeleted,
+				TenantID:   "acme",
+				UserID:     users.UserID(uuid.New()),
+				OccurredAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			data, err := MarshalEvent(test.event)
+			require.NoError(t, err)
+
+			// Act
+			actual, err := UnmarshalEvent(data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.event, actual)
+		})
+	}
+}
+
+// deletedUser returns the user soft-deleted at the time.
+func deletedUser(user users.User, deletedAt time.Time) users.User {
+	return user.WithDeletedAt(deletedAt)
+}
+
+// verifiedUser returns the user with its email verified at the time.
+func verifiedUser(user users.User, verifiedAt time.Time) users.User {
+	return user.WithEmailVerifiedAt(verifiedAt)
+}
@@ -0,0 +1,274 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/info"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+)
+
+const (
+	// relayBatchSize is the maximum number of events delivered in each pass of the relay.
+	relayBatchSize = 100
+	// minRetryDelay is the delay before retrying a failed delivery the first time, doubled on every attempt.
+	minRetryDelay = time.Second
+	// maxRetryDelay is the maximum delay before retrying a failed delivery.
+	maxRetryDelay = 5 * time.Minute
+)
+
+type (
+	// Relay delivers the events pending in the outbox to the publishers, retrying the failed deliveries
+	// with exponential backoff in the publishers that failed. The events of a user are delivered in order,
+	// so a failed one holds back the next ones of the same user.
+	Relay struct {
+		store        Store
+		publishers   Publishers
+		metrics      relayMetrics
+		logger       *slog.Logger
+		pollInterval time.Duration
+	}
+
+	relayMetrics struct {
+		deliveries metric.Int64Counter
+		latency    metric.Float64Histogram
+	}
+)
+
+// NewRelay creates the relay, that looks for pending events every pollInterval.
+// Its metrics are recorded with the meter provider, including the outbox lag, observed from the store.
+func NewRelay(
+	store Store,
+	publishers Publishers,
+	mp metric.MeterProvider,
+	logger *slog.Logger,
+	pollInterval time.Duration,
+) (Relay, error) {
+	metrics, err := newRelayMetrics(mp, store)
+	if err != nil {
+		return Relay{}, err
+	}
+
+	return Relay{
+		store:        store,
+		publishers:   publishers,
+		metrics:      metrics,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}, nil
+}
+
+// Run delivers the pending events until the context is done.
+func (r Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := r.Relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "Failed to relay outbox events", slog.Any("error", err))
+		}
+
+		// Keep going while there are events due, otherwise wait for new ones.
+		if delivered > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Relay delivers the events due, returning how many of them were delivered.
+func (r Relay) Relay(ctx context.Context) (int, error) {
+	ctx, span := observability.StartSpan(ctx, "Relay.Relay")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	entries, err := r.store.Pending(ctx, now, relayBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error getting pending events: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("pending", len(entries)))
+
+	delivered := 0
+
+	for _, entry := range entries {
+		ok, errDeliver := r.deliver(ctx, entry)
+		if errDeliver != nil {
+			return delivered, errDeliver
+		}
+
+		if ok {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// deliver publishes the entry and records the outcome, returning whether it was delivered.
+// It only returns an error if the outcome can't be recorded.
+func (r Relay) deliver(ctx context.Context, entry Entry) (bool, error) {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"Relay.deliver",
+		oteltrace.WithAttributes(
+			attribute.Int64("id", entry.ID),
+			attribute.String("type", string(entry.Event.Type)),
+			attribute.Int("attempts", entry.Attempts),
+		),
+	)
+	defer span.End()
+
+	errPublish := r.publish(ctx, entry)
+	if errPublish != nil {
+		r.metrics.deliveries.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "failure")))
+
+		retryAt := time.Now().UTC().Add(retryDelay(entry.Attempts))
+		r.logger.WarnContext(
+			ctx,
+			"Failed to deliver outbox event",
+			slog.Int64("id", entry.ID),
+			slog.Int("attempts", entry.Attempts+1),
+			slog.Time("retryAt", retryAt),
+			slog.Any("error", errPublish),
+		)
+
+		err := r.store.Failed(ctx, entry.ID, retryAt, errPublish.Error())
+		if err != nil {
+			return false, fmt.Errorf("error recording failed delivery: %w", err)
+		}
+
+		return false, nil
+	}
+
+	r.metrics.deliveries.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "success")))
+	r.metrics.latency.Record(ctx, time.Since(entry.CreatedAt).Seconds())
+
+	err := r.store.Delivered(ctx, entry.ID)
+	if err != nil {
+		return false, fmt.Errorf("error recording delivery: %w", err)
+	}
+
+	return true, nil
+}
+
+// publish delivers the entry to the publishers that did not deliver it yet, recording each delivery,
+// so the publishers that succeeded don't deliver it again if it is retried.
+// It returns the errors of the publishers that failed, or whose delivery can't be recorded.
+func (r Relay) publish(ctx context.Context, entry Entry) error {
+	errs := make([]error, 0, len(r.publishers))
+
+	for _, name := range slices.Sorted(maps.Keys(r.publishers)) {
+		if slices.Contains(entry.Published, name) {
+			continue
+		}
+
+		err := r.publishers[name].Publish(ctx, entry.Event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error publishing to %s: %w", name, err))
+
+			continue
+		}
+
+		err = r.store.Published(ctx, entry.ID, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error recording delivery to %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// retryDelay returns the delay before retrying a delivery that failed after the attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for range attempts {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+
+	return delay
+}
+
+func newRelayMetrics(mp metric.MeterProvider, store Store) (relayMetrics, error) {
+	meter := mp.Meter(info.AppName)
+
+	deliveries, err := meter.Int64Counter(
+		"outbox.deliveries",
+		metric.WithDescription("Number of outbox event deliveries, by outcome."),
+		metric.WithUnit("{delivery}"),
+	)
+	if err != nil {
+		return relayMetrics{}, fmt.Errorf("error creating outbox deliveries counter: %w", err)
+	}
+
+	latency, err := meter.Float64Histogram(
+		"outbox.delivery.latency",
+		metric.WithDescription("Time from an event being recorded in the outbox until it is delivered."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return relayMetrics{}, fmt.Errorf("error creating outbox delivery latency histogram: %w", err)
+	}
+
+	pending, err := meter.Int64ObservableGauge(
+		"outbox.pending",
+		metric.WithDescription("Number of events pending to be delivered."),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return relayMetrics{}, fmt.Errorf("error creating outbox pending gauge: %w", err)
+	}
+
+	lag, err := meter.Float64ObservableGauge(
+		"outbox.lag",
+		metric.WithDescription("Age of the oldest event pending to be delivered."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return relayMetrics{}, fmt.Errorf("error creating outbox lag gauge: %w", err)
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		l, errLag := store.Lag(ctx)
+		if errLag != nil {
+			return fmt.Errorf("error getting outbox lag: %w", errLag)
+		}
+
+		o.ObserveInt64(pending, l.Pending)
+
+		if l.Pending > 0 {
+			o.ObserveFloat64(lag, time.Since(l.Oldest).Seconds())
+		} else {
+			o.ObserveFloat64(lag, 0)
+		}
+
+		return nil
+	}, pending, lag)
+	if err != nil {
+		return relayMetrics{}, fmt.Errorf("error registering outbox lag callback: %w", err)
+	}
+
+	return relayMetrics{
+		deliveries: deliveries,
+		latency:    latency,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestRelay_Relay(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		publishErr        error
+		expectedDelivered int
+	}{
+		"delivered": {
+			expectedDelivered: 2,
+		},
+		"failed delivery is retried later": {
+			publishErr:        errors.New("connection refused"),
+			expectedDelivered: 0,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			store := NewMockStore(ctrl)
+			publisher := NewMockPublisher(ctrl)
+
+			entries := []Entry{
+				{ID: 1, Event: users.NewDeletedEvent(users.UserID(uuid.New()), time.Now()), CreatedAt: time.Now()},
+				{ID: 2, Event: users.NewDeletedEvent(users.UserID(uuid.New()), time.Now()), Attempts: 2},
+			}
+			store.EXPECT().Pending(gomock.Any(), gomock.Any(), relayBatchSize).Return(entries, nil)
+
+			for _, entry := range entries {
+				publisher.EXPECT().Publish(gomock.Any(), entry.Event).Return(test.publishErr)
+
+				if test.publishErr == nil {
+					store.EXPECT().Published(gomock.Any(), entry.ID, "mock")
+					store.EXPECT().Delivered(gomock.Any(), entry.ID)
+
+					continue
+				}
+
+				retryAt := time.Now().Add(retryDelay(entry.Attempts))
+				store.EXPECT().Failed(gomock.Any(), entry.ID, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, actual time.Time, reason string) error {
+						assert.WithinDuration(t, retryAt, actual, time.Second)
+						assert.Contains(t, reason, test.publishErr.Error())
+
+						return nil
+					})
+			}
+
+			relay, err := NewRelay(
+				store, Publishers{"mock": publisher}, noop.NewMeterProvider(), slog.New(slog.DiscardHandler), time.Second,
+			)
+			require.NoError(t, err)
+
+			// Act
+			delivered, err := relay.Relay(t.Context())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDelivered, delivered)
+		})
+	}
+}
+
+func TestRelay_Relay_RetriesOnlyFailedPublishers(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	ctrl := gomock.NewController(t)
+	store := NewMockStore(ctrl)
+	succeeding := NewMockPublisher(ctrl)
+	failing := NewMockPublisher(ctrl)
+
+	entry := Entry{ID: 1, Event: users.NewDeletedEvent(users.UserID(uuid.New()), time.Now()), CreatedAt: time.Now()}
+	errPublish := errors.New("connection refused")
+
+	gomock.InOrder(
+		store.EXPECT().Pending(gomock.Any(), gomock.Any(), relayBatchSize).Return([]Entry{entry}, nil),
+		store.EXPECT().Pending(gomock.Any(), gomock.Any(), relayBatchSize).
+			Return([]Entry{{ID: entry.ID, Event: entry.Event, Attempts: 1, Published: []string{"succeeding"}}}, nil),
+	)
+	succeeding.EXPECT().Publish(gomock.Any(), entry.Event).Return(nil).Times(1)
+	store.EXPECT().Published(gomock.Any(), entry.ID, "succeeding").Return(nil)
+	gomock.InOrder(
+		failing.EXPECT().Publish(gomock.Any(), entry.Event).Return(errPublish),
+		failing.EXPECT().Publish(gomock.Any(), entry.Event).Return(nil),
+	)
+	store.EXPECT().Failed(gomock.Any(), entry.ID, gomock.Any(), gomock.Any()).Return(nil)
+	store.EXPECT().Published(gomock.Any(), entry.ID, "failing").Return(nil)
+	store.EXPECT().Delivered(gomock.Any(), entry.ID).Return(nil)
+
+	relay, err := NewRelay(
+		store,
+		Publishers{"succeeding": succeeding, "failing": failing},
+		noop.NewMeterProvider(),
+		slog.New(slog.DiscardHandler),
+		time.Second,
+	)
+	require.NoError(t, err)
+
+	// Act
+	first, errFirst := relay.Relay(t.Context())
+	retried, errRetried := relay.Relay(t.Context())
+
+	// Assert
+	require.NoError(t, errFirst)
+	require.NoError(t, errRetried)
+	assert.Equal(t, 0, first)
+	assert.Equal(t, 1, retried)
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		attempts int
+		expected time.Duration
+	}{
+		"first attempt": {
+			attempts: 0,
+			expected: time.Second,
+		},
+		"doubled on every attempt": {
+			attempts: 3,
+			expected: 8 * time.Second,
+		},
+		"capped": {
+			attempts: 20,
+			expected: maxRetryDelay,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual := retryDelay(test.attempts)
+
+			// Assert
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
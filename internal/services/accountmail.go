@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/mail"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+type (
+	// AccountMailer sends the emails with the account tokens, rendered from the templates,
+	// with links to the frontend at the base URL.
+	AccountMailer struct {
+		mailer      mail.Mailer
+		templates   mail.Templates
+		linkBaseURL string
+	}
+
+	// accountMail is the data of the templates of the account emails.
+	accountMail struct {
+		// Name is how the user is greeted, the display name or the username.
+		Name      string
+		Link      string
+		ExpiresAt time.Time
+	}
+)
+
+func NewAccountMailer(mailer mail.Mailer, templates mail.Templates, linkBaseURL string) AccountMailer {
+	return AccountMailer{
+		mailer:      mailer,
+		templates:   templates,
+		linkBaseURL: strings.TrimSuffix(linkBaseURL, "/"),
+	}
+}
+
+// send sends to the user the email of the template with the link to the path of the frontend with the token,
+// and the tenant of the user, unless it is the default one, for the frontend to send it back (X-Tenant-ID).
+func (m AccountMailer) send(
+	ctx context.Context,
+	template string,
+	user users.User,
+	path string,
+	token accounts.Token,
+	expiresAt time.Time,
+) error {
+	profile := user.Profile()
+
+	name := string(profile.DisplayName)
+	if name == "" {
+		name = string(user.Username())
+	}
+
+	query := url.Values{"token": {string(token)}}
+	if id := tenant.FromContext(ctx); id != tenant.Default {
+		query.Set("tenant", id.String())
+	}
+
+	message, err := m.templates.Render(template, string(profile.Email), accountMail{
+		Name:      name,
+		Link:      m.linkBaseURL + path + "?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error rendering mail: %w", err)
+	}
+
+	err = m.mailer.Send(ctx, message)
+	if err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+)
+
+const (
+	// lastUsedResolution is how often the last use of a key is recorded, so not every request writes it.
+	lastUsedResolution = time.Minute
+	// bootstrapKeyName is the name of the admin key created from the secret of the operators.
+	bootstrapKeyName = "bootstrap"
+)
+
+type APIKeys struct {
+	repository apikeys.Repository
+}
+
+func NewAPIKeys(repository apikeys.Repository) APIKeys {
+	return APIKeys{
+		repository: repository,
+	}
+}
+
+// Create creates the key with the name and the roles, returning it with its secret, that is not stored.
+// It either returns the key or one of the following errors:
+// - Validation error, the name or the roles are invalid.
+// - Database error, can't create the key.
+func (s APIKeys) Create(ctx context.Context, name string, roles []auth.Role) (apikeys.APIKey, apikeys.Secret, error) {
+	key, secret, err := apikeys.New(name, roles)
+	if err != nil {
+		return apikeys.APIKey{}, "", fmt.Errorf("error validating api key: %w", err)
+	}
+
+	created, err := s.repository.Create(ctx, key)
+	if err != nil {
+		return apikeys.APIKey{}, "", fmt.Errorf("error creating api key: %w", err)
+	}
+
+	return created, secret, nil
+}
+
+// Bootstrap creates the admin key authenticated with the secret, named "bootstrap", if it doesn't exist,
+// so the first keys can be created before any other admin can be authenticated.
+// The key is left as it is if it exists, even if it was revoked.
+// It either returns nil or one of the following errors:
+// - apikeys.ErrWeakSecret, the secret is malformed or too short.
+// - apikeys.ErrPrefixTaken, another key has the prefix of the secret.
+// - Database error, can't get or create the key.
+func (s APIKeys) Bootstrap(ctx context.Context, secret apikeys.Secret) error {
+	key, err := apikeys.FromSecret(bootstrapKeyName, []auth.Role{auth.RoleAdmin}, secret)
+	if err != nil {
+		return fmt.Errorf("error validating bootstrap api key: %w", err)
+	}
+
+	existing, err := s.repository.GetByPrefix(ctx, key.Prefix)
+	if err == nil {
+		if !existing.Matches(secret) {
+			return apikeys.ErrPrefixTaken
+		}
+
+		return nil
+	}
+
+	if !errors.Is(err, apikeys.ErrInvalidKey) {
+		return fmt.Errorf("error getting bootstrap api key: %w", err)
+	}
+
+	_, err = s.repository.Create(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error creating bootstrap api key: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the key with the secret, recording its use, and adding its id and name to the wide event.
+// It either returns the key or one of the following errors:
+// - apikeys.ErrInvalidKey, the secret is malformed, or its key doesn't exist or was revoked.
+// - Database error, can't get the key.
+func (s APIKeys) Authenticate(ctx context.Context, secret apikeys.Secret) (apikeys.APIKey, error) {
+	prefix, ok := secret.Prefix()
+	if !ok {
+		return apikeys.APIKey{}, apikeys.ErrInvalidKey
+	}
+
+	key, err := s.repository.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, apikeys.ErrInvalidKey) {
+			return apikeys.APIKey{}, apikeys.ErrInvalidKey
+		}
+
+		return apikeys.APIKey{}, fmt.Errorf("error getting api key: %w", err)
+	}
+
+	if !key.Matches(secret) || key.IsRevoked() {
+		return apikeys.APIKey{}, apikeys.ErrInvalidKey
+	}
+
+	now := time.Now()
+	if now.Sub(key.LastUsedAt) >= lastUsedResolution {
+		// The request is authenticated even if the last use can't be recorded.
+		errUsed := s.repository.MarkUsed(ctx, key.ID, now)
+		if errUsed != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to record api key use", slog.Any("err", errUsed))
+		} else {
+			key.LastUsedAt = now
+		}
+	}
+
+	logging.AddAttrs(ctx, slog.String("apiKeyId", key.ID.String()), slog.String("apiKeyName", key.Name))
+
+	return key, nil
+}
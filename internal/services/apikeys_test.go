@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/apikeys"
+	"github.com/manuelarte/go-web-layout/internal/auth"
+)
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		secret       func(apikeys.Secret) apikeys.Secret
+		revoked      bool
+		lastUsedAt   time.Time
+		getErr       error
+		wantMarkUsed bool
+		wantErr      error
+	}{
+		"authenticated, first use recorded": {
+			wantMarkUsed: true,
+		},
+		"authenticated, recent use not recorded": {
+			lastUsedAt: time.Now().Add(-time.Second),
+		},
+		"authenticated, old use recorded": {
+			lastUsedAt:   time.Now().Add(-time.Hour),
+			wantMarkUsed: true,
+		},
+		"malformed secret": {
+			secret: func(apikeys.Secret) apikeys.Secret {
+				return "malformed"
+			},
+			wantErr: apikeys.ErrInvalidKey,
+		},
+		"wrong secret": {
+			secret: func(s apikeys.Secret) apikeys.Secret {
+				return s + "x"
+			},
+			wantErr: apikeys.ErrInvalidKey,
+		},
+		"unknown key": {
+			getErr:  apikeys.ErrInvalidKey,
+			wantErr: apikeys.ErrInvalidKey,
+		},
+		"revoked key": {
+			revoked: true,
+			wantErr: apikeys.ErrInvalidKey,
+		},
+		"database error": {
+			getErr: errors.New("database is locked"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			key, secret, err := apikeys.New("ci", []auth.Role{auth.RoleReader})
+			require.NoError(t, err)
+
+			key.LastUsedAt = test.lastUsedAt
+			if test.revoked {
+				key.RevokedAt = time.Now()
+			}
+
+			if test.secret != nil {
+				secret = test.secret(secret)
+			}
+
+			repository := apikeys.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().GetByPrefix(gomock.Any(), key.Prefix).Return(key, test.getErr).MaxTimes(1)
+
+			if test.wantMarkUsed {
+				repository.EXPECT().MarkUsed(gomock.Any(), key.ID, gomock.Any()).Return(nil)
+			}
+
+			// Act
+			actual, err := NewAPIKeys(repository).Authenticate(t.Context(), secret)
+
+			// Assert
+			if test.getErr != nil && test.wantErr == nil {
+				require.ErrorIs(t, err, test.getErr)
+				require.NotErrorIs(t, err, apikeys.ErrInvalidKey)
+
+				return
+			}
+
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, actual.ID)
+			assert.Equal(t, "apikey:"+key.Prefix, actual.Principal().ID)
+		})
+	}
+}
+
+func TestAPIKeys_Bootstrap(t *testing.T) {
+	t.Parallel()
+
+	secret := apikeys.Secret("gwl_1a2b3c4d_" + strings.Repeat("a", 26))
+	existing, errExisting := apikeys.FromSecret("bootstrap", []auth.Role{auth.RoleAdmin}, secret)
+	require.NoError(t, errExisting)
+
+	other, errOther := apikeys.FromSecret("other", []auth.Role{auth.RoleReader}, secret+"b")
+	require.NoError(t, errOther)
+
+	errDB := errors.New("database is locked")
+
+	tests := map[string]struct {
+		secret     apikeys.Secret
+		existing   apikeys.APIKey
+		getErr     error
+		wantCreate bool
+		wantErr    error
+	}{
+		"created": {
+			secret:     secret,
+			getErr:     apikeys.ErrInvalidKey,
+			wantCreate: true,
+		},
+		"already created": {
+			secret:   secret,
+			existing: existing,
+		},
+		"prefix of another key": {
+			secret:   secret,
+			existing: other,
+			wantErr:  apikeys.ErrPrefixTaken,
+		},
+		"weak secret": {
+			secret:  "gwl_1a2b3c4d_short",
+			wantErr: apikeys.ErrWeakSecret,
+		},
+		"database error": {
+			secret:  secret,
+			getErr:  errDB,
+			wantErr: errDB,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			repository := apikeys.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().GetByPrefix(gomock.Any(), "gwl_1a2b3c4d").Return(test.existing, test.getErr).MaxTimes(1)
+
+			if test.wantCreate {
+				repository.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key apikeys.APIKey) (apikeys.APIKey, error) {
+						assert.Equal(t, "bootstrap", key.Name)
+						assert.Equal(t, []auth.Role{auth.RoleAdmin}, key.Roles)
+						assert.True(t, key.Matches(test.secret))
+
+						return key, nil
+					})
+			}
+
+			// Act
+			err := NewAPIKeys(repository).Bootstrap(t.Context(), test.secret)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -9,26 +9,40 @@ import (
 
 type CreateUser struct {
 	repository users.Repository
+	metrics    Metrics
 }
 
-func NewCreateUser(repository users.Repository) CreateUser {
+func NewCreateUser(repository users.Repository, metrics Metrics) CreateUser {
 	return CreateUser{
 		repository: repository,
+		metrics:    metrics,
 	}
 }
 
 // CreateUser creates a new user. It either returns the created user or one of the following errors:
-// - Validation error, username and/or password are wrong.
+// - Validation error, username, password and/or profile are wrong.
+// - Conflict error, the username or the email are taken.
 // - Database error, can't save the user.
 func (s CreateUser) CreateUser(
 	ctx context.Context,
 	u users.Username,
 	p users.Password,
+	profile users.Profile,
 ) (users.User, error) {
-	user, err := s.repository.Create(ctx, u, p)
+	user, err := s.repository.Create(ctx, u, p, profile)
 	if err != nil {
+		s.metrics.UserCreationFailed(ctx, creationFailureReason(err))
+
 		return users.User{}, fmt.Errorf("error creating user: %w", err)
 	}
 
+	s.metrics.UserCreated(ctx)
+
 	return user, nil
 }
+
+// ValidationFailed records that a user creation was rejected before calling CreateUser, because the request
+// was not valid, as CreateUser does for the validation errors of the repository.
+func (s CreateUser) ValidationFailed(ctx context.Context) {
+	s.metrics.UserCreationFailed(ctx, FailureReasonValidation)
+}
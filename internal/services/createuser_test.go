@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestCreateUser_Metrics(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		repositoryErr error
+		wantMetric    string
+		wantAttrs     attribute.Set
+	}{
+		"user created": {
+			wantMetric: "users.created",
+			wantAttrs: attribute.NewSet(
+				attribute.String("channel", observability.ChannelREST),
+				attribute.String("tenantId", "acme"),
+			),
+		},
+		"validation error": {
+			repositoryErr: errors.Join(users.ErrUsernameTooShort, users.ErrPasswordTooShort),
+			wantMetric:    "users.creation.failures",
+			wantAttrs: attribute.NewSet(
+				attribute.String("channel", observability.ChannelREST),
+				attribute.String("tenantId", "acme"),
+				attribute.String("reason", FailureReasonValidation),
+			),
+		},
+		"username taken": {
+			repositoryErr: users.UsernameTakenError{Username: "John"},
+			wantMetric:    "users.creation.failures",
+			wantAttrs: attribute.NewSet(
+				attribute.String("channel", observability.ChannelREST),
+				attribute.String("tenantId", "acme"),
+				attribute.String("reason", FailureReasonConflict),
+			),
+		},
+		"email taken": {
+			repositoryErr: users.EmailTakenError{Email: "john@example.com"},
+			wantMetric:    "users.creation.failures",
+			wantAttrs: attribute.NewSet(
+				attribute.String("channel", observability.ChannelREST),
+				attribute.String("tenantId", "acme"),
+				attribute.String("reason", FailureReasonConflict),
+			),
+		},
+		"database error": {
+			repositoryErr: errors.New("database is locked"),
+			wantMetric:    "users.creation.failures",
+			wantAttrs: attribute.NewSet(
+				attribute.String("channel", observability.ChannelREST),
+				attribute.String("tenantId", "acme"),
+				attribute.String("reason", FailureReasonDB),
+			),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctx := tenant.WithID(observability.WithChannel(t.Context(), observability.ChannelREST), "acme")
+			reader := sdkmetric.NewManualReader()
+			metrics, err := NewMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+			require.NoError(t, err)
+
+			repository := users.NewMockRepository(gomock.NewController(t))
+			user := users.NewUser(users.UserID(uuid.New()), time.Now(), time.Now(), "John", users.Profile{}, 1)
+			repository.EXPECT().Create(gomock.Any(), users.Username("John"), users.Password("12345678"), users.Profile{}).
+				Return(user, test.repositoryErr)
+
+			// Act
+			_, _ = NewCreateUser(repository, metrics).CreateUser(ctx, "John", "12345678", users.Profile{})
+
+			// Assert
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(ctx, &rm))
+			require.Len(t, rm.ScopeMetrics, 1)
+			require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+			got := rm.ScopeMetrics[0].Metrics[0]
+			assert.Equal(t, test.wantMetric, got.Name)
+
+			sum, ok := got.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+			require.Len(t, sum.DataPoints, 1)
+			assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+			assert.True(t, test.wantAttrs.Equals(&sum.DataPoints[0].Attributes))
+		})
+	}
+}
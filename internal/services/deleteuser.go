@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+type DeleteUser struct {
+	repository users.Repository
+	metrics    Metrics
+}
+
+func NewDeleteUser(repository users.Repository, metrics Metrics) DeleteUser {
+	return DeleteUser{
+		repository: repository,
+		metrics:    metrics,
+	}
+}
+
+// DeleteUser deletes the user, if it was not modified since the version was read.
+// It either deletes the user or returns one of the following errors:
+// - Not found error, the user does not exist.
+// - Version mismatch error, the user was modified.
+// - Database error, can't delete the user.
+func (s DeleteUser) DeleteUser(ctx context.Context, id users.UserID, version int64) error {
+	err := s.repository.Delete(ctx, id, version)
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	s.metrics.UserDeleted(ctx)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// EmailVerification verifies the emails of the users, with the tokens emailed to them.
+type EmailVerification struct {
+	repository         users.Repository
+	accountsRepository accounts.Repository
+	mailer             AccountMailer
+	ttl                time.Duration
+}
+
+// NewEmailVerification creates the email verification, with the tokens sent to the users expiring after the ttl.
+func NewEmailVerification(
+	repository users.Repository,
+	accountsRepository accounts.Repository,
+	mailer AccountMailer,
+	ttl time.Duration,
+) EmailVerification {
+	return EmailVerification{
+		repository:         repository,
+		accountsRepository: accountsRepository,
+		mailer:             mailer,
+		ttl:                ttl,
+	}
+}
+
+// Request sends to the email of the user a token to verify it, invalidating the previous ones.
+// It returns one of the following errors:
+// - Not found error, the user does not exist.
+// - accounts.ErrNoEmail, the user has no email.
+// - accounts.ErrEmailAlreadyVerified, the email of the user is already verified.
+// - Database or mail error, can't send the token.
+func (s EmailVerification) Request(ctx context.Context, id users.UserID) error {
+	user, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	if user.Profile().Email == "" {
+		return accounts.ErrNoEmail
+	}
+
+	if _, ok := user.EmailVerifiedAt(); ok {
+		return accounts.ErrEmailAlreadyVerified
+	}
+
+	token := accounts.NewToken()
+	expiresAt := time.Now().Add(s.ttl)
+
+	err = s.accountsRepository.CreateToken(ctx, id, accounts.PurposeEmailVerification, token, expiresAt)
+	if err != nil {
+		return fmt.Errorf("error creating email verification token: %w", err)
+	}
+
+	return s.mailer.send(ctx, "email_verification", user, "/verify-email", token, expiresAt)
+}
+
+// Confirm verifies the email of the user of the token.
+// It either returns the verified user or one of the following errors:
+// - accounts.ErrInvalidToken, the token does not exist, was used, expired, or its email changed.
+// - Database error, can't verify the email.
+func (s EmailVerification) Confirm(ctx context.Context, token accounts.Token) (users.User, error) {
+	user, err := s.accountsRepository.VerifyEmail(ctx, token)
+	if err != nil {
+		return users.User{}, fmt.Errorf("error verifying email: %w", err)
+	}
+
+	return user, nil
+}
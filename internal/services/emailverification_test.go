@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/mail"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestEmailVerification_Request(t *testing.T) {
+	t.Parallel()
+
+	id := users.UserID(uuid.New())
+	unverified := users.NewUser(id, time.Now(), time.Now(), "john", users.Profile{
+		Email:       "john@example.com",
+		DisplayName: "John Doe",
+	}, 1)
+
+	tests := map[string]struct {
+		user     users.User
+		wantErr  error
+		wantName string
+	}{
+		"email sent": {
+			user:     unverified,
+			wantName: "Hi John Doe,",
+		},
+		"email sent to the user without display name": {
+			user:     users.NewUser(id, time.Now(), time.Now(), "john", users.Profile{Email: "john@example.com"}, 1),
+			wantName: "Hi john,",
+		},
+		"user without email": {
+			user:    users.NewUser(id, time.Now(), time.Now(), "john", users.Profile{}, 1),
+			wantErr: accounts.ErrNoEmail,
+		},
+		"email already verified": {
+			user:    unverified.WithEmailVerifiedAt(time.Now()),
+			wantErr: accounts.ErrEmailAlreadyVerified,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			ctrl := gomock.NewController(t)
+			repository := users.NewMockRepository(ctrl)
+			accountsRepository := accounts.NewMockRepository(ctrl)
+			mailer := mail.NewMemoryMailer()
+
+			templates, err := mail.ParseTemplates(goweblayout.ResourcesFolder, "resources/mail/*.tmpl")
+			require.NoError(t, err)
+
+			repository.EXPECT().GetByID(gomock.Any(), id).Return(test.user, nil)
+
+			var token accounts.Token
+
+			if test.wantErr == nil {
+				accountsRepository.EXPECT().
+					CreateToken(gomock.Any(), id, accounts.PurposeEmailVerification, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ users.UserID, _ accounts.Purpose, sent accounts.Token, _ time.Time) error {
+						token = sent
+
+						return nil
+					})
+			}
+
+			s := NewEmailVerification(
+				repository,
+				accountsRepository,
+				NewAccountMailer(mailer, templates, "https://example.com/"),
+				time.Hour,
+			)
+
+			// Act
+			err = s.Request(t.Context(), id)
+
+			// Assert
+			if test.wantErr != nil {
+				require.ErrorIs(t, err, test.wantErr)
+				assert.Empty(t, mailer.Messages())
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			messages := mailer.Messages()
+			require.Len(t, messages, 1)
+			assert.Equal(t, "john@example.com", messages[0].To)
+			assert.Equal(t, "Verify your email", messages[0].Subject)
+			assert.Contains(t, messages[0].Body, test.wantName)
+			assert.Contains(t, messages[0].Body, "https://example.com/verify-email?token="+url.QueryEscape(string(token)))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+type ImportUsers struct {
+	repository users.Repository
+	metrics    Metrics
+	batchSize  int
+}
+
+// NewImportUsers creates the service to import users in bulk, inserting them in transactions of batchSize rows.
+func NewImportUsers(repository users.Repository, metrics Metrics, batchSize int) ImportUsers {
+	return ImportUsers{
+		repository: repository,
+		metrics:    metrics,
+		batchSize:  max(batchSize, 1),
+	}
+}
+
+// ImportUsers creates the users of the rows and returns the result of each row, sorted by line.
+// The rows that could not be read, or with an invalid username or password, are reported without reaching the
+// database, the valid ones are created in batched transactions.
+// If reading the rows, or a transaction, fails the import stops and the error is returned,
+// the batches already created are kept.
+func (s ImportUsers) ImportUsers(
+	ctx context.Context,
+	rows iter.Seq2[users.ImportRow, error],
+) ([]users.ImportResult, error) {
+	var results []users.ImportResult
+
+	batch := make([]users.ImportRow, 0, s.batchSize)
+
+	for row, err := range rows {
+		if err != nil {
+			return nil, fmt.Errorf("error reading rows: %w", err)
+		}
+
+		errValid := row.IsValid()
+		if errValid != nil {
+			s.metrics.UserCreationFailed(ctx, FailureReasonValidation)
+
+			results = append(results, users.ImportResult{Line: row.Line, Err: errValid})
+
+			continue
+		}
+
+		batch = append(batch, row)
+		if len(batch) < s.batchSize {
+			continue
+		}
+
+		created, errBatch := s.createBatch(ctx, batch)
+		if errBatch != nil {
+			return nil, errBatch
+		}
+
+		results = append(results, created...)
+		batch = batch[:0]
+	}
+
+	if len(batch) > 0 {
+		created, err := s.createBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, created...)
+	}
+
+	slices.SortFunc(results, func(a, b users.ImportResult) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+
+	return results, nil
+}
+
+func (s ImportUsers) createBatch(ctx context.Context, batch []users.ImportRow) ([]users.ImportResult, error) {
+	results, err := s.repository.CreateMany(ctx, batch)
+	if err != nil {
+		return nil, fmt.Errorf("error creating users: %w", err)
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			s.metrics.UserCreationFailed(ctx, creationFailureReason(result.Err))
+
+			continue
+		}
+
+		s.metrics.UserCreated(ctx)
+	}
+
+	return results, nil
+}
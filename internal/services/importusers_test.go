@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestImportUsers_ImportUsers(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		rows             []users.ImportRow
+		readErr          error
+		expectedBatches  [][]int
+		expectedFailures []int
+		expectedErr      bool
+	}{
+		"valid rows are created in batches": {
+			rows: []users.ImportRow{
+				{Line: 1, Username: "john", Password: "12345678"},
+				{Line: 2, Username: "jane", Password: "12345678"},
+				{Line: 3, Username: "jack", Password: "12345678"},
+			},
+			expectedBatches: [][]int{{1, 2}, {3}},
+		},
+		"invalid rows are not sent to the repository": {
+			rows: []users.ImportRow{
+				{Line: 1, Username: "jo", Password: "12345678"},
+				{Line: 2, Username: "jane", Password: "12345678"},
+				{Line: 3, Err: errors.New("wrong number of fields")},
+				{Line: 4, Username: "jack", Password: "1234"},
+			},
+			expectedBatches:  [][]int{{2}},
+			expectedFailures: []int{1, 3, 4},
+		},
+		"reading error stops the import": {
+			rows: []users.ImportRow{
+				{Line: 1, Username: "john", Password: "12345678"},
+			},
+			readErr:     errors.New("connection reset"),
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			metrics, err := NewMetrics(noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			repository := users.NewMockRepository(gomock.NewController(t))
+			for _, lines := range test.expectedBatches {
+				repository.EXPECT().CreateMany(gomock.Any(), gomock.Len(len(lines))).
+					DoAndReturn(createdResults(t, lines))
+			}
+
+			// Act
+			actual, err := NewImportUsers(repository, metrics, 2).ImportUsers(t.Context(), readRows(test.rows, test.readErr))
+
+			// Assert
+			if test.expectedErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, actual, len(test.rows))
+
+			for i, result := range actual {
+				assert.Equal(t, test.rows[i].Line, result.Line)
+				assert.Equal(t, slices.Contains(test.expectedFailures, result.Line), result.Err != nil)
+			}
+		})
+	}
+}
+
+// createdResults returns the results of the rows created by the repository, asserting they are the expected lines.
+func createdResults(
+	t *testing.T,
+	lines []int,
+) func(context.Context, []users.ImportRow) ([]users.ImportResult, error) {
+	t.Helper()
+
+	return func(_ context.Context, rows []users.ImportRow) ([]users.ImportResult, error) {
+		results := make([]users.ImportResult, 0, len(rows))
+		for _, row := range rows {
+			assert.Contains(t, lines, row.Line)
+
+			results = append(results, users.ImportResult{
+				Line: row.Line,
+				User: users.NewUser(users.UserID(uuid.New()), time.Now(), time.Now(), row.Username, users.Profile{}, 1),
+			})
+		}
+
+		return results, nil
+	}
+}
+
+// readRows returns the iterator over the rows, failing with readErr, if set, after them.
+func readRows(rows []users.ImportRow, readErr error) iter.Seq2[users.ImportRow, error] {
+	return func(yield func(users.ImportRow, error) bool) {
+		for _, row := range rows {
+			if !yield(row, nil) {
+				return
+			}
+		}
+
+		if readErr != nil {
+			yield(users.ImportRow{}, readErr)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+type (
+	Login struct {
+		repository     login.Repository
+		usernamePolicy login.Policy
+		ipPolicy       login.Policy
+		metrics        Metrics
+	}
+
+	// throttle is a key whose failed logins are tracked, with its policy.
+	throttle struct {
+		key    login.Key
+		policy login.Policy
+	}
+)
+
+// NewLogin creates the login, tracking the failed logins of the usernames and of the IPs with their policies.
+func NewLogin(repository login.Repository, usernamePolicy, ipPolicy login.Policy, metrics Metrics) Login {
+	return Login{
+		repository:     repository,
+		usernamePolicy: usernamePolicy,
+		ipPolicy:       ipPolicy,
+		metrics:        metrics,
+	}
+}
+
+// Login checks the password of the user with the username, for the client with the IP.
+// The attempt is reserved for the username and the IP before checking it, so the concurrent logins are throttled
+// as if the ones in flight failed.
+// It either returns the user or one of the following errors:
+// - login.ThrottledError, the username or the IP can't retry yet, or are locked out, after too many failed logins.
+// - users.ErrInvalidCredentials, there is no user with the username and the password.
+// - Database error, can't check the credentials.
+func (s Login) Login(
+	ctx context.Context,
+	username users.Username,
+	password users.Password,
+	ip string,
+) (users.User, error) {
+	now := time.Now()
+	throttles := []throttle{{key: login.KeyForUsername(username), policy: s.usernamePolicy}}
+
+	if ip != "" {
+		throttles = append(throttles, throttle{key: login.KeyForIP(ip), policy: s.ipPolicy})
+	}
+
+	for i, t := range throttles {
+		_, err := s.repository.Reserve(ctx, t.key, t.policy, now)
+		if err != nil {
+			errRelease := s.release(ctx, throttles[:i], now)
+			if throttledError, ok := errors.AsType[login.ThrottledError](err); ok {
+				s.metrics.Login(ctx, LoginOutcomeThrottled)
+
+				return users.User{}, errors.Join(throttledError, errRelease)
+			}
+
+			return users.User{}, errors.Join(fmt.Errorf("error reserving login attempt: %w", err), errRelease)
+		}
+	}
+
+	user, err := s.repository.Authenticate(ctx, username, password)
+	if err != nil {
+		if !errors.Is(err, users.ErrInvalidCredentials) {
+			return users.User{}, errors.Join(fmt.Errorf("error authenticating user: %w", err), s.release(ctx, throttles, now))
+		}
+
+		s.metrics.Login(ctx, LoginOutcomeFailure)
+
+		errFail := s.fail(ctx, throttles, now)
+		if errFail != nil {
+			return users.User{}, errFail
+		}
+
+		return users.User{}, users.ErrInvalidCredentials
+	}
+
+	// Only the failed logins of the username are forgotten, otherwise a user could reset the ones of its IP.
+	err = s.repository.Reset(ctx, throttles[0].key)
+	if err != nil {
+		return users.User{}, fmt.Errorf("error resetting login attempts: %w", err)
+	}
+
+	err = s.release(ctx, throttles[1:], now)
+	if err != nil {
+		return users.User{}, err
+	}
+
+	s.metrics.Login(ctx, LoginOutcomeSuccess)
+
+	return user, nil
+}
+
+// release releases the reserved logins of the keys, whose outcome is not a failure.
+func (s Login) release(ctx context.Context, throttles []throttle, now time.Time) error {
+	for _, t := range throttles {
+		err := s.repository.Release(ctx, t.key, t.policy, now)
+		if err != nil {
+			return fmt.Errorf("error releasing login attempt: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fail records the failed login of the keys, recording the lockouts.
+func (s Login) fail(ctx context.Context, throttles []throttle, now time.Time) error {
+	for _, t := range throttles {
+		attempts, err := s.repository.Fail(ctx, t.key, t.policy, now)
+		if err != nil {
+			return fmt.Errorf("error recording failed login: %w", err)
+		}
+
+		if attempts.IsLocked(now) {
+			s.metrics.Lockout(ctx, string(t.key.Scope))
+			logging.FromContext(ctx).WarnContext(
+				ctx,
+				"Locked out after too many failed logins",
+				slog.String("scope", string(t.key.Scope)),
+				slog.Time("lockedUntil", attempts.LockedUntil),
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/login"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestLogin_Login(t *testing.T) {
+	t.Parallel()
+
+	user := users.NewUser(users.UserID(uuid.New()), time.Now(), time.Now(), "john", users.Profile{}, 1)
+	usernameKey := login.KeyForUsername("John")
+	ipKey := login.KeyForIP("192.0.2.1")
+	usernamePolicy := login.Policy{MaxFailures: 5, LockoutDuration: time.Hour, BaseDelay: time.Second, Window: time.Hour}
+	ipPolicy := login.Policy{MaxFailures: 50, LockoutDuration: time.Hour, Window: time.Hour}
+
+	tests := map[string]struct {
+		usernameReserveErr error
+		ipReserveErr       error
+		authenticateErr    error
+		failAttempts       login.Attempts
+		wantErr            error
+		wantThrottled      *login.ThrottledError
+		wantLogins         map[string]int64
+		wantLockouts       map[string]int64
+	}{
+		"logged in": {
+			wantLogins: map[string]int64{LoginOutcomeSuccess: 1},
+		},
+		"invalid credentials": {
+			authenticateErr: users.ErrInvalidCredentials,
+			failAttempts:    login.Attempts{Failures: 1},
+			wantErr:         users.ErrInvalidCredentials,
+			wantLogins:      map[string]int64{LoginOutcomeFailure: 1},
+		},
+		"invalid credentials locking out": {
+			authenticateErr: users.ErrInvalidCredentials,
+			failAttempts:    login.Attempts{Failures: 5, LockedUntil: time.Now().Add(time.Hour)},
+			wantErr:         users.ErrInvalidCredentials,
+			wantLogins:      map[string]int64{LoginOutcomeFailure: 1},
+			wantLockouts:    map[string]int64{string(login.ScopeUsername): 1, string(login.ScopeIP): 1},
+		},
+		"database error releases the reservations": {
+			authenticateErr: errors.New("database error"),
+		},
+		"username delayed": {
+			usernameReserveErr: login.ThrottledError{Scope: login.ScopeUsername, RetryAfter: time.Minute},
+			wantThrottled:      &login.ThrottledError{Scope: login.ScopeUsername},
+			wantLogins:         map[string]int64{LoginOutcomeThrottled: 1},
+		},
+		"ip locked out releases the username reservation": {
+			ipReserveErr:  login.ThrottledError{Scope: login.ScopeIP, Locked: true, RetryAfter: time.Minute},
+			wantThrottled: &login.ThrottledError{Scope: login.ScopeIP, Locked: true},
+			wantLogins:    map[string]int64{LoginOutcomeThrottled: 1},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			reader := sdkmetric.NewManualReader()
+			metrics, err := NewMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+			require.NoError(t, err)
+
+			repository := login.NewMockRepository(gomock.NewController(t))
+			repository.EXPECT().Reserve(gomock.Any(), usernameKey, usernamePolicy, gomock.Any()).
+				Return(login.Attempts{InFlight: 1}, test.usernameReserveErr)
+
+			if test.usernameReserveErr == nil {
+				repository.EXPECT().Reserve(gomock.Any(), ipKey, ipPolicy, gomock.Any()).
+					Return(login.Attempts{InFlight: 1}, test.ipReserveErr)
+			}
+
+			if test.ipReserveErr != nil {
+				repository.EXPECT().Release(gomock.Any(), usernameKey, usernamePolicy, gomock.Any()).Return(nil)
+			}
+
+			if test.wantThrottled == nil {
+				repository.EXPECT().Authenticate(gomock.Any(), users.Username("John"), users.Password("password")).
+					Return(user, test.authenticateErr)
+			}
+
+			switch {
+			case errors.Is(test.authenticateErr, users.ErrInvalidCredentials):
+				repository.EXPECT().Fail(gomock.Any(), usernameKey, usernamePolicy, gomock.Any()).Return(test.failAttempts, nil)
+				repository.EXPECT().Fail(gomock.Any(), ipKey, ipPolicy, gomock.Any()).Return(test.failAttempts, nil)
+			case test.authenticateErr != nil:
+				repository.EXPECT().Release(gomock.Any(), usernameKey, usernamePolicy, gomock.Any()).Return(nil)
+				repository.EXPECT().Release(gomock.Any(), ipKey, ipPolicy, gomock.Any()).Return(nil)
+			case test.wantThrottled == nil:
+				repository.EXPECT().Reset(gomock.Any(), usernameKey).Return(nil)
+				repository.EXPECT().Release(gomock.Any(), ipKey, ipPolicy, gomock.Any()).Return(nil)
+			}
+
+			s := NewLogin(repository, usernamePolicy, ipPolicy, metrics)
+
+			// Act
+			actual, err := s.Login(t.Context(), "John", "password", "192.0.2.1")
+
+			// Assert
+			switch {
+			case test.wantThrottled != nil:
+				throttledError, ok := errors.AsType[login.ThrottledError](err)
+				require.True(t, ok)
+				assert.Equal(t, test.wantThrottled.Scope, throttledError.Scope)
+				assert.Equal(t, test.wantThrottled.Locked, throttledError.Locked)
+				assert.Positive(t, throttledError.RetryAfter)
+			case test.wantErr != nil:
+				require.ErrorIs(t, err, test.wantErr)
+			case test.authenticateErr != nil:
+				require.ErrorIs(t, err, test.authenticateErr)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, user.ID(), actual.ID())
+			}
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(t.Context(), &rm))
+
+			if test.wantLogins == nil {
+				assert.Empty(t, rm.ScopeMetrics)
+
+				return
+			}
+
+			require.Len(t, rm.ScopeMetrics, 1)
+
+			assert.Equal(t, test.wantLogins, sumsBy(t, rm, "users.logins", "outcome"))
+			assert.Equal(t, test.wantLockouts, sumsBy(t, rm, "users.lockouts", "scope"))
+		})
+	}
+}
+
+// sumsBy returns the sums of the counter by the value of the attribute, nil if it was not recorded.
+func sumsBy(t *testing.T, rm metricdata.ResourceMetrics, name string, key attribute.Key) map[string]int64 {
+	t.Helper()
+
+	var sums map[string]int64
+
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != name {
+			continue
+		}
+
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		require.True(t, ok)
+
+		sums = make(map[string]int64, len(sum.DataPoints))
+
+		for _, dp := range sum.DataPoints {
+			value, _ := dp.Attributes.Value(key)
+			sums[value.AsString()] = dp.Value
+		}
+	}
+
+	return sums
+}
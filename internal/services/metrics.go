@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/manuelarte/go-web-layout/internal/config/info"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// Reasons why a user creation failed.
+const (
+	FailureReasonValidation = "validation"
+	FailureReasonConflict   = "conflict"
+	FailureReasonDB         = "db"
+)
+
+// Outcomes of the logins.
+const (
+	LoginOutcomeSuccess = "success"
+	LoginOutcomeFailure = "failure"
+	// LoginOutcomeThrottled is the outcome of the logins rejected because they were delayed or locked out.
+	LoginOutcomeThrottled = "throttled"
+)
+
+// Metrics records the business metrics of the users domain.
+type Metrics struct {
+	created          metric.Int64Counter
+	deleted          metric.Int64Counter
+	creationFailures metric.Int64Counter
+	logins           metric.Int64Counter
+	lockouts         metric.Int64Counter
+}
+
+// NewMetrics creates the business metrics of the users domain.
+func NewMetrics(mp metric.MeterProvider) (Metrics, error) {
+	meter := mp.Meter(info.AppName)
+
+	created, err := meter.Int64Counter(
+		"users.created",
+		metric.WithDescription("Number of users created."),
+		metric.WithUnit("{user}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("error creating users created counter: %w", err)
+	}
+
+	deleted, err := meter.Int64Counter(
+		"users.deleted",
+		metric.WithDescription("Number of users deleted."),
+		metric.WithUnit("{user}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("error creating users deleted counter: %w", err)
+	}
+
+	creationFailures, err := meter.Int64Counter(
+		"users.creation.failures",
+		metric.WithDescription("Number of user creations that failed, by reason."),
+		metric.WithUnit("{failure}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("error creating users creation failures counter: %w", err)
+	}
+
+	logins, err := meter.Int64Counter(
+		"users.logins",
+		metric.WithDescription("Number of login attempts, by outcome."),
+		metric.WithUnit("{login}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("error creating users logins counter: %w", err)
+	}
+
+	lockouts, err := meter.Int64Counter(
+		"users.lockouts",
+		metric.WithDescription("Number of lockouts after too many failed logins, by scope."),
+		metric.WithUnit("{lockout}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("error creating users lockouts counter: %w", err)
+	}
+
+	return Metrics{
+		created:          created,
+		deleted:          deleted,
+		creationFailures: creationFailures,
+		logins:           logins,
+		lockouts:         lockouts,
+	}, nil
+}
+
+// UserCreated records that a user was created.
+func (m Metrics) UserCreated(ctx context.Context) {
+	m.created.Add(ctx, 1, attributes(ctx))
+}
+
+// UserCreationFailed records that a user creation failed, with the reason (e.g. FailureReasonValidation).
+func (m Metrics) UserCreationFailed(ctx context.Context, reason string) {
+	m.creationFailures.Add(ctx, 1, attributes(ctx, attribute.String("reason", reason)))
+}
+
+// UserDeleted records that a user was deleted.
+func (m Metrics) UserDeleted(ctx context.Context) {
+	m.deleted.Add(ctx, 1, attributes(ctx))
+}
+
+// Login records a login attempt, with its outcome (e.g. LoginOutcomeSuccess).
+func (m Metrics) Login(ctx context.Context, outcome string) {
+	m.logins.Add(ctx, 1, attributes(ctx, attribute.String("outcome", outcome)))
+}
+
+// Lockout records that a username or an IP, the scope, was locked out after too many failed logins.
+func (m Metrics) Lockout(ctx context.Context, scope string) {
+	m.lockouts.Add(ctx, 1, attributes(ctx, attribute.String("scope", scope)))
+}
+
+// RegisterTotalUsers exports the total number of users of each tenant as a gauge,
+// counted every time the metrics are collected.
+func RegisterTotalUsers(mp metric.MeterProvider, repository users.Repository) error {
+	meter := mp.Meter(info.AppName)
+
+	_, err := meter.Int64ObservableGauge(
+		"users.count",
+		metric.WithDescription("Total number of users."),
+		metric.WithUnit("{user}"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			counts, err := repository.CountByTenant(ctx)
+			if err != nil {
+				return fmt.Errorf("error counting users: %w", err)
+			}
+
+			for id, count := range counts {
+				o.Observe(count, metric.WithAttributes(id.Attribute()))
+			}
+
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating users total gauge: %w", err)
+	}
+
+	return nil
+}
+
+// creationFailureReason classifies the error returned when creating a user.
+func creationFailureReason(err error) string {
+	if users.IsValidationError(err) {
+		return FailureReasonValidation
+	}
+
+	if users.IsTakenError(err) {
+		return FailureReasonConflict
+	}
+
+	return FailureReasonDB
+}
+
+// attributes returns the attributes of the measurements: the channel and the tenant of the request, and the others.
+func attributes(ctx context.Context, others ...attribute.KeyValue) metric.MeasurementOption {
+	attrs := make([]attribute.KeyValue, 0, 2+len(others))
+	attrs = append(
+		attrs,
+		attribute.String("channel", observability.ChannelFromContext(ctx)),
+		tenant.FromContext(ctx).Attribute(),
+	)
+
+	return metric.WithAttributes(append(attrs, others...)...)
+}
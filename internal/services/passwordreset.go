@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// PasswordReset resets the passwords of the users, with the tokens emailed to them.
+type PasswordReset struct {
+	accountsRepository accounts.Repository
+	mailer             AccountMailer
+	ttl                time.Duration
+}
+
+// NewPasswordReset creates the password reset, with the tokens sent to the users expiring after the ttl.
+func NewPasswordReset(accountsRepository accounts.Repository, mailer AccountMailer, ttl time.Duration) PasswordReset {
+	return PasswordReset{
+		accountsRepository: accountsRepository,
+		mailer:             mailer,
+		ttl:                ttl,
+	}
+}
+
+// Request sends to the user with the email a token to reset its password, invalidating the previous ones.
+// The token is created and sent in the background, and its errors are only logged, so the request takes the same
+// time and has the same outcome whether there is a user with the email or not, and the emails are not disclosed.
+// It only returns a database error if the user can't be looked up.
+func (s PasswordReset) Request(ctx context.Context, email users.Email) error {
+	user, err := s.accountsRepository.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.As(err, new(users.NotFoundError)) {
+			return nil
+		}
+
+		return fmt.Errorf("error getting user by email: %w", err)
+	}
+
+	go s.send(context.WithoutCancel(ctx), user)
+
+	return nil
+}
+
+// Confirm sets the password of the user of the token.
+// It either returns the user or one of the following errors:
+// - accounts.ErrInvalidToken, the token does not exist, was used or expired.
+// - Validation error, the password is wrong.
+// - Database error, can't save the password.
+func (s PasswordReset) Confirm(ctx context.Context, token accounts.Token, password users.Password) (users.User, error) {
+	user, err := s.accountsRepository.ResetPassword(ctx, token, password)
+	if err != nil {
+		return users.User{}, fmt.Errorf("error resetting password: %w", err)
+	}
+
+	return user, nil
+}
+
+// send creates a token to reset the password of the user and emails it, logging the errors.
+func (s PasswordReset) send(ctx context.Context, user users.User) {
+	token := accounts.NewToken()
+	expiresAt := time.Now().Add(s.ttl)
+
+	err := s.accountsRepository.CreateToken(ctx, user.ID(), accounts.PurposePasswordReset, token, expiresAt)
+	if err == nil {
+		err = s.mailer.send(ctx, "password_reset", user, "/reset-password", token, expiresAt)
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(
+			ctx, "Failed to send password reset", slog.String("userId", user.ID().String()), slog.Any("err", err),
+		)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	goweblayout "github.com/manuelarte/go-web-layout"
+	"github.com/manuelarte/go-web-layout/internal/accounts"
+	"github.com/manuelarte/go-web-layout/internal/mail"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestPasswordReset_Request(t *testing.T) {
+	t.Parallel()
+
+	id := users.UserID(uuid.New())
+	user := users.NewUser(id, time.Now(), time.Now(), "john", users.Profile{Email: "john@example.com"}, 1)
+
+	tests := map[string]struct {
+		getErr      error
+		tokenErr    error
+		wantErr     bool
+		wantToken   bool
+		wantMessage bool
+	}{
+		"email sent": {
+			wantToken:   true,
+			wantMessage: true,
+		},
+		"unknown email": {
+			getErr: users.NotFoundError{},
+		},
+		"token not created is not disclosed": {
+			tokenErr:  errors.New("database is locked"),
+			wantToken: true,
+		},
+		"database error": {
+			getErr:  errors.New("database is locked"),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			accountsRepository := accounts.NewMockRepository(gomock.NewController(t))
+			mailer := mail.NewMemoryMailer()
+
+			templates, err := mail.ParseTemplates(goweblayout.ResourcesFolder, "resources/mail/*.tmpl")
+			require.NoError(t, err)
+
+			accountsRepository.EXPECT().GetByEmail(gomock.Any(), users.Email("john@example.com")).
+				Return(user, test.getErr)
+
+			created := make(chan struct{})
+
+			if test.wantToken {
+				accountsRepository.EXPECT().
+					CreateToken(gomock.Any(), id, accounts.PurposePasswordReset, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(context.Context, users.UserID, accounts.Purpose, accounts.Token, time.Time) error {
+						close(created)
+
+						return test.tokenErr
+					})
+			}
+
+			s := NewPasswordReset(accountsRepository, NewAccountMailer(mailer, templates, "https://example.com/"), time.Hour)
+
+			// Act
+			err = s.Request(t.Context(), "john@example.com")
+
+			// Assert
+			if test.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			if test.wantToken {
+				<-created
+			}
+
+			if !test.wantMessage {
+				assert.Never(t, func() bool { return len(mailer.Messages()) > 0 }, 50*time.Millisecond, time.Millisecond)
+
+				return
+			}
+
+			require.Eventually(t, func() bool { return len(mailer.Messages()) == 1 }, time.Second, time.Millisecond)
+			assert.Equal(t, "john@example.com", mailer.Messages()[0].To)
+		})
+	}
+}
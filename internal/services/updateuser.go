@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+type UpdateUser struct {
+	repository users.Repository
+}
+
+func NewUpdateUser(repository users.Repository) UpdateUser {
+	return UpdateUser{
+		repository: repository,
+	}
+}
+
+// UpdateUser updates the user, if it was not modified since the version was read.
+// It either returns the updated user or one of the following errors:
+// - Not found error, the user does not exist.
+// - Version mismatch error, the user was modified.
+// - Validation error, username and/or password are wrong.
+// - Database error, can't save the user.
+func (s UpdateUser) UpdateUser(
+	ctx context.Context,
+	id users.UserID,
+	version int64,
+	update users.Update,
+) (users.User, error) {
+	user, err := s.repository.Update(ctx, id, version, update)
+	if err != nil {
+		return users.User{}, fmt.Errorf("error updating user: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,110 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/manuelarte/go-web-layout/internal/config/logging"
+)
+
+// Default is the tenant of the requests that don't specify one, and of the users created before the tenants.
+const Default ID = "default"
+
+const (
+	// attributeKey is the key of the tenant in the spans, the logs and the metrics.
+	attributeKey = "tenantId"
+	// maxLength is the maximum length of the tenant ids.
+	maxLength = 63
+)
+
+var (
+	// ErrInvalidID is returned for the tenant ids that are empty, too long, or have characters other than
+	// lowercase letters, digits, hyphens and underscores.
+	ErrInvalidID = errors.New("invalid tenant id")
+	// ErrForbidden is returned for the requests for a tenant other than the one their principal is bound to.
+	ErrForbidden = errors.New("the principal can't act on the tenant")
+)
+
+type (
+	// ID identifies a tenant, its users are isolated from the ones of the other tenants,
+	// e.g. the same username can be taken in each of them.
+	ID string
+
+	idKey struct{}
+)
+
+// Parse returns the tenant id, or ErrInvalidID if it is not valid.
+func Parse(s string) (ID, error) {
+	if s == "" || len(s) > maxLength {
+		return "", fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+
+	for i, c := range s {
+		alphanumeric := (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+		// The hyphens and underscores separate words, so the ids start with a letter or a digit.
+		separator := i > 0 && (c == '-' || c == '_')
+
+		if !alphanumeric && !separator {
+			return "", fmt.Errorf("%w: %q", ErrInvalidID, s)
+		}
+	}
+
+	return ID(s), nil
+}
+
+// Resolve returns the tenant of a request: the requested one, e.g. in a header, or the one the principal is bound to
+// if none was requested, or Default if the principal is not bound to any, bound is empty.
+// It either returns the tenant or one of the following errors:
+// - ErrInvalidID, the requested tenant is not valid.
+// - ErrForbidden, the principal is bound to another tenant.
+func Resolve(requested string, bound ID) (ID, error) {
+	if requested == "" {
+		if bound != "" {
+			return bound, nil
+		}
+
+		return Default, nil
+	}
+
+	id, err := Parse(requested)
+	if err != nil {
+		return "", err
+	}
+
+	if bound != "" && id != bound {
+		return "", fmt.Errorf("%w: %s", ErrForbidden, id)
+	}
+
+	return id, nil
+}
+
+func (id ID) String() string {
+	return string(id)
+}
+
+// Attribute returns the tenant as a span or metric attribute.
+func (id ID) Attribute() attribute.KeyValue {
+	return attribute.String(attributeKey, string(id))
+}
+
+// WithID returns a copy of the context with the tenant of the request, also added to its span and its wide event.
+func WithID(ctx context.Context, id ID) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(id.Attribute())
+	logging.AddAttrs(ctx, slog.String(attributeKey, string(id)))
+
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the tenant of the request, or Default if it has none, e.g. for the background jobs.
+func FromContext(ctx context.Context) ID {
+	if id, ok := ctx.Value(idKey{}).(ID); ok {
+		return id
+	}
+
+	return Default
+}
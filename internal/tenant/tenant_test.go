@@ -0,0 +1,124 @@
+package tenant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		s       string
+		want    ID
+		wantErr error
+	}{
+		"letters and digits": {
+			s:    "acme42",
+			want: "acme42",
+		},
+		"with separators": {
+			s:    "acme-eu_1",
+			want: "acme-eu_1",
+		},
+		"longest": {
+			s:    strings.Repeat("a", 63),
+			want: ID(strings.Repeat("a", 63)),
+		},
+		"empty": {
+			wantErr: ErrInvalidID,
+		},
+		"too long": {
+			s:       strings.Repeat("a", 64),
+			wantErr: ErrInvalidID,
+		},
+		"uppercase": {
+			s:       "Acme",
+			wantErr: ErrInvalidID,
+		},
+		"starting with a separator": {
+			s:       "-acme",
+			wantErr: ErrInvalidID,
+		},
+		"with a space": {
+			s:       "acme inc",
+			wantErr: ErrInvalidID,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual, err := Parse(test.s)
+
+			// Assert
+			require.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.want, actual)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		requested string
+		bound     ID
+		want      ID
+		wantErr   error
+	}{
+		"nothing requested": {
+			want: Default,
+		},
+		"requested": {
+			requested: "acme",
+			want:      "acme",
+		},
+		"bound principal": {
+			bound: "acme",
+			want:  "acme",
+		},
+		"tenant of the bound principal requested": {
+			requested: "acme",
+			bound:     "acme",
+			want:      "acme",
+		},
+		"other tenant than the one of the bound principal requested": {
+			requested: "globex",
+			bound:     "acme",
+			wantErr:   ErrForbidden,
+		},
+		"invalid tenant requested": {
+			requested: "ACME",
+			wantErr:   ErrInvalidID,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			actual, err := Resolve(test.requested, test.bound)
+
+			// Assert
+			require.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.want, actual)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	withoutTenant := FromContext(t.Context())
+	withTenant := FromContext(WithID(t.Context(), "acme"))
+
+	// Assert
+	assert.Equal(t, Default, withoutTenant)
+	assert.Equal(t, ID("acme"), withTenant)
+}
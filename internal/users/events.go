@@ -0,0 +1,45 @@
+package users
+
+import (
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+)
+
+// Types of the user events.
+const (
+	EventCreated EventType = "user.created"
+	EventUpdated EventType = "user.updated"
+	EventDeleted EventType = "user.deleted"
+)
+
+type (
+	// EventType is the kind of change of a user event.
+	EventType string
+
+	// Event is a change of a user, recorded in the outbox with it.
+	Event struct {
+		Type EventType
+		// TenantID is the tenant of the user.
+		TenantID tenant.ID
+		UserID   UserID
+		// User is the user after the change, the zero value if it was deleted.
+		User       User
+		OccurredAt time.Time
+	}
+)
+
+// NewCreatedEvent returns the event of the user being created.
+func NewCreatedEvent(user User) Event {
+	return Event{Type: EventCreated, UserID: user.ID(), User: user, OccurredAt: user.CreatedAt()}
+}
+
+// NewUpdatedEvent returns the event of the user being updated.
+func NewUpdatedEvent(user User) Event {
+	return Event{Type: EventUpdated, UserID: user.ID(), User: user, OccurredAt: user.UpdatedAt()}
+}
+
+// NewDeletedEvent returns the event of the user being deleted.
+func NewDeletedEvent(id UserID, deletedAt time.Time) Event {
+	return Event{Type: EventDeleted, UserID: id, OccurredAt: deletedAt}
+}
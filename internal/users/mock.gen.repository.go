@@ -11,9 +11,12 @@ package users
 
 import (
 	context "context"
+	iter "iter"
 	reflect "reflect"
+	time "time"
 
 	pagination "github.com/manuelarte/go-web-layout/internal/pagination"
+	tenant "github.com/manuelarte/go-web-layout/internal/tenant"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,19 +44,96 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// All mocks base method.
+func (m *MockRepository) All(arg0 context.Context) iter.Seq2[User, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "All", arg0)
+	ret0, _ := ret[0].(iter.Seq2[User, error])
+	return ret0
+}
+
+// All indicates an expected call of All.
+func (mr *MockRepositoryMockRecorder) All(arg0 any) *MockRepositoryAllCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockRepository)(nil).All), arg0)
+	return &MockRepositoryAllCall{Call: call}
+}
+
+// MockRepositoryAllCall wrap *gomock.Call
+type MockRepositoryAllCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryAllCall) Return(arg0 iter.Seq2[User, error]) *MockRepositoryAllCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryAllCall) Do(f func(context.Context) iter.Seq2[User, error]) *MockRepositoryAllCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryAllCall) DoAndReturn(f func(context.Context) iter.Seq2[User, error]) *MockRepositoryAllCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CountByTenant mocks base method.
+func (m *MockRepository) CountByTenant(arg0 context.Context) (map[tenant.ID]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByTenant", arg0)
+	ret0, _ := ret[0].(map[tenant.ID]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByTenant indicates an expected call of CountByTenant.
+func (mr *MockRepositoryMockRecorder) CountByTenant(arg0 any) *MockRepositoryCountByTenantCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByTenant", reflect.TypeOf((*MockRepository)(nil).CountByTenant), arg0)
+	return &MockRepositoryCountByTenantCall{Call: call}
+}
+
+// MockRepositoryCountByTenantCall wrap *gomock.Call
+type MockRepositoryCountByTenantCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCountByTenantCall) Return(arg0 map[tenant.ID]int64, arg1 error) *MockRepositoryCountByTenantCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCountByTenantCall) Do(f func(context.Context) (map[tenant.ID]int64, error)) *MockRepositoryCountByTenantCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCountByTenantCall) DoAndReturn(f func(context.Context) (map[tenant.ID]int64, error)) *MockRepositoryCountByTenantCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Create mocks base method.
-func (m *MockRepository) Create(arg0 context.Context, arg1 Username, arg2 Password) (User, error) {
+func (m *MockRepository) Create(arg0 context.Context, arg1 Username, arg2 Password, arg3 Profile) (User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRepositoryMockRecorder) Create(arg0, arg1, arg2 any) *MockRepositoryCreateCall {
+func (mr *MockRepositoryMockRecorder) Create(arg0, arg1, arg2, arg3 any) *MockRepositoryCreateCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1, arg2)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1, arg2, arg3)
 	return &MockRepositoryCreateCall{Call: call}
 }
 
@@ -69,30 +149,107 @@ func (c *MockRepositoryCreateCall) Return(arg0 User, arg1 error) *MockRepository
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockRepositoryCreateCall) Do(f func(context.Context, Username, Password) (User, error)) *MockRepositoryCreateCall {
+func (c *MockRepositoryCreateCall) Do(f func(context.Context, Username, Password, Profile) (User, error)) *MockRepositoryCreateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCreateCall) DoAndReturn(f func(context.Context, Username, Password, Profile) (User, error)) *MockRepositoryCreateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateMany mocks base method.
+func (m *MockRepository) CreateMany(arg0 context.Context, arg1 []ImportRow) ([]ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", arg0, arg1)
+	ret0, _ := ret[0].([]ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockRepositoryMockRecorder) CreateMany(arg0, arg1 any) *MockRepositoryCreateManyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockRepository)(nil).CreateMany), arg0, arg1)
+	return &MockRepositoryCreateManyCall{Call: call}
+}
+
+// MockRepositoryCreateManyCall wrap *gomock.Call
+type MockRepositoryCreateManyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCreateManyCall) Return(arg0 []ImportResult, arg1 error) *MockRepositoryCreateManyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCreateManyCall) Do(f func(context.Context, []ImportRow) ([]ImportResult, error)) *MockRepositoryCreateManyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCreateManyCall) DoAndReturn(f func(context.Context, []ImportRow) ([]ImportResult, error)) *MockRepositoryCreateManyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(arg0 context.Context, arg1 UserID, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(arg0, arg1, arg2 any) *MockRepositoryDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), arg0, arg1, arg2)
+	return &MockRepositoryDeleteCall{Call: call}
+}
+
+// MockRepositoryDeleteCall wrap *gomock.Call
+type MockRepositoryDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryDeleteCall) Return(arg0 error) *MockRepositoryDeleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryDeleteCall) Do(f func(context.Context, UserID, int64) error) *MockRepositoryDeleteCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockRepositoryCreateCall) DoAndReturn(f func(context.Context, Username, Password) (User, error)) *MockRepositoryCreateCall {
+func (c *MockRepositoryDeleteCall) DoAndReturn(f func(context.Context, UserID, int64) error) *MockRepositoryDeleteCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
 // GetAll mocks base method.
-func (m *MockRepository) GetAll(arg0 context.Context, arg1 pagination.PageRequest) (pagination.Page[User], error) {
+func (m *MockRepository) GetAll(arg0 context.Context, arg1 pagination.PageRequest, arg2 Filter) (pagination.Page[User], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetAll", arg0, arg1, arg2)
 	ret0, _ := ret[0].(pagination.Page[User])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockRepositoryMockRecorder) GetAll(arg0, arg1 any) *MockRepositoryGetAllCall {
+func (mr *MockRepositoryMockRecorder) GetAll(arg0, arg1, arg2 any) *MockRepositoryGetAllCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), arg0, arg1)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), arg0, arg1, arg2)
 	return &MockRepositoryGetAllCall{Call: call}
 }
 
@@ -108,13 +265,13 @@ func (c *MockRepositoryGetAllCall) Return(arg0 pagination.Page[User], arg1 error
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockRepositoryGetAllCall) Do(f func(context.Context, pagination.PageRequest) (pagination.Page[User], error)) *MockRepositoryGetAllCall {
+func (c *MockRepositoryGetAllCall) Do(f func(context.Context, pagination.PageRequest, Filter) (pagination.Page[User], error)) *MockRepositoryGetAllCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockRepositoryGetAllCall) DoAndReturn(f func(context.Context, pagination.PageRequest) (pagination.Page[User], error)) *MockRepositoryGetAllCall {
+func (c *MockRepositoryGetAllCall) DoAndReturn(f func(context.Context, pagination.PageRequest, Filter) (pagination.Page[User], error)) *MockRepositoryGetAllCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -157,3 +314,337 @@ func (c *MockRepositoryGetByIDCall) DoAndReturn(f func(context.Context, UserID)
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// IsUsernameTaken mocks base method.
+func (m *MockRepository) IsUsernameTaken(arg0 context.Context, arg1 Username) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsUsernameTaken", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsUsernameTaken indicates an expected call of IsUsernameTaken.
+func (mr *MockRepositoryMockRecorder) IsUsernameTaken(arg0, arg1 any) *MockRepositoryIsUsernameTakenCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUsernameTaken", reflect.TypeOf((*MockRepository)(nil).IsUsernameTaken), arg0, arg1)
+	return &MockRepositoryIsUsernameTakenCall{Call: call}
+}
+
+// MockRepositoryIsUsernameTakenCall wrap *gomock.Call
+type MockRepositoryIsUsernameTakenCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryIsUsernameTakenCall) Return(arg0 bool, arg1 error) *MockRepositoryIsUsernameTakenCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryIsUsernameTakenCall) Do(f func(context.Context, Username) (bool, error)) *MockRepositoryIsUsernameTakenCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryIsUsernameTakenCall) DoAndReturn(f func(context.Context, Username) (bool, error)) *MockRepositoryIsUsernameTakenCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Purge mocks base method.
+func (m *MockRepository) Purge(arg0 context.Context, arg1 UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockRepositoryMockRecorder) Purge(arg0, arg1 any) *MockRepositoryPurgeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), arg0, arg1)
+	return &MockRepositoryPurgeCall{Call: call}
+}
+
+// MockRepositoryPurgeCall wrap *gomock.Call
+type MockRepositoryPurgeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryPurgeCall) Return(arg0 error) *MockRepositoryPurgeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryPurgeCall) Do(f func(context.Context, UserID) error) *MockRepositoryPurgeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryPurgeCall) DoAndReturn(f func(context.Context, UserID) error) *MockRepositoryPurgeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockRepository) PurgeDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockRepositoryMockRecorder) PurgeDeleted(arg0, arg1 any) *MockRepositoryPurgeDeletedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockRepository)(nil).PurgeDeleted), arg0, arg1)
+	return &MockRepositoryPurgeDeletedCall{Call: call}
+}
+
+// MockRepositoryPurgeDeletedCall wrap *gomock.Call
+type MockRepositoryPurgeDeletedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryPurgeDeletedCall) Return(arg0 int64, arg1 error) *MockRepositoryPurgeDeletedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryPurgeDeletedCall) Do(f func(context.Context, time.Time) (int64, error)) *MockRepositoryPurgeDeletedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryPurgeDeletedCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockRepositoryPurgeDeletedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Restore mocks base method.
+func (m *MockRepository) Restore(arg0 context.Context, arg1 UserID) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepositoryMockRecorder) Restore(arg0, arg1 any) *MockRepositoryRestoreCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), arg0, arg1)
+	return &MockRepositoryRestoreCall{Call: call}
+}
+
+// MockRepositoryRestoreCall wrap *gomock.Call
+type MockRepositoryRestoreCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryRestoreCall) Return(arg0 User, arg1 error) *MockRepositoryRestoreCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryRestoreCall) Do(f func(context.Context, UserID) (User, error)) *MockRepositoryRestoreCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryRestoreCall) DoAndReturn(f func(context.Context, UserID) (User, error)) *MockRepositoryRestoreCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(arg0 context.Context, arg1 UserID, arg2 int64, arg3 Update) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(arg0, arg1, arg2, arg3 any) *MockRepositoryUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), arg0, arg1, arg2, arg3)
+	return &MockRepositoryUpdateCall{Call: call}
+}
+
+// MockRepositoryUpdateCall wrap *gomock.Call
+type MockRepositoryUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryUpdateCall) Return(arg0 User, arg1 error) *MockRepositoryUpdateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryUpdateCall) Do(f func(context.Context, UserID, int64, Update) (User, error)) *MockRepositoryUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryUpdateCall) DoAndReturn(f func(context.Context, UserID, int64, Update) (User, error)) *MockRepositoryUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockTrash is a mock of Trash interface.
+type MockTrash struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrashMockRecorder
+	isgomock struct{}
+}
+
+// MockTrashMockRecorder is the mock recorder for MockTrash.
+type MockTrashMockRecorder struct {
+	mock *MockTrash
+}
+
+// NewMockTrash creates a new mock instance.
+func NewMockTrash(ctrl *gomock.Controller) *MockTrash {
+	mock := &MockTrash{ctrl: ctrl}
+	mock.recorder = &MockTrashMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTrash) EXPECT() *MockTrashMockRecorder {
+	return m.recorder
+}
+
+// Purge mocks base method.
+func (m *MockTrash) Purge(arg0 context.Context, arg1 UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockTrashMockRecorder) Purge(arg0, arg1 any) *MockTrashPurgeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockTrash)(nil).Purge), arg0, arg1)
+	return &MockTrashPurgeCall{Call: call}
+}
+
+// MockTrashPurgeCall wrap *gomock.Call
+type MockTrashPurgeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTrashPurgeCall) Return(arg0 error) *MockTrashPurgeCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTrashPurgeCall) Do(f func(context.Context, UserID) error) *MockTrashPurgeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTrashPurgeCall) DoAndReturn(f func(context.Context, UserID) error) *MockTrashPurgeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockTrash) PurgeDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockTrashMockRecorder) PurgeDeleted(arg0, arg1 any) *MockTrashPurgeDeletedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockTrash)(nil).PurgeDeleted), arg0, arg1)
+	return &MockTrashPurgeDeletedCall{Call: call}
+}
+
+// MockTrashPurgeDeletedCall wrap *gomock.Call
+type MockTrashPurgeDeletedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTrashPurgeDeletedCall) Return(arg0 int64, arg1 error) *MockTrashPurgeDeletedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTrashPurgeDeletedCall) Do(f func(context.Context, time.Time) (int64, error)) *MockTrashPurgeDeletedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTrashPurgeDeletedCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockTrashPurgeDeletedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Restore mocks base method.
+func (m *MockTrash) Restore(arg0 context.Context, arg1 UserID) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTrashMockRecorder) Restore(arg0, arg1 any) *MockTrashRestoreCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTrash)(nil).Restore), arg0, arg1)
+	return &MockTrashRestoreCall{Call: call}
+}
+
+// MockTrashRestoreCall wrap *gomock.Call
+type MockTrashRestoreCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTrashRestoreCall) Return(arg0 User, arg1 error) *MockTrashRestoreCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTrashRestoreCall) Do(f func(context.Context, UserID) (User, error)) *MockTrashRestoreCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTrashRestoreCall) DoAndReturn(f func(context.Context, UserID) (User, error)) *MockTrashRestoreCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
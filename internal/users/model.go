@@ -3,20 +3,69 @@ package users
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/manuelarte/go-web-layout/internal/config/redaction"
+)
+
+var (
+	ErrUsernameTooShort = errors.New("username too short")
+	ErrUsernameTooLong  = errors.New("username too long")
+	// ErrUsernameReserved is returned for the usernames that can't be taken, like admin.
+	ErrUsernameReserved = errors.New("username reserved")
+	ErrPasswordTooShort = errors.New("password too short")
+	ErrPasswordTooLong  = errors.New("password too long")
+	ErrInvalidEmail     = errors.New("invalid email")
+	// ErrDisplayNameTooLong is returned for the display names longer than 64 characters.
+	ErrDisplayNameTooLong = errors.New("display name too long")
+	// ErrInvalidLocale is returned for the locales that are not BCP 47 language tags.
+	ErrInvalidLocale = errors.New("invalid locale")
+	// ErrInvalidAvatarURL is returned for the avatar URLs that are not absolute https URLs.
+	ErrInvalidAvatarURL = errors.New("invalid avatar url")
+	// ErrInvalidRow is returned for the rows of an import that could not be read.
+	ErrInvalidRow = errors.New("invalid row")
+	// ErrInvalidCredentials is returned for the logins with a wrong password, or a username no user has.
+	ErrInvalidCredentials       = errors.New("invalid username or password")
+	_                     error = new(NotFoundError)
+	_                     error = new(VersionMismatchError)
+	_                     error = new(NotDeletedError)
+	_                     error = new(UsernameTakenError)
+	_                     error = new(EmailTakenError)
 )
 
 var (
-	ErrUsernameTooShort       = errors.New("username too short")
-	ErrUsernameTooLong        = errors.New("username too long")
-	ErrPasswordTooShort       = errors.New("password too short")
-	ErrPasswordTooLong        = errors.New("password too long")
-	_                   error = new(NotFoundError)
+	_ slog.LogValuer = Username("")
+	_ slog.LogValuer = Password("")
+	_ slog.LogValuer = Email("")
 )
 
+const (
+	maxEmailLength       = 254
+	maxDisplayNameLength = 64
+	maxAvatarURLLength   = 2048
+)
+
+// reservedUsernames are the normalized usernames that can't be taken,
+// because they could be mistaken for the application or its staff.
+//
+//nolint:gochecknoglobals // read-only list
+var reservedUsernames = []Username{
+	"admin", "administrator", "anonymous", "api", "me", "moderator", "root", "support", "system",
+}
+
 type (
 	// User model to represent a user.
 	//godddlint:entity
@@ -26,6 +75,55 @@ type (
 		createdAt time.Time
 		updatedAt time.Time
 		username  Username
+		profile   Profile
+		version   int64
+		deletedAt *time.Time
+		// emailVerifiedAt is when the current email was verified, nil if it was not.
+		emailVerifiedAt *time.Time
+	}
+
+	// Profile contains the optional fields describing the user, the empty ones are not set.
+	Profile struct {
+		// Email is unique, compared in its normalized form.
+		Email       Email
+		DisplayName DisplayName
+		Locale      Locale
+		AvatarURL   AvatarURL
+	}
+
+	// Filter contains the criteria of the users listed.
+	Filter struct {
+		// IncludeDeleted lists also the soft-deleted users, that are excluded by default.
+		IncludeDeleted bool
+	}
+
+	// Update contains the fields of a user to be updated, the nil ones are not modified.
+	// The profile fields set to empty are cleared.
+	Update struct {
+		Username    *Username
+		Password    *Password
+		Email       *Email
+		DisplayName *DisplayName
+		Locale      *Locale
+		AvatarURL   *AvatarURL
+	}
+
+	// ImportRow is a user to be created in a bulk import.
+	ImportRow struct {
+		// Line is the position of the row in the import, starting at 1.
+		Line     int
+		Username Username
+		Password Password
+		Profile  Profile
+		// Err is set, wrapping ErrInvalidRow, if the row could not be read, so the user is not created.
+		Err error
+	}
+
+	// ImportResult is the result of importing a row: the user created, or the error why it was not.
+	ImportResult struct {
+		Line int
+		User User
+		Err  error
 	}
 
 	UserID uuid.UUID
@@ -34,29 +132,117 @@ type (
 
 	Password string
 
+	// Email is the email address of the user, e.g. john@example.com, without a display name.
+	Email string
+
+	// DisplayName is the name of the user shown instead of the username, e.g. John Doe.
+	DisplayName string
+
+	// Locale is the BCP 47 language tag of the preferred language of the user, e.g. en-US.
+	Locale string
+
+	// AvatarURL is the absolute https URL of the picture of the user.
+	AvatarURL string
+
 	NotFoundError struct {
 		ID UserID
 	}
+
+	// VersionMismatchError is returned when the user was modified, so its version is not the expected one.
+	VersionMismatchError struct {
+		ID      UserID
+		Version int64
+	}
+
+	// NotDeletedError is returned when restoring or purging a user that is not soft-deleted.
+	NotDeletedError struct {
+		ID UserID
+	}
+
+	// UsernameTakenError is returned when creating or renaming a user with a username already taken,
+	// compared in its normalized form.
+	UsernameTakenError struct {
+		Username Username
+	}
+
+	// EmailTakenError is returned when creating or updating a user with an email already taken,
+	// compared in its normalized form.
+	EmailTakenError struct {
+		Email Email
+	}
 )
 
 func (u NotFoundError) Error() string {
 	return fmt.Sprintf("user with id %s not found", u.ID.String())
 }
 
+func (e VersionMismatchError) Error() string {
+	return fmt.Sprintf("user with id %s was modified, version %d is not the current one", e.ID.String(), e.Version)
+}
+
+func (e NotDeletedError) Error() string {
+	return fmt.Sprintf("user with id %s is not deleted", e.ID.String())
+}
+
+func (e UsernameTakenError) Error() string {
+	return "username already taken"
+}
+
+func (e EmailTakenError) Error() string {
+	return "email already taken"
+}
+
+// IsValidationError returns whether the error is due to invalid user fields, so it can be shown to the client.
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrUsernameTooShort) || errors.Is(err, ErrUsernameTooLong) ||
+		errors.Is(err, ErrUsernameReserved) ||
+		errors.Is(err, ErrPasswordTooShort) || errors.Is(err, ErrPasswordTooLong) ||
+		errors.Is(err, ErrInvalidEmail) || errors.Is(err, ErrDisplayNameTooLong) ||
+		errors.Is(err, ErrInvalidLocale) || errors.Is(err, ErrInvalidAvatarURL) ||
+		errors.Is(err, ErrInvalidRow)
+}
+
+// IsTakenError returns whether the error is due to a username or an email already taken by another user.
+func IsTakenError(err error) bool {
+	return errors.As(err, new(UsernameTakenError)) || errors.As(err, new(EmailTakenError))
+}
+
 func NewUser(
 	id UserID,
 	createdAt time.Time,
 	updatedAt time.Time,
 	username Username,
+	profile Profile,
+	version int64,
 ) User {
 	return User{
-		id:        id,
-		createdAt: createdAt,
-		updatedAt: updatedAt,
-		username:  username,
+		id:              id,
+		createdAt:       createdAt,
+		updatedAt:       updatedAt,
+		username:        username,
+		profile:         profile,
+		version:         version,
+		deletedAt:       nil,
+		emailVerifiedAt: nil,
 	}
 }
 
+// WithDeletedAt returns the user soft-deleted at the time.
+func (u *User) WithDeletedAt(deletedAt time.Time) User {
+	deleted := *u
+	deleted.deletedAt = &deletedAt
+
+	return deleted
+}
+
+// WithEmailVerifiedAt returns the user with its email verified at the time.
+func (u *User) WithEmailVerifiedAt(verifiedAt time.Time) User {
+	verified := *u
+	verified.emailVerifiedAt = &verifiedAt
+
+	return verified
+}
+
 func (id UserID) String() string {
 	return uuid.UUID(id).String()
 }
@@ -70,9 +256,30 @@ func (u Username) IsValid() error {
 		return ErrUsernameTooLong
 	}
 
+	if slices.Contains(reservedUsernames, u.Normalize()) {
+		return ErrUsernameReserved
+	}
+
 	return nil
 }
 
+// Normalize returns the form of the username compared to check if it is taken:
+// NFKC normalized and case folded, so "John" and "ＪＯＨＮ" are the same username.
+func (u Username) Normalize() Username {
+	// Case folding can produce not normalized strings, so it is normalized again, as in NFKC_Casefold.
+	return Username(norm.NFKC.String(cases.Fold().String(norm.NFKC.String(string(u)))))
+}
+
+// LogValue masks the username, so it's not logged unless the logger is configured to reveal it.
+func (u Username) LogValue() slog.Value {
+	return slog.StringValue(redaction.Masked)
+}
+
+// SensitiveValue returns the username, to be redacted by the logger's configured policy.
+func (u Username) SensitiveValue() string {
+	return string(u)
+}
+
 func (p Password) IsValid() error {
 	if len(p) < 8 {
 		return ErrPasswordTooShort
@@ -85,6 +292,93 @@ func (p Password) IsValid() error {
 	return nil
 }
 
+// LogValue masks the password, a password is never logged.
+func (p Password) LogValue() slog.Value {
+	return slog.StringValue(redaction.Masked)
+}
+
+func (e Email) IsValid() error {
+	if len(e) > maxEmailLength {
+		return ErrInvalidEmail
+	}
+
+	// Only the bare address is accepted, not one with a display name like "John <john@example.com>".
+	address, err := mail.ParseAddress(string(e))
+	if err != nil || address.Address != string(e) {
+		return ErrInvalidEmail
+	}
+
+	return nil
+}
+
+// Normalize returns the form of the email compared to check if it is taken, lower cased.
+func (e Email) Normalize() Email {
+	return Email(strings.ToLower(string(e)))
+}
+
+// LogValue masks the email, so it's not logged unless the logger is configured to reveal it.
+func (e Email) LogValue() slog.Value {
+	return slog.StringValue(redaction.Masked)
+}
+
+// SensitiveValue returns the email, to be redacted by the logger's configured policy.
+func (e Email) SensitiveValue() string {
+	return string(e)
+}
+
+func (d DisplayName) IsValid() error {
+	if utf8.RuneCountInString(string(d)) > maxDisplayNameLength {
+		return ErrDisplayNameTooLong
+	}
+
+	return nil
+}
+
+func (l Locale) IsValid() error {
+	_, err := language.Parse(string(l))
+	if err != nil {
+		return ErrInvalidLocale
+	}
+
+	return nil
+}
+
+func (a AvatarURL) IsValid() error {
+	if len(a) > maxAvatarURLLength {
+		return ErrInvalidAvatarURL
+	}
+
+	u, err := url.Parse(string(a))
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return ErrInvalidAvatarURL
+	}
+
+	return nil
+}
+
+// IsValid validates the fields of the profile that are set.
+func (p Profile) IsValid() error {
+	var errs []error
+
+	if p.Email != "" {
+		errs = append(errs, p.Email.IsValid())
+	}
+
+	if p.DisplayName != "" {
+		errs = append(errs, p.DisplayName.IsValid())
+	}
+
+	if p.Locale != "" {
+		errs = append(errs, p.Locale.IsValid())
+	}
+
+	if p.AvatarURL != "" {
+		errs = append(errs, p.AvatarURL.IsValid())
+	}
+
+	return errors.Join(errs...)
+}
+
 func (p Password) Hash() (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(p), 14)
 	if err != nil {
@@ -94,10 +388,19 @@ func (p Password) Hash() (string, error) {
 	return string(bytes), nil
 }
 
+// Matches returns whether the password is the one of the hash.
+func (p Password) Matches(hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(p)) == nil
+}
+
 func (u *User) Username() Username {
 	return u.username
 }
 
+func (u *User) Profile() Profile {
+	return u.profile
+}
+
 func (u *User) ID() UserID {
 	return u.id
 }
@@ -109,3 +412,58 @@ func (u *User) CreatedAt() time.Time {
 func (u *User) UpdatedAt() time.Time {
 	return u.updatedAt
 }
+
+// Version returns the version of the user, incremented on every update, to detect concurrent modifications.
+func (u *User) Version() int64 {
+	return u.version
+}
+
+// EmailVerifiedAt returns when the email of the user was verified, and whether it was.
+func (u *User) EmailVerifiedAt() (time.Time, bool) {
+	if u.emailVerifiedAt == nil {
+		return time.Time{}, false
+	}
+
+	return *u.emailVerifiedAt, true
+}
+
+// DeletedAt returns when the user was soft-deleted, and whether it was.
+func (u *User) DeletedAt() (time.Time, bool) {
+	if u.deletedAt == nil {
+		return time.Time{}, false
+	}
+
+	return *u.deletedAt, true
+}
+
+// IsValid validates the username, password and profile of the row.
+func (r ImportRow) IsValid() error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	return errors.Join(r.Username.IsValid(), r.Password.IsValid(), r.Profile.IsValid())
+}
+
+// IsValid validates the fields to be updated.
+func (u Update) IsValid() error {
+	var errs []error
+
+	if u.Username != nil {
+		errs = append(errs, u.Username.IsValid())
+	}
+
+	if u.Password != nil {
+		errs = append(errs, u.Password.IsValid())
+	}
+
+	// The profile fields are validated as a profile, so the empty ones, that clear them, are valid.
+	errs = append(errs, Profile{
+		Email:       lo.FromPtr(u.Email),
+		DisplayName: lo.FromPtr(u.DisplayName),
+		Locale:      lo.FromPtr(u.Locale),
+		AvatarURL:   lo.FromPtr(u.AvatarURL),
+	}.IsValid())
+
+	return errors.Join(errs...)
+}
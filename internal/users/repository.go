@@ -2,21 +2,59 @@ package users
 
 import (
 	"context"
+	"iter"
+	"time"
 
 	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
 )
 
 //go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package users -destination ./mock.gen.$GOFILE
 type (
 	// Repository interface with the user's repository methods.
+	// The users are scoped to the tenant of the context, the ones of the other tenants are not found.
 	Repository interface {
-		// Create creates a new user.
-		Create(context.Context, Username, Password) (User, error)
-		// GetAll gets all users paginated.
-		GetAll(context.Context, pagination.PageRequest) (pagination.Page[User], error)
-		// GetByID gets a user by its ID.
+		// CountByTenant counts the users of each tenant, except the soft-deleted ones.
+		CountByTenant(context.Context) (map[tenant.ID]int64, error)
+		// Create creates a new user with the profile.
+		// Can return either a validation error, UsernameTakenError, EmailTakenError, or any other database error.
+		Create(context.Context, Username, Password, Profile) (User, error)
+		// CreateMany creates the users of the rows in a single transaction.
+		// The rows failing, e.g. because the username is taken, are reported in their result
+		// without rolling back the others, the error is only returned if the transaction fails.
+		CreateMany(context.Context, []ImportRow) ([]ImportResult, error)
+		// All iterates over all the users, except the soft-deleted ones,
+		// fetching them in batches instead of loading them all in memory.
+		// The iteration stops at the first error.
+		All(context.Context) iter.Seq2[User, error]
+		// GetAll gets the users matching the filter paginated.
+		GetAll(context.Context, pagination.PageRequest, Filter) (pagination.Page[User], error)
+		// IsUsernameTaken returns whether the username, in its normalized form, is taken by a user,
+		// including the soft-deleted ones until they are purged.
+		IsUsernameTaken(context.Context, Username) (bool, error)
+		// GetByID gets a user by its ID, the soft-deleted users are not found.
 		// Can return either UserNotFoundError if the user id is not found,
 		// or any other database error.
 		GetByID(context.Context, UserID) (User, error)
+		// Update updates the user if its version is the expected one.
+		// Can return either NotFoundError, VersionMismatchError, a validation error, UsernameTakenError,
+		// EmailTakenError, or any other database error.
+		Update(context.Context, UserID, int64, Update) (User, error)
+		// Delete soft-deletes the user if its version is the expected one, it is kept until it is purged.
+		// Can return either NotFoundError, VersionMismatchError, or any other database error.
+		Delete(context.Context, UserID, int64) error
+		Trash
+	}
+
+	// Trash interface with the methods of the soft-deleted users.
+	Trash interface {
+		// Restore restores the soft-deleted user.
+		// Can return either NotFoundError, NotDeletedError, or any other database error.
+		Restore(context.Context, UserID) (User, error)
+		// Purge deletes permanently the soft-deleted user.
+		// Can return either NotFoundError, NotDeletedError, or any other database error.
+		Purge(context.Context, UserID) error
+		// PurgeDeleted deletes permanently the users of all the tenants soft-deleted before the time, returning how many.
+		PurgeDeleted(context.Context, time.Time) (int64, error)
 	}
 )
@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination is returned when a delivery would connect to an address that is not public,
+// e.g. loopback, link-local or private, and is not in the allowed networks.
+var ErrForbiddenDestination = errors.New("the destination address is not public")
+
+// nonPublicNetworks are the special-purpose ranges that are not public, besides the ones of the netip.Addr methods:
+// the carrier-grade NAT, the IETF protocol assignments, the benchmarking, the reserved,
+// and the local-use IPv4/IPv6 translation ranges.
+//
+//nolint:gochecknoglobals // read-only list
+var nonPublicNetworks = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// NewClient returns the client that sends the deliveries, waiting timeout for each response.
+// It only connects to public addresses, checked when dialing, after the host is resolved and for every redirect,
+// so a subscription can't reach the internal services of the network (SSRF).
+// The addresses in the allowed networks can be reached even if they are not public, e.g. 127.0.0.0/8 for local use.
+func NewClient(timeout time.Duration, allowed []netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second, //nolint:mnd // as http.DefaultTransport
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkDestination(address, allowed)
+		},
+	}
+
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	// The proxy would be dialed instead of the destination, skipping its check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// checkDestination returns ErrForbiddenDestination if the address, ip:port, is not public nor allowed.
+func checkDestination(address string, allowed []netip.Prefix) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, address)
+	}
+
+	addr := addrPort.Addr().Unmap()
+
+	if slices.ContainsFunc(allowed, func(prefix netip.Prefix) bool { return prefix.Contains(addr) }) {
+		return nil
+	}
+
+	if !isPublic(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, addr)
+	}
+
+	return nil
+}
+
+// isPublic returns whether the address is a public unicast one.
+func isPublic(addr netip.Addr) bool {
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	return !slices.ContainsFunc(nonPublicNetworks, func(prefix netip.Prefix) bool { return prefix.Contains(addr) })
+}
@@ -0,0 +1,125 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDestination(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		address string
+		allowed []netip.Prefix
+		wantErr error
+	}{
+		"public ipv4": {
+			address: "93.184.215.14:443",
+		},
+		"public ipv6": {
+			address: "[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443",
+		},
+		"loopback": {
+			address: "127.0.0.1:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"loopback ipv6": {
+			address: "[::1]:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"ipv4 mapped loopback": {
+			address: "[::ffff:127.0.0.1]:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"link-local, e.g. the cloud metadata": {
+			address: "169.254.169.254:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"private": {
+			address: "10.0.0.1:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"unique local ipv6": {
+			address: "[fd00::1]:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"carrier-grade nat": {
+			address: "100.64.0.1:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"unspecified": {
+			address: "0.0.0.0:80",
+			wantErr: ErrForbiddenDestination,
+		},
+		"allowed loopback": {
+			address: "127.0.0.1:80",
+			allowed: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+		},
+		"private not in the allowed networks": {
+			address: "192.168.1.1:80",
+			allowed: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+			wantErr: ErrForbiddenDestination,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			err := checkDestination(test.address, test.allowed)
+
+			// Assert
+			require.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		allowed []netip.Prefix
+		wantErr error
+	}{
+		"loopback is rejected": {
+			wantErr: ErrForbiddenDestination,
+		},
+		"loopback in the allowed networks": {
+			allowed: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			t.Cleanup(server.Close)
+
+			client := NewClient(time.Second, test.allowed)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL, http.NoBody)
+			require.NoError(t, err)
+
+			// Act
+			resp, err := client.Do(req)
+
+			// Assert
+			require.ErrorIs(t, err, test.wantErr)
+
+			if test.wantErr == nil {
+				_ = resp.Body.Close()
+
+				assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			}
+		})
+	}
+}
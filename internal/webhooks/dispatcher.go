@@ -0,0 +1,310 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/manuelarte/go-web-layout/internal/config/info"
+	"github.com/manuelarte/go-web-layout/internal/config/observability"
+	"github.com/manuelarte/go-web-layout/internal/outbox"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+const (
+	// dispatchBatchSize is the maximum number of deliveries sent in each pass of the dispatcher.
+	dispatchBatchSize = 50
+	// dispatchConcurrency is the maximum number of deliveries sent at the same time.
+	dispatchConcurrency = 10
+	// maxAttempts is the number of attempts of a delivery before it is marked as failed.
+	maxAttempts = 8
+	// minRetryDelay is the delay before retrying a failed attempt the first time, doubled on every attempt.
+	minRetryDelay = 10 * time.Second
+	// maxRetryDelay is the maximum delay before retrying a failed attempt.
+	maxRetryDelay = time.Hour
+	// maxResponseSize is the maximum size of the response read, so the connection can be reused.
+	maxResponseSize = 64 * 1024
+)
+
+var _ outbox.Publisher = new(Dispatcher)
+
+type (
+	// Dispatcher delivers the user events to the webhook subscriptions.
+	// The deliveries are queued when the events are published, and sent in the background,
+	// retrying the failed attempts with exponential backoff, so a slow or failing subscription does not
+	// hold back the others.
+	Dispatcher struct {
+		repository   Repository
+		client       *http.Client
+		logger       *slog.Logger
+		pollInterval time.Duration
+	}
+
+	// testPayload is the payload of the test deliveries.
+	testPayload struct {
+		Type       users.EventType `json:"type"`
+		WebhookID  uuid.UUID       `json:"webhookId"`
+		OccurredAt time.Time       `json:"occurredAt"`
+	}
+)
+
+// NewDispatcher creates the dispatcher, sending the deliveries with the client,
+// and looking for pending deliveries every pollInterval.
+func NewDispatcher(
+	repository Repository,
+	client *http.Client,
+	logger *slog.Logger,
+	pollInterval time.Duration,
+) Dispatcher {
+	return Dispatcher{
+		repository:   repository,
+		client:       client,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+// Publish queues a delivery of the event to each subscription to it of the tenant of the user.
+func (d Dispatcher) Publish(ctx context.Context, event users.Event) error {
+	subscriptions, err := d.repository.Subscribed(ctx, event.TenantID, event.Type)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions: %w", err)
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := outbox.MarshalEvent(event)
+	if err != nil {
+		return fmt.Errorf("error creating payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	deliveries := make([]Delivery, 0, len(subscriptions))
+
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, NewDelivery(subscription, event.Type, payload, now))
+	}
+
+	err = d.repository.CreateDeliveries(ctx, deliveries...)
+	if err != nil {
+		return fmt.Errorf("error creating deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// Run sends the pending deliveries until the context is done.
+func (d Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := d.Dispatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.ErrorContext(ctx, "Failed to dispatch webhooks", slog.Any("error", err))
+		}
+
+		// Keep going while there are deliveries due, otherwise wait for new ones.
+		if sent > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends the deliveries due, returning how many of them were attempted.
+func (d Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	ctx, span := observability.StartSpan(ctx, "Dispatcher.Dispatch")
+	defer span.End()
+
+	deliveries, err := d.repository.PendingDeliveries(ctx, time.Now().UTC(), dispatchBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error getting pending deliveries: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("pending", len(deliveries)))
+
+	subscriptions := make(map[SubscriptionID]Subscription)
+
+	for _, delivery := range deliveries {
+		if _, ok := subscriptions[delivery.SubscriptionID]; ok {
+			continue
+		}
+
+		subscription, errGet := d.repository.GetByID(tenant.WithID(ctx, delivery.TenantID), delivery.SubscriptionID)
+		if errGet != nil {
+			return 0, fmt.Errorf("error getting subscription: %w", errGet)
+		}
+
+		subscriptions[subscription.ID] = subscription
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(dispatchConcurrency)
+
+	for _, delivery := range deliveries {
+		g.Go(func() error {
+			attempted := d.attempt(gCtx, subscriptions[delivery.SubscriptionID], delivery)
+
+			errUpdate := d.repository.UpdateDelivery(gCtx, attempted)
+			if errUpdate != nil {
+				return fmt.Errorf("error updating delivery: %w", errUpdate)
+			}
+
+			return nil
+		})
+	}
+
+	err = g.Wait()
+	if err != nil {
+		return 0, err //nolint:wrapcheck // Already wrapped in the goroutine
+	}
+
+	return len(deliveries), nil
+}
+
+// Test sends a test event to the subscription, recording it in its deliveries.
+// The test deliveries are attempted only once, so the result is known right away.
+// Can return either NotFoundError if the subscription id is not found, or any other database error.
+func (d Dispatcher) Test(ctx context.Context, id SubscriptionID) (Delivery, error) {
+	ctx, span := observability.StartSpan(ctx, "Dispatcher.Test")
+	defer span.End()
+
+	subscription, err := d.repository.GetByID(ctx, id)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("error getting subscription: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	payload, err := json.Marshal(testPayload{Type: EventTest, WebhookID: uuid.UUID(id), OccurredAt: now})
+	if err != nil {
+		return Delivery{}, fmt.Errorf("error creating payload: %w", err)
+	}
+
+	delivery := d.attempt(ctx, subscription, NewDelivery(subscription, EventTest, payload, now))
+	if delivery.Status == DeliveryPending {
+		delivery.Status = DeliveryFailed
+	}
+
+	err = d.repository.CreateDeliveries(ctx, delivery)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("error creating delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// attempt sends the delivery to the subscription, returning it with the outcome of the attempt:
+// succeeded, pending to be retried, or failed if it was the last attempt.
+func (d Dispatcher) attempt(ctx context.Context, subscription Subscription, delivery Delivery) Delivery {
+	ctx, span := observability.StartSpan(
+		ctx,
+		"Dispatcher.attempt",
+		oteltrace.WithAttributes(
+			attribute.String("id", delivery.ID.String()),
+			attribute.String("webhookId", subscription.ID.String()),
+			attribute.Int("attempts", delivery.Attempts),
+		),
+	)
+	defer span.End()
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+
+	statusCode, err := d.send(ctx, subscription, delivery, now)
+	delivery.StatusCode = statusCode
+
+	if err == nil {
+		delivery.Status = DeliverySucceeded
+		delivery.Error = ""
+
+		return delivery
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= maxAttempts {
+		delivery.Status = DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempts - 1))
+	}
+
+	d.logger.WarnContext(
+		ctx,
+		"Failed to deliver webhook",
+		slog.String("id", delivery.ID.String()),
+		slog.String("webhookId", subscription.ID.String()),
+		slog.Int("attempts", delivery.Attempts),
+		slog.String("status", string(delivery.Status)),
+		slog.Any("error", err),
+	)
+
+	return delivery
+}
+
+// send posts the signed delivery to the subscription url, returning the status code of the response.
+func (d Dispatcher) send(
+	ctx context.Context,
+	subscription Subscription,
+	delivery Delivery,
+	now time.Time,
+) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", info.AppName+"-webhooks")
+	req.Header.Set(HeaderID, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, delivery.ID.String(), now, delivery.Payload))
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %w", err)
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// retryDelay returns the delay before retrying a delivery that failed after the attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for range attempts {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+
+	return delay
+}
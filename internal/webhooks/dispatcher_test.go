@@ -0,0 +1,136 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		statusCode     int
+		attempts       int
+		expectedStatus DeliveryStatus
+		expectedRetry  bool
+	}{
+		"succeeded": {
+			statusCode:     http.StatusNoContent,
+			expectedStatus: DeliverySucceeded,
+		},
+		"failed attempt is retried later": {
+			statusCode:     http.StatusInternalServerError,
+			attempts:       2,
+			expectedStatus: DeliveryPending,
+			expectedRetry:  true,
+		},
+		"failed after the last attempt": {
+			statusCode:     http.StatusInternalServerError,
+			attempts:       maxAttempts - 1,
+			expectedStatus: DeliveryFailed,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			subscription := Subscription{
+				ID:     SubscriptionID(uuid.New()),
+				Secret: "whsec_secret",
+				Events: Events(),
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, Verify(subscription.Secret, r.Header, body, time.Now(), time.Minute))
+				assert.Equal(t, string(users.EventCreated), r.Header.Get(HeaderEvent))
+
+				w.WriteHeader(test.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			subscription.URL = server.URL
+
+			delivery := NewDelivery(subscription, users.EventCreated, []byte(`{}`), time.Now())
+			delivery.Attempts = test.attempts
+
+			ctrl := gomock.NewController(t)
+			repository := NewMockRepository(ctrl)
+			repository.EXPECT().PendingDeliveries(gomock.Any(), gomock.Any(), dispatchBatchSize).
+				Return([]Delivery{delivery}, nil)
+			repository.EXPECT().GetByID(gomock.Any(), subscription.ID).Return(subscription, nil)
+			repository.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, actual Delivery) error {
+					assert.Equal(t, test.expectedStatus, actual.Status)
+					assert.Equal(t, test.attempts+1, actual.Attempts)
+					assert.Equal(t, test.statusCode, actual.StatusCode)
+
+					if test.expectedRetry {
+						retryAt := time.Now().Add(retryDelay(test.attempts))
+						assert.WithinDuration(t, retryAt, actual.NextAttemptAt, time.Second)
+					}
+
+					return nil
+				})
+
+			dispatcher := NewDispatcher(repository, server.Client(), slog.New(slog.DiscardHandler), time.Second)
+
+			// Act
+			sent, err := dispatcher.Dispatch(t.Context())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, 1, sent)
+		})
+	}
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	subscriptions := []Subscription{
+		{ID: SubscriptionID(uuid.New()), TenantID: "acme"},
+		{ID: SubscriptionID(uuid.New()), TenantID: "acme"},
+	}
+	event := users.NewDeletedEvent(users.UserID(uuid.New()), time.Now())
+	event.TenantID = "acme"
+
+	ctrl := gomock.NewController(t)
+	repository := NewMockRepository(ctrl)
+	repository.EXPECT().Subscribed(gomock.Any(), event.TenantID, users.EventDeleted).Return(subscriptions, nil)
+	repository.EXPECT().CreateDeliveries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, deliveries ...Delivery) error {
+			require.Len(t, deliveries, len(subscriptions))
+
+			for i, delivery := range deliveries {
+				assert.Equal(t, subscriptions[i].ID, delivery.SubscriptionID)
+				assert.Equal(t, event.TenantID, delivery.TenantID)
+				assert.Equal(t, DeliveryPending, delivery.Status)
+			}
+
+			return nil
+		})
+
+	dispatcher := NewDispatcher(repository, http.DefaultClient, slog.New(slog.DiscardHandler), time.Second)
+
+	// Act
+	err := dispatcher.Publish(t.Context(), event)
+
+	// Assert
+	require.NoError(t, err)
+}
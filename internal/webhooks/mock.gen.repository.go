@@ -0,0 +1,437 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+//
+// Generated by this command:
+//
+//	mockgen -typed -package webhooks -source repository.go -package webhooks -destination ./mock.gen.repository.go
+//
+
+// Package webhooks is a generated GoMock package.
+package webhooks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	pagination "github.com/manuelarte/go-web-layout/internal/pagination"
+	tenant "github.com/manuelarte/go-web-layout/internal/tenant"
+	users "github.com/manuelarte/go-web-layout/internal/users"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(arg0 context.Context, arg1 Subscription) (Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(arg0, arg1 any) *MockRepositoryCreateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1)
+	return &MockRepositoryCreateCall{Call: call}
+}
+
+// MockRepositoryCreateCall wrap *gomock.Call
+type MockRepositoryCreateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCreateCall) Return(arg0 Subscription, arg1 error) *MockRepositoryCreateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCreateCall) Do(f func(context.Context, Subscription) (Subscription, error)) *MockRepositoryCreateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCreateCall) DoAndReturn(f func(context.Context, Subscription) (Subscription, error)) *MockRepositoryCreateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateDeliveries mocks base method.
+func (m *MockRepository) CreateDeliveries(arg0 context.Context, arg1 ...Delivery) error {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateDeliveries", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveries indicates an expected call of CreateDeliveries.
+func (mr *MockRepositoryMockRecorder) CreateDeliveries(arg0 any, arg1 ...any) *MockRepositoryCreateDeliveriesCall {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0}, arg1...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveries", reflect.TypeOf((*MockRepository)(nil).CreateDeliveries), varargs...)
+	return &MockRepositoryCreateDeliveriesCall{Call: call}
+}
+
+// MockRepositoryCreateDeliveriesCall wrap *gomock.Call
+type MockRepositoryCreateDeliveriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryCreateDeliveriesCall) Return(arg0 error) *MockRepositoryCreateDeliveriesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryCreateDeliveriesCall) Do(f func(context.Context, ...Delivery) error) *MockRepositoryCreateDeliveriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryCreateDeliveriesCall) DoAndReturn(f func(context.Context, ...Delivery) error) *MockRepositoryCreateDeliveriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(arg0 context.Context, arg1 SubscriptionID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(arg0, arg1 any) *MockRepositoryDeleteCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), arg0, arg1)
+	return &MockRepositoryDeleteCall{Call: call}
+}
+
+// MockRepositoryDeleteCall wrap *gomock.Call
+type MockRepositoryDeleteCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryDeleteCall) Return(arg0 error) *MockRepositoryDeleteCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryDeleteCall) Do(f func(context.Context, SubscriptionID) error) *MockRepositoryDeleteCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryDeleteCall) DoAndReturn(f func(context.Context, SubscriptionID) error) *MockRepositoryDeleteCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetAll mocks base method.
+func (m *MockRepository) GetAll(arg0 context.Context, arg1 pagination.PageRequest) (pagination.Page[Subscription], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0, arg1)
+	ret0, _ := ret[0].(pagination.Page[Subscription])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRepositoryMockRecorder) GetAll(arg0, arg1 any) *MockRepositoryGetAllCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), arg0, arg1)
+	return &MockRepositoryGetAllCall{Call: call}
+}
+
+// MockRepositoryGetAllCall wrap *gomock.Call
+type MockRepositoryGetAllCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetAllCall) Return(arg0 pagination.Page[Subscription], arg1 error) *MockRepositoryGetAllCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetAllCall) Do(f func(context.Context, pagination.PageRequest) (pagination.Page[Subscription], error)) *MockRepositoryGetAllCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetAllCall) DoAndReturn(f func(context.Context, pagination.PageRequest) (pagination.Page[Subscription], error)) *MockRepositoryGetAllCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetByID mocks base method.
+func (m *MockRepository) GetByID(arg0 context.Context, arg1 SubscriptionID) (Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
+	ret0, _ := ret[0].(Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockRepositoryMockRecorder) GetByID(arg0, arg1 any) *MockRepositoryGetByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), arg0, arg1)
+	return &MockRepositoryGetByIDCall{Call: call}
+}
+
+// MockRepositoryGetByIDCall wrap *gomock.Call
+type MockRepositoryGetByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetByIDCall) Return(arg0 Subscription, arg1 error) *MockRepositoryGetByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetByIDCall) Do(f func(context.Context, SubscriptionID) (Subscription, error)) *MockRepositoryGetByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetByIDCall) DoAndReturn(f func(context.Context, SubscriptionID) (Subscription, error)) *MockRepositoryGetByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetDeliveries mocks base method.
+func (m *MockRepository) GetDeliveries(arg0 context.Context, arg1 SubscriptionID, arg2 pagination.PageRequest) (pagination.Page[Delivery], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].(pagination.Page[Delivery])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockRepositoryMockRecorder) GetDeliveries(arg0, arg1, arg2 any) *MockRepositoryGetDeliveriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockRepository)(nil).GetDeliveries), arg0, arg1, arg2)
+	return &MockRepositoryGetDeliveriesCall{Call: call}
+}
+
+// MockRepositoryGetDeliveriesCall wrap *gomock.Call
+type MockRepositoryGetDeliveriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryGetDeliveriesCall) Return(arg0 pagination.Page[Delivery], arg1 error) *MockRepositoryGetDeliveriesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryGetDeliveriesCall) Do(f func(context.Context, SubscriptionID, pagination.PageRequest) (pagination.Page[Delivery], error)) *MockRepositoryGetDeliveriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryGetDeliveriesCall) DoAndReturn(f func(context.Context, SubscriptionID, pagination.PageRequest) (pagination.Page[Delivery], error)) *MockRepositoryGetDeliveriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PendingDeliveries mocks base method.
+func (m *MockRepository) PendingDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingDeliveries", ctx, now, limit)
+	ret0, _ := ret[0].([]Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingDeliveries indicates an expected call of PendingDeliveries.
+func (mr *MockRepositoryMockRecorder) PendingDeliveries(ctx, now, limit any) *MockRepositoryPendingDeliveriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingDeliveries", reflect.TypeOf((*MockRepository)(nil).PendingDeliveries), ctx, now, limit)
+	return &MockRepositoryPendingDeliveriesCall{Call: call}
+}
+
+// MockRepositoryPendingDeliveriesCall wrap *gomock.Call
+type MockRepositoryPendingDeliveriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryPendingDeliveriesCall) Return(arg0 []Delivery, arg1 error) *MockRepositoryPendingDeliveriesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryPendingDeliveriesCall) Do(f func(context.Context, time.Time, int) ([]Delivery, error)) *MockRepositoryPendingDeliveriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryPendingDeliveriesCall) DoAndReturn(f func(context.Context, time.Time, int) ([]Delivery, error)) *MockRepositoryPendingDeliveriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Subscribed mocks base method.
+func (m *MockRepository) Subscribed(arg0 context.Context, arg1 tenant.ID, arg2 users.EventType) ([]Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribed", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribed indicates an expected call of Subscribed.
+func (mr *MockRepositoryMockRecorder) Subscribed(arg0, arg1, arg2 any) *MockRepositorySubscribedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribed", reflect.TypeOf((*MockRepository)(nil).Subscribed), arg0, arg1, arg2)
+	return &MockRepositorySubscribedCall{Call: call}
+}
+
+// MockRepositorySubscribedCall wrap *gomock.Call
+type MockRepositorySubscribedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositorySubscribedCall) Return(arg0 []Subscription, arg1 error) *MockRepositorySubscribedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositorySubscribedCall) Do(f func(context.Context, tenant.ID, users.EventType) ([]Subscription, error)) *MockRepositorySubscribedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositorySubscribedCall) DoAndReturn(f func(context.Context, tenant.ID, users.EventType) ([]Subscription, error)) *MockRepositorySubscribedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(arg0 context.Context, arg1 SubscriptionID, arg2 Update) (Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(arg0, arg1, arg2 any) *MockRepositoryUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), arg0, arg1, arg2)
+	return &MockRepositoryUpdateCall{Call: call}
+}
+
+// MockRepositoryUpdateCall wrap *gomock.Call
+type MockRepositoryUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryUpdateCall) Return(arg0 Subscription, arg1 error) *MockRepositoryUpdateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryUpdateCall) Do(f func(context.Context, SubscriptionID, Update) (Subscription, error)) *MockRepositoryUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryUpdateCall) DoAndReturn(f func(context.Context, SubscriptionID, Update) (Subscription, error)) *MockRepositoryUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockRepository) UpdateDelivery(arg0 context.Context, arg1 Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockRepositoryMockRecorder) UpdateDelivery(arg0, arg1 any) *MockRepositoryUpdateDeliveryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockRepository)(nil).UpdateDelivery), arg0, arg1)
+	return &MockRepositoryUpdateDeliveryCall{Call: call}
+}
+
+// MockRepositoryUpdateDeliveryCall wrap *gomock.Call
+type MockRepositoryUpdateDeliveryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockRepositoryUpdateDeliveryCall) Return(arg0 error) *MockRepositoryUpdateDeliveryCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockRepositoryUpdateDeliveryCall) Do(f func(context.Context, Delivery) error) *MockRepositoryUpdateDeliveryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockRepositoryUpdateDeliveryCall) DoAndReturn(f func(context.Context, Delivery) error) *MockRepositoryUpdateDeliveryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
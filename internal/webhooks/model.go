@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+// Statuses of a delivery.
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// EventTest is the type of the event sent by the test deliveries.
+const EventTest users.EventType = "webhook.test"
+
+// secretSize is the number of random bytes of the secrets signing the deliveries.
+const secretSize = 32
+
+var (
+	ErrInvalidURL         = errors.New("the url must be an absolute http or https url")
+	ErrNoEvents           = errors.New("at least one event is required")
+	ErrUnknownEvent       = errors.New("unknown event")
+	_               error = new(NotFoundError)
+)
+
+type (
+	// Subscription is a URL notified of the events of the users of its tenant it is subscribed to.
+	Subscription struct {
+		ID SubscriptionID
+		// TenantID is the tenant the subscription belongs to, set when it is created.
+		TenantID tenant.ID
+		URL      string
+		Events   []users.EventType
+		// Secret is the key the deliveries are signed with.
+		Secret    string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// Update contains the fields of a subscription to be updated, the nil ones are not modified.
+	Update struct {
+		URL    *string
+		Events []users.EventType
+	}
+
+	// Delivery is the notification of an event to a subscription, retried until it succeeds or fails too many times.
+	Delivery struct {
+		ID DeliveryID
+		// TenantID is the tenant of the subscription.
+		TenantID       tenant.ID
+		SubscriptionID SubscriptionID
+		EventType      users.EventType
+		Payload        []byte
+		Status         DeliveryStatus
+		Attempts       int
+		// NextAttemptAt is when the delivery is retried, while it is pending.
+		NextAttemptAt time.Time
+		// StatusCode is the status code of the last response, 0 if there was none.
+		StatusCode int
+		// Error is why the last attempt failed.
+		Error     string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	SubscriptionID uuid.UUID
+
+	DeliveryID uuid.UUID
+
+	DeliveryStatus string
+
+	NotFoundError struct {
+		ID SubscriptionID
+	}
+)
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("webhook with id %s not found", e.ID.String())
+}
+
+// IsValidationError returns whether the error is due to invalid subscription fields, so it can be shown to the client.
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrInvalidURL) || errors.Is(err, ErrNoEvents) || errors.Is(err, ErrUnknownEvent)
+}
+
+// NewSubscription creates the subscription of the url to the events, with a new id and secret.
+func NewSubscription(u string, events []users.EventType) (Subscription, error) {
+	err := errors.Join(ValidateURL(u), ValidateEvents(events))
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	secret := make([]byte, secretSize)
+	_, _ = rand.Read(secret)
+
+	return Subscription{
+		ID:     SubscriptionID(uuid.New()),
+		URL:    u,
+		Events: slices.Compact(slices.Sorted(slices.Values(events))),
+		Secret: "whsec_" + base64.RawURLEncoding.EncodeToString(secret),
+	}, nil
+}
+
+// NewDelivery creates the pending delivery of the event payload to the subscription, to be sent at now.
+func NewDelivery(subscription Subscription, eventType users.EventType, payload []byte, now time.Time) Delivery {
+	return Delivery{
+		ID:             DeliveryID(uuid.New()),
+		TenantID:       subscription.TenantID,
+		SubscriptionID: subscription.ID,
+		EventType:      eventType,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Events returns the user events that can be subscribed to.
+func Events() []users.EventType {
+	return []users.EventType{users.EventCreated, users.EventUpdated, users.EventDeleted}
+}
+
+// ValidateURL returns ErrInvalidURL if the url is not an absolute http or https url.
+func ValidateURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+
+	return nil
+}
+
+// ValidateEvents returns ErrNoEvents if there are no events, or ErrUnknownEvent if any can't be subscribed to.
+func ValidateEvents(events []users.EventType) error {
+	if len(events) == 0 {
+		return ErrNoEvents
+	}
+
+	for _, event := range events {
+		if !slices.Contains(Events(), event) {
+			return fmt.Errorf("%w: %s", ErrUnknownEvent, event)
+		}
+	}
+
+	return nil
+}
+
+// IsValid validates the fields to be updated.
+func (u Update) IsValid() error {
+	var errURL, errEvents error
+	if u.URL != nil {
+		errURL = ValidateURL(*u.URL)
+	}
+
+	if u.Events != nil {
+		errEvents = ValidateEvents(u.Events)
+	}
+
+	return errors.Join(errURL, errEvents)
+}
+
+func (id SubscriptionID) String() string {
+	return uuid.UUID(id).String()
+}
+
+func (id DeliveryID) String() string {
+	return uuid.UUID(id).String()
+}
@@ -0,0 +1,43 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/manuelarte/go-web-layout/internal/pagination"
+	"github.com/manuelarte/go-web-layout/internal/tenant"
+	"github.com/manuelarte/go-web-layout/internal/users"
+)
+
+//go:generate mockgen -typed -package $GOPACKAGE -source $GOFILE -package webhooks -destination ./mock.gen.$GOFILE
+type (
+	// Repository interface with the webhook subscriptions and deliveries repository methods.
+	// The subscriptions, and their deliveries, are the ones of the tenant of the context,
+	// except for the methods of the dispatcher, which are of every tenant.
+	Repository interface {
+		// Create creates the subscription in the tenant of the context.
+		Create(context.Context, Subscription) (Subscription, error)
+		// GetAll gets all the subscriptions paginated.
+		GetAll(context.Context, pagination.PageRequest) (pagination.Page[Subscription], error)
+		// GetByID gets a subscription by its ID.
+		// Can return either NotFoundError if the subscription id is not found, or any other database error.
+		GetByID(context.Context, SubscriptionID) (Subscription, error)
+		// Subscribed gets the subscriptions of the tenant to the event type.
+		Subscribed(context.Context, tenant.ID, users.EventType) ([]Subscription, error)
+		// Update updates the subscription.
+		// Can return either NotFoundError, a validation error, or any other database error.
+		Update(context.Context, SubscriptionID, Update) (Subscription, error)
+		// Delete deletes the subscription and its deliveries.
+		// Can return either NotFoundError, or any other database error.
+		Delete(context.Context, SubscriptionID) error
+		// CreateDeliveries creates the deliveries.
+		CreateDeliveries(context.Context, ...Delivery) error
+		// PendingDeliveries gets, oldest first, up to limit pending deliveries of every tenant due to be sent at now.
+		PendingDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+		// UpdateDelivery records the outcome of an attempt of the delivery.
+		UpdateDelivery(context.Context, Delivery) error
+		// GetDeliveries gets the deliveries of the subscription paginated, newest first.
+		// Can return either NotFoundError if the subscription id is not found, or any other database error.
+		GetDeliveries(context.Context, SubscriptionID, pagination.PageRequest) (pagination.Page[Delivery], error)
+	}
+)
@@ -0,0 +1,65 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of the deliveries.
+const (
+	// HeaderID is the header with the id of the delivery, the same in all its attempts, to detect duplicates.
+	HeaderID = "Webhook-Id"
+	// HeaderTimestamp is the header with the unix time the attempt was sent at.
+	HeaderTimestamp = "Webhook-Timestamp"
+	// HeaderSignature is the header with the signature of the attempt, "v1=" and the hex HMAC-SHA256.
+	HeaderSignature = "Webhook-Signature"
+	// HeaderEvent is the header with the type of the event delivered.
+	HeaderEvent = "Webhook-Event"
+)
+
+// signatureVersion is the prefix of the signatures, identifying how they are computed.
+const signatureVersion = "v1="
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrTimestampTooOld  = errors.New("webhook timestamp out of tolerance")
+)
+
+// Sign returns the signature of an attempt of the delivery: the HMAC-SHA256, with the secret,
+// of its id, the unix timestamp and the body, joined by dots.
+// The timestamp is signed, so the receivers can reject the attempts replayed later.
+func Sign(secret, id string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(id + "." + strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	_, _ = mac.Write(body)
+
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks, as a receiver, that the attempt with the headers and body was signed with the secret,
+// and sent less than tolerance before now.
+// Returns ErrInvalidSignature if the signature does not match, or ErrTimestampTooOld.
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	timestamp := time.Unix(unix, 0)
+
+	expected := Sign(secret, header.Get(HeaderID), timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(HeaderSignature))) {
+		return ErrInvalidSignature
+	}
+
+	if now.Sub(timestamp).Abs() > tolerance {
+		return ErrTimestampTooOld
+	}
+
+	return nil
+}
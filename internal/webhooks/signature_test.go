@@ -0,0 +1,65 @@
+package webhooks
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	body := []byte(`{"type":"user.created"}`)
+
+	tests := map[string]struct {
+		secret      string
+		sentAt      time.Time
+		body        []byte
+		expectedErr error
+	}{
+		"valid signature": {
+			secret: "whsec_secret",
+			sentAt: now,
+			body:   body,
+		},
+		"wrong secret": {
+			secret:      "whsec_other",
+			sentAt:      now,
+			body:        body,
+			expectedErr: ErrInvalidSignature,
+		},
+		"tampered body": {
+			secret:      "whsec_secret",
+			sentAt:      now,
+			body:        []byte(`{"type":"user.deleted"}`),
+			expectedErr: ErrInvalidSignature,
+		},
+		"replayed later": {
+			secret:      "whsec_secret",
+			sentAt:      now.Add(-10 * time.Minute),
+			body:        body,
+			expectedErr: ErrTimestampTooOld,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			header := http.Header{}
+			header.Set(HeaderID, "delivery-id")
+			header.Set(HeaderTimestamp, strconv.FormatInt(test.sentAt.Unix(), 10))
+			header.Set(HeaderSignature, Sign(test.secret, "delivery-id", test.sentAt, body))
+
+			// Act
+			err := Verify("whsec_secret", header, test.body, now, 5*time.Minute)
+
+			// Assert
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
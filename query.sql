@@ -1,16 +1,290 @@
 -- name: GetUserByID :one
-SELECT * FROM users WHERE ID = ?;
+SELECT * FROM users WHERE tenant_id = ? AND ID = ? AND deleted_at IS NULL;
+
+-- name: GetUserByIDIncludingDeleted :one
+SELECT * FROM users WHERE tenant_id = ? AND ID = ?;
 
 -- name: GetUsers :many
-SELECT * FROM users LIMIT ? OFFSET ?;
+SELECT * FROM users
+WHERE tenant_id = sqlc.arg(tenant_id) AND (deleted_at IS NULL OR CAST(sqlc.arg(include_deleted) AS boolean))
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: GetUsersAfterID :many
+SELECT * FROM users WHERE tenant_id = ? AND id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?;
 
 -- name: CountUsers :one
-SELECT COUNT(*) FROM users;
+SELECT COUNT(*) FROM users
+WHERE tenant_id = sqlc.arg(tenant_id) AND (deleted_at IS NULL OR CAST(sqlc.arg(include_deleted) AS boolean));
+
+-- name: CountUsersByTenant :many
+SELECT tenant_id, COUNT(*) AS count FROM users WHERE deleted_at IS NULL GROUP BY tenant_id;
+
+-- name: IsUsernameTaken :one
+SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id = ? AND username_normalized = ?);
 
 -- name: CreateUser :one
 INSERT INTO users (
-    id, username, password
+    id, tenant_id, username, username_normalized, password, email, email_normalized, display_name, locale, avatar_url
 ) VALUES (
-  ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
+
+-- name: UpdateUser :one
+UPDATE users SET
+    username = COALESCE(sqlc.narg(username), username),
+    username_normalized = COALESCE(sqlc.narg(username_normalized), username_normalized),
+    password = COALESCE(sqlc.narg(password), password),
+    email = COALESCE(sqlc.narg(email), email),
+    email_normalized = COALESCE(sqlc.narg(email_normalized), email_normalized),
+    display_name = COALESCE(sqlc.narg(display_name), display_name),
+    locale = COALESCE(sqlc.narg(locale), locale),
+    avatar_url = COALESCE(sqlc.narg(avatar_url), avatar_url),
+    email_verified_at = CASE
+        WHEN COALESCE(sqlc.narg(email_normalized), email_normalized) = email_normalized THEN email_verified_at
+    END,
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND version = sqlc.arg(version) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteUser :execrows
+UPDATE users SET
+    deleted_at = sqlc.arg(deleted_at),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND version = sqlc.arg(version) AND deleted_at IS NULL;
+
+-- name: RestoreUser :one
+UPDATE users SET
+    deleted_at = NULL,
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeUser :one
+DELETE FROM users WHERE tenant_id = ? AND id = ? AND deleted_at IS NOT NULL RETURNING *;
+
+-- name: PurgeDeletedUsers :many
+-- The users are purged by the retention job, so those of all the tenants.
+DELETE FROM users WHERE deleted_at <= sqlc.arg(deleted_before) RETURNING *;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE tenant_id = ? AND email_normalized = ? AND email_normalized != '' AND deleted_at IS NULL;
+
+-- name: VerifyUserEmail :one
+UPDATE users SET
+    email_verified_at = sqlc.arg(email_verified_at),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND email_normalized = sqlc.arg(email_normalized)
+    AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ResetUserPassword :one
+UPDATE users SET
+    password = sqlc.arg(password),
+    updated_at = sqlc.arg(updated_at),
+    version = version + 1
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CreateUserToken :exec
+INSERT INTO user_tokens (
+    token_hash, user_id, purpose, email, created_at, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+);
+
+-- name: DeleteUserTokens :exec
+DELETE FROM user_tokens WHERE user_id = ? AND purpose = ?;
+
+-- name: UseUserToken :one
+DELETE FROM user_tokens
+WHERE token_hash = sqlc.arg(token_hash) AND purpose = sqlc.arg(purpose) AND expires_at > sqlc.arg(now)
+RETURNING *;
+
+-- name: DeleteExpiredUserTokens :execrows
+DELETE FROM user_tokens WHERE expires_at <= ?;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users WHERE tenant_id = ? AND username_normalized = ? AND deleted_at IS NULL;
+
+-- name: GetLoginAttempts :one
+SELECT * FROM login_attempts WHERE scope = ? AND value = ?;
+
+-- name: UpsertLoginAttempts :exec
+INSERT INTO login_attempts (
+    scope, value, failures, last_failure_at, retry_at, locked_until, in_flight, in_flight_until, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (scope, value) DO UPDATE SET
+    failures = excluded.failures,
+    last_failure_at = excluded.last_failure_at,
+    retry_at = excluded.retry_at,
+    locked_until = excluded.locked_until,
+    in_flight = excluded.in_flight,
+    in_flight_until = excluded.in_flight_until,
+    expires_at = excluded.expires_at;
+
+-- name: DeleteLoginAttempts :exec
+DELETE FROM login_attempts WHERE scope = ? AND value = ?;
+
+-- name: DeleteExpiredLoginAttempts :execrows
+DELETE FROM login_attempts WHERE expires_at <= ?;
+
+-- name: DeleteIdempotencyKeyIfExpired :exec
+DELETE FROM idempotency_keys WHERE scope = ? AND key = ? AND expires_at <= ?;
+
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (
+    scope, key, fingerprint, expires_at
+) VALUES (
+  ?, ?, ?, ?
+)
+ON CONFLICT (scope, key) DO NOTHING;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys WHERE scope = ? AND key = ?;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys SET status_code = ?, response = ? WHERE scope = ? AND key = ?;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE scope = ? AND key = ?;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= ?;
+
+-- name: CreateOutboxEntry :exec
+INSERT INTO outbox (
+    aggregate_id, event_type, payload, created_at, next_attempt_at
+) VALUES (
+  sqlc.arg(aggregate_id), sqlc.arg(event_type), sqlc.arg(payload), sqlc.arg(created_at), sqlc.arg(created_at)
+);
+
+-- name: GetPendingOutboxEntries :many
+SELECT * FROM outbox o
+WHERE o.next_attempt_at <= sqlc.arg(now)
+  AND NOT EXISTS (SELECT 1 FROM outbox p WHERE p.aggregate_id = o.aggregate_id AND p.id < o.id)
+ORDER BY o.id
+LIMIT sqlc.arg(limit);
+
+-- name: DeleteOutboxEntry :exec
+DELETE FROM outbox WHERE id = ?;
+
+-- name: FailOutboxEntry :exec
+UPDATE outbox SET attempts = attempts + 1, next_attempt_at = ?, last_error = ? WHERE id = ?;
+
+-- name: PublishOutboxEntry :exec
+UPDATE outbox SET published = published || sqlc.arg(publisher) || ',' WHERE id = sqlc.arg(id);
+
+-- name: CountOutboxEntries :one
+SELECT COUNT(*) FROM outbox;
+
+-- name: GetOldestOutboxEntry :one
+SELECT * FROM outbox ORDER BY id LIMIT 1;
+
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    id, tenant_id, url, events, secret
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions WHERE tenant_id = ? ORDER BY created_at, id LIMIT ? OFFSET ?;
+
+-- name: CountWebhookSubscriptions :one
+SELECT COUNT(*) FROM webhook_subscriptions WHERE tenant_id = ?;
+
+-- name: GetWebhookSubscriptionByID :one
+SELECT * FROM webhook_subscriptions WHERE tenant_id = ? AND id = ?;
+
+-- name: GetSubscribedWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE tenant_id = sqlc.arg(tenant_id) AND ',' || events || ',' LIKE '%,' || CAST(sqlc.arg(event_type) AS text) || ',%'
+ORDER BY created_at, id;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions SET
+    url = COALESCE(sqlc.narg(url), url),
+    events = COALESCE(sqlc.narg(events), events),
+    updated_at = sqlc.arg(updated_at)
+WHERE tenant_id = sqlc.arg(tenant_id) AND id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions WHERE tenant_id = ? AND id = ?;
+
+-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE subscription_id = ?;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    id, tenant_id, subscription_id, event_type, payload, status, attempts, next_attempt_at, status_code, last_error,
+    created_at, updated_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetPendingWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(now)
+ORDER BY next_attempt_at, created_at
+LIMIT sqlc.arg(limit);
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries SET
+    status = ?, attempts = ?, next_attempt_at = ?, status_code = ?, last_error = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE tenant_id = ? AND subscription_id = ?
+ORDER BY created_at DESC, id
+LIMIT ? OFFSET ?;
+
+-- name: CountWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries WHERE tenant_id = ? AND subscription_id = ?;
+
+-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (
+    tenant_id, user_id, actor, action, changes, request_id, trace_id, occurred_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetAuditEntriesByUserID :many
+SELECT * FROM audit_log WHERE tenant_id = ? AND user_id = ? ORDER BY id DESC LIMIT ? OFFSET ?;
+
+-- name: CountAuditEntriesByUserID :one
+SELECT COUNT(*) FROM audit_log WHERE tenant_id = ? AND user_id = ?;
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    id, name, prefix, key_hash, roles
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetAPIKeys :many
+SELECT * FROM api_keys ORDER BY created_at DESC, id LIMIT ? OFFSET ?;
+
+-- name: CountAPIKeys :one
+SELECT COUNT(*) FROM api_keys;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT * FROM api_keys WHERE prefix = ?;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys SET revoked_at = COALESCE(revoked_at, sqlc.arg(revoked_at))
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAPIKeyLastUsedAt :exec
+UPDATE api_keys SET last_used_at = ? WHERE id = ?;
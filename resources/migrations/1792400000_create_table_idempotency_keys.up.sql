@@ -0,0 +1,11 @@
+CREATE TABLE idempotency_keys
+(
+    scope       text      NOT NULL,
+    key         text      NOT NULL,
+    fingerprint text      NOT NULL,
+    status_code integer,
+    response    blob,
+    created_at  timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at  timestamp NOT NULL,
+    PRIMARY KEY (scope, key)
+)
@@ -0,0 +1,13 @@
+CREATE TABLE outbox
+(
+    id              integer   NOT NULL PRIMARY KEY AUTOINCREMENT,
+    aggregate_id    uuid      NOT NULL,
+    event_type      text      NOT NULL,
+    payload         blob      NOT NULL,
+    created_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts        integer   NOT NULL DEFAULT 0,
+    next_attempt_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error      text
+);
+
+CREATE INDEX outbox_aggregate_id ON outbox (aggregate_id, id);
@@ -0,0 +1,27 @@
+CREATE TABLE webhook_subscriptions
+(
+    id         uuid      NOT NULL PRIMARY KEY,
+    url        text      NOT NULL,
+    events     text      NOT NULL,
+    secret     text      NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id              uuid      NOT NULL PRIMARY KEY,
+    subscription_id uuid      NOT NULL,
+    event_type      text      NOT NULL,
+    payload         blob      NOT NULL,
+    status          text      NOT NULL,
+    attempts        integer   NOT NULL DEFAULT 0,
+    next_attempt_at timestamp NOT NULL,
+    status_code     integer,
+    last_error      text,
+    created_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX webhook_deliveries_pending ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id, created_at);
@@ -0,0 +1,23 @@
+CREATE TABLE audit_log
+(
+    id          integer   NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id     uuid      NOT NULL,
+    actor       text      NOT NULL,
+    action      text      NOT NULL,
+    changes     blob      NOT NULL,
+    request_id  text      NOT NULL,
+    trace_id    text      NOT NULL,
+    occurred_at timestamp NOT NULL
+);
+
+CREATE INDEX audit_log_user_id ON audit_log (user_id, id);
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
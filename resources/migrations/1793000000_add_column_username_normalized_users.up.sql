@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN username_normalized text NOT NULL DEFAULT '';
+
+UPDATE users SET username_normalized = lower(username);
+
+CREATE UNIQUE INDEX users_username_normalized ON users (username_normalized);
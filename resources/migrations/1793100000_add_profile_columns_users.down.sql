@@ -0,0 +1,6 @@
+DROP INDEX users_email_normalized;
+ALTER TABLE users DROP COLUMN avatar_url;
+ALTER TABLE users DROP COLUMN locale;
+ALTER TABLE users DROP COLUMN display_name;
+ALTER TABLE users DROP COLUMN email_normalized;
+ALTER TABLE users DROP COLUMN email;
@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN email text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN email_normalized text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN display_name text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN locale text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_url text NOT NULL DEFAULT '';
+
+-- The email is optional, so only the users with one are unique.
+CREATE UNIQUE INDEX users_email_normalized ON users (email_normalized) WHERE email_normalized != '';
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN email_verified_at timestamp;
+
+CREATE TABLE user_tokens
+(
+    token_hash text      NOT NULL PRIMARY KEY,
+    user_id    uuid      NOT NULL,
+    purpose    text      NOT NULL,
+    -- email is the normalized email the token was sent to, the email verification tokens can only verify it.
+    email      text      NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at timestamp NOT NULL
+);
+
+CREATE INDEX user_tokens_user_id_purpose ON user_tokens (user_id, purpose);
+CREATE INDEX user_tokens_expires_at ON user_tokens (expires_at);
@@ -0,0 +1,15 @@
+CREATE TABLE login_attempts
+(
+    -- scope is what the failed logins are tracked by, username or ip, and value the normalized username or the ip.
+    scope           text      NOT NULL,
+    value           text      NOT NULL,
+    failures        integer   NOT NULL,
+    last_failure_at timestamp NOT NULL,
+    retry_at        timestamp NOT NULL,
+    locked_until    timestamp,
+    -- expires_at is when the failed logins are forgotten, they left the window and the lockout ended.
+    expires_at      timestamp NOT NULL,
+    PRIMARY KEY (scope, value)
+);
+
+CREATE INDEX login_attempts_expires_at ON login_attempts (expires_at);
@@ -0,0 +1,12 @@
+CREATE TABLE api_keys
+(
+    id           uuid      NOT NULL PRIMARY KEY,
+    name         text      NOT NULL,
+    -- prefix is the start of the key, that identifies it without disclosing it.
+    prefix       text      NOT NULL UNIQUE,
+    key_hash     text      NOT NULL,
+    roles        text      NOT NULL,
+    created_at   timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at timestamp,
+    revoked_at   timestamp
+);
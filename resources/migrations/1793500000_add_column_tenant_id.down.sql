@@ -0,0 +1,42 @@
+-- The usernames are unique again across the tenants, so only the users of the default tenant are kept.
+CREATE TABLE users_tenants
+(
+    id                  uuid      NOT NULL,
+    created_at          timestamp DEFAULT CURRENT_TIMESTAMP,
+    updated_at          timestamp DEFAULT CURRENT_TIMESTAMP,
+    username            text      NOT NULL UNIQUE,
+    password            text      NOT NULL,
+    version             integer   NOT NULL DEFAULT 1,
+    deleted_at          timestamp,
+    username_normalized text      NOT NULL DEFAULT '',
+    email               text      NOT NULL DEFAULT '',
+    email_normalized    text      NOT NULL DEFAULT '',
+    display_name        text      NOT NULL DEFAULT '',
+    locale              text      NOT NULL DEFAULT '',
+    avatar_url          text      NOT NULL DEFAULT '',
+    email_verified_at   timestamp,
+    PRIMARY KEY (id)
+);
+
+INSERT INTO users_tenants (
+    id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized,
+    display_name, locale, avatar_url, email_verified_at
+)
+SELECT id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized,
+       display_name, locale, avatar_url, email_verified_at
+FROM users
+WHERE tenant_id = 'default';
+
+DROP TABLE users;
+
+ALTER TABLE users_tenants RENAME TO users;
+
+CREATE INDEX users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX users_username_normalized ON users (username_normalized);
+-- The email is optional, so only the users with one are unique.
+CREATE UNIQUE INDEX users_email_normalized ON users (email_normalized) WHERE email_normalized != '';
+
+ALTER TABLE audit_log DROP COLUMN tenant_id;
+
+DELETE FROM login_attempts WHERE scope = 'username' AND value NOT LIKE 'default/%';
+UPDATE login_attempts SET value = substr(value, length('default/') + 1) WHERE scope = 'username';
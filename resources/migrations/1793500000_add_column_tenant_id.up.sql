@@ -0,0 +1,43 @@
+-- SQLite can't drop the unique constraint of the username, so the table is rebuilt with the usernames and the emails
+-- unique per tenant. The existing users belong to the default tenant.
+CREATE TABLE users_tenants
+(
+    id                  uuid      NOT NULL,
+    tenant_id           text      NOT NULL DEFAULT 'default',
+    created_at          timestamp DEFAULT CURRENT_TIMESTAMP,
+    updated_at          timestamp DEFAULT CURRENT_TIMESTAMP,
+    username            text      NOT NULL,
+    password            text      NOT NULL,
+    version             integer   NOT NULL DEFAULT 1,
+    deleted_at          timestamp,
+    username_normalized text      NOT NULL DEFAULT '',
+    email               text      NOT NULL DEFAULT '',
+    email_normalized    text      NOT NULL DEFAULT '',
+    display_name        text      NOT NULL DEFAULT '',
+    locale              text      NOT NULL DEFAULT '',
+    avatar_url          text      NOT NULL DEFAULT '',
+    email_verified_at   timestamp,
+    PRIMARY KEY (id)
+);
+
+INSERT INTO users_tenants (
+    id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized,
+    display_name, locale, avatar_url, email_verified_at
+)
+SELECT id, created_at, updated_at, username, password, version, deleted_at, username_normalized, email, email_normalized,
+       display_name, locale, avatar_url, email_verified_at
+FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_tenants RENAME TO users;
+
+CREATE INDEX users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX users_username_normalized ON users (tenant_id, username_normalized);
+-- The email is optional, so only the users with one are unique.
+CREATE UNIQUE INDEX users_email_normalized ON users (tenant_id, email_normalized) WHERE email_normalized != '';
+
+ALTER TABLE audit_log ADD COLUMN tenant_id text NOT NULL DEFAULT 'default';
+
+-- The failed logins of the usernames are tracked per tenant too.
+UPDATE login_attempts SET value = 'default/' || value WHERE scope = 'username';
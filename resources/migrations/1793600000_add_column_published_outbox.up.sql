@@ -0,0 +1,2 @@
+-- The publishers that already delivered the event, so the retries only deliver it to the ones that failed.
+ALTER TABLE outbox ADD COLUMN published text NOT NULL DEFAULT '';
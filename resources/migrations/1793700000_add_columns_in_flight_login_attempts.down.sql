@@ -0,0 +1,2 @@
+ALTER TABLE login_attempts DROP COLUMN in_flight_until;
+ALTER TABLE login_attempts DROP COLUMN in_flight;
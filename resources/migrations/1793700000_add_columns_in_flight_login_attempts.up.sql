@@ -0,0 +1,4 @@
+-- in_flight is the number of logins reserved whose password is being checked, until in_flight_until,
+-- so the concurrent logins can't bypass the delays and the lockouts.
+ALTER TABLE login_attempts ADD COLUMN in_flight integer NOT NULL DEFAULT 0;
+ALTER TABLE login_attempts ADD COLUMN in_flight_until timestamp;
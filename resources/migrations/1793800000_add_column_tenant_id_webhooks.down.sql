@@ -0,0 +1,4 @@
+DROP INDEX webhook_subscriptions_tenant_id;
+
+ALTER TABLE webhook_deliveries DROP COLUMN tenant_id;
+ALTER TABLE webhook_subscriptions DROP COLUMN tenant_id;
@@ -0,0 +1,6 @@
+-- The webhooks are notified of the events of the users of their tenant only.
+-- The existing subscriptions, and their deliveries, belong to the default tenant.
+ALTER TABLE webhook_subscriptions ADD COLUMN tenant_id text NOT NULL DEFAULT 'default';
+ALTER TABLE webhook_deliveries ADD COLUMN tenant_id text NOT NULL DEFAULT 'default';
+
+CREATE INDEX webhook_subscriptions_tenant_id ON webhook_subscriptions (tenant_id, created_at);